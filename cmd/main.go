@@ -6,17 +6,36 @@ import (
 	netHttp "net/http"
 	"os/signal"
 	"regexp"
+	"sync"
 	"syscall"
 	"time"
+	_ "time/tzdata" // recurrence rules need IANA zones even without system tzdata
 
 	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	authRepo "todo-app/internal/auth/infrastructure/repository"
+	calendarDomain "todo-app/internal/calendar/domain"
+	calendarRepo "todo-app/internal/calendar/infrastructure/repository"
 	"todo-app/internal/config"
+	linkDomain "todo-app/internal/link/domain"
+	linkRepo "todo-app/internal/link/infrastructure/repository"
+	projectDomain "todo-app/internal/project/domain"
+	projectRepo "todo-app/internal/project/infrastructure/repository"
+	reminderDomain "todo-app/internal/reminder/domain"
+	reminderNotifier "todo-app/internal/reminder/infrastructure/notifier"
+	reminderRepo "todo-app/internal/reminder/infrastructure/repository"
+	reminderUsecase "todo-app/internal/reminder/usecase"
 	"todo-app/internal/server"
+	todoDomain "todo-app/internal/todo/domain"
+	todoBlob "todo-app/internal/todo/infrastructure/blob"
 	todoRepo "todo-app/internal/todo/infrastructure/repository"
+	todoUsecase "todo-app/internal/todo/usecase"
+	webhookDomain "todo-app/internal/webhook/domain"
+	webhookRepo "todo-app/internal/webhook/infrastructure/repository"
+	webhookSender "todo-app/internal/webhook/infrastructure/sender"
+	webhookUsecase "todo-app/internal/webhook/usecase"
 )
 
 func main() {
@@ -52,11 +71,103 @@ func main() {
 		log.Printf("Auth repository: memory")
 	}
 
+	// Select todo repository implementation based on config
+	var todoRepository todoDomain.TodoRepository
+	var historyRepository todoDomain.HistoryRepository
+	var shareRepository todoDomain.ShareRepository
+	var commentRepository todoDomain.CommentRepository
+	var reminderRepository reminderDomain.ReminderRepository
+	var webhookRepository webhookDomain.SubscriptionRepository
+	var deliveryRepository webhookDomain.DeliveryRepository
+	var feedRepository calendarDomain.FeedRepository
+	var projectRepository projectDomain.ProjectRepository
+	var linkRepository linkDomain.LinkRepository
+	if cfg.TodoRepo == "memory" {
+		todoRepository = todoRepo.NewMemoryTodoRepository()
+		historyRepository = todoRepo.NewMemoryHistoryRepository()
+		shareRepository = todoRepo.NewMemoryShareRepository()
+		commentRepository = todoRepo.NewMemoryCommentRepository()
+		reminderRepository = reminderRepo.NewMemoryReminderRepository()
+		webhookRepository = webhookRepo.NewMemorySubscriptionRepository()
+		deliveryRepository = webhookRepo.NewMemoryDeliveryRepository()
+		feedRepository = calendarRepo.NewMemoryFeedRepository()
+		projectRepository = projectRepo.NewMemoryProjectRepository()
+		linkRepository = linkRepo.NewMemoryLinkRepository()
+		log.Printf("Todo repository: memory")
+	} else {
+		todoRepository = todoRepo.NewMongoTodoRepository(db)
+		historyRepository = todoRepo.NewMongoHistoryRepository(db)
+		shareRepository = todoRepo.NewMongoShareRepository(db)
+		commentRepository = todoRepo.NewMongoCommentRepository(db)
+		reminderRepository = reminderRepo.NewMongoReminderRepository(db)
+		webhookRepository = webhookRepo.NewMongoSubscriptionRepository(db)
+		deliveryRepository = webhookRepo.NewMongoDeliveryRepository(db)
+		feedRepository = calendarRepo.NewMongoFeedRepository(db)
+		projectRepository = projectRepo.NewMongoProjectRepository(db)
+		linkRepository = linkRepo.NewMongoLinkRepository(db)
+		log.Printf("Todo repository: mongo (db=%s)", cfg.MongoDB)
+	}
+
+	// Reminder channels; email is only offered when SMTP is configured
+	notifiers := map[string]reminderDomain.Notifier{
+		reminderDomain.ChannelLog:     reminderNotifier.LogNotifier{},
+		reminderDomain.ChannelWebhook: reminderNotifier.NewWebhookNotifier(10 * time.Second),
+	}
+	if cfg.SMTPAddr != "" {
+		notifiers[reminderDomain.ChannelEmail] = &reminderNotifier.EmailNotifier{Mailer: &reminderNotifier.SMTPMailer{
+			Addr:     cfg.SMTPAddr,
+			From:     cfg.SMTPFrom,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+		}}
+	}
+
+	// Attachment content goes to a directory, or to GridFS next to the todos
+	var blobStore todoDomain.BlobStore
+	if cfg.AttachmentStore == "gridfs" {
+		blobStore, err = todoBlob.NewGridFSBlobStore(db)
+		log.Printf("Attachment store: gridfs (db=%s)", cfg.MongoDB)
+	} else {
+		blobStore, err = todoBlob.NewLocalBlobStore(cfg.AttachmentDir)
+		log.Printf("Attachment store: local (dir=%s)", cfg.AttachmentDir)
+	}
+	if err != nil {
+		log.Fatalf("attachment store: %v", err)
+	}
+	attachmentLimits := todoDomain.AttachmentLimits{MaxSize: int64(cfg.AttachmentMaxSize), ContentTypes: cfg.AttachmentTypes}
+
+	// Live clients of GET /todos/events are disconnected on shutdown, so
+	// that the open streams do not hold it up
+	events := todoUsecase.NewBroker(cfg.EventReplaySize)
+
+	// Expired trash is purged through the todo use case, which takes what
+	// hangs off the todos with them
+	trashSweeper := todoUsecase.NewTrashSweeper(cfg.TrashRetention, time.Minute)
+	// Reminders are only sent while their owners can still see the todo,
+	// which the dispatcher asks the todo use case about
+	reminders := reminderUsecase.NewDispatcher(reminderRepository, todoRepository, notifiers, cfg.ReminderInterval, cfg.ReminderMaxAttempts)
+
 	deps := server.Deps{
-		JWTSecret: cfg.JWTSecret,
-		AuthRepo:  authRepository,
-		TokenGen:  &authRepo.JWTTokenGenerator{Secret: cfg.JWTSecret},
-		TodoRepo:  todoRepo.NewMongoTodoRepository(db),
+		JWTSecret:    cfg.JWTSecret,
+		AuthRepo:     authRepository,
+		TokenGen:     &authRepo.JWTTokenGenerator{Secret: cfg.JWTSecret},
+		TodoRepo:     todoRepository,
+		HistoryRepo:  historyRepository,
+		ShareRepo:    shareRepository,
+		CommentRepo:  commentRepository,
+		ReminderRepo: reminderRepository,
+		Notifiers:    notifiers,
+
+		WebhookRepo:         webhookRepository,
+		WebhookDeliveryRepo: deliveryRepository,
+		FeedRepo:            feedRepository,
+		ProjectRepo:         projectRepository,
+		LinkRepo:            linkRepository,
+		BlobStore:           blobStore,
+		AttachmentLimits:    attachmentLimits,
+		Events:              events,
+		TrashSweeper:        trashSweeper,
+		Reminders:           reminders,
 	}
 
 	h := server.NewHandler(deps)
@@ -65,6 +176,24 @@ func main() {
 		Addr:    cfg.ServerAddress,
 		Handler: h,
 	}
+	srv.RegisterOnShutdown(events.Close)
+
+	// Background workers stop when ctx is cancelled
+	var workers sync.WaitGroup
+	webhooks := webhookUsecase.NewDispatcher(webhookRepository, deliveryRepository, webhookSender.NewHTTPSender(10*time.Second),
+		cfg.WebhookInterval, cfg.WebhookMaxAttempts, cfg.WebhookDisableAfter)
+	for _, run := range []func(context.Context){reminders.Run, webhooks.Run, trashSweeper.Run} {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(ctx)
+		}()
+	}
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
 
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != netHttp.ErrServerClosed {
@@ -83,6 +212,13 @@ func main() {
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Printf("server forced to shutdown: %v", err)
 	}
+	// the workers stopped claiming when the signal arrived; wait for the
+	// deliveries in flight so they are recorded
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		log.Printf("background workers did not stop in time")
+	}
 
 	log.Println("Server exiting")
 }
@@ -14,7 +14,8 @@ import (
 )
 
 // HTTPLogger is a top-level chi middleware.
-// Logs request metadata, optional JSON request body, status, latency and JSON response body.
+// Logs request metadata, optional JSON request body, status, latency and JSON response body,
+// with secrets such as passwords and feed or link tokens redacted.
 // Place early (r.Use) to reliably capture raw request & response.
 func HTTPLogger() func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -49,12 +50,12 @@ func HTTPLogger() func(next http.Handler) http.Handler {
 
 			rec := &respRecorder{ResponseWriter: w, status: 200}
 
-			attrs := []any{"id", requestID, "method", r.Method, "path", r.URL.Path}
+			attrs := []any{"id", requestID, "method", r.Method, "path", redactPaths(r.URL.Path)}
 			if r.URL.RawQuery != "" {
 				attrs = append(attrs, "query", r.URL.RawQuery)
 			}
 			if len(reqBodyPreview) > 0 {
-				attrs = append(attrs, slog.String("json_body", reqBodyPreview))
+				attrs = append(attrs, slog.String("json_body", redactBody(reqBodyPreview)))
 			}
 			slog.Info("http request", attrs...)
 
@@ -70,7 +71,7 @@ func HTTPLogger() func(next http.Handler) http.Handler {
 					truncated = append(truncated, []byte("... (truncated)")...)
 					snippet = truncated
 				}
-				endAttrs = append(endAttrs, "response_body", string(snippet))
+				endAttrs = append(endAttrs, "response_body", redactBody(string(snippet)))
 			}
 			slog.Info("http request", endAttrs...)
 		})
@@ -88,11 +89,28 @@ func (r *respRecorder) WriteHeader(code int) {
 	r.ResponseWriter.WriteHeader(code)
 }
 
+// maxLoggedBody caps how much of a response is kept for the log, so that
+// long-lived streams do not grow without bound.
+const maxLoggedBody = 1025
+
 func (r *respRecorder) Write(b []byte) (int, error) {
-	r.body = append(r.body, b...)
+	if room := maxLoggedBody - len(r.body); room > 0 {
+		r.body = append(r.body, b[:min(room, len(b))]...)
+	}
 	return r.ResponseWriter.Write(b)
 }
 
+// Unwrap exposes the underlying writer so that streaming handlers can reach
+// its Flusher, e.g. through http.ResponseController.
+func (r *respRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// SetWriteDeadline lets streaming handlers extend the deadline per write.
+func (r *respRecorder) SetWriteDeadline(t time.Time) error {
+	return http.NewResponseController(r.ResponseWriter).SetWriteDeadline(t)
+}
+
 func allowBody(m string) bool {
 	switch strings.ToUpper(m) {
 	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
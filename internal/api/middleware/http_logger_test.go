@@ -0,0 +1,60 @@
+package middleware_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-app/internal/api/middleware"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// logRequest serves one request through the logger and returns what it
+// logged.
+func logRequest(t *testing.T, req *http.Request, response string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	h := middleware.HTTPLogger()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, response)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	return buf.String()
+}
+
+func TestHTTPLogger_RedactsFeedTokens(t *testing.T) {
+	logged := logRequest(t, httptest.NewRequest(http.MethodGet, "/calendar/feedsecret/todos.ics", nil), "")
+	assert.NotContains(t, logged, "feedsecret")
+	assert.Contains(t, logged, "/calendar/[redacted]/todos.ics")
+
+	req := httptest.NewRequest(http.MethodPost, "/calendar/feed/rotate", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	logged = logRequest(t, req, `{"feed":{},"path":"/calendar/feedsecret/todos.ics"}`)
+	assert.NotContains(t, logged, "feedsecret")
+}
+
+func TestHTTPLogger_RedactsLinkSecrets(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/links", strings.NewReader(`{"todoId":"a","password":"open \"sesame\""}`))
+	req.Header.Set("Content-Type", "application/json")
+	logged := logRequest(t, req, `{"link":{"id":"l1","token":"linksecret"},"path":"/public/links/linksecret"}`)
+	assert.NotContains(t, logged, "sesame")
+	assert.NotContains(t, logged, "linksecret")
+	assert.Contains(t, logged, "l1", "the rest of the body is still logged")
+
+	logged = logRequest(t, httptest.NewRequest(http.MethodGet, "/public/links/linksecret?page=0", nil), "")
+	assert.NotContains(t, logged, "linksecret")
+	assert.Contains(t, logged, "/public/links/[redacted]")
+
+	// a value cut off by the truncated body is redacted all the same
+	long := `{"data":"` + strings.Repeat("x", 1000) + `","token":"linksecret"}`
+	logged = logRequest(t, httptest.NewRequest(http.MethodGet, "/links", nil), long)
+	assert.NotContains(t, logged, "linksec")
+}
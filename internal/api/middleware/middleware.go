@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -10,6 +11,15 @@ import (
 	"github.com/lestrrat-go/jwx/v3/jwt"
 )
 
+type userIDKey struct{}
+
+// UserID returns the username of the authenticated caller, or "" if the
+// request was not authenticated.
+func UserID(ctx context.Context) string {
+	v, _ := ctx.Value(userIDKey{}).(string)
+	return v
+}
+
 func NewAuthMiddleware(api huma.API, secret []byte) func(ctx huma.Context, next func(huma.Context)) {
 
 	return func(ctx huma.Context, next func(huma.Context)) {
@@ -48,6 +58,8 @@ func NewAuthMiddleware(api huma.API, secret []byte) func(ctx huma.Context, next
 		}
 		fmt.Printf("Parsed JWT: %v\n", parsed)
 
-		next(ctx)
+		var userID string
+		_ = parsed.Get("user_id", &userID)
+		next(huma.WithValue(ctx, userIDKey{}, userID))
 	}
 }
@@ -66,3 +66,35 @@ func TestNewAuthMiddleware(t *testing.T) {
 	})
 	require.Equal(t, 204, resp.Code)
 }
+
+func TestNewAuthMiddleware_UserID(t *testing.T) {
+	config := huma.DefaultConfig("Todo API", "1.0.0")
+	config.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
+		"myAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+	}
+	_, api := humatest.New(t, config)
+	secret := []byte("your-256")
+	api.UseMiddleware(middleware.NewAuthMiddleware(api, secret))
+
+	type whoamiResp struct {
+		Body struct {
+			User string `json:"user"`
+		}
+	}
+	huma.Register(api, huma.Operation{
+		OperationID: "whoami",
+		Method:      http.MethodGet,
+		Path:        "/whoami",
+		Security:    []map[string][]string{{"myAuth": {}}},
+	}, func(ctx context.Context, i *struct{}) (*whoamiResp, error) {
+		resp := &whoamiResp{}
+		resp.Body.User = middleware.UserID(ctx)
+		return resp, nil
+	})
+
+	token, err := (&repository.JWTTokenGenerator{Secret: string(secret)}).Generate(domain.AuthUser{Username: "alice"})
+	require.NoError(t, err)
+	resp := api.Get("/whoami", "Authorization: Bearer "+token)
+	require.Equal(t, 200, resp.Code)
+	require.Contains(t, resp.Body.String(), `"user":"alice"`)
+}
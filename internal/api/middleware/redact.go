@@ -0,0 +1,34 @@
+package middleware
+
+import "regexp"
+
+// redacted stands in for secrets in the log.
+const redacted = "[redacted]"
+
+// secretPaths match the URL paths whose secret segment lets anyone who
+// reads it in the log read somebody's todos, such as calendar feed URLs
+// and public share links.
+var secretPaths = []*regexp.Regexp{
+	regexp.MustCompile(`(/calendar/)[^/"?\s]+(/todos\.ics)`),
+	regexp.MustCompile(`(/public/links/)[^/"?\s]+()`),
+}
+
+// secretField matches a JSON string field whose name says it holds a
+// password, token or secret, up to the end of its value, or of the text
+// when a truncated body cuts the value off.
+var secretField = regexp.MustCompile(`("[^"]*(?i:password|token|secret)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*(?:"|$)`)
+
+// redactBody replaces the values of secret fields in a JSON body, and the
+// secrets in the paths it mentions.
+func redactBody(s string) string {
+	return redactPaths(secretField.ReplaceAllString(s, `${1}"`+redacted+`"`))
+}
+
+// redactPaths replaces the secret segment of the URL paths found in s,
+// both request paths and paths that response bodies hand out.
+func redactPaths(s string) string {
+	for _, re := range secretPaths {
+		s = re.ReplaceAllString(s, "${1}"+redacted+"${2}")
+	}
+	return s
+}
@@ -0,0 +1,175 @@
+// Package problem turns errors into RFC 7807 problem responses. It is the
+// single place where domain errors are given an HTTP status and a stable,
+// machine-readable code, so handlers can simply return what the use cases
+// return.
+package problem
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/danielgtaylor/huma/v2"
+
+	authDomain "todo-app/internal/auth/domain"
+	calendarDomain "todo-app/internal/calendar/domain"
+	linkDomain "todo-app/internal/link/domain"
+	projectDomain "todo-app/internal/project/domain"
+	reminderDomain "todo-app/internal/reminder/domain"
+	todoDomain "todo-app/internal/todo/domain"
+	webhookDomain "todo-app/internal/webhook/domain"
+)
+
+// Problem is a huma error model extended with a stable error code that
+// clients can switch on instead of parsing human-readable messages.
+type Problem struct {
+	huma.ErrorModel
+	Code string `json:"code" example:"todo_not_found" doc:"Stable machine-readable error code"`
+}
+
+// mapping ties a domain sentinel error to its HTTP representation.
+type mapping struct {
+	target error
+	status int
+	code   string
+}
+
+var mappings = []mapping{
+	{todoDomain.ErrNotFound, http.StatusNotFound, "todo_not_found"},
+	{todoDomain.ErrChecklistItemNotFound, http.StatusNotFound, "checklist_item_not_found"},
+	{todoDomain.ErrNotRecurring, http.StatusConflict, "todo_not_recurring"},
+	{todoDomain.ErrSeriesEnded, http.StatusConflict, "series_ended"},
+	{todoDomain.ErrConflict, http.StatusConflict, "todo_conflict"},
+	{todoDomain.ErrVersionConflict, http.StatusPreconditionFailed, "todo_version_conflict"},
+	{todoDomain.ErrForbidden, http.StatusForbidden, "todo_forbidden"},
+	{todoDomain.ErrShareNotFound, http.StatusNotFound, "share_not_found"},
+	{todoDomain.ErrCommentNotFound, http.StatusNotFound, "comment_not_found"},
+	{todoDomain.ErrNotCommentAuthor, http.StatusForbidden, "comment_forbidden"},
+	{todoDomain.ErrAttachmentNotFound, http.StatusNotFound, "attachment_not_found"},
+	{todoDomain.ErrAttachmentTooLarge, http.StatusRequestEntityTooLarge, "attachment_too_large"},
+	{todoDomain.ErrAttachmentType, http.StatusUnsupportedMediaType, "attachment_type_unsupported"},
+	{reminderDomain.ErrNotFound, http.StatusNotFound, "reminder_not_found"},
+	{webhookDomain.ErrNotFound, http.StatusNotFound, "webhook_not_found"},
+	{webhookDomain.ErrDeliveryNotFound, http.StatusNotFound, "webhook_delivery_not_found"},
+	{webhookDomain.ErrDisabled, http.StatusConflict, "webhook_disabled"},
+	{projectDomain.ErrNotFound, http.StatusNotFound, "project_not_found"},
+	{projectDomain.ErrArchived, http.StatusConflict, "project_archived"},
+	{calendarDomain.ErrFeedNotFound, http.StatusNotFound, "calendar_feed_not_found"},
+	{linkDomain.ErrNotFound, http.StatusNotFound, "share_link_not_found"},
+	{linkDomain.ErrExpired, http.StatusGone, "share_link_expired"},
+	{linkDomain.ErrPassword, http.StatusUnauthorized, "share_link_password"},
+	{authDomain.ErrUserNotFound, http.StatusNotFound, "user_not_found"},
+	{authDomain.ErrUserExists, http.StatusConflict, "user_exists"},
+	{authDomain.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+}
+
+// statusCodes are the codes used for errors that are not domain errors,
+// e.g. request validation done by huma or the auth middleware.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            "bad_request",
+	http.StatusUnauthorized:          "unauthorized",
+	http.StatusForbidden:             "forbidden",
+	http.StatusNotFound:              "not_found",
+	http.StatusMethodNotAllowed:      "method_not_allowed",
+	http.StatusNotAcceptable:         "not_acceptable",
+	http.StatusConflict:              "conflict",
+	http.StatusGone:                  "gone",
+	http.StatusPreconditionFailed:    "precondition_failed",
+	http.StatusRequestEntityTooLarge: "payload_too_large",
+	http.StatusUnsupportedMediaType:  "unsupported_media_type",
+	http.StatusUnprocessableEntity:   "validation_failed",
+	http.StatusFailedDependency:      "failed_dependency",
+	http.StatusTooManyRequests:       "too_many_requests",
+	http.StatusInternalServerError:   "internal_error",
+	http.StatusNotImplemented:        "not_implemented",
+	http.StatusServiceUnavailable:    "service_unavailable",
+}
+
+// Install replaces huma's error constructors so every error response,
+// including ones huma produces itself, is a Problem. Errors returned from
+// handlers that huma does not recognise are matched against the known
+// domain errors before falling back to 500.
+func Install() {
+	huma.NewError = New
+	huma.NewErrorWithContext = func(_ huma.Context, status int, msg string, errs ...error) huma.StatusError {
+		for _, err := range errs {
+			if p := FromError(err); p != nil {
+				return p
+			}
+		}
+		return New(status, msg, errs...)
+	}
+}
+
+// New builds a Problem for the given status, using the generic code for it.
+func New(status int, msg string, errs ...error) huma.StatusError {
+	code, ok := statusCodes[status]
+	if !ok {
+		code = "error"
+	}
+	return newProblem(status, code, msg, errs...)
+}
+
+// FromError returns the Problem for a known domain error, or nil if err is
+// not one.
+func FromError(err error) *Problem {
+	var todoInvalid *todoDomain.ValidationError
+	if errors.As(err, &todoInvalid) {
+		return validation(todoInvalid.Field, todoInvalid.Message)
+	}
+	var reminderInvalid *reminderDomain.ValidationError
+	if errors.As(err, &reminderInvalid) {
+		return validation(reminderInvalid.Field, reminderInvalid.Message)
+	}
+	var webhookInvalid *webhookDomain.ValidationError
+	if errors.As(err, &webhookInvalid) {
+		return validation(webhookInvalid.Field, webhookInvalid.Message)
+	}
+	var projectInvalid *projectDomain.ValidationError
+	if errors.As(err, &projectInvalid) {
+		return validation(projectInvalid.Field, projectInvalid.Message)
+	}
+	var linkInvalid *linkDomain.ValidationError
+	if errors.As(err, &linkInvalid) {
+		return validation(linkInvalid.Field, linkInvalid.Message)
+	}
+	var authInvalid *authDomain.ValidationError
+	if errors.As(err, &authInvalid) {
+		return validation(authInvalid.Field, authInvalid.Message)
+	}
+	for _, m := range mappings {
+		if errors.Is(err, m.target) {
+			return newProblem(m.status, m.code, err.Error())
+		}
+	}
+	return nil
+}
+
+func validation(field, message string) *Problem {
+	return newProblem(http.StatusUnprocessableEntity, "validation_failed", "validation failed", &huma.ErrorDetail{
+		Message:  message,
+		Location: "body." + field,
+	})
+}
+
+func newProblem(status int, code, msg string, errs ...error) *Problem {
+	details := make([]*huma.ErrorDetail, 0, len(errs))
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if converted, ok := err.(huma.ErrorDetailer); ok {
+			details = append(details, converted.ErrorDetail())
+			continue
+		}
+		details = append(details, &huma.ErrorDetail{Message: err.Error()})
+	}
+	return &Problem{
+		ErrorModel: huma.ErrorModel{
+			Status: status,
+			Title:  http.StatusText(status),
+			Detail: msg,
+			Errors: details,
+		},
+		Code: code,
+	}
+}
@@ -0,0 +1,63 @@
+package problem_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"todo-app/internal/api/problem"
+	authDomain "todo-app/internal/auth/domain"
+	reminderDomain "todo-app/internal/reminder/domain"
+	todoDomain "todo-app/internal/todo/domain"
+	webhookDomain "todo-app/internal/webhook/domain"
+)
+
+func TestFromError(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{todoDomain.ErrNotFound, http.StatusNotFound, "todo_not_found"},
+		{fmt.Errorf("load: %w", todoDomain.ErrNotFound), http.StatusNotFound, "todo_not_found"},
+		{todoDomain.ErrVersionConflict, http.StatusPreconditionFailed, "todo_version_conflict"},
+		{todoDomain.ErrConflict, http.StatusConflict, "todo_conflict"},
+		{&todoDomain.ValidationError{Field: "title", Message: "must not be empty"}, http.StatusUnprocessableEntity, "validation_failed"},
+		{reminderDomain.ErrNotFound, http.StatusNotFound, "reminder_not_found"},
+		{&reminderDomain.ValidationError{Field: "channel", Message: "must be one of log"}, http.StatusUnprocessableEntity, "validation_failed"},
+		{webhookDomain.ErrDisabled, http.StatusConflict, "webhook_disabled"},
+		{&webhookDomain.ValidationError{Field: "url", Message: "must be an http or https URL"}, http.StatusUnprocessableEntity, "validation_failed"},
+		{authDomain.ErrUserExists, http.StatusConflict, "user_exists"},
+		{authDomain.ErrInvalidCredentials, http.StatusUnauthorized, "invalid_credentials"},
+		{&authDomain.ValidationError{Field: "username", Message: "must not be empty"}, http.StatusUnprocessableEntity, "validation_failed"},
+	}
+	for _, c := range cases {
+		p := problem.FromError(c.err)
+		if assert.NotNil(t, p, c.err.Error()) {
+			assert.Equal(t, c.status, p.GetStatus(), c.err.Error())
+			assert.Equal(t, c.code, p.Code, c.err.Error())
+		}
+	}
+
+	assert.Nil(t, problem.FromError(errors.New("boom")))
+}
+
+func TestFromError_ValidationLocation(t *testing.T) {
+	p := problem.FromError(&todoDomain.ValidationError{Field: "title", Message: "must not be empty"})
+	if assert.Len(t, p.Errors, 1) {
+		assert.Equal(t, "body.title", p.Errors[0].Location)
+		assert.Equal(t, "must not be empty", p.Errors[0].Message)
+	}
+}
+
+func TestNew_GenericCode(t *testing.T) {
+	err := problem.New(http.StatusUnauthorized, "Unauthorized")
+	p, ok := err.(*problem.Problem)
+	if assert.True(t, ok) {
+		assert.Equal(t, "unauthorized", p.Code)
+		assert.Equal(t, "application/problem+json", p.ContentType("application/json"))
+	}
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrUserNotFound is returned when no user exists with the given username.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrUserExists is returned when registering a username that is taken.
+	ErrUserExists = errors.New("user already exists")
+	// ErrInvalidCredentials is returned when a username/password pair does not
+	// identify a user. It deliberately does not say which half was wrong.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// ValidationError reports an auth field that does not satisfy the domain rules.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
@@ -1,7 +1,6 @@
 package repository
 
 import (
-	"errors"
 	"todo-app/internal/auth/domain"
 )
 
@@ -15,7 +14,7 @@ func NewMemoryRepo() domain.AuthRepository {
 
 func (r *memoryRepo) CreateUser(user domain.AuthUser) error {
 	if _, ok := users[user.Username]; ok {
-		return errors.New("user exists")
+		return domain.ErrUserExists
 	}
 	users[user.Username] = user
 	return nil
@@ -23,7 +22,7 @@ func (r *memoryRepo) CreateUser(user domain.AuthUser) error {
 func (r *memoryRepo) GetUserByUsername(username string) (domain.AuthUser, error) {
 	u, ok := users[username]
 	if !ok {
-		return domain.AuthUser{}, errors.New("user [" + username + "] not found")
+		return domain.AuthUser{}, domain.ErrUserNotFound
 	}
 	return u, nil
 }
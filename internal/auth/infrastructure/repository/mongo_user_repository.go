@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"errors"
 	"time"
 	"todo-app/internal/auth/domain"
 
@@ -41,9 +40,9 @@ func (r *MongoAuthRepository) CreateUser(user domain.AuthUser) error {
 		"updatedAt":     time.Now(),
 	})
 	if err != nil {
-		// Normalize duplicate key error to a simple error string as memory repo
+		// Normalize duplicate key error to the same domain error as memory repo
 		if mongo.IsDuplicateKeyError(err) {
-			return errors.New("user exists")
+			return domain.ErrUserExists
 		}
 		return err
 	}
@@ -61,7 +60,7 @@ func (r *MongoAuthRepository) GetUserByUsername(username string) (domain.AuthUse
 	err := r.collection.FindOne(ctx, bson.M{"_id": username}).Decode(&doc)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return domain.AuthUser{}, errors.New("user [" + username + "] not found")
+			return domain.AuthUser{}, domain.ErrUserNotFound
 		}
 		return domain.AuthUser{}, err
 	}
@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"errors"
+	"todo-app/internal/auth/domain"
+)
+
+// Directory tells other modules which users are registered, without
+// handing out their credentials.
+type Directory struct {
+	repo domain.AuthRepository
+}
+
+func NewDirectory(repo domain.AuthRepository) *Directory {
+	return &Directory{repo: repo}
+}
+
+// Exists reports whether username belongs to a registered user.
+func (d *Directory) Exists(username string) (bool, error) {
+	_, err := d.repo.GetUserByUsername(username)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -1,7 +1,7 @@
 package usecase
 
-import "errors"
+import "todo-app/internal/auth/domain"
 
 var (
-	ErrUserExists = errors.New("user already exists")
+	ErrUserExists = domain.ErrUserExists
 )
@@ -28,10 +28,10 @@ func NewLoginUsecase(repo domain.AuthRepository, tokenGen domain.TokenGenerator)
 func (uc *loginUsecase) Login(username, password string) (LoginResult, error) {
 	user, err := uc.repo.GetUserByUsername(username)
 	if err != nil {
-		return LoginResult{}, errors.New("invalid credentials")
+		return LoginResult{}, domain.ErrInvalidCredentials
 	}
 	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
-		return LoginResult{}, errors.New("invalid credentials")
+		return LoginResult{}, domain.ErrInvalidCredentials
 	}
 	tokenString, err := uc.tokenGen.Generate(user)
 	if err != nil {
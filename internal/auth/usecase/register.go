@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"strings"
 	"todo-app/internal/auth/domain"
 
 	"golang.org/x/crypto/bcrypt"
@@ -19,6 +20,12 @@ func NewRegisterUsecase(repo domain.AuthRepository) RegisterUsecase {
 }
 
 func (uc *registerUsecase) Register(username, password string) error {
+	if strings.TrimSpace(username) == "" {
+		return &domain.ValidationError{Field: "username", Message: "must not be empty"}
+	}
+	if password == "" {
+		return &domain.ValidationError{Field: "password", Message: "must not be empty"}
+	}
 	_, err := uc.repo.GetUserByUsername(username)
 	if err == nil {
 		return ErrUserExists
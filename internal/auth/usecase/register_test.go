@@ -82,3 +82,14 @@ func TestRegister_CreateUserFailure(t *testing.T) {
 }
 
 // Note: Simulating bcrypt.GenerateFromPassword error directly is non-trivial without abstraction; skipped.
+
+func TestRegister_Validation(t *testing.T) {
+	uc := usecase.NewRegisterUsecase(&regMockRepo{})
+	var invalid *domain.ValidationError
+	if err := uc.Register(" ", "pwd"); !errors.As(err, &invalid) || invalid.Field != "username" {
+		t.Fatalf("expected username validation error, got %v", err)
+	}
+	if err := uc.Register("someone", ""); !errors.As(err, &invalid) || invalid.Field != "password" {
+		t.Fatalf("expected password validation error, got %v", err)
+	}
+}
@@ -0,0 +1,9 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrFeedNotFound is returned when no calendar feed has the requested
+	// token, e.g. because it has been rotated.
+	ErrFeedNotFound = errors.New("calendar feed not found")
+)
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// Feed is a user's calendar feed. Its Token is the secret in the feed URL:
+// anyone who has the URL can read the feed, so it is unguessable, and
+// rotating it cuts off every calendar subscribed to the old URL.
+type Feed struct {
+	Owner     string    `json:"owner" readOnly:"true" doc:"User whose todos the feed shows"`
+	Token     string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt" readOnly:"true" doc:"When the current token was issued"`
+}
@@ -0,0 +1,10 @@
+package domain
+
+// FeedRepository stores the calendar feeds, one per user.
+type FeedRepository interface {
+	// FindByOwner returns ErrFeedNotFound when the user has no feed yet.
+	FindByOwner(owner string) (*Feed, error)
+	FindByToken(token string) (*Feed, error)
+	// Save creates the user's feed, or replaces it when it exists.
+	Save(feed *Feed) error
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"sync"
+	"todo-app/internal/calendar/domain"
+)
+
+type MemoryFeedRepository struct {
+	mu    sync.Mutex
+	feeds map[string]domain.Feed
+}
+
+func NewMemoryFeedRepository() *MemoryFeedRepository {
+	return &MemoryFeedRepository{feeds: map[string]domain.Feed{}}
+}
+
+func (r *MemoryFeedRepository) FindByOwner(owner string) (*domain.Feed, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	feed, ok := r.feeds[owner]
+	if !ok {
+		return nil, domain.ErrFeedNotFound
+	}
+	return &feed, nil
+}
+
+func (r *MemoryFeedRepository) FindByToken(token string) (*domain.Feed, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, feed := range r.feeds {
+		if feed.Token == token {
+			return &feed, nil
+		}
+	}
+	return nil, domain.ErrFeedNotFound
+}
+
+func (r *MemoryFeedRepository) Save(feed *domain.Feed) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.feeds[feed.Owner] = *feed
+	return nil
+}
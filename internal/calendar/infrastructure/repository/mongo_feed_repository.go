@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"todo-app/internal/calendar/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoFeedRepository implements domain.FeedRepository using MongoDB.
+type MongoFeedRepository struct {
+	collection *mongo.Collection
+}
+
+// feedDocument is the persisted shape of a feed in the "calendar_feeds"
+// collection, keyed by its owner.
+type feedDocument struct {
+	Owner     string    `bson:"_id"`
+	Token     string    `bson:"token"`
+	CreatedAt time.Time `bson:"createdAt"`
+}
+
+// NewMongoFeedRepository creates the repository and ensures a unique index
+// for looking feeds up by token.
+func NewMongoFeedRepository(db *mongo.Database) *MongoFeedRepository {
+	coll := db.Collection("calendar_feeds")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "token", Value: 1}},
+		Options: options.Index().SetName("token").SetUnique(true),
+	})
+	if err != nil {
+		log.Printf("calendar feeds: ensure token index: %v", err)
+	}
+	return &MongoFeedRepository{collection: coll}
+}
+
+func (r *MongoFeedRepository) FindByOwner(owner string) (*domain.Feed, error) {
+	return r.findOne(bson.M{"_id": owner})
+}
+
+func (r *MongoFeedRepository) FindByToken(token string) (*domain.Feed, error) {
+	return r.findOne(bson.M{"token": token})
+}
+
+func (r *MongoFeedRepository) findOne(filter bson.M) (*domain.Feed, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var doc feedDocument
+	err := r.collection.FindOne(ctx, filter).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrFeedNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Feed{Owner: doc.Owner, Token: doc.Token, CreatedAt: doc.CreatedAt}, nil
+}
+
+func (r *MongoFeedRepository) Save(feed *domain.Feed) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": feed.Owner},
+		feedDocument{Owner: feed.Owner, Token: feed.Token, CreatedAt: feed.CreatedAt},
+		options.Replace().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,131 @@
+package caldav
+
+import (
+	"strings"
+	"time"
+	"todo-app/internal/todo/domain"
+)
+
+// icalTime is the UTC date-time form of iCalendar, used by time ranges.
+const icalTime = "20060102T150405Z"
+
+// matches reports whether the todo, as a calendar object holding one
+// VTODO, passes a calendar-query filter. Filters on components other than
+// VTODO only match when they ask for the component not to be there.
+func matches(f compFilter, todo *domain.Todo) bool {
+	if !strings.EqualFold(f.Name, "VCALENDAR") || f.IsNotDefined != nil {
+		return false
+	}
+	for _, comp := range f.Comps {
+		if !strings.EqualFold(comp.Name, "VTODO") {
+			if comp.IsNotDefined == nil {
+				return false
+			}
+			continue
+		}
+		if !matchesTodo(comp, todo) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchesTodo(f compFilter, todo *domain.Todo) bool {
+	if f.IsNotDefined != nil {
+		return false
+	}
+	if f.TimeRange != nil && !todoInRange(*f.TimeRange, todo) {
+		return false
+	}
+	for _, comp := range f.Comps {
+		// todos carry no alarms or other nested components
+		if comp.IsNotDefined == nil {
+			return false
+		}
+	}
+	props := todoProperties(todo)
+	times := todoTimes(todo)
+	for _, pf := range f.Props {
+		name := strings.ToUpper(pf.Name)
+		value, defined := props[name]
+		switch {
+		case pf.IsNotDefined != nil:
+			if defined {
+				return false
+			}
+		case !defined:
+			return false
+		case pf.TimeRange != nil:
+			at, ok := times[name]
+			if !ok || !inRange(*pf.TimeRange, at) {
+				return false
+			}
+		case pf.TextMatch != nil:
+			if textMatches(*pf.TextMatch, value) == (pf.TextMatch.Negate == "yes") {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// todoProperties are the VTODO properties a todo is written with, by name.
+func todoProperties(todo *domain.Todo) map[string]string {
+	props := map[string]string{"UID": todo.ID, "SUMMARY": todo.Title, "STATUS": "NEEDS-ACTION"}
+	if todo.Done {
+		props["STATUS"] = "COMPLETED"
+	}
+	for name, at := range todoTimes(todo) {
+		props[name] = at.UTC().Format(icalTime)
+	}
+	if len(todo.Tags) > 0 {
+		props["CATEGORIES"] = strings.Join(todo.Tags, ",")
+	}
+	return props
+}
+
+func todoTimes(todo *domain.Todo) map[string]time.Time {
+	times := map[string]time.Time{}
+	if !todo.DueDate.IsZero() {
+		times["DUE"] = todo.DueDate
+	}
+	if todo.Done && todo.CompletedAt != nil {
+		times["COMPLETED"] = *todo.CompletedAt
+	}
+	return times
+}
+
+// todoInRange applies a time range to a VTODO the way RFC 4791 does for
+// the properties a todo has: by its due date, else by its completion, and
+// a todo with neither is always in range.
+func todoInRange(tr timeRange, todo *domain.Todo) bool {
+	times := todoTimes(todo)
+	if due, ok := times["DUE"]; ok {
+		return inRange(tr, due)
+	}
+	if completed, ok := times["COMPLETED"]; ok {
+		return inRange(tr, completed)
+	}
+	return true
+}
+
+// inRange reports whether at is in [start, end); an absent or unreadable
+// bound does not limit the range.
+func inRange(tr timeRange, at time.Time) bool {
+	if start, err := time.Parse(icalTime, tr.Start); err == nil && at.Before(start) {
+		return false
+	}
+	if end, err := time.Parse(icalTime, tr.End); err == nil && !at.Before(end) {
+		return false
+	}
+	return true
+}
+
+// textMatches compares case-insensitively, unless the i;octet collation
+// asks for an exact comparison.
+func textMatches(tm textMatch, value string) bool {
+	if tm.Collation == "i;octet" {
+		return strings.Contains(value, tm.Value)
+	}
+	return strings.Contains(strings.ToLower(value), strings.ToLower(tm.Value))
+}
@@ -0,0 +1,508 @@
+// Package caldav serves a user's todos to calendar clients over CalDAV
+// (RFC 4791), as a calendar collection holding a VTODO per todo. It speaks
+// WebDAV methods, so it is a plain http.Handler next to the API rather
+// than a part of it.
+//
+// The resources are laid out as:
+//
+//	{prefix}/                    root, pointing clients at their principal
+//	{prefix}/{user}/             principal and calendar home of a user
+//	{prefix}/{user}/todos/       calendar collection of the user's todos
+//	{prefix}/{user}/todos/{id}.ics  a todo as a calendar object
+package caldav
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/api/problem"
+	authUsecase "todo-app/internal/auth/usecase"
+	"todo-app/internal/calendar/usecase"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/interface/format"
+)
+
+// maxBodyBytes caps request bodies.
+const maxBodyBytes = 1 << 20
+
+// syncTokenPrefix starts every sync token; the rest is the time the next
+// sync resumes from, in Unix nanoseconds.
+const syncTokenPrefix = "urn:todo-app:sync:"
+
+type Handler struct {
+	uc     *usecase.CalendarUseCase
+	login  authUsecase.LoginUsecase
+	prefix string
+}
+
+// NewHandler serves CalDAV under prefix, such as "/caldav". Clients
+// authenticate with HTTP Basic, using the credentials of POST /auth/login.
+func NewHandler(prefix string, uc *usecase.CalendarUseCase, login authUsecase.LoginUsecase) *Handler {
+	return &Handler{uc: uc, login: login, prefix: strings.TrimSuffix(prefix, "/")}
+}
+
+// target is the resource a request names.
+type target struct {
+	kind string // "root", "home", "collection" or "object"
+	user string
+	id   string
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("DAV", "1, 3, calendar-access")
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	user, ok := h.authenticate(r)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="todo-app", charset="UTF-8"`)
+		http.Error(w, "authentication required", http.StatusUnauthorized)
+		return
+	}
+	t, ok := h.resolve(r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if t.user != "" && t.user != user {
+		http.Error(w, "resources of other users are not accessible", http.StatusForbidden)
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	switch r.Method {
+	case "PROPFIND":
+		h.propfind(w, r, user, t)
+	case "REPORT":
+		h.report(w, r, user, t)
+	case http.MethodGet, http.MethodHead:
+		h.get(w, r, user, t)
+	case http.MethodPut:
+		h.put(w, r, user, t)
+	case http.MethodDelete:
+		h.delete(w, r, user, t)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) authenticate(r *http.Request) (string, bool) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return "", false
+	}
+	result, err := h.login.Login(username, password)
+	if err != nil {
+		return "", false
+	}
+	return result.User.Username, true
+}
+
+func (h *Handler) resolve(path string) (target, bool) {
+	rest, ok := strings.CutPrefix(path, h.prefix+"/")
+	if !ok {
+		return target{kind: "root"}, path == h.prefix
+	}
+	segments := strings.Split(strings.TrimSuffix(rest, "/"), "/")
+	switch {
+	case rest == "":
+		return target{kind: "root"}, true
+	case len(segments) == 1:
+		return target{kind: "home", user: segments[0]}, true
+	case len(segments) == 2 && segments[1] == "todos":
+		return target{kind: "collection", user: segments[0]}, true
+	case len(segments) == 3 && segments[1] == "todos" && segments[2] != "":
+		return target{kind: "object", user: segments[0], id: strings.TrimSuffix(segments[2], ".ics")}, true
+	}
+	return target{}, false
+}
+
+func (h *Handler) homePath(user string) string {
+	return h.prefix + "/" + url.PathEscape(user) + "/"
+}
+
+func (h *Handler) collectionPath(user string) string {
+	return h.homePath(user) + "todos/"
+}
+
+func (h *Handler) objectPath(user, id string) string {
+	return h.collectionPath(user) + url.PathEscape(id) + ".ics"
+}
+
+func (h *Handler) propfind(w http.ResponseWriter, r *http.Request, user string, t target) {
+	var req propfindRequest
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := xml.Unmarshal(body, &req); err != nil {
+			http.Error(w, "invalid propfind: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	// an empty body asks for all properties
+	names, namesOnly := []xml.Name(req.Prop), req.PropName != nil
+	deep := r.Header.Get("Depth") != "0"
+
+	ms := newMultistatus()
+	switch t.kind {
+	case "root":
+		ms.add(h.resource(h.prefix+"/", h.rootProps(user), names, namesOnly))
+		if deep {
+			ms.add(h.resource(h.homePath(user), h.homeProps(user), names, namesOnly))
+		}
+	case "home":
+		ms.add(h.resource(h.homePath(user), h.homeProps(user), names, namesOnly))
+		if deep {
+			props, err := h.collectionProps(user)
+			if err != nil {
+				h.fail(w, err)
+				return
+			}
+			ms.add(h.resource(h.collectionPath(user), props, names, namesOnly))
+		}
+	case "collection":
+		props, err := h.collectionProps(user)
+		if err != nil {
+			h.fail(w, err)
+			return
+		}
+		ms.add(h.resource(h.collectionPath(user), props, names, namesOnly))
+		if deep {
+			todos, err := h.uc.ListTodos(user)
+			if err != nil {
+				h.fail(w, err)
+				return
+			}
+			for _, todo := range todos {
+				ms.add(h.resource(h.objectPath(user, todo.ID), objectProps(todo), names, namesOnly))
+			}
+		}
+	case "object":
+		todo, err := h.uc.GetTodo(user, t.id)
+		if err != nil {
+			h.fail(w, err)
+			return
+		}
+		ms.add(h.resource(h.objectPath(user, todo.ID), objectProps(todo), names, namesOnly))
+	}
+	ms.write(w, "")
+}
+
+func (h *Handler) report(w http.ResponseWriter, r *http.Request, user string, t target) {
+	var req reportRequest
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid report: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if t.kind != "collection" {
+		writeError(w, http.StatusForbidden, xml.Name{Space: nsDAV, Local: "supported-report"})
+		return
+	}
+	names := []xml.Name(req.Prop)
+	ms := newMultistatus()
+	syncToken := ""
+	switch req.XMLName {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		todos, err := h.uc.ListTodos(user)
+		if err != nil {
+			h.fail(w, err)
+			return
+		}
+		for _, todo := range todos {
+			if req.Filter == nil || matches(req.Filter.Comp, todo) {
+				ms.add(h.resource(h.objectPath(user, todo.ID), objectProps(todo), names, false))
+			}
+		}
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		for _, ref := range req.Hrefs {
+			parsed, err := url.Parse(ref)
+			if err != nil {
+				ms.add(response{href: ref, status: http.StatusNotFound})
+				continue
+			}
+			ot, ok := h.resolve(parsed.Path)
+			if !ok || ot.kind != "object" || ot.user != user {
+				ms.add(response{href: ref, status: http.StatusNotFound})
+				continue
+			}
+			todo, err := h.uc.GetTodo(user, ot.id)
+			if errors.Is(err, domain.ErrNotFound) {
+				ms.add(response{href: ref, status: http.StatusNotFound})
+				continue
+			}
+			if err != nil {
+				h.fail(w, err)
+				return
+			}
+			ms.add(h.resource(h.objectPath(user, todo.ID), objectProps(todo), names, false))
+		}
+	case xml.Name{Space: nsDAV, Local: "sync-collection"}:
+		var since time.Time
+		if req.SyncToken != "" {
+			nanos, err := strconv.ParseInt(strings.TrimPrefix(req.SyncToken, syncTokenPrefix), 10, 64)
+			if err != nil || !strings.HasPrefix(req.SyncToken, syncTokenPrefix) {
+				writeError(w, http.StatusForbidden, xml.Name{Space: nsDAV, Local: "valid-sync-token"})
+				return
+			}
+			since = time.Unix(0, nanos)
+		}
+		changed, removed, next, truncated, err := h.uc.Changes(user, since)
+		if err != nil {
+			h.fail(w, err)
+			return
+		}
+		for _, todo := range changed {
+			ms.add(h.resource(h.objectPath(user, todo.ID), objectProps(todo), names, false))
+		}
+		for _, id := range removed {
+			ms.add(response{href: h.objectPath(user, id), status: http.StatusNotFound})
+		}
+		if truncated {
+			ms.add(response{href: h.collectionPath(user), status: http.StatusInsufficientStorage})
+		}
+		syncToken = syncTokenPrefix + strconv.FormatInt(next.UnixNano(), 10)
+	default:
+		writeError(w, http.StatusForbidden, xml.Name{Space: nsDAV, Local: "supported-report"})
+		return
+	}
+	ms.write(w, syncToken)
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request, user string, t target) {
+	if t.kind != "object" {
+		http.Error(w, "only calendar objects can be read", http.StatusMethodNotAllowed)
+		return
+	}
+	todo, err := h.uc.GetTodo(user, t.id)
+	if err != nil {
+		h.fail(w, err)
+		return
+	}
+	data := calendarData(todo)
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("ETag", etag(todo.Version))
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write([]byte(data))
+}
+
+// put stores a calendar object holding a single VTODO whose UID is the
+// name of the resource. The stored todo does not keep properties it has no
+// field for, so no ETag is returned: clients read it back instead.
+func (h *Handler) put(w http.ResponseWriter, r *http.Request, user string, t target) {
+	if t.kind != "object" {
+		http.Error(w, "only calendar objects can be written", http.StatusMethodNotAllowed)
+		return
+	}
+	version, err := ifMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		h.fail(w, err)
+		return
+	}
+	rows, err := format.ReadICal(r.Body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		writeError(w, http.StatusBadRequest, xml.Name{Space: nsCalDAV, Local: "valid-calendar-data"})
+		return
+	}
+	switch {
+	case len(rows) == 0:
+		writeError(w, http.StatusForbidden, xml.Name{Space: nsCalDAV, Local: "supported-calendar-component"})
+		return
+	case len(rows) > 1 || rows[0].ID != t.id:
+		writeError(w, http.StatusForbidden, xml.Name{Space: nsCalDAV, Local: "valid-calendar-object-resource"})
+		return
+	}
+	if r.Header.Get("If-Match") == "*" {
+		// the todo has to exist, whatever its version
+		if _, err := h.uc.GetTodo(user, t.id); errors.Is(err, domain.ErrNotFound) {
+			h.fail(w, domain.ErrVersionConflict)
+			return
+		}
+	}
+	_, created, err := h.uc.PutTodo(user, t.id, rows[0], version, r.Header.Get("If-None-Match") == "*")
+	if err != nil {
+		h.fail(w, err)
+		return
+	}
+	if created {
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request, user string, t target) {
+	if t.kind != "object" {
+		http.Error(w, "only calendar objects can be deleted", http.StatusMethodNotAllowed)
+		return
+	}
+	version, err := ifMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		h.fail(w, err)
+		return
+	}
+	if err := h.uc.DeleteTodo(user, t.id, version); err != nil {
+		h.fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// fail answers with the status the API gives the error.
+func (h *Handler) fail(w http.ResponseWriter, err error) {
+	p := problem.FromError(err)
+	if p == nil {
+		log.Printf("caldav: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	msg := p.Detail
+	for _, detail := range p.Errors {
+		msg += ": " + detail.Message
+	}
+	http.Error(w, msg, p.Status)
+}
+
+// resource renders the requested properties of a resource; nil names
+// asks for all of them.
+func (h *Handler) resource(path string, props []property, names []xml.Name, namesOnly bool) response {
+	r := response{href: path}
+	if names == nil {
+		for _, p := range props {
+			if p.name == calendarDataName {
+				// too expensive to be part of allprop, as RFC 4791 says
+				continue
+			}
+			if namesOnly {
+				p.value = ""
+			}
+			r.found = append(r.found, p)
+		}
+		return r
+	}
+	for _, name := range names {
+		i := slices.IndexFunc(props, func(p property) bool { return p.name == name })
+		if i < 0 {
+			r.missing = append(r.missing, name)
+			continue
+		}
+		r.found = append(r.found, props[i])
+	}
+	return r
+}
+
+var calendarDataName = xml.Name{Space: nsCalDAV, Local: "calendar-data"}
+
+func davProp(local, value string) property {
+	return property{name: xml.Name{Space: nsDAV, Local: local}, value: value}
+}
+
+func (h *Handler) rootProps(user string) []property {
+	return []property{
+		davProp("resourcetype", "<d:collection/>"),
+		davProp("current-user-principal", href(h.homePath(user))),
+	}
+}
+
+func (h *Handler) homeProps(user string) []property {
+	return []property{
+		davProp("resourcetype", "<d:collection/><d:principal/>"),
+		davProp("displayname", escape(user)),
+		davProp("current-user-principal", href(h.homePath(user))),
+		davProp("principal-URL", href(h.homePath(user))),
+		{name: xml.Name{Space: nsCalDAV, Local: "calendar-home-set"}, value: href(h.homePath(user))},
+	}
+}
+
+func (h *Handler) collectionProps(user string) ([]property, error) {
+	todos, err := h.uc.ListTodos(user)
+	if err != nil {
+		return nil, err
+	}
+	token := syncTokenPrefix + strconv.FormatInt(time.Now().Add(-usecase.SyncLag).UnixNano(), 10)
+	reports := ""
+	for _, report := range []string{"<c:calendar-query/>", "<c:calendar-multiget/>", "<d:sync-collection/>"} {
+		reports += "<d:supported-report><d:report>" + report + "</d:report></d:supported-report>"
+	}
+	return []property{
+		davProp("resourcetype", "<d:collection/><c:calendar/>"),
+		davProp("displayname", "Todos"),
+		davProp("owner", href(h.homePath(user))),
+		davProp("current-user-principal", href(h.homePath(user))),
+		davProp("current-user-privilege-set", "<d:privilege><d:read/></d:privilege><d:privilege><d:write/></d:privilege>"),
+		davProp("supported-report-set", reports),
+		davProp("sync-token", escape(token)),
+		{name: xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"}, value: `<c:comp name="VTODO"/>`},
+		{name: xml.Name{Space: nsCS, Local: "getctag"}, value: ctag(todos)},
+	}, nil
+}
+
+func objectProps(todo *domain.Todo) []property {
+	return []property{
+		davProp("resourcetype", ""),
+		davProp("getetag", escape(etag(todo.Version))),
+		davProp("getcontenttype", "text/calendar; charset=utf-8; component=VTODO"),
+		{name: calendarDataName, value: escape(calendarData(todo))},
+	}
+}
+
+// ctag changes whenever a todo of the collection is added, changed or
+// removed, so clients that poll it know when to look for changes.
+func ctag(todos []*domain.Todo) string {
+	versions := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		versions = append(versions, todo.ID+":"+strconv.FormatInt(todo.Version, 10))
+	}
+	slices.Sort(versions)
+	sum := sha256.Sum256([]byte(strings.Join(versions, "\n")))
+	return hex.EncodeToString(sum[:16])
+}
+
+func calendarData(todo *domain.Todo) string {
+	var b strings.Builder
+	w := format.NewICalWriter(&b, format.ICalOptions{})
+	_ = w.WriteHeader()
+	_ = w.Write(todo)
+	_ = w.Close()
+	return b.String()
+}
+
+// etag renders a version as a strong entity tag, the same as the API does.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatch reads the version out of an If-Match header; "*" and an absent
+// header give zero, which matches any version.
+func ifMatch(header string) (int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	version, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(header, "W/"), `"`), 10, 64)
+	if err != nil || version <= 0 {
+		return 0, domain.ErrVersionConflict
+	}
+	return version, nil
+}
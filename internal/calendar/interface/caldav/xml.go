@@ -0,0 +1,181 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	nsDAV    = "DAV:"
+	nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+	nsCS     = "http://calendarserver.org/ns/"
+)
+
+// prefixes are declared on every multistatus, so property values can use
+// them.
+var prefixes = map[string]string{nsDAV: "d", nsCalDAV: "c", nsCS: "cs"}
+
+// propNames reads the property names listed in a DAV:prop element. Their
+// content, such as the component selection of calendar-data, is skipped.
+type propNames []xml.Name
+
+func (p *propNames) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			*p = append(*p, t.Name)
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+type propfindRequest struct {
+	XMLName  xml.Name  `xml:"DAV: propfind"`
+	AllProp  *struct{} `xml:"DAV: allprop"`
+	PropName *struct{} `xml:"DAV: propname"`
+	Prop     propNames `xml:"DAV: prop"`
+}
+
+// reportRequest holds the elements of the reports that are supported; the
+// root element tells which one it is.
+type reportRequest struct {
+	XMLName   xml.Name
+	Prop      propNames `xml:"DAV: prop"`
+	Hrefs     []string  `xml:"DAV: href"`
+	SyncToken string    `xml:"DAV: sync-token"`
+	Filter    *struct {
+		Comp compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	} `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+type compFilter struct {
+	Name         string       `xml:"name,attr"`
+	IsNotDefined *struct{}    `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *timeRange   `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	Comps        []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	Props        []propFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+}
+
+type propFilter struct {
+	Name         string     `xml:"name,attr"`
+	IsNotDefined *struct{}  `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *timeRange `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	TextMatch    *textMatch `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+type timeRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+type textMatch struct {
+	Value     string `xml:",chardata"`
+	Collation string `xml:"collation,attr"`
+	Negate    string `xml:"negate-condition,attr"`
+}
+
+// property is a property name with its value as XML content.
+type property struct {
+	name  xml.Name
+	value string
+}
+
+// response is one resource of a multistatus: either the properties that
+// were found and the names of those that were not, or a bare status.
+type response struct {
+	href    string
+	found   []property
+	missing []xml.Name
+	status  int
+}
+
+// multistatus builds a DAV:multistatus document.
+type multistatus struct {
+	b strings.Builder
+}
+
+func newMultistatus() *multistatus {
+	m := &multistatus{}
+	m.b.WriteString(xml.Header)
+	m.b.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:c="` + nsCalDAV + `" xmlns:cs="` + nsCS + `">`)
+	return m
+}
+
+func (m *multistatus) add(r response) {
+	m.b.WriteString("<d:response><d:href>" + escape(r.href) + "</d:href>")
+	if r.status != 0 {
+		m.b.WriteString("<d:status>" + statusLine(r.status) + "</d:status></d:response>")
+		return
+	}
+	if len(r.found) > 0 || len(r.missing) == 0 {
+		m.b.WriteString("<d:propstat><d:prop>")
+		for _, p := range r.found {
+			m.b.WriteString(element(p.name, p.value))
+		}
+		m.b.WriteString("</d:prop><d:status>" + statusLine(http.StatusOK) + "</d:status></d:propstat>")
+	}
+	if len(r.missing) > 0 {
+		m.b.WriteString("<d:propstat><d:prop>")
+		for _, name := range r.missing {
+			m.b.WriteString(element(name, ""))
+		}
+		m.b.WriteString("</d:prop><d:status>" + statusLine(http.StatusNotFound) + "</d:status></d:propstat>")
+	}
+	m.b.WriteString("</d:response>")
+}
+
+func (m *multistatus) write(w http.ResponseWriter, syncToken string) {
+	if syncToken != "" {
+		m.b.WriteString("<d:sync-token>" + escape(syncToken) + "</d:sync-token>")
+	}
+	m.b.WriteString("</d:multistatus>")
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	_, _ = w.Write([]byte(m.b.String()))
+}
+
+// writeError answers with a DAV:error naming the precondition that failed.
+func writeError(w http.ResponseWriter, status int, condition xml.Name) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header + `<d:error xmlns:d="DAV:" xmlns:c="` + nsCalDAV + `">` + element(condition, "") + `</d:error>`))
+}
+
+// element renders an element with the given content, declaring its
+// namespace unless it has one of the common prefixes.
+func element(name xml.Name, value string) string {
+	tag, attr := name.Local, ""
+	if prefix, ok := prefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+	} else if name.Space != "" {
+		tag, attr = "x:"+name.Local, ` xmlns:x="`+escape(name.Space)+`"`
+	}
+	if value == "" {
+		return "<" + tag + attr + "/>"
+	}
+	return "<" + tag + attr + ">" + value + "</" + tag + ">"
+}
+
+func href(path string) string {
+	return element(xml.Name{Space: nsDAV, Local: "href"}, escape(path))
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func statusLine(status int) string {
+	return "HTTP/1.1 " + strconv.Itoa(status) + " " + http.StatusText(status)
+}
@@ -0,0 +1,21 @@
+package http
+
+import (
+	"todo-app/internal/calendar/domain"
+)
+
+type (
+	CalendarFeedInput struct {
+		Token  string `path:"token" doc:"Secret token of the feed"`
+		Events bool   `query:"events" doc:"Also add an event at the due date of every todo item, for calendar apps that do not show tasks" example:"true"`
+	}
+)
+
+type (
+	FeedOutput struct {
+		Body struct {
+			Feed *domain.Feed `json:"feed" doc:"Calendar feed of the caller"`
+			Path string       `json:"path" example:"/calendar/3f2a.../todos.ics" doc:"Path of the feed URL; anyone who has the URL can read the feed"`
+		}
+	}
+)
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"todo-app/internal/api/middleware"
+	"todo-app/internal/calendar/domain"
+	"todo-app/internal/calendar/usecase"
+	"todo-app/internal/todo/interface/format"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// feedPageSize is how many todos a feed loads at a time.
+const feedPageSize = 500
+
+type FeedHandler struct {
+	uc *usecase.FeedUseCase
+}
+
+func NewFeedHandler(api huma.API, uc *usecase.FeedUseCase) {
+	handler := &FeedHandler{uc: uc}
+
+	grp := huma.NewGroup(api, "/calendar")
+	myAuthSecurity := []map[string][]string{
+		{"myAuth": {}},
+	}
+	huma.Register(grp, huma.Operation{
+		OperationID: "get-calendar-feed",
+		Summary:     "Get your calendar feed URL",
+		Description: "Returns the secret URL of an iCalendar feed of your todo items, creating it on first use.",
+		Method:      http.MethodGet,
+		Path:        "/feed",
+		Security:    myAuthSecurity,
+	}, handler.Get)
+	huma.Register(grp, huma.Operation{
+		OperationID: "rotate-calendar-feed",
+		Summary:     "Rotate your calendar feed URL",
+		Description: "Issues a new secret feed URL. Calendars subscribed to the old URL stop getting updates.",
+		Method:      http.MethodPost,
+		Path:        "/feed/rotate",
+		Security:    myAuthSecurity,
+	}, handler.Rotate)
+	huma.Register(grp, huma.Operation{
+		OperationID: "read-calendar-feed",
+		Summary:     "Read a calendar feed",
+		Description: "Serves the live todo items of the feed's owner as iCalendar, a VTODO per todo item. The token authenticates the request.",
+		Method:      http.MethodGet,
+		Path:        "/{token}/todos.ics",
+		Responses: map[string]*huma.Response{
+			"200": {Description: "iCalendar data", Content: map[string]*huma.MediaType{"text/calendar": {}}},
+		},
+	}, handler.Read)
+}
+
+func (h *FeedHandler) Get(ctx context.Context, input *struct{}) (*FeedOutput, error) {
+	feed, err := h.uc.GetFeed(middleware.UserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return feedOutput(feed), nil
+}
+
+func (h *FeedHandler) Rotate(ctx context.Context, input *struct{}) (*FeedOutput, error) {
+	feed, err := h.uc.RotateFeed(middleware.UserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return feedOutput(feed), nil
+}
+
+func (h *FeedHandler) Read(ctx context.Context, input *CalendarFeedInput) (*huma.StreamResponse, error) {
+	feed, err := h.uc.OpenFeed(input.Token)
+	if err != nil {
+		return nil, err
+	}
+	// load the first page up front, so failures still get a proper status
+	first, err := h.uc.FeedTodos(feed, 0, feedPageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &huma.StreamResponse{Body: func(hctx huma.Context) {
+		hctx.SetHeader("Content-Type", "text/calendar; charset=utf-8")
+		hctx.SetHeader("Cache-Control", "private, no-cache")
+		w := format.NewICalWriter(hctx.BodyWriter(), format.ICalOptions{Name: "Todos of " + feed.Owner, Events: input.Events})
+		if err := w.WriteHeader(); err != nil {
+			return
+		}
+		todos := first
+		for page := 1; ; page++ {
+			for _, todo := range todos {
+				if err := w.Write(todo); err != nil {
+					return
+				}
+			}
+			if len(todos) < feedPageSize {
+				break
+			}
+			if todos, err = h.uc.FeedTodos(feed, page, feedPageSize); err != nil {
+				// the status is already sent; a cut-off calendar is all we can do
+				log.Printf("calendar feed: load page %d: %v", page, err)
+				return
+			}
+		}
+		_ = w.Close()
+	}}, nil
+}
+
+func feedOutput(feed *domain.Feed) *FeedOutput {
+	resp := &FeedOutput{}
+	resp.Body.Feed = feed
+	resp.Body.Path = "/calendar/" + url.PathEscape(feed.Token) + "/todos.ics"
+	return resp
+}
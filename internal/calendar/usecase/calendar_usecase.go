@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+	todoDomain "todo-app/internal/todo/domain"
+	todoUsecase "todo-app/internal/todo/usecase"
+)
+
+// SyncLag is how far back of the present a sync resumes. History entries
+// are appended just after their change is written, so the ones of the
+// last moments may still be on their way; they are reported again rather
+// than missed.
+const SyncLag = 5 * time.Second
+
+// MaxSyncChanges caps the number of history entries one sync looks at.
+const MaxSyncChanges = 1000
+
+// listPageSize is how many todos are loaded at a time when listing all of
+// a user's todos.
+const listPageSize = 500
+
+// CalendarUseCase presents the todos a user created as a calendar of
+// tasks, for calendar clients that sync both ways. Changes go through the
+// TodoUseCase, so they are recorded and published like any other.
+type CalendarUseCase struct {
+	todos   *todoUsecase.TodoUseCase
+	repo    todoDomain.TodoRepository
+	history todoDomain.HistoryRepository
+}
+
+func NewCalendarUseCase(todos *todoUsecase.TodoUseCase, repo todoDomain.TodoRepository, history todoDomain.HistoryRepository) *CalendarUseCase {
+	return &CalendarUseCase{todos: todos, repo: repo, history: history}
+}
+
+// ListTodos returns all live todos of the owner.
+func (uc *CalendarUseCase) ListTodos(owner string) ([]*todoDomain.Todo, error) {
+	var all []*todoDomain.Todo
+	for page := 0; ; page++ {
+		list, _, err := uc.todos.GetAllTodos(page, listPageSize, todoDomain.TodoFilter{Owner: owner})
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, list...)
+		if len(list) < listPageSize {
+			return all, nil
+		}
+	}
+}
+
+// GetTodo returns a live todo of the owner; todos of other users are not
+// found.
+func (uc *CalendarUseCase) GetTodo(owner, id string) (*todoDomain.Todo, error) {
+	todo, err := uc.todos.GetTodoByID(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if todo.Owner != owner {
+		return nil, todoDomain.ErrNotFound
+	}
+	return todo, nil
+}
+
+// PutTodo stores a task under id, replacing the todo with that ID or
+// creating it: fields the row does not have are cleared. A non-zero
+// version must match the stored todo, and createOnly fails with a version
+// conflict when the todo exists. It reports whether the todo was created.
+func (uc *CalendarUseCase) PutTodo(owner, id string, row todoUsecase.ImportRow, version int64, createOnly bool) (*todoDomain.Todo, bool, error) {
+	if row.Err != nil {
+		return nil, false, row.Err
+	}
+	title, due, done, tags := "", time.Time{}, false, []string{}
+	if row.Title != nil {
+		title = *row.Title
+	}
+	if row.DueDate != nil {
+		due = *row.DueDate
+	}
+	allDay := row.AllDay != nil && *row.AllDay
+	if row.Done != nil {
+		done = *row.Done
+	}
+	if row.Tags != nil {
+		tags = *row.Tags
+	}
+	_, err := uc.GetTodo(owner, id)
+	switch {
+	case err == nil && createOnly:
+		return nil, false, todoDomain.ErrVersionConflict
+	case err == nil:
+		todo, err := uc.todos.PatchTodo(owner, id, todoUsecase.TodoPatch{Title: &title, DueDate: &due, AllDay: &allDay, Done: &done, Tags: &tags}, version)
+		return todo, false, err
+	case !errors.Is(err, todoDomain.ErrNotFound):
+		return nil, false, err
+	case version != 0:
+		// there is no todo for the version to match
+		return nil, false, todoDomain.ErrVersionConflict
+	}
+	if err := uc.todos.CreateTodo(owner, title, due, done, todoUsecase.WithID(id), todoUsecase.WithTags(tags), todoUsecase.WithDueZone(allDay, "")); err != nil {
+		return nil, false, err
+	}
+	todo, err := uc.todos.GetTodoByID(owner, id)
+	return todo, true, err
+}
+
+// DeleteTodo moves a todo of the owner to the trash. A non-zero version
+// must match.
+func (uc *CalendarUseCase) DeleteTodo(owner, id string, version int64) error {
+	if _, err := uc.GetTodo(owner, id); err != nil {
+		return err
+	}
+	return uc.todos.DeleteTodo(owner, id, version)
+}
+
+// Changes lists what happened to the owner's todos since a sync: the live
+// todos that changed, and the IDs of ones that were deleted. A zero since
+// starts a sync, listing all live todos. next is where the following sync
+// resumes. When there were more changes than one sync looks at, truncated
+// is set and the rest follow from next.
+func (uc *CalendarUseCase) Changes(owner string, since time.Time) (changed []*todoDomain.Todo, removed []string, next time.Time, truncated bool, err error) {
+	next = time.Now().Add(-SyncLag)
+	if since.IsZero() {
+		changed, err = uc.ListTodos(owner)
+		return changed, nil, next, false, err
+	}
+	entries, err := uc.history.FindSince(owner, since, MaxSyncChanges+1)
+	if err != nil {
+		return nil, nil, time.Time{}, false, err
+	}
+	if len(entries) > MaxSyncChanges {
+		entries, truncated = entries[:MaxSyncChanges], true
+		next = entries[len(entries)-1].At
+	}
+	if next.Before(since) {
+		next = since
+	}
+	last := map[string]*todoDomain.HistoryEntry{}
+	var ids []string
+	for _, entry := range entries {
+		if _, seen := last[entry.TodoID]; !seen {
+			ids = append(ids, entry.TodoID)
+		}
+		last[entry.TodoID] = entry
+	}
+	for _, id := range ids {
+		todo, err := uc.repo.FindByID(id)
+		if err == nil {
+			if todo.Owner == owner {
+				changed = append(changed, todo)
+			}
+			continue
+		}
+		if !errors.Is(err, todoDomain.ErrNotFound) {
+			return nil, nil, time.Time{}, false, err
+		}
+		trashed, err := uc.repo.FindTrashedByID(id)
+		switch {
+		case err == nil:
+			if trashed.Owner == owner {
+				removed = append(removed, id)
+			}
+		case errors.Is(err, todoDomain.ErrNotFound):
+			// purged; only its history remembers whose it was
+			if purgedOwner(last[id]) == owner {
+				removed = append(removed, id)
+			}
+		default:
+			return nil, nil, time.Time{}, false, err
+		}
+	}
+	return changed, removed, next, truncated, nil
+}
+
+// purgedOwner reads the owner of a purged todo off its purge entry, which
+// lists every field as it was before.
+func purgedOwner(entry *todoDomain.HistoryEntry) string {
+	if entry.Action != todoDomain.HistoryPurged {
+		return ""
+	}
+	for _, change := range entry.Changes {
+		if change.Field == "owner" {
+			owner, _ := change.Before.(string)
+			return owner
+		}
+	}
+	return ""
+}
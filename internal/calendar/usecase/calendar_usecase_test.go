@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	todoDomain "todo-app/internal/todo/domain"
+	todoRepository "todo-app/internal/todo/infrastructure/repository"
+	todoUsecase "todo-app/internal/todo/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCalendarUseCase() (*CalendarUseCase, *todoUsecase.TodoUseCase) {
+	repo := todoRepository.NewMemoryTodoRepository()
+	history := todoRepository.NewMemoryHistoryRepository()
+	todos := todoUsecase.NewTodoUseCase(repo, history)
+	return NewCalendarUseCase(todos, repo, history), todos
+}
+
+func TestCalendar_PutTodo(t *testing.T) {
+	uc, _ := newCalendarUseCase()
+	title, due, tags := "Buy milk", time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC), []string{"home"}
+	row := todoUsecase.ImportRow{ID: "task-1", Title: &title, DueDate: &due, Tags: &tags}
+
+	_, _, err := uc.PutTodo("alice", "task-1", row, 1, false)
+	assert.ErrorIs(t, err, todoDomain.ErrVersionConflict)
+	todo, created, err := uc.PutTodo("alice", "task-1", row, 0, true)
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "alice", todo.Owner)
+	assert.Equal(t, []string{"home"}, todo.Tags)
+	_, _, err = uc.PutTodo("alice", "task-1", row, 0, true)
+	assert.ErrorIs(t, err, todoDomain.ErrVersionConflict)
+
+	// a replacement clears what the task no longer has
+	done := true
+	todo, created, err = uc.PutTodo("alice", "task-1", todoUsecase.ImportRow{ID: "task-1", Title: &title, Done: &done}, todo.Version, false)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.True(t, todo.Done)
+	assert.NotNil(t, todo.CompletedAt)
+	assert.True(t, todo.DueDate.IsZero())
+	assert.Empty(t, todo.Tags)
+
+	_, err = uc.GetTodo("bob", "task-1")
+	assert.ErrorIs(t, err, todoDomain.ErrNotFound)
+	assert.ErrorIs(t, uc.DeleteTodo("bob", "task-1", 0), todoDomain.ErrNotFound)
+}
+
+func TestCalendar_Changes(t *testing.T) {
+	uc, todos := newCalendarUseCase()
+	require.NoError(t, todos.CreateTodo("alice", "Buy milk", time.Time{}, false, todoUsecase.WithID("a")))
+	require.NoError(t, todos.CreateTodo("alice", "File taxes", time.Time{}, false, todoUsecase.WithID("b")))
+	require.NoError(t, todos.CreateTodo("bob", "Walk dog", time.Time{}, false, todoUsecase.WithID("c")))
+
+	changed, removed, next, truncated, err := uc.Changes("alice", time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, changed, 2)
+	assert.Empty(t, removed)
+	assert.False(t, truncated)
+	assert.True(t, next.Before(time.Now().Add(-SyncLag+time.Second)))
+
+	since := time.Now().Add(-time.Millisecond)
+	require.NoError(t, todos.DeleteTodo("alice", "a", 0))
+	require.NoError(t, todos.DeleteTodo("alice", "b", 0))
+	require.NoError(t, todos.PurgeTodo("alice", "b", 0))
+	require.NoError(t, todos.DeleteTodo("bob", "c", 0))
+	changed, removed, _, _, err = uc.Changes("alice", since)
+	require.NoError(t, err)
+	assert.Empty(t, changed)
+	assert.ElementsMatch(t, []string{"a", "b"}, removed)
+}
+
+func TestCalendar_ChangesOfOthersDoNotCount(t *testing.T) {
+	uc, todos := newCalendarUseCase()
+	since := time.Now().Add(-time.Millisecond)
+	for i := 0; i < MaxSyncChanges; i++ {
+		require.NoError(t, todos.CreateTodo("bob", "Walk dog", time.Time{}, false))
+	}
+	require.NoError(t, todos.CreateTodo("alice", "Buy milk", time.Time{}, false, todoUsecase.WithID("a")))
+
+	changed, removed, _, truncated, err := uc.Changes("alice", since)
+	require.NoError(t, err)
+	assert.False(t, truncated, "only the changes to alice's todos are looked at")
+	require.Len(t, changed, 1)
+	assert.Equal(t, "a", changed[0].ID)
+	assert.Empty(t, removed)
+}
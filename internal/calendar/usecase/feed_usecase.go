@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+	"todo-app/internal/calendar/domain"
+	todoDomain "todo-app/internal/todo/domain"
+	todoUsecase "todo-app/internal/todo/usecase"
+)
+
+// FeedUseCase hands out the secret calendar feed URLs of users and reads
+// the todos a feed shows: the live todos its owner created.
+type FeedUseCase struct {
+	feeds domain.FeedRepository
+	todos *todoUsecase.TodoUseCase
+}
+
+func NewFeedUseCase(feeds domain.FeedRepository, todos *todoUsecase.TodoUseCase) *FeedUseCase {
+	return &FeedUseCase{feeds: feeds, todos: todos}
+}
+
+// GetFeed returns the user's feed, creating it on first use.
+func (uc *FeedUseCase) GetFeed(owner string) (*domain.Feed, error) {
+	feed, err := uc.feeds.FindByOwner(owner)
+	if errors.Is(err, domain.ErrFeedNotFound) {
+		return uc.RotateFeed(owner)
+	}
+	return feed, err
+}
+
+// RotateFeed gives the user's feed a new token; the old URL stops working.
+func (uc *FeedUseCase) RotateFeed(owner string) (*domain.Feed, error) {
+	feed := &domain.Feed{Owner: owner, Token: generateToken(), CreatedAt: time.Now()}
+	if err := uc.feeds.Save(feed); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+// OpenFeed looks a feed up by the token of its URL.
+func (uc *FeedUseCase) OpenFeed(token string) (*domain.Feed, error) {
+	if token == "" {
+		return nil, domain.ErrFeedNotFound
+	}
+	return uc.feeds.FindByToken(token)
+}
+
+// FeedTodos returns a page of the todos the feed shows, as the API shows
+// them.
+func (uc *FeedUseCase) FeedTodos(feed *domain.Feed, page, limit int) ([]*todoDomain.Todo, error) {
+	list, _, err := uc.todos.GetAllTodos(page, limit, todoDomain.TodoFilter{Owner: feed.Owner})
+	return list, err
+}
+
+func generateToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
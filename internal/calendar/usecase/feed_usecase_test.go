@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	"todo-app/internal/calendar/domain"
+	"todo-app/internal/calendar/infrastructure/repository"
+	todoRepository "todo-app/internal/todo/infrastructure/repository"
+	todoUsecase "todo-app/internal/todo/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeed_GetAndRotate(t *testing.T) {
+	todos := todoUsecase.NewTodoUseCase(todoRepository.NewMemoryTodoRepository(), todoRepository.NewMemoryHistoryRepository())
+	uc := NewFeedUseCase(repository.NewMemoryFeedRepository(), todos)
+
+	feed, err := uc.GetFeed("alice")
+	require.NoError(t, err)
+	assert.Len(t, feed.Token, 64)
+	again, err := uc.GetFeed("alice")
+	require.NoError(t, err)
+	assert.Equal(t, feed.Token, again.Token)
+	other, err := uc.GetFeed("bob")
+	require.NoError(t, err)
+	assert.NotEqual(t, feed.Token, other.Token)
+
+	rotated, err := uc.RotateFeed("alice")
+	require.NoError(t, err)
+	assert.NotEqual(t, feed.Token, rotated.Token)
+	_, err = uc.OpenFeed(feed.Token)
+	assert.ErrorIs(t, err, domain.ErrFeedNotFound)
+	opened, err := uc.OpenFeed(rotated.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", opened.Owner)
+	_, err = uc.OpenFeed("")
+	assert.ErrorIs(t, err, domain.ErrFeedNotFound)
+}
+
+func TestFeed_TodosOfOwner(t *testing.T) {
+	todos := todoUsecase.NewTodoUseCase(todoRepository.NewMemoryTodoRepository(), todoRepository.NewMemoryHistoryRepository())
+	todos.UseComments(todoRepository.NewMemoryCommentRepository())
+	uc := NewFeedUseCase(repository.NewMemoryFeedRepository(), todos)
+	due := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, todos.CreateTodo("alice", "Buy milk", due, false))
+	require.NoError(t, todos.CreateTodo("alice", "File taxes", due, true, todoUsecase.WithID("taxes")))
+	_, err := todos.AddComment("alice", "taxes", "", "Receipts are in the drawer")
+	require.NoError(t, err)
+	require.NoError(t, todos.CreateTodo("bob", "Walk dog", due, false))
+
+	feed, err := uc.GetFeed("alice")
+	require.NoError(t, err)
+	list, err := uc.FeedTodos(feed, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	for _, todo := range list {
+		assert.Equal(t, "alice", todo.Owner)
+		if todo.Done {
+			assert.NotNil(t, todo.CompletedAt)
+			// derived fields are filled in as the API fills them in
+			assert.Equal(t, 1, todo.CommentCount)
+		}
+	}
+}
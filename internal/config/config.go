@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -11,15 +14,69 @@ type Config struct {
 	MongoDB       string
 	JWTSecret     string
 	AuthRepo      string // "memory" or "mongo"
+	TodoRepo      string // "memory" or "mongo"
+	// TrashRetention is how long deleted todos stay in the trash before
+	// they are purged for good.
+	TrashRetention time.Duration
+	// ReminderInterval is how often the dispatcher looks for due reminders.
+	ReminderInterval time.Duration
+	// ReminderMaxAttempts is how often a reminder delivery is tried before
+	// it is given up on.
+	ReminderMaxAttempts int
+	// SMTP settings for the email reminder channel, which is only enabled
+	// when SMTPAddr is set.
+	SMTPAddr     string
+	SMTPFrom     string
+	SMTPUsername string
+	SMTPPassword string
+	// WebhookInterval is how often queued webhook deliveries are sent.
+	WebhookInterval time.Duration
+	// WebhookMaxAttempts is how often a delivery is tried before it fails
+	// for good.
+	WebhookMaxAttempts int
+	// WebhookDisableAfter is the number of consecutive failed deliveries
+	// after which a webhook is disabled.
+	WebhookDisableAfter int
+	// EventReplaySize is the number of recent todo events kept for SSE
+	// clients that reconnect.
+	EventReplaySize int
+	// AttachmentStore is where the content of attachments is kept:
+	// "local" for files under AttachmentDir, or "gridfs" for MongoDB.
+	AttachmentStore string
+	AttachmentDir   string
+	// AttachmentMaxSize is the largest file in bytes that can be attached.
+	AttachmentMaxSize int
+	// AttachmentTypes are the media types files can be attached with; a
+	// type like "image/*" allows every subtype.
+	AttachmentTypes []string
 }
 
 func Load() Config {
 	return Config{
-		ServerAddress: getOr("SERVER_ADDRESS", "localhost:8080"),
-		MongoURI:      must("MONGO_DB_URI"),
-		MongoDB:       must("MONGO_DB_NAME"),
-		JWTSecret:     must("JWT_SECRET"),
-		AuthRepo:      getOr("AUTH_REPO", "memory"),
+		ServerAddress:  getOr("SERVER_ADDRESS", "localhost:8080"),
+		MongoURI:       must("MONGO_DB_URI"),
+		MongoDB:        must("MONGO_DB_NAME"),
+		JWTSecret:      must("JWT_SECRET"),
+		AuthRepo:       getOr("AUTH_REPO", "memory"),
+		TodoRepo:       getOr("TODO_REPO", "mongo"),
+		TrashRetention: durationOr("TRASH_RETENTION", 30*24*time.Hour),
+
+		ReminderInterval:    durationOr("REMINDER_INTERVAL", 30*time.Second),
+		ReminderMaxAttempts: intOr("REMINDER_MAX_ATTEMPTS", 5),
+		SMTPAddr:            os.Getenv("SMTP_ADDR"),
+		SMTPFrom:            getOr("SMTP_FROM", "todo@localhost"),
+		SMTPUsername:        os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:        os.Getenv("SMTP_PASSWORD"),
+
+		WebhookInterval:     durationOr("WEBHOOK_INTERVAL", 5*time.Second),
+		WebhookMaxAttempts:  intOr("WEBHOOK_MAX_ATTEMPTS", 6),
+		WebhookDisableAfter: intOr("WEBHOOK_DISABLE_AFTER", 5),
+		EventReplaySize:     intOr("EVENT_REPLAY_SIZE", 1000),
+
+		AttachmentStore:   getOr("ATTACHMENT_STORE", "local"),
+		AttachmentDir:     getOr("ATTACHMENT_DIR", "data/attachments"),
+		AttachmentMaxSize: intOr("ATTACHMENT_MAX_SIZE", 10<<20),
+		AttachmentTypes:   listOr("ATTACHMENT_TYPES", "image/png,image/jpeg,image/gif,image/webp,application/pdf,text/plain"),
 	}
 }
 
@@ -38,3 +95,37 @@ func getOr(k, def string) string {
 	}
 	return v
 }
+
+func durationOr(k string, def time.Duration) time.Duration {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Fatalf("invalid duration in env %s: %q", k, v)
+	}
+	return d
+}
+
+func intOr(k string, def int) int {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		log.Fatalf("invalid positive integer in env %s: %q", k, v)
+	}
+	return n
+}
+
+func listOr(k, def string) []string {
+	var list []string
+	for _, item := range strings.Split(getOr(k, def), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,18 @@
+package delivery
+
+import "time"
+
+// Exponential doubles the delay from base with every failed attempt, up to
+// max. attempts is the number of attempts that have failed so far.
+func Exponential(base, max time.Duration) func(attempts int) time.Duration {
+	return func(attempts int) time.Duration {
+		d := base
+		for i := 1; i < attempts && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		return d
+	}
+}
@@ -0,0 +1,109 @@
+// Package delivery runs the background workers that deliver queued items,
+// such as due reminders and webhook calls, retrying failed attempts with
+// backoff.
+package delivery
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Queue holds the items a worker delivers. ClaimDue leases the items it
+// returns, so that concurrent workers do not deliver the same item and an
+// item whose delivery was interrupted is picked up again once its lease
+// runs out.
+type Queue[T any] interface {
+	// ClaimDue returns up to limit items whose next attempt is due at now,
+	// hiding them from other claims for lease.
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]T, error)
+	// Update stores the outcome of an attempt.
+	Update(item T) error
+}
+
+// Worker claims the items that are due every Interval and hands them to
+// its deliver function one at a time, which attempts the item and records
+// the outcome on it.
+type Worker[T any] struct {
+	name    string
+	queue   Queue[T]
+	deliver func(item T, now time.Time) bool
+
+	// Interval is the time between passes.
+	Interval time.Duration
+	// Lease is how long a claimed item is hidden from other passes while it
+	// is being delivered. It should comfortably exceed the time an attempt
+	// takes to give up.
+	Lease time.Duration
+	// BatchSize caps the items claimed in one pass.
+	BatchSize int
+	// MaxAttempts is the number of attempts before an item fails for good.
+	MaxAttempts int
+	// Backoff returns the delay before retrying after the given number of
+	// failed attempts.
+	Backoff func(attempts int) time.Duration
+}
+
+// NewWorker creates a worker with sensible defaults for the lease, batch
+// size and backoff. name prefixes its log lines. deliver returns false to
+// leave an item alone until its lease runs out, e.g. when something it
+// needs could not be read; otherwise the item is updated in the queue.
+func NewWorker[T any](name string, queue Queue[T], deliver func(item T, now time.Time) bool, interval time.Duration, maxAttempts int) *Worker[T] {
+	return &Worker[T]{
+		name:        name,
+		queue:       queue,
+		deliver:     deliver,
+		Interval:    interval,
+		Lease:       time.Minute,
+		BatchSize:   100,
+		MaxAttempts: maxAttempts,
+		Backoff:     Exponential(10*time.Second, time.Hour),
+	}
+}
+
+// Run makes a pass every Interval until ctx is cancelled. It returns once
+// the attempt in flight, if any, has finished; items claimed but not yet
+// attempted are picked up again when their lease runs out.
+func (w *Worker[T]) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		w.Pass(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Pass makes a single pass and returns the number of items it handled.
+func (w *Worker[T]) Pass(ctx context.Context, now time.Time) int {
+	due, err := w.queue.ClaimDue(now, w.Lease, w.BatchSize)
+	if err != nil {
+		log.Printf("%s: claim due: %v", w.name, err)
+	}
+	handled := 0
+	for _, item := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		handled++
+		if !w.deliver(item, now) {
+			continue
+		}
+		if err := w.queue.Update(item); err != nil {
+			log.Printf("%s: record delivery: %v", w.name, err)
+		}
+	}
+	return handled
+}
+
+// Retry returns when to try again after the given number of failed
+// attempts, or false once the item has run out of attempts.
+func (w *Worker[T]) Retry(attempts int, now time.Time) (time.Time, bool) {
+	if attempts >= w.MaxAttempts {
+		return time.Time{}, false
+	}
+	return now.Add(w.Backoff(attempts)), true
+}
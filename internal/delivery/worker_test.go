@@ -0,0 +1,63 @@
+package delivery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeQueue hands out every item on the first claim.
+type fakeQueue struct {
+	items   []string
+	updated []string
+}
+
+func (q *fakeQueue) ClaimDue(_ time.Time, _ time.Duration, limit int) ([]string, error) {
+	claimed := q.items[:min(limit, len(q.items))]
+	q.items = q.items[len(claimed):]
+	return claimed, nil
+}
+
+func (q *fakeQueue) Update(item string) error {
+	q.updated = append(q.updated, item)
+	return nil
+}
+
+func TestWorker_Pass(t *testing.T) {
+	queue := &fakeQueue{items: []string{"a", "skip", "b"}}
+	w := NewWorker("test", queue, func(item string, _ time.Time) bool { return item != "skip" }, time.Second, 3)
+	w.BatchSize = 2
+
+	assert.Equal(t, 2, w.Pass(context.Background(), time.Now()))
+	assert.Equal(t, []string{"a"}, queue.updated, "skipped items are left to their lease")
+	assert.Equal(t, 1, w.Pass(context.Background(), time.Now()))
+	assert.Equal(t, []string{"a", "b"}, queue.updated)
+}
+
+func TestWorker_PassStopsOnShutdown(t *testing.T) {
+	queue := &fakeQueue{items: []string{"a", "b"}}
+	ctx, cancel := context.WithCancel(context.Background())
+	w := NewWorker("test", queue, func(string, time.Time) bool {
+		cancel()
+		return true
+	}, time.Second, 3)
+
+	assert.Equal(t, 1, w.Pass(ctx, time.Now()))
+	assert.Equal(t, []string{"a"}, queue.updated)
+}
+
+func TestWorker_Retry(t *testing.T) {
+	w := NewWorker[string]("test", &fakeQueue{}, nil, time.Second, 3)
+	now := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	next, ok := w.Retry(1, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(10*time.Second), next)
+	next, ok = w.Retry(2, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(20*time.Second), next)
+	_, ok = w.Retry(3, now)
+	assert.False(t, ok)
+}
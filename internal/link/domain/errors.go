@@ -0,0 +1,24 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when no share link has the requested token or
+	// ID, e.g. because it was revoked, or when the todo it shows is gone.
+	ErrNotFound = errors.New("share link not found")
+	// ErrExpired is returned when opening a share link past its expiry.
+	ErrExpired = errors.New("share link has expired")
+	// ErrPassword is returned when opening a password-protected share link
+	// without its password, or with a wrong one.
+	ErrPassword = errors.New("share link password is missing or wrong")
+)
+
+// ValidationError reports a share link field that does not satisfy the domain rules.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
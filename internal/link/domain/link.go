@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"time"
+	todoDomain "todo-app/internal/todo/domain"
+)
+
+// Link is a public, read-only link to one todo of its owner, or to the
+// todos of its owner matching Filter. Its Token is the secret in the link
+// URL: anyone who has the URL can read what it shows, so it is unguessable.
+type Link struct {
+	ID        string      `json:"id" readOnly:"true" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7" doc:"Unique identifier for the share link"`
+	Owner     string      `json:"owner" readOnly:"true" example:"alice" doc:"User who created the share link"`
+	Token     string      `json:"token" readOnly:"true" doc:"Secret in the URL of the share link"`
+	TodoID    string      `json:"todoId,omitempty" example:"123e4567-e89b-12d3-a456-426614174000" doc:"Todo item the link shows"`
+	Filter    *LinkFilter `json:"filter,omitempty" doc:"Filter of the todo items the link shows"`
+	ExpiresAt *time.Time  `json:"expiresAt,omitempty" example:"2025-08-01T00:00:00Z" doc:"When the link stops working; never when omitted"`
+	// PasswordHash is the bcrypt hash of the password, empty for links
+	// without one.
+	PasswordHash string    `json:"-"`
+	Protected    bool      `json:"passwordProtected" readOnly:"true" doc:"Whether the link asks for a password"`
+	CreatedAt    time.Time `json:"createdAt" readOnly:"true" doc:"When the share link was created"`
+}
+
+// Expired reports whether the link has expired at now.
+func (l *Link) Expired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
+
+// LinkFilter selects the todos of a list link, like the filters of the todo
+// list. Zero fields do not filter.
+type LinkFilter struct {
+	Title   string `json:"title,omitempty" example:"milk" doc:"Only todo items whose title contains this"`
+	Done    *bool  `json:"done,omitempty" example:"false" doc:"Only done, or only open, todo items"`
+	Tag     string `json:"tag,omitempty" example:"home" doc:"Only todo items with this tag"`
+	Project string `json:"project,omitempty" example:"0b7e6f1c-8a3d-4c55-9d2e-3f1a2b4c5d6e" doc:"Only todo items of this project, or inbox for those in none"`
+}
+
+// TodoFilter is the todo list filter that selects the todos of owner the
+// link shows.
+func (f LinkFilter) TodoFilter(owner string) todoDomain.TodoFilter {
+	return todoDomain.TodoFilter{Title: f.Title, Done: f.Done, Tag: f.Tag, Owner: owner, Project: f.Project}
+}
+
+// PublicTodo is what a share link shows of a todo: its content, without
+// IDs, owner, project or history.
+type PublicTodo struct {
+	Title       string                        `json:"title" example:"Buy milk" doc:"Title of the todo item"`
+	DueDate     *time.Time                    `json:"dueDate,omitempty" example:"2023-10-10T10:00:00Z" doc:"Due date of the todo item"`
+	AllDay      bool                          `json:"allDay,omitempty" example:"false" doc:"Whether the todo item is due any time on its date"`
+	TimeZone    string                        `json:"timeZone,omitempty" example:"Asia/Taipei" doc:"IANA time zone the due date was set in"`
+	Done        bool                          `json:"done" example:"false" doc:"Completion status of the todo item"`
+	CompletedAt *time.Time                    `json:"completedAt,omitempty" example:"2023-10-09T18:30:00Z" doc:"When the todo item was completed"`
+	Priority    string                        `json:"priority,omitempty" example:"A" doc:"Priority of the todo item, from A (highest) to Z"`
+	Tags        []string                      `json:"tags,omitempty" example:"[\"home\"]" doc:"Labels of the todo item"`
+	Checklist   []PublicChecklistItem         `json:"checklist,omitempty" doc:"Checklist items of the todo item, in order"`
+	Progress    *todoDomain.ChecklistProgress `json:"progress,omitempty" doc:"Checked and total checklist items"`
+}
+
+// PublicChecklistItem is what a share link shows of a checklist item.
+type PublicChecklistItem struct {
+	Text    string `json:"text" example:"Milk" doc:"Text of the checklist item"`
+	Checked bool   `json:"checked" example:"false" doc:"Whether the checklist item is done"`
+}
+
+// NewPublicTodo copies the fields a share link shows from todo.
+func NewPublicTodo(todo *todoDomain.Todo) PublicTodo {
+	p := PublicTodo{
+		Title:       todo.Title,
+		AllDay:      todo.AllDay,
+		TimeZone:    todo.TimeZone,
+		Done:        todo.Done,
+		CompletedAt: todo.CompletedAt,
+		Priority:    todo.Priority,
+		Tags:        todo.Tags,
+		Progress:    todo.Progress,
+	}
+	if !todo.DueDate.IsZero() {
+		due := todo.DueDate
+		p.DueDate = &due
+	}
+	for _, item := range todo.Checklist {
+		p.Checklist = append(p.Checklist, PublicChecklistItem{Text: item.Text, Checked: item.Checked})
+	}
+	return p
+}
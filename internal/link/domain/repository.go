@@ -0,0 +1,11 @@
+package domain
+
+// LinkRepository stores the share links of users.
+type LinkRepository interface {
+	Save(link *Link) error
+	// FindByToken returns the link with the token of its URL.
+	FindByToken(token string) (*Link, error)
+	// FindByOwner lists the links of a user, newest first.
+	FindByOwner(owner string) ([]*Link, error)
+	DeleteByID(owner, id string) error
+}
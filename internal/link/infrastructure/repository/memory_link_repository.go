@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"todo-app/internal/link/domain"
+)
+
+type MemoryLinkRepository struct {
+	mu    sync.Mutex
+	links map[string]*domain.Link
+}
+
+func NewMemoryLinkRepository() *MemoryLinkRepository {
+	return &MemoryLinkRepository{links: map[string]*domain.Link{}}
+}
+
+func (r *MemoryLinkRepository) Save(link *domain.Link) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := *link
+	r.links[link.ID] = &c
+	return nil
+}
+
+func (r *MemoryLinkRepository) FindByToken(token string) (*domain.Link, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, link := range r.links {
+		if link.Token == token {
+			c := *link
+			return &c, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *MemoryLinkRepository) FindByOwner(owner string) ([]*domain.Link, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := []*domain.Link{}
+	for _, link := range r.links {
+		if link.Owner == owner {
+			c := *link
+			list = append(list, &c)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.After(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+func (r *MemoryLinkRepository) DeleteByID(owner, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	link, ok := r.links[id]
+	if !ok || link.Owner != owner {
+		return domain.ErrNotFound
+	}
+	delete(r.links, id)
+	return nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo-app/internal/link/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoLinkRepository implements domain.LinkRepository using MongoDB.
+type MongoLinkRepository struct {
+	collection *mongo.Collection
+}
+
+// linkDocument is the persisted shape of a link in the "share_links"
+// collection.
+type linkDocument struct {
+	ID           string          `bson:"_id"`
+	Owner        string          `bson:"owner"`
+	Token        string          `bson:"token"`
+	TodoID       string          `bson:"todoId,omitempty"`
+	Filter       *filterDocument `bson:"filter,omitempty"`
+	ExpiresAt    *time.Time      `bson:"expiresAt,omitempty"`
+	PasswordHash string          `bson:"passwordHash,omitempty"`
+	CreatedAt    time.Time       `bson:"createdAt"`
+}
+
+type filterDocument struct {
+	Title   string `bson:"title,omitempty"`
+	Done    *bool  `bson:"done,omitempty"`
+	Tag     string `bson:"tag,omitempty"`
+	Project string `bson:"project,omitempty"`
+}
+
+func newLinkDocument(link *domain.Link) linkDocument {
+	doc := linkDocument{
+		ID:           link.ID,
+		Owner:        link.Owner,
+		Token:        link.Token,
+		TodoID:       link.TodoID,
+		ExpiresAt:    link.ExpiresAt,
+		PasswordHash: link.PasswordHash,
+		CreatedAt:    link.CreatedAt,
+	}
+	if link.Filter != nil {
+		f := filterDocument(*link.Filter)
+		doc.Filter = &f
+	}
+	return doc
+}
+
+func (d *linkDocument) toDomain() *domain.Link {
+	link := &domain.Link{
+		ID:           d.ID,
+		Owner:        d.Owner,
+		Token:        d.Token,
+		TodoID:       d.TodoID,
+		ExpiresAt:    d.ExpiresAt,
+		PasswordHash: d.PasswordHash,
+		Protected:    d.PasswordHash != "",
+		CreatedAt:    d.CreatedAt,
+	}
+	if d.Filter != nil {
+		f := domain.LinkFilter(*d.Filter)
+		link.Filter = &f
+	}
+	return link
+}
+
+// NewMongoLinkRepository creates the repository and ensures a unique index
+// for looking links up by token, and one for listing a user's links.
+func NewMongoLinkRepository(db *mongo.Database) *MongoLinkRepository {
+	coll := db.Collection("share_links")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, _ = coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token", Value: 1}},
+			Options: options.Index().SetName("token").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "owner", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("owner_createdAt"),
+		},
+	})
+	return &MongoLinkRepository{collection: coll}
+}
+
+func (r *MongoLinkRepository) Save(link *domain.Link) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := r.collection.InsertOne(ctx, newLinkDocument(link))
+	return err
+}
+
+func (r *MongoLinkRepository) FindByToken(token string) (*domain.Link, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var doc linkDocument
+	err := r.collection.FindOne(ctx, bson.M{"token": token}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc.toDomain(), nil
+}
+
+func (r *MongoLinkRepository) FindByOwner(owner string) ([]*domain.Link, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cursor, err := r.collection.Find(ctx, bson.M{"owner": owner}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	list := []*domain.Link{}
+	for cursor.Next(ctx) {
+		var doc linkDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		list = append(list, doc.toDomain())
+	}
+	return list, cursor.Err()
+}
+
+func (r *MongoLinkRepository) DeleteByID(owner, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "owner": owner})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
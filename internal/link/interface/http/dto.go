@@ -0,0 +1,61 @@
+package http
+
+import (
+	"time"
+	"todo-app/internal/link/domain"
+)
+
+type (
+	ListQueryParams struct {
+		Page  int `query:"page" doc:"Page number for pagination" example:"0"`
+		Limit int `query:"limit" doc:"Number of items per page" example:"10"`
+	}
+	CreateLinkInput struct {
+		Body struct {
+			TodoID    string             `json:"todoId,omitempty" doc:"Todo item of yours the link shows; give either this or filter" example:"123e4567-e89b-12d3-a456-426614174000"`
+			Filter    *domain.LinkFilter `json:"filter,omitempty" doc:"Filter of your todo items the link shows, as they are when the link is opened"`
+			ExpiresAt *time.Time         `json:"expiresAt,omitempty" doc:"When the link stops working; never when omitted" example:"2025-08-01T00:00:00Z"`
+			Password  string             `json:"password,omitempty" maxLength:"72" doc:"Password the link asks for; none when omitted" example:"open sesame"`
+		}
+	}
+	LinkInput struct {
+		ID string `path:"id" doc:"ID of the share link"`
+	}
+	ReadLinkInput struct {
+		ListQueryParams
+		Token    string `path:"token" doc:"Secret token of the share link"`
+		Password string `header:"X-Link-Password" doc:"Password of the share link, if it has one"`
+	}
+)
+
+type (
+	LinkPageMeta struct {
+		Page  int   `json:"page" example:"0" doc:"Current page number"`
+		Limit int   `json:"limit" example:"10" doc:"Number of items per page"`
+		Total int64 `json:"total" example:"100" doc:"Total number of items"`
+	}
+	LinkOutput struct {
+		Body struct {
+			Link *domain.Link `json:"link" doc:"Share link"`
+			Path string       `json:"path" example:"/public/links/3f2a..." doc:"Path of the link URL; anyone who has the URL can read what the link shows"`
+		}
+	}
+	ListLinksOutput struct {
+		Body struct {
+			Data []*domain.Link `json:"data" doc:"Share links of the caller, newest first"`
+		}
+	}
+	RevokeLinkOutput struct {
+		Body struct {
+			Message string `json:"message" example:"Share link revoked successfully" doc:"Confirmation message"`
+		}
+	}
+	ReadLinkOutput struct {
+		CacheControl string `header:"Cache-Control"`
+		Body         struct {
+			Data      []domain.PublicTodo `json:"data" doc:"Todo items the link shows"`
+			Meta      LinkPageMeta        `json:"meta" doc:"Pagination metadata"`
+			ExpiresAt *time.Time          `json:"expiresAt,omitempty" doc:"When the link stops working"`
+		}
+	}
+)
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"todo-app/internal/api/middleware"
+	"todo-app/internal/link/usecase"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+type LinkHandler struct {
+	uc *usecase.LinkUseCase
+}
+
+func NewLinkHandler(api huma.API, uc *usecase.LinkUseCase) {
+	handler := &LinkHandler{uc: uc}
+
+	grp := huma.NewGroup(api, "/links")
+	myAuthSecurity := []map[string][]string{
+		{"myAuth": {}},
+	}
+	huma.Register(grp, huma.Operation{
+		OperationID: "create-link",
+		Summary:     "Create a public share link",
+		Description: "Creates an unguessable, read-only link to one of your todo items, or to those matching a filter, " +
+			"optionally expiring and protected by a password. Anyone who has the link can read the todo items, without logging in.",
+		Method:   http.MethodPost,
+		Path:     "",
+		Security: myAuthSecurity,
+	}, handler.Create)
+	huma.Register(grp, huma.Operation{
+		OperationID: "list-links",
+		Summary:     "List your public share links",
+		Method:      http.MethodGet,
+		Path:        "",
+		Security:    myAuthSecurity,
+	}, handler.List)
+	huma.Register(grp, huma.Operation{
+		OperationID: "revoke-link",
+		Summary:     "Revoke a public share link",
+		Method:      http.MethodDelete,
+		Path:        "/{id}",
+		Security:    myAuthSecurity,
+	}, handler.Revoke)
+	huma.Register(api, huma.Operation{
+		OperationID: "read-link",
+		Summary:     "Read a public share link",
+		Description: "Shows the todo items of a share link, read-only and without their owner, IDs or project. " +
+			"The token authenticates the request; password-protected links also need X-Link-Password.",
+		Method: http.MethodGet,
+		Path:   "/public/links/{token}",
+	}, handler.Read)
+}
+
+func (h *LinkHandler) Create(ctx context.Context, input *CreateLinkInput) (*LinkOutput, error) {
+	link, err := h.uc.CreateLink(middleware.UserID(ctx), usecase.LinkSpec{
+		TodoID:    input.Body.TodoID,
+		Filter:    input.Body.Filter,
+		ExpiresAt: input.Body.ExpiresAt,
+		Password:  input.Body.Password,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &LinkOutput{}
+	resp.Body.Link = link
+	resp.Body.Path = "/public/links/" + url.PathEscape(link.Token)
+	return resp, nil
+}
+
+func (h *LinkHandler) List(ctx context.Context, input *struct{}) (*ListLinksOutput, error) {
+	links, err := h.uc.ListLinks(middleware.UserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListLinksOutput{}
+	resp.Body.Data = links
+	return resp, nil
+}
+
+func (h *LinkHandler) Revoke(ctx context.Context, input *LinkInput) (*RevokeLinkOutput, error) {
+	if err := h.uc.RevokeLink(middleware.UserID(ctx), input.ID); err != nil {
+		return nil, err
+	}
+	resp := &RevokeLinkOutput{}
+	resp.Body.Message = "Share link revoked successfully"
+	return resp, nil
+}
+
+func (h *LinkHandler) Read(ctx context.Context, input *ReadLinkInput) (*ReadLinkOutput, error) {
+	link, err := h.uc.OpenLink(input.Token, input.Password)
+	if err != nil {
+		return nil, err
+	}
+	todos, total, err := h.uc.LinkTodos(link, input.Page, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ReadLinkOutput{CacheControl: "private, no-cache"}
+	resp.Body.Data = todos
+	resp.Body.Meta = LinkPageMeta{
+		Page:  input.Page,
+		Limit: input.Limit,
+		Total: total,
+	}
+	resp.Body.ExpiresAt = link.ExpiresAt
+	return resp, nil
+}
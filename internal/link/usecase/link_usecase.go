@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+	"todo-app/internal/link/domain"
+	todoDomain "todo-app/internal/todo/domain"
+	todoUsecase "todo-app/internal/todo/usecase"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// maxPasswordLength is the most bcrypt hashes, in bytes.
+const maxPasswordLength = 72
+
+// LinkUseCase hands out public, read-only share links to todos and reads
+// what a link shows to anybody who has it.
+type LinkUseCase struct {
+	links domain.LinkRepository
+	todos *todoUsecase.TodoUseCase
+}
+
+func NewLinkUseCase(links domain.LinkRepository, todos *todoUsecase.TodoUseCase) *LinkUseCase {
+	return &LinkUseCase{links: links, todos: todos}
+}
+
+// LinkSpec describes the link to create: either TodoID or Filter, and an
+// optional expiry and password.
+type LinkSpec struct {
+	TodoID    string
+	Filter    *domain.LinkFilter
+	ExpiresAt *time.Time
+	Password  string
+}
+
+// CreateLink creates a share link to a todo of owner, or to the todos of
+// owner matching a filter. Todos shared with owner cannot be linked.
+func (uc *LinkUseCase) CreateLink(owner string, spec LinkSpec) (*domain.Link, error) {
+	if (spec.TodoID == "") == (spec.Filter == nil) {
+		return nil, &domain.ValidationError{Field: "todoId", Message: "give either a todo or a filter"}
+	}
+	now := time.Now()
+	if spec.ExpiresAt != nil && !spec.ExpiresAt.After(now) {
+		return nil, &domain.ValidationError{Field: "expiresAt", Message: "must be in the future"}
+	}
+	if len(spec.Password) > maxPasswordLength {
+		return nil, &domain.ValidationError{Field: "password", Message: "must be at most 72 bytes"}
+	}
+	if spec.TodoID != "" {
+		todo, err := uc.todos.GetTodoByID(owner, spec.TodoID)
+		if err != nil {
+			return nil, err
+		}
+		if todo.Owner != owner {
+			return nil, todoDomain.ErrForbidden
+		}
+	}
+	link := &domain.Link{
+		ID:        uuid.New().String(),
+		Owner:     owner,
+		Token:     generateToken(),
+		TodoID:    spec.TodoID,
+		Filter:    spec.Filter,
+		ExpiresAt: spec.ExpiresAt,
+		CreatedAt: now,
+	}
+	if spec.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(spec.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		link.PasswordHash, link.Protected = string(hash), true
+	}
+	if err := uc.links.Save(link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// ListLinks lists the links of owner, newest first, expired ones included.
+func (uc *LinkUseCase) ListLinks(owner string) ([]*domain.Link, error) {
+	return uc.links.FindByOwner(owner)
+}
+
+// RevokeLink deletes a link of owner; its URL stops working at once.
+func (uc *LinkUseCase) RevokeLink(owner, id string) error {
+	return uc.links.DeleteByID(owner, id)
+}
+
+// OpenLink looks a link up by the token of its URL and checks that it has
+// not expired and that password is its password, if it has one.
+func (uc *LinkUseCase) OpenLink(token, password string) (*domain.Link, error) {
+	if token == "" {
+		return nil, domain.ErrNotFound
+	}
+	link, err := uc.links.FindByToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if link.Expired(time.Now()) {
+		return nil, domain.ErrExpired
+	}
+	if link.PasswordHash != "" && bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)) != nil {
+		return nil, domain.ErrPassword
+	}
+	return link, nil
+}
+
+// LinkTodos returns a page of what an opened link shows: its todo, or the
+// live todos of its owner matching its filter. A link to a todo that was
+// deleted, or is no longer its owner's, is not found.
+func (uc *LinkUseCase) LinkTodos(link *domain.Link, page, limit int) ([]domain.PublicTodo, int64, error) {
+	var todos []*todoDomain.Todo
+	var total int64
+	if link.TodoID != "" {
+		todo, err := uc.todos.GetTodoByID(link.Owner, link.TodoID)
+		if errors.Is(err, todoDomain.ErrNotFound) || err == nil && todo.Owner != link.Owner {
+			return nil, 0, domain.ErrNotFound
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		total = 1
+		if page == 0 && limit > 0 {
+			todos = append(todos, todo)
+		}
+	} else {
+		var err error
+		todos, total, err = uc.todos.GetAllTodos(page, limit, link.Filter.TodoFilter(link.Owner))
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	list := make([]domain.PublicTodo, 0, len(todos))
+	for _, todo := range todos {
+		list = append(list, domain.NewPublicTodo(todo))
+	}
+	return list, total, nil
+}
+
+func generateToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
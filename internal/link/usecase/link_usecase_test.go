@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	"todo-app/internal/link/domain"
+	"todo-app/internal/link/infrastructure/repository"
+	todoDomain "todo-app/internal/todo/domain"
+	todoRepository "todo-app/internal/todo/infrastructure/repository"
+	todoUsecase "todo-app/internal/todo/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFixture(t *testing.T) (*LinkUseCase, *todoUsecase.TodoUseCase, string) {
+	t.Helper()
+	todos := todoUsecase.NewTodoUseCase(todoRepository.NewMemoryTodoRepository(), todoRepository.NewMemoryHistoryRepository())
+	require.NoError(t, todos.CreateTodo("alice", "Buy milk", time.Time{}, false, todoUsecase.WithTags([]string{"shop"})))
+	require.NoError(t, todos.CreateTodo("alice", "Buy eggs", time.Time{}, true, todoUsecase.WithTags([]string{"shop"})))
+	require.NoError(t, todos.CreateTodo("alice", "Call mom", time.Time{}, false))
+	require.NoError(t, todos.CreateTodo("bob", "Buy bread", time.Time{}, false, todoUsecase.WithTags([]string{"shop"})))
+	list, _, err := todos.GetAllTodos(0, 10, todoDomain.TodoFilter{Owner: "alice", Title: "Call mom"})
+	require.NoError(t, err)
+	return NewLinkUseCase(repository.NewMemoryLinkRepository(), todos), todos, list[0].ID
+}
+
+func TestCreateLink(t *testing.T) {
+	uc, _, id := newFixture(t)
+	var invalid *domain.ValidationError
+
+	_, err := uc.CreateLink("alice", LinkSpec{})
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "todoId", invalid.Field)
+	past := time.Now().Add(-time.Minute)
+	_, err = uc.CreateLink("alice", LinkSpec{TodoID: id, ExpiresAt: &past})
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "expiresAt", invalid.Field)
+	_, err = uc.CreateLink("bob", LinkSpec{TodoID: id})
+	assert.ErrorIs(t, err, todoDomain.ErrNotFound)
+
+	link, err := uc.CreateLink("alice", LinkSpec{TodoID: id, Password: "open sesame"})
+	require.NoError(t, err)
+	assert.Len(t, link.Token, 64)
+	assert.True(t, link.Protected)
+	assert.NotContains(t, link.PasswordHash, "open sesame")
+	list, err := uc.ListLinks("alice")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.True(t, list[0].Protected)
+
+	assert.ErrorIs(t, uc.RevokeLink("bob", link.ID), domain.ErrNotFound)
+	require.NoError(t, uc.RevokeLink("alice", link.ID))
+	_, err = uc.OpenLink(link.Token, "open sesame")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestOpenLink(t *testing.T) {
+	uc, todos, id := newFixture(t)
+
+	link, err := uc.CreateLink("alice", LinkSpec{TodoID: id, Password: "open sesame"})
+	require.NoError(t, err)
+	_, err = uc.OpenLink(link.Token, "")
+	assert.ErrorIs(t, err, domain.ErrPassword)
+	_, err = uc.OpenLink(link.Token, "guess")
+	assert.ErrorIs(t, err, domain.ErrPassword)
+	opened, err := uc.OpenLink(link.Token, "open sesame")
+	require.NoError(t, err)
+	shown, total, err := uc.LinkTodos(opened, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, shown, 1)
+	assert.Equal(t, "Call mom", shown[0].Title)
+
+	past := time.Now().Add(-time.Minute)
+	require.NoError(t, uc.links.Save(&domain.Link{ID: "old", Owner: "alice", Token: "expired", TodoID: id, ExpiresAt: &past}))
+	_, err = uc.OpenLink("expired", "")
+	assert.ErrorIs(t, err, domain.ErrExpired)
+	_, err = uc.OpenLink("", "")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	// a link to a deleted todo shows nothing
+	require.NoError(t, todos.DeleteTodo("alice", id, 0))
+	_, _, err = uc.LinkTodos(opened, 0, 10)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestLinkTodos_Filter(t *testing.T) {
+	uc, _, _ := newFixture(t)
+	open := false
+	link, err := uc.CreateLink("alice", LinkSpec{Filter: &domain.LinkFilter{Tag: "shop", Done: &open}})
+	require.NoError(t, err)
+
+	shown, total, err := uc.LinkTodos(link, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total, "todos of other users are never shown")
+	require.Len(t, shown, 1)
+	assert.Equal(t, "Buy milk", shown[0].Title)
+	assert.Equal(t, []string{"shop"}, shown[0].Tags)
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when the user has no project with the
+	// requested ID.
+	ErrNotFound = errors.New("project not found")
+	// ErrArchived is returned when filing a todo under an archived project;
+	// it has to be unarchived first.
+	ErrArchived = errors.New("project is archived")
+)
+
+// ValidationError reports a project field that does not satisfy the domain rules.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// Project groups a user's todos. Todos that are in no project are in the
+// user's inbox.
+type Project struct {
+	ID       string `json:"id" example:"0b7e6f1c-8a3d-4c55-9d2e-3f1a2b4c5d6e"`
+	Owner    string `json:"owner" readOnly:"true" doc:"User the project belongs to"`
+	Name     string `json:"name" example:"Groceries" doc:"Name of the project"`
+	Color    string `json:"color,omitempty" example:"#3b82f6" doc:"Color of the project as #rrggbb"`
+	Archived bool   `json:"archived" doc:"Whether the project is archived; no todos can be filed under an archived project"`
+	// Position orders a user's projects, from 0.
+	Position  int       `json:"position" readOnly:"true" doc:"Zero-based position of the project in the user's list"`
+	CreatedAt time.Time `json:"createdAt" readOnly:"true"`
+}
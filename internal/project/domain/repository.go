@@ -0,0 +1,13 @@
+package domain
+
+// ProjectRepository stores projects. All lookups are scoped to the owning
+// user.
+type ProjectRepository interface {
+	Save(p *Project) error
+	FindByID(owner, id string) (*Project, error)
+	// FindByOwner returns a user's projects ordered by position.
+	FindByOwner(owner string) ([]*Project, error)
+	// Update writes Name, Color, Archived and Position.
+	Update(p *Project) error
+	DeleteByID(owner, id string) error
+}
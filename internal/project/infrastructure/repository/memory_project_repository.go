@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"todo-app/internal/project/domain"
+)
+
+type MemoryProjectRepository struct {
+	mu       sync.Mutex
+	projects map[string]*domain.Project
+}
+
+func NewMemoryProjectRepository() *MemoryProjectRepository {
+	return &MemoryProjectRepository{projects: map[string]*domain.Project{}}
+}
+
+func (r *MemoryProjectRepository) Save(p *domain.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := *p
+	r.projects[p.ID] = &c
+	return nil
+}
+
+func (r *MemoryProjectRepository) FindByID(owner, id string) (*domain.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.projects[id]
+	if !ok || p.Owner != owner {
+		return nil, domain.ErrNotFound
+	}
+	c := *p
+	return &c, nil
+}
+
+func (r *MemoryProjectRepository) FindByOwner(owner string) ([]*domain.Project, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := []*domain.Project{}
+	for _, p := range r.projects {
+		if p.Owner == owner {
+			c := *p
+			list = append(list, &c)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Position != list[j].Position {
+			return list[i].Position < list[j].Position
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+func (r *MemoryProjectRepository) Update(p *domain.Project) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.projects[p.ID]
+	if !ok || stored.Owner != p.Owner {
+		return domain.ErrNotFound
+	}
+	stored.Name = p.Name
+	stored.Color = p.Color
+	stored.Archived = p.Archived
+	stored.Position = p.Position
+	return nil
+}
+
+func (r *MemoryProjectRepository) DeleteByID(owner, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.projects[id]
+	if !ok || p.Owner != owner {
+		return domain.ErrNotFound
+	}
+	delete(r.projects, id)
+	return nil
+}
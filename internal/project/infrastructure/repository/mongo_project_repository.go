@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo-app/internal/project/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoProjectRepository implements domain.ProjectRepository using MongoDB.
+type MongoProjectRepository struct {
+	collection *mongo.Collection
+}
+
+// projectDocument is the persisted shape of a project in the "projects"
+// collection.
+type projectDocument struct {
+	ID        string    `bson:"_id"`
+	Owner     string    `bson:"owner"`
+	Name      string    `bson:"name"`
+	Color     string    `bson:"color,omitempty"`
+	Archived  bool      `bson:"archived"`
+	Position  int       `bson:"position"`
+	CreatedAt time.Time `bson:"createdAt"`
+}
+
+func (d *projectDocument) toDomain() *domain.Project {
+	p := domain.Project(*d)
+	return &p
+}
+
+// NewMongoProjectRepository creates the repository and ensures an index
+// for listing a user's projects in order.
+func NewMongoProjectRepository(db *mongo.Database) *MongoProjectRepository {
+	coll := db.Collection("projects")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, _ = coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "owner", Value: 1}, {Key: "position", Value: 1}},
+		Options: options.Index().SetName("owner_position"),
+	})
+	return &MongoProjectRepository{collection: coll}
+}
+
+func (r *MongoProjectRepository) Save(p *domain.Project) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := r.collection.InsertOne(ctx, projectDocument(*p))
+	return err
+}
+
+func (r *MongoProjectRepository) FindByID(owner, id string) (*domain.Project, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var doc projectDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "owner": owner}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc.toDomain(), nil
+}
+
+func (r *MongoProjectRepository) FindByOwner(owner string) ([]*domain.Project, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cursor, err := r.collection.Find(ctx, bson.M{"owner": owner}, options.Find().SetSort(bson.D{{Key: "position", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	list := []*domain.Project{}
+	for cursor.Next(ctx) {
+		var doc projectDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		list = append(list, doc.toDomain())
+	}
+	return list, cursor.Err()
+}
+
+func (r *MongoProjectRepository) Update(p *domain.Project) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": p.ID, "owner": p.Owner}, bson.M{"$set": bson.M{
+		"name":     p.Name,
+		"color":    p.Color,
+		"archived": p.Archived,
+		"position": p.Position,
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoProjectRepository) DeleteByID(owner, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "owner": owner})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package http
+
+import (
+	"todo-app/internal/project/domain"
+)
+
+type (
+	CreateProjectInput struct {
+		Body struct {
+			Name  string `json:"name" maxLength:"100" doc:"Name of the project" example:"Groceries"`
+			Color string `json:"color,omitempty" doc:"Color of the project as #rrggbb" example:"#3b82f6"`
+		}
+	}
+	ListProjectsInput struct {
+		Archived string `query:"archived" enum:"true,false" doc:"Only list archived, or only unarchived, projects"`
+	}
+	ProjectInput struct {
+		ID string `path:"id" doc:"ID of the project"`
+	}
+	UpdateProjectInput struct {
+		ID   string `path:"id" doc:"ID of the project"`
+		Body struct {
+			Name     *string `json:"name,omitempty" maxLength:"100" doc:"New name" example:"Groceries"`
+			Color    *string `json:"color,omitempty" doc:"New color as #rrggbb; empty to clear it" example:"#22c55e"`
+			Archived *bool   `json:"archived,omitempty" doc:"Archive or unarchive the project" example:"true"`
+		}
+	}
+	ReorderProjectsInput struct {
+		Body struct {
+			ProjectIDs []string `json:"projectIds" doc:"IDs of all your projects in their new order"`
+		}
+	}
+	DeleteProjectInput struct {
+		ID    string `path:"id" doc:"ID of the project"`
+		Todos string `query:"todos" enum:"inbox,delete" default:"inbox" doc:"Move the project's todo items to the inbox, or to the trash"`
+	}
+)
+
+type (
+	ProjectOutput struct {
+		Body struct {
+			Project *domain.Project `json:"project" doc:"Project"`
+		}
+	}
+	ListProjectsOutput struct {
+		Body struct {
+			Data []*domain.Project `json:"data" doc:"Projects of the caller, in order"`
+		}
+	}
+	DeleteProjectOutput struct {
+		Body struct {
+			Message string `json:"message" example:"Project deleted successfully" doc:"Confirmation message"`
+		}
+	}
+)
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"todo-app/internal/api/middleware"
+	"todo-app/internal/project/usecase"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+type ProjectHandler struct {
+	uc *usecase.ProjectUseCase
+}
+
+func NewProjectHandler(api huma.API, uc *usecase.ProjectUseCase) {
+	handler := &ProjectHandler{uc: uc}
+
+	grp := huma.NewGroup(api, "/projects")
+	myAuthSecurity := []map[string][]string{
+		{"myAuth": {}},
+	}
+	huma.Register(grp, huma.Operation{
+		OperationID: "create-project",
+		Summary:     "Create a project",
+		Method:      http.MethodPost,
+		Path:        "",
+		Security:    myAuthSecurity,
+	}, handler.Create)
+	huma.Register(grp, huma.Operation{
+		OperationID: "list-projects",
+		Summary:     "List your projects in order",
+		Method:      http.MethodGet,
+		Path:        "",
+		Security:    myAuthSecurity,
+	}, handler.List)
+	// static paths go before /{id} for routers that match in order
+	huma.Register(grp, huma.Operation{
+		OperationID: "reorder-projects",
+		Summary:     "Reorder your projects",
+		Method:      http.MethodPut,
+		Path:        "/order",
+		Security:    myAuthSecurity,
+	}, handler.Reorder)
+	huma.Register(grp, huma.Operation{
+		OperationID: "get-project",
+		Summary:     "Get a project",
+		Method:      http.MethodGet,
+		Path:        "/{id}",
+		Security:    myAuthSecurity,
+	}, handler.Get)
+	huma.Register(grp, huma.Operation{
+		OperationID: "update-project",
+		Summary:     "Rename, recolor, archive or unarchive a project",
+		Method:      http.MethodPatch,
+		Path:        "/{id}",
+		Security:    myAuthSecurity,
+	}, handler.Update)
+	huma.Register(grp, huma.Operation{
+		OperationID: "delete-project",
+		Summary:     "Delete a project",
+		Description: "The project's todo items are moved to the inbox, or with todos=delete to the trash.",
+		Method:      http.MethodDelete,
+		Path:        "/{id}",
+		Security:    myAuthSecurity,
+	}, handler.Delete)
+}
+
+func (h *ProjectHandler) Create(ctx context.Context, input *CreateProjectInput) (*ProjectOutput, error) {
+	p, err := h.uc.CreateProject(middleware.UserID(ctx), input.Body.Name, input.Body.Color)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ProjectOutput{}
+	resp.Body.Project = p
+	return resp, nil
+}
+
+func (h *ProjectHandler) List(ctx context.Context, input *ListProjectsInput) (*ListProjectsOutput, error) {
+	var archived *bool
+	if input.Archived != "" {
+		value := input.Archived == "true"
+		archived = &value
+	}
+	list, err := h.uc.ListProjects(middleware.UserID(ctx), archived)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListProjectsOutput{}
+	resp.Body.Data = list
+	return resp, nil
+}
+
+func (h *ProjectHandler) Reorder(ctx context.Context, input *ReorderProjectsInput) (*ListProjectsOutput, error) {
+	list, err := h.uc.ReorderProjects(middleware.UserID(ctx), input.Body.ProjectIDs)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListProjectsOutput{}
+	resp.Body.Data = list
+	return resp, nil
+}
+
+func (h *ProjectHandler) Get(ctx context.Context, input *ProjectInput) (*ProjectOutput, error) {
+	p, err := h.uc.GetProject(middleware.UserID(ctx), input.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ProjectOutput{}
+	resp.Body.Project = p
+	return resp, nil
+}
+
+func (h *ProjectHandler) Update(ctx context.Context, input *UpdateProjectInput) (*ProjectOutput, error) {
+	p, err := h.uc.UpdateProject(middleware.UserID(ctx), input.ID, input.Body.Name, input.Body.Color, input.Body.Archived)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ProjectOutput{}
+	resp.Body.Project = p
+	return resp, nil
+}
+
+func (h *ProjectHandler) Delete(ctx context.Context, input *DeleteProjectInput) (*DeleteProjectOutput, error) {
+	if err := h.uc.DeleteProject(middleware.UserID(ctx), input.ID, usecase.DeleteMode(input.Todos)); err != nil {
+		return nil, err
+	}
+	resp := &DeleteProjectOutput{}
+	resp.Body.Message = "Project deleted successfully"
+	return resp, nil
+}
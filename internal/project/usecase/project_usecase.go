@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/project/domain"
+	todoDomain "todo-app/internal/todo/domain"
+	todoUsecase "todo-app/internal/todo/usecase"
+
+	"github.com/google/uuid"
+)
+
+// DeleteMode says what happens to the todos of a project that is deleted.
+type DeleteMode string
+
+const (
+	// DeleteTodos moves the todos to the trash along with the project.
+	DeleteTodos DeleteMode = "delete"
+	// MoveToInbox keeps the todos, filed under no project.
+	MoveToInbox DeleteMode = "inbox"
+)
+
+// maxNameLength caps the length of a project name, in characters.
+const maxNameLength = 100
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+// ProjectUseCase manages a user's projects. It is what the todo use case
+// checks the projects of todos with.
+type ProjectUseCase struct {
+	projects domain.ProjectRepository
+	todos    *todoUsecase.TodoUseCase
+}
+
+func NewProjectUseCase(projects domain.ProjectRepository, todos *todoUsecase.TodoUseCase) *ProjectUseCase {
+	return &ProjectUseCase{projects: projects, todos: todos}
+}
+
+// CreateProject adds a project at the end of owner's list.
+func (uc *ProjectUseCase) CreateProject(owner, name, color string) (*domain.Project, error) {
+	name, err := validateName(name)
+	if err != nil {
+		return nil, err
+	}
+	if color, err = validateColor(color); err != nil {
+		return nil, err
+	}
+	existing, err := uc.projects.FindByOwner(owner)
+	if err != nil {
+		return nil, err
+	}
+	p := &domain.Project{
+		ID:        uuid.New().String(),
+		Owner:     owner,
+		Name:      name,
+		Color:     color,
+		CreatedAt: time.Now(),
+	}
+	if len(existing) > 0 {
+		p.Position = existing[len(existing)-1].Position + 1
+	}
+	if err := uc.projects.Save(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ListProjects returns owner's projects in order. A non-nil archived only
+// returns the projects that are, or are not, archived.
+func (uc *ProjectUseCase) ListProjects(owner string, archived *bool) ([]*domain.Project, error) {
+	list, err := uc.projects.FindByOwner(owner)
+	if err != nil || archived == nil {
+		return list, err
+	}
+	kept := list[:0]
+	for _, p := range list {
+		if p.Archived == *archived {
+			kept = append(kept, p)
+		}
+	}
+	return kept, nil
+}
+
+func (uc *ProjectUseCase) GetProject(owner, id string) (*domain.Project, error) {
+	return uc.projects.FindByID(owner, id)
+}
+
+// UpdateProject changes the fields that are not nil. An empty color
+// clears it.
+func (uc *ProjectUseCase) UpdateProject(owner, id string, name, color *string, archived *bool) (*domain.Project, error) {
+	p, err := uc.projects.FindByID(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if name != nil {
+		if p.Name, err = validateName(*name); err != nil {
+			return nil, err
+		}
+	}
+	if color != nil {
+		if p.Color, err = validateColor(*color); err != nil {
+			return nil, err
+		}
+	}
+	if archived != nil {
+		p.Archived = *archived
+	}
+	if err := uc.projects.Update(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ReorderProjects puts owner's projects in the given order. ids must list
+// every project of owner exactly once.
+func (uc *ProjectUseCase) ReorderProjects(owner string, ids []string) ([]*domain.Project, error) {
+	list, err := uc.projects.FindByOwner(owner)
+	if err != nil {
+		return nil, err
+	}
+	invalid := &domain.ValidationError{Field: "projectIds", Message: "must list every project exactly once"}
+	if len(ids) != len(list) {
+		return nil, invalid
+	}
+	byID := make(map[string]*domain.Project, len(list))
+	for _, p := range list {
+		byID[p.ID] = p
+	}
+	ordered := make([]*domain.Project, 0, len(ids))
+	for pos, id := range ids {
+		p, ok := byID[id]
+		if !ok {
+			return nil, invalid
+		}
+		delete(byID, id)
+		if p.Position != pos {
+			p.Position = pos
+			if err := uc.projects.Update(p); err != nil {
+				return nil, err
+			}
+		}
+		ordered = append(ordered, p)
+	}
+	return ordered, nil
+}
+
+// DeleteProject deletes a project after moving its todos to the trash or
+// to the inbox, as mode says. Todos of the project that are already in the
+// trash go to the inbox when they are restored.
+func (uc *ProjectUseCase) DeleteProject(actor, id string, mode DeleteMode) error {
+	var action todoDomain.BulkAction
+	var change todoDomain.BulkChange
+	switch mode {
+	case DeleteTodos:
+		action = todoDomain.BulkDelete
+	case MoveToInbox:
+		inbox := ""
+		action, change.ProjectID = todoDomain.BulkMove, &inbox
+	default:
+		return &domain.ValidationError{Field: "todos", Message: "unknown mode " + strconv.Quote(string(mode))}
+	}
+	if _, err := uc.projects.FindByID(actor, id); err != nil {
+		return err
+	}
+	// bulk operations take a limited number of todos at a time
+	filter := todoDomain.TodoFilter{Owner: actor, Project: id}
+	for {
+		todos, _, err := uc.todos.GetAllTodos(0, todoDomain.MaxBulkItems, filter)
+		if err != nil {
+			return err
+		}
+		if len(todos) == 0 {
+			break
+		}
+		ids := make([]string, 0, len(todos))
+		for _, todo := range todos {
+			ids = append(ids, todo.ID)
+		}
+		results, err := uc.todos.BulkTodos(actor, todoUsecase.BulkSelection{IDs: ids}, action, change)
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			if r.Status == todoDomain.BulkFailed {
+				return r.Err
+			}
+		}
+	}
+	return uc.projects.DeleteByID(actor, id)
+}
+
+// CheckProject implements todoDomain.Projects: todos can be filed under
+// projects that exist and are not archived.
+func (uc *ProjectUseCase) CheckProject(owner, id string) error {
+	p, err := uc.projects.FindByID(owner, id)
+	if err != nil {
+		return err
+	}
+	if p.Archived {
+		return domain.ErrArchived
+	}
+	return nil
+}
+
+// HasProject implements todoDomain.Projects.
+func (uc *ProjectUseCase) HasProject(owner, id string) (bool, error) {
+	_, err := uc.projects.FindByID(owner, id)
+	if errors.Is(err, domain.ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > maxNameLength {
+		return "", &domain.ValidationError{Field: "name", Message: "must be 1 to " + strconv.Itoa(maxNameLength) + " characters"}
+	}
+	return name, nil
+}
+
+func validateColor(color string) (string, error) {
+	color = strings.ToLower(color)
+	if color != "" && !colorPattern.MatchString(color) {
+		return "", &domain.ValidationError{Field: "color", Message: "must be a color as #rrggbb"}
+	}
+	return color, nil
+}
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	"todo-app/internal/project/domain"
+	"todo-app/internal/project/infrastructure/repository"
+	todoDomain "todo-app/internal/todo/domain"
+	todoRepository "todo-app/internal/todo/infrastructure/repository"
+	todoUsecase "todo-app/internal/todo/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFixture() (*ProjectUseCase, *todoUsecase.TodoUseCase) {
+	todos := todoUsecase.NewTodoUseCase(todoRepository.NewMemoryTodoRepository(), todoRepository.NewMemoryHistoryRepository())
+	uc := NewProjectUseCase(repository.NewMemoryProjectRepository(), todos)
+	todos.UseProjects(uc)
+	return uc, todos
+}
+
+// titles lists the titles of the live todos under the project filter.
+func titles(t *testing.T, todos *todoUsecase.TodoUseCase, project string) []string {
+	t.Helper()
+	list, _, err := todos.GetAllTodos(0, 100, todoDomain.TodoFilter{Project: project})
+	require.NoError(t, err)
+	titles := []string{}
+	for _, todo := range list {
+		titles = append(titles, todo.Title)
+	}
+	return titles
+}
+
+func TestProjects_CRUD(t *testing.T) {
+	uc, _ := newFixture()
+	var invalid *domain.ValidationError
+
+	_, err := uc.CreateProject("alice", "  ", "")
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "name", invalid.Field)
+	_, err = uc.CreateProject("alice", "Home", "blue")
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "color", invalid.Field)
+
+	home, err := uc.CreateProject("alice", " Home ", "#3B82F6")
+	require.NoError(t, err)
+	assert.Equal(t, "Home", home.Name)
+	assert.Equal(t, "#3b82f6", home.Color)
+	work, err := uc.CreateProject("alice", "Work", "")
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1}, []int{home.Position, work.Position})
+
+	_, err = uc.GetProject("bob", home.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	archived := true
+	_, err = uc.UpdateProject("alice", work.ID, nil, nil, &archived)
+	require.NoError(t, err)
+	list, err := uc.ListProjects("alice", &archived)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "Work", list[0].Name)
+
+	_, err = uc.ReorderProjects("alice", []string{work.ID, work.ID})
+	require.ErrorAs(t, err, &invalid)
+	_, err = uc.ReorderProjects("alice", []string{work.ID, home.ID})
+	require.NoError(t, err)
+	list, err = uc.ListProjects("alice", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Work", "Home"}, []string{list[0].Name, list[1].Name})
+}
+
+func TestProjects_FileTodos(t *testing.T) {
+	uc, todos := newFixture()
+	home, err := uc.CreateProject("alice", "Home", "")
+	require.NoError(t, err)
+	work, err := uc.CreateProject("alice", "Work", "")
+	require.NoError(t, err)
+
+	require.NoError(t, todos.CreateTodo("alice", "Dishes", time.Time{}, false, todoUsecase.WithProject(home.ID)))
+	require.NoError(t, todos.CreateTodo("alice", "Loose end", time.Time{}, false))
+	err = todos.CreateTodo("bob", "Sneak in", time.Time{}, false, todoUsecase.WithProject(home.ID))
+	assert.ErrorIs(t, err, domain.ErrNotFound, "projects of other users cannot be used")
+	assert.Equal(t, []string{"Dishes"}, titles(t, todos, home.ID))
+	assert.Equal(t, []string{"Loose end"}, titles(t, todos, todoDomain.Inbox))
+
+	list, _, err := todos.GetAllTodos(0, 10, todoDomain.TodoFilter{Title: "Dishes"})
+	require.NoError(t, err)
+	dishes := list[0]
+	moved, err := todos.PatchTodo("alice", dishes.ID, todoUsecase.TodoPatch{ProjectID: &work.ID}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, work.ID, moved.ProjectID)
+
+	archived := true
+	_, err = uc.UpdateProject("alice", home.ID, nil, nil, &archived)
+	require.NoError(t, err)
+	_, err = todos.PatchTodo("alice", dishes.ID, todoUsecase.TodoPatch{ProjectID: &home.ID}, 0)
+	assert.ErrorIs(t, err, domain.ErrArchived)
+
+	results, err := todos.BulkTodos("alice", todoUsecase.BulkSelection{Filter: todoDomain.TodoFilter{Project: todoDomain.Inbox}},
+		todoDomain.BulkMove, todoDomain.BulkChange{ProjectID: &work.ID})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, todoDomain.BulkApplied, results[0].Status)
+	assert.ElementsMatch(t, []string{"Dishes", "Loose end"}, titles(t, todos, work.ID))
+}
+
+func TestDeleteProject(t *testing.T) {
+	uc, todos := newFixture()
+	var invalid *domain.ValidationError
+	home, err := uc.CreateProject("alice", "Home", "")
+	require.NoError(t, err)
+	work, err := uc.CreateProject("alice", "Work", "")
+	require.NoError(t, err)
+	require.NoError(t, todos.CreateTodo("alice", "Dishes", time.Time{}, false, todoUsecase.WithProject(home.ID)))
+	require.NoError(t, todos.CreateTodo("alice", "Report", time.Time{}, false, todoUsecase.WithProject(work.ID)))
+	require.NoError(t, todos.CreateTodo("alice", "Slides", time.Time{}, false, todoUsecase.WithProject(work.ID)))
+
+	require.ErrorAs(t, uc.DeleteProject("alice", home.ID, "archive"), &invalid)
+	assert.ErrorIs(t, uc.DeleteProject("bob", home.ID, MoveToInbox), domain.ErrNotFound)
+
+	require.NoError(t, uc.DeleteProject("alice", home.ID, MoveToInbox))
+	assert.Equal(t, []string{"Dishes"}, titles(t, todos, todoDomain.Inbox))
+	_, err = uc.GetProject("alice", home.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	require.NoError(t, uc.DeleteProject("alice", work.ID, DeleteTodos))
+	assert.Equal(t, []string{"Dishes"}, titles(t, todos, ""))
+	trash, _, err := todos.GetTrash("alice", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, trash, 2)
+
+	// a todo restored after its project is gone lands in the inbox
+	restored, err := todos.RestoreTodo("alice", trash[0].ID, 0)
+	require.NoError(t, err)
+	assert.Empty(t, restored.ProjectID)
+	assert.Len(t, titles(t, todos, todoDomain.Inbox), 2)
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when a todo has no reminder with the requested ID.
+	ErrNotFound = errors.New("reminder not found")
+)
+
+// ValidationError reports a reminder field that does not satisfy the domain rules.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
@@ -0,0 +1,75 @@
+package domain
+
+import "time"
+
+// Status is where a reminder is in its delivery lifecycle.
+type Status string
+
+const (
+	// StatusPending reminders are waiting to fire or to be retried.
+	StatusPending Status = "pending"
+	// StatusSent reminders have been delivered.
+	StatusSent Status = "sent"
+	// StatusFailed reminders ran out of delivery attempts.
+	StatusFailed Status = "failed"
+	// StatusCancelled reminders were dropped because their todo was done or
+	// deleted by the time they fired.
+	StatusCancelled Status = "cancelled"
+)
+
+// Channels a reminder can be delivered over. Which of them are available
+// depends on how the server is configured.
+const (
+	ChannelLog     = "log"
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+)
+
+// Reminder notifies about a todo at a point in time, either an absolute one
+// (At) or one relative to the todo's due date (OffsetMinutes before it).
+// Offset reminders follow the todo when its due date moves.
+type Reminder struct {
+	ID            string     `json:"id"`
+	TodoID        string     `json:"todoId"`
+	Owner         string     `json:"owner" readOnly:"true" doc:"User who created the reminder"`
+	At            *time.Time `json:"at,omitempty" example:"2023-10-09T08:30:00Z" doc:"Absolute time to fire at; mutually exclusive with offsetMinutes"`
+	OffsetMinutes *int       `json:"offsetMinutes,omitempty" example:"30" doc:"Minutes before the todo's due date to fire at"`
+	Channel       string     `json:"channel" example:"webhook" doc:"Delivery channel: log, webhook or email"`
+	Target        string     `json:"target,omitempty" example:"https://example.com/hooks/todo" doc:"Webhook URL or email address; unused by the log channel"`
+	FireAt        time.Time  `json:"fireAt" readOnly:"true" doc:"When the reminder is due to fire"`
+	Status        Status     `json:"status" readOnly:"true" enum:"pending,sent,failed,cancelled"`
+	Attempts      int        `json:"attempts" readOnly:"true" doc:"Delivery attempts made so far"`
+	LastError     string     `json:"lastError,omitempty" readOnly:"true"`
+	SentAt        *time.Time `json:"sentAt,omitempty" readOnly:"true"`
+	// NextAttemptAt is when the dispatcher may pick the reminder up next. It
+	// starts at FireAt, is pushed out by a lease while a delivery is in
+	// flight and by the backoff after a failed one.
+	NextAttemptAt time.Time `json:"-"`
+}
+
+// Schedule computes FireAt for a todo due at due and resets the reminder so
+// it fires there. Offset reminders cannot be scheduled without a due date.
+func (r *Reminder) Schedule(due time.Time) error {
+	switch {
+	case r.At != nil:
+		r.FireAt = *r.At
+	case r.OffsetMinutes != nil:
+		if due.IsZero() {
+			return &ValidationError{Field: "offsetMinutes", Message: "needs the todo to have a due date"}
+		}
+		r.FireAt = due.Add(-time.Duration(*r.OffsetMinutes) * time.Minute)
+	default:
+		return &ValidationError{Field: "at", Message: "either at or offsetMinutes is required"}
+	}
+	r.NextAttemptAt = r.FireAt
+	return nil
+}
+
+// Notification is what a channel delivers when a reminder fires.
+type Notification struct {
+	ReminderID string    `json:"reminderId"`
+	TodoID     string    `json:"todoId"`
+	Title      string    `json:"title"`
+	DueDate    time.Time `json:"dueDate"`
+	FireAt     time.Time `json:"fireAt"`
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// ReminderRepository stores reminders. The dispatcher finds work through
+// ClaimDue, which leases reminders so that concurrent dispatchers do not
+// deliver the same one twice while a delivery is in flight; a dispatcher
+// that dies mid-delivery lets its lease run out and the reminder is claimed
+// again, which makes delivery at-least-once.
+type ReminderRepository interface {
+	Save(reminder *Reminder) error
+	FindByTodoID(todoID string) ([]*Reminder, error)
+	DeleteByID(todoID, id string) error
+	DeleteByTodoID(todoID string) error
+	// Reschedule moves the pending, not yet attempted offset reminders of
+	// a todo to fire relative to its new due date.
+	Reschedule(todoID string, due time.Time) error
+
+	// ClaimDue returns up to limit pending reminders whose next attempt is
+	// at or before now, and leases them until now+lease.
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]*Reminder, error)
+	// Update writes the delivery state (Status, Attempts, LastError, SentAt
+	// and NextAttemptAt) of a claimed reminder.
+	Update(reminder *Reminder) error
+}
+
+// Notifier delivers notifications over one channel. target is the
+// reminder's channel-specific address.
+type Notifier interface {
+	Notify(target string, n Notification) error
+}
+
+// Mailer sends plain text email. The email channel is built on it so the
+// transport (SMTP, a provider API, a test double) can be swapped.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
@@ -0,0 +1,43 @@
+package notifier
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+	"todo-app/internal/reminder/domain"
+)
+
+// EmailNotifier mails the reminder to its target address through a Mailer.
+type EmailNotifier struct {
+	Mailer domain.Mailer
+}
+
+func (e *EmailNotifier) Notify(target string, n domain.Notification) error {
+	// titles are free text; keep them from smuggling in extra headers
+	subject := "Reminder: " + strings.NewReplacer("\r", " ", "\n", " ").Replace(n.Title)
+	body := fmt.Sprintf("%s\n\nDue: %s\nTodo: %s\n", n.Title, n.DueDate.Format("Mon, 02 Jan 2006 15:04 MST"), n.TodoID)
+	return e.Mailer.Send(target, subject, body)
+}
+
+// SMTPMailer sends mail through an SMTP server, authenticating with PLAIN
+// auth when a username is set.
+type SMTPMailer struct {
+	Addr     string // host:port
+	From     string
+	Username string
+	Password string
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		host, _, _ := strings.Cut(m.Addr, ":")
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+	msg := "From: " + m.From + "\r\n" +
+		"To: " + to + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" + strings.ReplaceAll(body, "\n", "\r\n")
+	return smtp.SendMail(m.Addr, auth, m.From, []string{to}, []byte(msg))
+}
@@ -0,0 +1,15 @@
+package notifier
+
+import (
+	"log"
+	"todo-app/internal/reminder/domain"
+)
+
+// LogNotifier writes reminders to the process log. It never fails, which
+// makes it a useful default channel and a stand-in during development.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(_ string, n domain.Notification) error {
+	log.Printf("reminder %s: todo %s %q is due %s", n.ReminderID, n.TodoID, n.Title, n.DueDate.Format("2006-01-02 15:04 MST"))
+	return nil
+}
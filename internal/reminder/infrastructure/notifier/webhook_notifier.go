@@ -0,0 +1,43 @@
+package notifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"todo-app/internal/reminder/domain"
+)
+
+// WebhookNotifier POSTs the notification as JSON to the reminder's target
+// URL. Any non-2xx response counts as a failed delivery.
+type WebhookNotifier struct {
+	Client *http.Client
+}
+
+func NewWebhookNotifier(timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{Client: &http.Client{Timeout: timeout}}
+}
+
+func (w *WebhookNotifier) Notify(target string, n domain.Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// receivers can deduplicate redeliveries on this
+	req.Header.Set("Idempotency-Key", n.ReminderID)
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+	"todo-app/internal/reminder/domain"
+)
+
+type MemoryReminderRepository struct {
+	mu        sync.Mutex
+	reminders map[string]*domain.Reminder
+}
+
+func NewMemoryReminderRepository() *MemoryReminderRepository {
+	return &MemoryReminderRepository{reminders: map[string]*domain.Reminder{}}
+}
+
+func (r *MemoryReminderRepository) Save(reminder *domain.Reminder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *reminder
+	r.reminders[reminder.ID] = &stored
+	return nil
+}
+
+func (r *MemoryReminderRepository) FindByTodoID(todoID string) ([]*domain.Reminder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := []*domain.Reminder{}
+	for _, rem := range r.reminders {
+		if rem.TodoID == todoID {
+			copy := *rem
+			list = append(list, &copy)
+		}
+	}
+	sortByFireAt(list)
+	return list, nil
+}
+
+func (r *MemoryReminderRepository) DeleteByID(todoID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rem, ok := r.reminders[id]
+	if !ok || rem.TodoID != todoID {
+		return domain.ErrNotFound
+	}
+	delete(r.reminders, id)
+	return nil
+}
+
+func (r *MemoryReminderRepository) DeleteByTodoID(todoID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, rem := range r.reminders {
+		if rem.TodoID == todoID {
+			delete(r.reminders, id)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryReminderRepository) Reschedule(todoID string, due time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rem := range r.reminders {
+		if rem.TodoID != todoID || rem.OffsetMinutes == nil || rem.Status != domain.StatusPending || rem.Attempts > 0 {
+			continue
+		}
+		if due.IsZero() {
+			// without a due date there is nothing to be reminded of
+			rem.Status = domain.StatusCancelled
+			continue
+		}
+		_ = rem.Schedule(due)
+	}
+	return nil
+}
+
+func (r *MemoryReminderRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]*domain.Reminder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	due := []*domain.Reminder{}
+	for _, rem := range r.reminders {
+		if rem.Status == domain.StatusPending && !rem.NextAttemptAt.After(now) {
+			due = append(due, rem)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	claimed := make([]*domain.Reminder, 0, len(due))
+	for _, rem := range due {
+		rem.NextAttemptAt = now.Add(lease)
+		copy := *rem
+		claimed = append(claimed, &copy)
+	}
+	return claimed, nil
+}
+
+func (r *MemoryReminderRepository) Update(reminder *domain.Reminder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rem, ok := r.reminders[reminder.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	rem.Status = reminder.Status
+	rem.Attempts = reminder.Attempts
+	rem.LastError = reminder.LastError
+	rem.SentAt = reminder.SentAt
+	rem.NextAttemptAt = reminder.NextAttemptAt
+	return nil
+}
+
+func sortByFireAt(list []*domain.Reminder) {
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].FireAt.Equal(list[j].FireAt) {
+			return list[i].FireAt.Before(list[j].FireAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+}
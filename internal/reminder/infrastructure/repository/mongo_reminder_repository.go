@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo-app/internal/reminder/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoReminderRepository implements domain.ReminderRepository using MongoDB.
+type MongoReminderRepository struct {
+	collection *mongo.Collection
+}
+
+// reminderDocument is the persisted shape of a reminder in the "reminders"
+// collection.
+type reminderDocument struct {
+	ID            string     `bson:"_id"`
+	TodoID        string     `bson:"todoId"`
+	Owner         string     `bson:"owner"`
+	At            *time.Time `bson:"at,omitempty"`
+	OffsetMinutes *int       `bson:"offsetMinutes,omitempty"`
+	Channel       string     `bson:"channel"`
+	Target        string     `bson:"target,omitempty"`
+	FireAt        time.Time  `bson:"fireAt"`
+	Status        string     `bson:"status"`
+	Attempts      int        `bson:"attempts"`
+	LastError     string     `bson:"lastError,omitempty"`
+	SentAt        *time.Time `bson:"sentAt,omitempty"`
+	NextAttemptAt time.Time  `bson:"nextAttemptAt"`
+}
+
+func (d *reminderDocument) toDomain() *domain.Reminder {
+	return &domain.Reminder{
+		ID:            d.ID,
+		TodoID:        d.TodoID,
+		Owner:         d.Owner,
+		At:            d.At,
+		OffsetMinutes: d.OffsetMinutes,
+		Channel:       d.Channel,
+		Target:        d.Target,
+		FireAt:        d.FireAt,
+		Status:        domain.Status(d.Status),
+		Attempts:      d.Attempts,
+		LastError:     d.LastError,
+		SentAt:        d.SentAt,
+		NextAttemptAt: d.NextAttemptAt,
+	}
+}
+
+// NewMongoReminderRepository creates the repository and ensures the indexes
+// used to list a todo's reminders and to find due ones.
+func NewMongoReminderRepository(db *mongo.Database) *MongoReminderRepository {
+	coll := db.Collection("reminders")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, _ = coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "todoId", Value: 1}, {Key: "fireAt", Value: 1}},
+			Options: options.Index().SetName("todoId_fireAt"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}},
+			Options: options.Index().SetName("status_nextAttemptAt"),
+		},
+	})
+	return &MongoReminderRepository{collection: coll}
+}
+
+func (r *MongoReminderRepository) Save(reminder *domain.Reminder) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := r.collection.InsertOne(ctx, reminderDocument{
+		ID:            reminder.ID,
+		TodoID:        reminder.TodoID,
+		Owner:         reminder.Owner,
+		At:            reminder.At,
+		OffsetMinutes: reminder.OffsetMinutes,
+		Channel:       reminder.Channel,
+		Target:        reminder.Target,
+		FireAt:        reminder.FireAt,
+		Status:        string(reminder.Status),
+		Attempts:      reminder.Attempts,
+		LastError:     reminder.LastError,
+		SentAt:        reminder.SentAt,
+		NextAttemptAt: reminder.NextAttemptAt,
+	})
+	return err
+}
+
+func (r *MongoReminderRepository) FindByTodoID(todoID string) ([]*domain.Reminder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cursor, err := r.collection.Find(ctx, bson.M{"todoId": todoID}, options.Find().SetSort(bson.D{{Key: "fireAt", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	return decodeAll(ctx, cursor)
+}
+
+func (r *MongoReminderRepository) DeleteByID(todoID, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "todoId": todoID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoReminderRepository) DeleteByTodoID(todoID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := r.collection.DeleteMany(ctx, bson.M{"todoId": todoID})
+	return err
+}
+
+func (r *MongoReminderRepository) Reschedule(todoID string, due time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	filter := bson.M{
+		"todoId":        todoID,
+		"offsetMinutes": bson.M{"$ne": nil},
+		"status":        string(domain.StatusPending),
+		"attempts":      0,
+	}
+	if due.IsZero() {
+		// without a due date there is nothing to be reminded of
+		_, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"status": string(domain.StatusCancelled)}})
+		return err
+	}
+	// fireAt = due - offsetMinutes, computed per document
+	fireAt := bson.M{"$subtract": bson.A{due, bson.M{"$multiply": bson.A{"$offsetMinutes", 60 * 1000}}}}
+	_, err := r.collection.UpdateMany(ctx, filter, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"fireAt": fireAt, "nextAttemptAt": fireAt}}},
+	})
+	return err
+}
+
+func (r *MongoReminderRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]*domain.Reminder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	filter := bson.M{"status": string(domain.StatusPending), "nextAttemptAt": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"nextAttemptAt": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}}).
+		SetReturnDocument(options.After)
+	// claim one at a time so each lease is taken atomically
+	claimed := []*domain.Reminder{}
+	for len(claimed) < limit {
+		var doc reminderDocument
+		err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&doc)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			return claimed, err
+		}
+		claimed = append(claimed, doc.toDomain())
+	}
+	return claimed, nil
+}
+
+func (r *MongoReminderRepository) Update(reminder *domain.Reminder) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := r.collection.UpdateByID(ctx, reminder.ID, bson.M{"$set": bson.M{
+		"status":        string(reminder.Status),
+		"attempts":      reminder.Attempts,
+		"lastError":     reminder.LastError,
+		"sentAt":        reminder.SentAt,
+		"nextAttemptAt": reminder.NextAttemptAt,
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func decodeAll(ctx context.Context, cursor *mongo.Cursor) ([]*domain.Reminder, error) {
+	defer cursor.Close(ctx)
+	list := []*domain.Reminder{}
+	for cursor.Next(ctx) {
+		var doc reminderDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		list = append(list, doc.toDomain())
+	}
+	return list, cursor.Err()
+}
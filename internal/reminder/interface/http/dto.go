@@ -0,0 +1,44 @@
+package http
+
+import (
+	"time"
+	"todo-app/internal/reminder/domain"
+)
+
+type (
+	AddReminderInput struct {
+		ID   string `path:"id" doc:"ID of the todo item"`
+		Body struct {
+			At            *time.Time `json:"at,omitempty" doc:"Absolute time to fire at; mutually exclusive with offsetMinutes" example:"2023-10-10T08:00:00Z"`
+			OffsetMinutes *int       `json:"offsetMinutes,omitempty" doc:"Minutes before the todo's due date to fire at" example:"30"`
+			Channel       string     `json:"channel" doc:"Delivery channel: log, webhook or email, as far as configured" example:"webhook"`
+			Target        string     `json:"target,omitempty" doc:"Webhook URL or email address; unused by the log channel" example:"https://example.com/hooks/todo"`
+		}
+	}
+	ListRemindersInput struct {
+		ID string `path:"id" doc:"ID of the todo item"`
+	}
+	DeleteReminderInput struct {
+		ID         string `path:"id" doc:"ID of the todo item"`
+		ReminderID string `path:"reminderId" doc:"ID of the reminder"`
+	}
+)
+
+type (
+	AddReminderOutput struct {
+		Body struct {
+			Reminder *domain.Reminder `json:"reminder" doc:"Created reminder"`
+		}
+	}
+	ListRemindersOutput struct {
+		Body struct {
+			Data     []*domain.Reminder `json:"data" doc:"Reminders of the todo item, soonest first"`
+			Channels []string           `json:"channels" doc:"Delivery channels configured on this server"`
+		}
+	}
+	DeleteReminderOutput struct {
+		Body struct {
+			Message string `json:"message" example:"Reminder deleted successfully" doc:"Confirmation message"`
+		}
+	}
+)
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"todo-app/internal/api/middleware"
+	"todo-app/internal/reminder/usecase"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+type ReminderHandler struct {
+	uc *usecase.ReminderUseCase
+}
+
+func NewReminderHandler(api huma.API, uc *usecase.ReminderUseCase) {
+	handler := &ReminderHandler{uc: uc}
+
+	grp := huma.NewGroup(api, "/todos")
+	myAuthSecurity := []map[string][]string{
+		{"myAuth": {}},
+	}
+	huma.Register(grp, huma.Operation{
+		OperationID: "add-reminder",
+		Summary:     "Add a reminder to a todo item",
+		Description: "Reminders fire at an absolute time or a number of minutes before the due date; offset reminders follow the due date when it changes.",
+		Method:      http.MethodPost,
+		Path:        "/{id}/reminders",
+		Security:    myAuthSecurity,
+	}, handler.Add)
+	huma.Register(grp, huma.Operation{
+		OperationID: "list-reminders",
+		Summary:     "List the reminders of a todo item",
+		Method:      http.MethodGet,
+		Path:        "/{id}/reminders",
+		Security:    myAuthSecurity,
+	}, handler.List)
+	huma.Register(grp, huma.Operation{
+		OperationID: "delete-reminder",
+		Summary:     "Delete a reminder",
+		Method:      http.MethodDelete,
+		Path:        "/{id}/reminders/{reminderId}",
+		Security:    myAuthSecurity,
+	}, handler.Delete)
+}
+
+func (h *ReminderHandler) Add(ctx context.Context, input *AddReminderInput) (*AddReminderOutput, error) {
+	reminder, err := h.uc.AddReminder(middleware.UserID(ctx), input.ID, input.Body.At, input.Body.OffsetMinutes, input.Body.Channel, input.Body.Target)
+	if err != nil {
+		return nil, err
+	}
+	resp := &AddReminderOutput{}
+	resp.Body.Reminder = reminder
+	return resp, nil
+}
+
+func (h *ReminderHandler) List(ctx context.Context, input *ListRemindersInput) (*ListRemindersOutput, error) {
+	reminders, err := h.uc.ListReminders(middleware.UserID(ctx), input.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListRemindersOutput{}
+	resp.Body.Data = reminders
+	resp.Body.Channels = h.uc.Channels()
+	return resp, nil
+}
+
+func (h *ReminderHandler) Delete(ctx context.Context, input *DeleteReminderInput) (*DeleteReminderOutput, error) {
+	if err := h.uc.DeleteReminder(middleware.UserID(ctx), input.ID, input.ReminderID); err != nil {
+		return nil, err
+	}
+	resp := &DeleteReminderOutput{}
+	resp.Body.Message = "Reminder deleted successfully"
+	return resp, nil
+}
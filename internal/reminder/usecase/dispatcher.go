@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+	"todo-app/internal/delivery"
+	"todo-app/internal/reminder/domain"
+	todoDomain "todo-app/internal/todo/domain"
+	todoUsecase "todo-app/internal/todo/usecase"
+)
+
+// Dispatcher delivers due reminders in the background. Each pass claims the
+// reminders that are due, delivers them over their channel and records the
+// outcome; failed deliveries are retried with exponential backoff until
+// MaxAttempts is reached. A reminder is only delivered while its owner can
+// still see its todo.
+type Dispatcher struct {
+	*delivery.Worker[*domain.Reminder]
+	todos    todoDomain.TodoRepository
+	channels map[string]domain.Notifier
+
+	// TrashRecheck is how long the reminders of todos in the trash wait
+	// before they are looked at again, in case the todo was restored.
+	TrashRecheck time.Duration
+
+	mu     sync.Mutex
+	access *todoUsecase.TodoUseCase
+}
+
+// NewDispatcher creates a dispatcher with sensible defaults for the lease,
+// batch size and backoff. It delivers nothing until it is given the todo
+// use case to check access with.
+func NewDispatcher(repo domain.ReminderRepository, todos todoDomain.TodoRepository, channels map[string]domain.Notifier, interval time.Duration, maxAttempts int) *Dispatcher {
+	d := &Dispatcher{todos: todos, channels: channels, TrashRecheck: time.Hour}
+	d.Worker = delivery.NewWorker("reminders", repo, d.deliver, interval, maxAttempts)
+	// channels such as email can take a while to give up
+	d.Lease = 5 * time.Minute
+	d.Backoff = delivery.Exponential(30*time.Second, time.Hour)
+	return d
+}
+
+// Use sets the todo use case that decides whether the owner of a reminder
+// can still see its todo, e.g. after a share was revoked.
+func (d *Dispatcher) Use(todos *todoUsecase.TodoUseCase) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.access = todos
+}
+
+func (d *Dispatcher) deliver(reminder *domain.Reminder, now time.Time) bool {
+	d.mu.Lock()
+	access := d.access
+	d.mu.Unlock()
+	if access == nil {
+		return false
+	}
+	todo, err := access.GetTodoByID(reminder.Owner, reminder.TodoID)
+	switch {
+	case errors.Is(err, todoDomain.ErrNotFound):
+		return d.unavailable(reminder, now)
+	case err != nil:
+		// the todo could not be read; try again once the lease runs out
+		log.Printf("reminders: load todo %s for reminder %s: %v", reminder.TodoID, reminder.ID, err)
+		return false
+	case todo.Done:
+		reminder.Status = domain.StatusCancelled
+		reminder.LastError = "todo was done"
+	default:
+		d.notify(reminder, todo, now)
+	}
+	return true
+}
+
+// unavailable handles a reminder whose owner cannot see its todo: the todo
+// is in the trash, from where it may come back, or it was deleted, or it
+// is no longer shared with them.
+func (d *Dispatcher) unavailable(reminder *domain.Reminder, now time.Time) bool {
+	_, err := d.todos.FindTrashedByID(reminder.TodoID)
+	if err == nil {
+		// not an attempt; it fires once the todo is restored
+		reminder.LastError = "todo is in the trash"
+		reminder.NextAttemptAt = now.Add(d.TrashRecheck)
+		return true
+	}
+	if !errors.Is(err, todoDomain.ErrNotFound) {
+		log.Printf("reminders: load todo %s for reminder %s: %v", reminder.TodoID, reminder.ID, err)
+		return false
+	}
+	reminder.Status = domain.StatusCancelled
+	reminder.LastError = "todo was deleted"
+	if _, err := d.todos.FindByID(reminder.TodoID); err == nil {
+		reminder.LastError = "todo is no longer shared with the owner of the reminder"
+	}
+	return true
+}
+
+func (d *Dispatcher) notify(reminder *domain.Reminder, todo *todoDomain.Todo, now time.Time) {
+	reminder.Attempts++
+	err := errors.New("channel " + reminder.Channel + " is not configured")
+	if notifier, ok := d.channels[reminder.Channel]; ok {
+		err = notifier.Notify(reminder.Target, domain.Notification{
+			ReminderID: reminder.ID,
+			TodoID:     todo.ID,
+			Title:      todo.Title,
+			DueDate:    todo.DueDate,
+			FireAt:     reminder.FireAt,
+		})
+	}
+	if err == nil {
+		sentAt := now
+		reminder.Status = domain.StatusSent
+		reminder.SentAt = &sentAt
+		reminder.LastError = ""
+		return
+	}
+	reminder.LastError = err.Error()
+	next, ok := d.Retry(reminder.Attempts, now)
+	if !ok {
+		reminder.Status = domain.StatusFailed
+		log.Printf("reminders: giving up on %s after %d attempts: %v", reminder.ID, reminder.Attempts, err)
+		return
+	}
+	reminder.NextAttemptAt = next
+}
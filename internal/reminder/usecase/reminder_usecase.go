@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"log"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"todo-app/internal/reminder/domain"
+	todoDomain "todo-app/internal/todo/domain"
+	todoUsecase "todo-app/internal/todo/usecase"
+
+	"github.com/google/uuid"
+)
+
+// ReminderUseCase manages the reminders of todos and keeps them in step with
+// changes to the todos themselves.
+type ReminderUseCase struct {
+	repo     domain.ReminderRepository
+	todos    *todoUsecase.TodoUseCase
+	channels map[string]domain.Notifier
+}
+
+// NewReminderUseCase creates the use case. channels holds the notifiers
+// that are configured, keyed by channel name; reminders can only be created
+// for those.
+func NewReminderUseCase(repo domain.ReminderRepository, todos *todoUsecase.TodoUseCase, channels map[string]domain.Notifier) *ReminderUseCase {
+	return &ReminderUseCase{repo: repo, todos: todos, channels: channels}
+}
+
+// AddReminder adds a reminder of actor to a live todo they can see.
+// Exactly one of at and offsetMinutes must be set.
+func (uc *ReminderUseCase) AddReminder(actor, todoID string, at *time.Time, offsetMinutes *int, channel, target string) (*domain.Reminder, error) {
+	todo, err := uc.todos.GetTodoByID(actor, todoID)
+	if err != nil {
+		return nil, err
+	}
+	if at != nil && offsetMinutes != nil {
+		return nil, &domain.ValidationError{Field: "at", Message: "cannot be combined with offsetMinutes"}
+	}
+	if offsetMinutes != nil && *offsetMinutes < 0 {
+		return nil, &domain.ValidationError{Field: "offsetMinutes", Message: "must not be negative"}
+	}
+	target, err = uc.validateTarget(channel, strings.TrimSpace(target))
+	if err != nil {
+		return nil, err
+	}
+	reminder := &domain.Reminder{
+		ID:            uuid.New().String(),
+		TodoID:        todo.ID,
+		Owner:         actor,
+		At:            at,
+		OffsetMinutes: offsetMinutes,
+		Channel:       channel,
+		Target:        target,
+		Status:        domain.StatusPending,
+	}
+	if err := reminder.Schedule(todo.DueDate); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.Save(reminder); err != nil {
+		return nil, err
+	}
+	return reminder, nil
+}
+
+// ListReminders returns the reminders actor set on a live todo they can
+// see, soonest first. The reminders of others on a shared todo, and the
+// addresses they go to, are not listed.
+func (uc *ReminderUseCase) ListReminders(actor, todoID string) ([]*domain.Reminder, error) {
+	if _, err := uc.todos.GetTodoByID(actor, todoID); err != nil {
+		return nil, err
+	}
+	all, err := uc.repo.FindByTodoID(todoID)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*domain.Reminder, 0, len(all))
+	for _, reminder := range all {
+		if reminder.Owner == actor {
+			list = append(list, reminder)
+		}
+	}
+	return list, nil
+}
+
+// DeleteReminder removes a reminder of actor from a live todo they can
+// see; the reminders of others are not found.
+func (uc *ReminderUseCase) DeleteReminder(actor, todoID, id string) error {
+	reminders, err := uc.ListReminders(actor, todoID)
+	if err != nil {
+		return err
+	}
+	for _, reminder := range reminders {
+		if reminder.ID == id {
+			return uc.repo.DeleteByID(todoID, id)
+		}
+	}
+	return domain.ErrNotFound
+}
+
+// Channels lists the names of the configured delivery channels.
+func (uc *ReminderUseCase) Channels() []string {
+	names := make([]string, 0, len(uc.channels))
+	for name := range uc.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HandleTodoEvent moves offset reminders along with their todo's due date
+// and drops the reminders of purged todos. Reminders of todos that are done
+// or in the trash are left alone; the dispatcher cancels them if the todo
+// is still done when they fire, and holds them back while it is in the
+// trash.
+func (uc *ReminderUseCase) HandleTodoEvent(e todoDomain.Event) {
+	var err error
+	switch e.Action {
+	case todoDomain.HistoryUpdated:
+		if e.Before.DueDate.Equal(e.After.DueDate) {
+			return
+		}
+		err = uc.repo.Reschedule(e.After.ID, e.After.DueDate)
+	case todoDomain.HistoryPurged:
+		err = uc.repo.DeleteByTodoID(e.Before.ID)
+	default:
+		return
+	}
+	if err != nil {
+		log.Printf("reminders: %s of todo %s: %v", e.Action, e.Todo().ID, err)
+	}
+}
+
+// validateTarget checks the target against what the channel delivers to
+// and returns it normalised.
+func (uc *ReminderUseCase) validateTarget(channel, target string) (string, error) {
+	if _, ok := uc.channels[channel]; !ok {
+		return "", &domain.ValidationError{Field: "channel", Message: "must be one of " + strings.Join(uc.Channels(), ", ")}
+	}
+	switch channel {
+	case domain.ChannelWebhook:
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", &domain.ValidationError{Field: "target", Message: "must be an http or https URL"}
+		}
+	case domain.ChannelEmail:
+		addr, err := mail.ParseAddress(target)
+		if err != nil {
+			return "", &domain.ValidationError{Field: "target", Message: "must be an email address"}
+		}
+		target = addr.Address
+	case domain.ChannelLog:
+		target = ""
+	}
+	return target, nil
+}
@@ -0,0 +1,297 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-app/internal/reminder/domain"
+	"todo-app/internal/reminder/infrastructure/repository"
+	todoDomain "todo-app/internal/todo/domain"
+	todoRepository "todo-app/internal/todo/infrastructure/repository"
+	todoUsecase "todo-app/internal/todo/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNotifier records deliveries and fails the first failures of them.
+type fakeNotifier struct {
+	failures int
+	sent     []domain.Notification
+}
+
+func (f *fakeNotifier) Notify(_ string, n domain.Notification) error {
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("unreachable")
+	}
+	f.sent = append(f.sent, n)
+	return nil
+}
+
+type fixture struct {
+	todos    *todoUsecase.TodoUseCase
+	todoRepo *todoRepository.MemoryTodoRepository
+	repo     *repository.MemoryReminderRepository
+	uc       *ReminderUseCase
+	notifier *fakeNotifier
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		todoRepo: todoRepository.NewMemoryTodoRepository(),
+		repo:     repository.NewMemoryReminderRepository(),
+		notifier: &fakeNotifier{},
+	}
+	f.todos = todoUsecase.NewTodoUseCase(f.todoRepo, todoRepository.NewMemoryHistoryRepository())
+	channels := map[string]domain.Notifier{domain.ChannelLog: f.notifier, domain.ChannelWebhook: f.notifier}
+	f.uc = NewReminderUseCase(f.repo, f.todos, channels)
+	f.todos.Subscribe(f.uc)
+	return f
+}
+
+func (f *fixture) createTodo(t *testing.T, due time.Time) *todoDomain.Todo {
+	t.Helper()
+	require.NoError(t, f.todos.CreateTodo("tester", "Pay rent", due, false))
+	list, _, err := f.todos.GetAllTodos(0, 100, todoDomain.TodoFilter{})
+	require.NoError(t, err)
+	return list[len(list)-1]
+}
+
+func (f *fixture) dispatcher() *Dispatcher {
+	channels := map[string]domain.Notifier{domain.ChannelLog: f.notifier, domain.ChannelWebhook: f.notifier}
+	d := NewDispatcher(f.repo, f.todoRepo, channels, time.Second, 3)
+	d.Use(f.todos)
+	return d
+}
+
+func intPtr(n int) *int { return &n }
+
+func TestAddReminder_Validation(t *testing.T) {
+	f := newFixture()
+	todo := f.createTodo(t, time.Time{})
+	at := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+
+	var invalid *domain.ValidationError
+	_, err := f.uc.AddReminder("tester", todo.ID, nil, intPtr(30), domain.ChannelLog, "")
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "offsetMinutes", invalid.Field)
+
+	_, err = f.uc.AddReminder("tester", todo.ID, &at, intPtr(30), domain.ChannelLog, "")
+	require.ErrorAs(t, err, &invalid)
+
+	_, err = f.uc.AddReminder("tester", todo.ID, &at, nil, domain.ChannelEmail, "a@example.com")
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "channel", invalid.Field)
+
+	_, err = f.uc.AddReminder("tester", todo.ID, &at, nil, domain.ChannelWebhook, "ftp://example.com")
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "target", invalid.Field)
+
+	_, err = f.uc.AddReminder("tester", "missing", &at, nil, domain.ChannelLog, "")
+	assert.ErrorIs(t, err, todoDomain.ErrNotFound)
+
+	reminder, err := f.uc.AddReminder("tester", todo.ID, &at, nil, domain.ChannelLog, "ignored")
+	require.NoError(t, err)
+	assert.Equal(t, at, reminder.FireAt)
+	assert.Empty(t, reminder.Target)
+	assert.Equal(t, domain.StatusPending, reminder.Status)
+}
+
+func TestReminder_FollowsDueDate(t *testing.T) {
+	f := newFixture()
+	due := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	todo := f.createTodo(t, due)
+	_, err := f.uc.AddReminder("tester", todo.ID, nil, intPtr(90), domain.ChannelLog, "")
+	require.NoError(t, err)
+
+	moved := due.Add(24 * time.Hour)
+	_, err = f.todos.PatchTodo("tester", todo.ID, todoUsecase.TodoPatch{DueDate: &moved}, 0)
+	require.NoError(t, err)
+	reminders, err := f.uc.ListReminders("tester", todo.ID)
+	require.NoError(t, err)
+	require.Len(t, reminders, 1)
+	assert.True(t, moved.Add(-90*time.Minute).Equal(reminders[0].FireAt))
+
+	// purging the todo drops its reminders
+	require.NoError(t, f.todos.DeleteTodo("tester", todo.ID, 0))
+	require.NoError(t, f.todos.PurgeTodo("tester", todo.ID, 0))
+	reminders, _ = f.repo.FindByTodoID(todo.ID)
+	assert.Empty(t, reminders)
+}
+
+func TestDispatcher_DeliversOnce(t *testing.T) {
+	f := newFixture()
+	due := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	todo := f.createTodo(t, due)
+	_, err := f.uc.AddReminder("tester", todo.ID, nil, intPtr(30), domain.ChannelWebhook, "https://example.com/hook")
+	require.NoError(t, err)
+	d := f.dispatcher()
+	ctx := context.Background()
+
+	assert.Equal(t, 0, d.Pass(ctx, due.Add(-31*time.Minute)), "not due yet")
+	assert.Equal(t, 1, d.Pass(ctx, due.Add(-29*time.Minute)))
+	assert.Equal(t, 0, d.Pass(ctx, due), "already sent")
+
+	require.Len(t, f.notifier.sent, 1)
+	assert.Equal(t, "Pay rent", f.notifier.sent[0].Title)
+	reminders, _ := f.uc.ListReminders("tester", todo.ID)
+	assert.Equal(t, domain.StatusSent, reminders[0].Status)
+	assert.Equal(t, 1, reminders[0].Attempts)
+}
+
+func TestDispatcher_RetriesWithBackoff(t *testing.T) {
+	f := newFixture()
+	at := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	todo := f.createTodo(t, time.Time{})
+	_, err := f.uc.AddReminder("tester", todo.ID, &at, nil, domain.ChannelLog, "")
+	require.NoError(t, err)
+	f.notifier.failures = 1
+	d := f.dispatcher()
+	ctx := context.Background()
+
+	assert.Equal(t, 1, d.Pass(ctx, at))
+	reminders, _ := f.uc.ListReminders("tester", todo.ID)
+	assert.Equal(t, domain.StatusPending, reminders[0].Status)
+	assert.Equal(t, "unreachable", reminders[0].LastError)
+
+	assert.Equal(t, 0, d.Pass(ctx, at.Add(29*time.Second)), "backing off")
+	assert.Equal(t, 1, d.Pass(ctx, at.Add(30*time.Second)))
+	reminders, _ = f.uc.ListReminders("tester", todo.ID)
+	assert.Equal(t, domain.StatusSent, reminders[0].Status)
+	assert.Equal(t, 2, reminders[0].Attempts)
+}
+
+func TestDispatcher_GivesUp(t *testing.T) {
+	f := newFixture()
+	at := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	todo := f.createTodo(t, time.Time{})
+	_, err := f.uc.AddReminder("tester", todo.ID, &at, nil, domain.ChannelLog, "")
+	require.NoError(t, err)
+	f.notifier.failures = 10
+	d := f.dispatcher()
+
+	for now := at; now.Before(at.Add(time.Hour)); now = now.Add(time.Minute) {
+		d.Pass(context.Background(), now)
+	}
+	reminders, _ := f.uc.ListReminders("tester", todo.ID)
+	assert.Equal(t, domain.StatusFailed, reminders[0].Status)
+	assert.Equal(t, 3, reminders[0].Attempts)
+}
+
+func TestDispatcher_LeaseExpires(t *testing.T) {
+	f := newFixture()
+	at := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	todo := f.createTodo(t, time.Time{})
+	_, err := f.uc.AddReminder("tester", todo.ID, &at, nil, domain.ChannelLog, "")
+	require.NoError(t, err)
+
+	// a dispatcher that claims but dies before delivering
+	claimed, err := f.repo.ClaimDue(at, 5*time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	d := f.dispatcher()
+	assert.Equal(t, 0, d.Pass(context.Background(), at.Add(time.Minute)), "leased")
+	assert.Equal(t, 1, d.Pass(context.Background(), at.Add(5*time.Minute)))
+	assert.Len(t, f.notifier.sent, 1)
+}
+
+func TestDispatcher_CancelsForDoneTodo(t *testing.T) {
+	f := newFixture()
+	at := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	todo := f.createTodo(t, time.Time{})
+	_, err := f.uc.AddReminder("tester", todo.ID, &at, nil, domain.ChannelLog, "")
+	require.NoError(t, err)
+	done := true
+	_, err = f.todos.PatchTodo("tester", todo.ID, todoUsecase.TodoPatch{Done: &done}, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, f.dispatcher().Pass(context.Background(), at))
+	assert.Empty(t, f.notifier.sent)
+	reminders, _ := f.uc.ListReminders("tester", todo.ID)
+	assert.Equal(t, domain.StatusCancelled, reminders[0].Status)
+}
+
+func TestDispatcher_WaitsForTrashedTodo(t *testing.T) {
+	f := newFixture()
+	at := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	todo := f.createTodo(t, time.Time{})
+	_, err := f.uc.AddReminder("tester", todo.ID, &at, nil, domain.ChannelLog, "")
+	require.NoError(t, err)
+	require.NoError(t, f.todos.DeleteTodo("tester", todo.ID, 0))
+	d := f.dispatcher()
+	ctx := context.Background()
+
+	assert.Equal(t, 1, d.Pass(ctx, at))
+	reminders, _ := f.repo.FindByTodoID(todo.ID)
+	assert.Equal(t, domain.StatusPending, reminders[0].Status)
+	assert.Equal(t, 0, reminders[0].Attempts)
+	assert.Equal(t, "todo is in the trash", reminders[0].LastError)
+
+	_, err = f.todos.RestoreTodo("tester", todo.ID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, d.Pass(ctx, at.Add(time.Minute)), "waiting")
+	assert.Equal(t, 1, d.Pass(ctx, at.Add(time.Hour)))
+	require.Len(t, f.notifier.sent, 1)
+	reminders, _ = f.uc.ListReminders("tester", todo.ID)
+	assert.Equal(t, domain.StatusSent, reminders[0].Status)
+}
+
+type knownUsers map[string]bool
+
+func (u knownUsers) Exists(username string) (bool, error) {
+	return u[username], nil
+}
+
+func TestReminders_Access(t *testing.T) {
+	f := newFixture()
+	f.todos.UseUsers(knownUsers{"tester": true, "bob": true, "carol": true})
+	f.todos.UseSharing(todoRepository.NewMemoryShareRepository())
+	todo := f.createTodo(t, time.Time{})
+	at := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	mine, err := f.uc.AddReminder("tester", todo.ID, &at, nil, domain.ChannelWebhook, "https://example.com/tester")
+	require.NoError(t, err)
+
+	// users the todo is not shared with cannot tell it exists
+	_, err = f.uc.AddReminder("carol", todo.ID, &at, nil, domain.ChannelLog, "")
+	assert.ErrorIs(t, err, todoDomain.ErrNotFound)
+	_, err = f.uc.ListReminders("carol", todo.ID)
+	assert.ErrorIs(t, err, todoDomain.ErrNotFound)
+	assert.ErrorIs(t, f.uc.DeleteReminder("carol", todo.ID, mine.ID), todoDomain.ErrNotFound)
+
+	// users it is shared with keep their own reminders, and see nobody else's
+	_, err = f.todos.ShareTodo("tester", todo.ID, "bob", todoDomain.PermissionView)
+	require.NoError(t, err)
+	theirs, err := f.uc.AddReminder("bob", todo.ID, &at, nil, domain.ChannelLog, "")
+	require.NoError(t, err)
+	list, err := f.uc.ListReminders("bob", todo.ID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, theirs.ID, list[0].ID)
+	assert.ErrorIs(t, f.uc.DeleteReminder("bob", todo.ID, mine.ID), domain.ErrNotFound)
+	require.NoError(t, f.uc.DeleteReminder("tester", todo.ID, mine.ID))
+	list, err = f.uc.ListReminders("tester", todo.ID)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestDispatcher_CancelsWhenShareIsRevoked(t *testing.T) {
+	f := newFixture()
+	f.todos.UseUsers(knownUsers{"tester": true, "bob": true})
+	f.todos.UseSharing(todoRepository.NewMemoryShareRepository())
+	at := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	todo := f.createTodo(t, time.Time{})
+	_, err := f.todos.ShareTodo("tester", todo.ID, "bob", todoDomain.PermissionView)
+	require.NoError(t, err)
+	_, err = f.uc.AddReminder("bob", todo.ID, &at, nil, domain.ChannelLog, "")
+	require.NoError(t, err)
+	require.NoError(t, f.todos.RevokeShare("tester", todo.ID, "bob"))
+
+	assert.Equal(t, 1, f.dispatcher().Pass(context.Background(), at))
+	assert.Empty(t, f.notifier.sent)
+	reminders, _ := f.repo.FindByTodoID(todo.ID)
+	require.Len(t, reminders, 1)
+	assert.Equal(t, domain.StatusCancelled, reminders[0].Status)
+}
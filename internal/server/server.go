@@ -8,20 +8,67 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"todo-app/internal/api/middleware"
+	"todo-app/internal/api/problem"
 	authDomain "todo-app/internal/auth/domain"
 	authRepo "todo-app/internal/auth/infrastructure/repository"
 	authHttp "todo-app/internal/auth/interface/http"
 	authUsecase "todo-app/internal/auth/usecase"
+	calendarDomain "todo-app/internal/calendar/domain"
+	calendarCaldav "todo-app/internal/calendar/interface/caldav"
+	calendarHttp "todo-app/internal/calendar/interface/http"
+	calendarUsecase "todo-app/internal/calendar/usecase"
+	linkDomain "todo-app/internal/link/domain"
+	linkHttp "todo-app/internal/link/interface/http"
+	linkUsecase "todo-app/internal/link/usecase"
+	projectDomain "todo-app/internal/project/domain"
+	projectHttp "todo-app/internal/project/interface/http"
+	projectUsecase "todo-app/internal/project/usecase"
+	reminderDomain "todo-app/internal/reminder/domain"
+	reminderHttp "todo-app/internal/reminder/interface/http"
+	reminderUsecase "todo-app/internal/reminder/usecase"
 	todoDomain "todo-app/internal/todo/domain"
 	todoHttp "todo-app/internal/todo/interface/http"
 	todoUsecase "todo-app/internal/todo/usecase"
+	webhookDomain "todo-app/internal/webhook/domain"
+	webhookHttp "todo-app/internal/webhook/interface/http"
+	webhookUsecase "todo-app/internal/webhook/usecase"
 )
 
 type Deps struct {
-	JWTSecret string
-	AuthRepo  authDomain.AuthRepository
-	TokenGen  *authRepo.JWTTokenGenerator
-	TodoRepo  todoDomain.TodoRepository
+	JWTSecret    string
+	AuthRepo     authDomain.AuthRepository
+	TokenGen     *authRepo.JWTTokenGenerator
+	TodoRepo     todoDomain.TodoRepository
+	HistoryRepo  todoDomain.HistoryRepository
+	ShareRepo    todoDomain.ShareRepository
+	CommentRepo  todoDomain.CommentRepository
+	ReminderRepo reminderDomain.ReminderRepository
+	// Notifiers are the reminder delivery channels, keyed by channel name.
+	Notifiers           map[string]reminderDomain.Notifier
+	WebhookRepo         webhookDomain.SubscriptionRepository
+	WebhookDeliveryRepo webhookDomain.DeliveryRepository
+	FeedRepo            calendarDomain.FeedRepository
+	ProjectRepo         projectDomain.ProjectRepository
+	LinkRepo            linkDomain.LinkRepository
+	// BlobStore keeps the content of attachments, within AttachmentLimits;
+	// without it nothing can be attached to todos.
+	BlobStore        todoDomain.BlobStore
+	AttachmentLimits todoDomain.AttachmentLimits
+	// Events streams todo changes to live clients; a broker with the
+	// default replay buffer is created when it is nil.
+	Events *todoUsecase.Broker
+	// TrashSweeper, when set, is given the todo use case to purge expired
+	// trash through.
+	TrashSweeper *todoUsecase.TrashSweeper
+	// Reminders, when set, is given the todo use case to check that the
+	// owners of reminders can still see their todos.
+	Reminders *reminderUsecase.Dispatcher
+}
+
+func init() {
+	// chi only routes the methods it knows
+	chi.RegisterMethod("PROPFIND")
+	chi.RegisterMethod("REPORT")
 }
 
 // NewHandler creates http.Handler with routes registered.
@@ -34,16 +81,52 @@ func NewHandler(d Deps) http.Handler {
 		"myAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
 	}
 	api := humachi.New(r, cfg)
-	Register(api, d)
+	caldav := Register(api, d)
+	r.Handle("/caldav", caldav)
+	r.Handle("/caldav/*", caldav)
+	// RFC 6764 lets clients find the server from the host name alone
+	r.Handle("/.well-known/caldav", http.RedirectHandler("/caldav/", http.StatusMovedPermanently))
 	return r
 }
 
 // Register wires middleware & handlers onto an existing huma.API (for tests or custom adapters).
-func Register(api huma.API, d Deps) {
+// It returns the CalDAV handler, which speaks WebDAV methods huma does not
+// know and has to be mounted at /caldav next to the API.
+func Register(api huma.API, d Deps) http.Handler {
+	problem.Install()
 	api.UseMiddleware(middleware.NewAuthMiddleware(api, []byte(d.JWTSecret)))
-	todoUC := todoUsecase.NewTodoUseCase(d.TodoRepo)
+	todoUC := todoUsecase.NewTodoUseCase(d.TodoRepo, d.HistoryRepo)
+	events := d.Events
+	if events == nil {
+		events = todoUsecase.NewBroker(todoUsecase.DefaultReplaySize)
+	}
+	todoUC.Subscribe(events)
+	reminderUC := reminderUsecase.NewReminderUseCase(d.ReminderRepo, todoUC, d.Notifiers)
+	todoUC.Subscribe(reminderUC)
+	webhookUC := webhookUsecase.NewWebhookUseCase(d.WebhookRepo, d.WebhookDeliveryRepo)
+	todoUC.Subscribe(webhookUC)
+	projectUC := projectUsecase.NewProjectUseCase(d.ProjectRepo, todoUC)
+	todoUC.UseProjects(projectUC)
+	todoUC.UseUsers(authUsecase.NewDirectory(d.AuthRepo))
+	todoUC.UseSharing(d.ShareRepo)
+	todoUC.UseComments(d.CommentRepo)
+	todoUC.UseAttachments(d.BlobStore, d.AttachmentLimits)
+	if d.TrashSweeper != nil {
+		d.TrashSweeper.Use(todoUC)
+	}
+	if d.Reminders != nil {
+		d.Reminders.Use(todoUC)
+	}
+	linkUC := linkUsecase.NewLinkUseCase(d.LinkRepo, todoUC)
+	feedUC := calendarUsecase.NewFeedUseCase(d.FeedRepo, todoUC)
 	loginUC := authUsecase.NewLoginUsecase(d.AuthRepo, d.TokenGen)
 	registerUC := authUsecase.NewRegisterUsecase(d.AuthRepo)
-	todoHttp.NewTodoHandler(api, todoUC)
+	todoHttp.NewTodoHandler(api, todoUC, events)
+	reminderHttp.NewReminderHandler(api, reminderUC)
+	webhookHttp.NewWebhookHandler(api, webhookUC)
+	projectHttp.NewProjectHandler(api, projectUC)
+	linkHttp.NewLinkHandler(api, linkUC)
+	calendarHttp.NewFeedHandler(api, feedUC)
 	authHttp.NewHandler(api, registerUC, loginUC)
+	return calendarCaldav.NewHandler("/caldav", calendarUsecase.NewCalendarUseCase(todoUC, d.TodoRepo, d.HistoryRepo), loginUC)
 }
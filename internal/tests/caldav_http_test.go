@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	authRepo "todo-app/internal/auth/infrastructure/repository"
+	calendarRepo "todo-app/internal/calendar/infrastructure/repository"
+	linkRepo "todo-app/internal/link/infrastructure/repository"
+	projectRepo "todo-app/internal/project/infrastructure/repository"
+	reminderDomain "todo-app/internal/reminder/domain"
+	reminderNotifier "todo-app/internal/reminder/infrastructure/notifier"
+	reminderRepo "todo-app/internal/reminder/infrastructure/repository"
+	"todo-app/internal/server"
+	todoRepo "todo-app/internal/todo/infrastructure/repository"
+	webhookRepo "todo-app/internal/webhook/infrastructure/repository"
+)
+
+func TestCalDAV_HTTP(t *testing.T) {
+	deps := server.Deps{
+		JWTSecret:    "test-secret",
+		AuthRepo:     authRepo.NewMemoryRepo(),
+		TokenGen:     &authRepo.JWTTokenGenerator{Secret: "test-secret"},
+		TodoRepo:     todoRepo.NewMemoryTodoRepository(),
+		HistoryRepo:  todoRepo.NewMemoryHistoryRepository(),
+		ShareRepo:    todoRepo.NewMemoryShareRepository(),
+		CommentRepo:  todoRepo.NewMemoryCommentRepository(),
+		ReminderRepo: reminderRepo.NewMemoryReminderRepository(),
+		Notifiers: map[string]reminderDomain.Notifier{
+			reminderDomain.ChannelLog: reminderNotifier.LogNotifier{},
+		},
+		WebhookRepo:         webhookRepo.NewMemorySubscriptionRepository(),
+		WebhookDeliveryRepo: webhookRepo.NewMemoryDeliveryRepository(),
+		FeedRepo:            calendarRepo.NewMemoryFeedRepository(),
+		ProjectRepo:         projectRepo.NewMemoryProjectRepository(),
+		LinkRepo:            linkRepo.NewMemoryLinkRepository(),
+	}
+	ts := httptest.NewServer(server.NewHandler(deps))
+	defer ts.Close()
+	res, err := http.Post(ts.URL+"/auth/register", "application/json", strings.NewReader(`{"username":"alice","password":"secret123"}`))
+	if err != nil || res.StatusCode != 200 {
+		t.Fatalf("register: %v %v", err, res)
+	}
+	res.Body.Close()
+
+	do := func(method, path, body string, headers ...string) (*http.Response, string) {
+		t.Helper()
+		req, _ := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+		req.SetBasicAuth("alice", "secret123")
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer res.Body.Close()
+		out, _ := io.ReadAll(res.Body)
+		return res, string(out)
+	}
+
+	req, _ := http.NewRequest("PROPFIND", ts.URL+"/caldav/", nil)
+	if res, err := http.DefaultClient.Do(req); err != nil || res.StatusCode != 401 {
+		t.Fatalf("unauthenticated: expected 401 got %v %v", err, res)
+	}
+	res, body := do("PROPFIND", "/caldav/alice/", `<d:propfind xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:prop><c:calendar-home-set/><d:getlastmodified/></d:prop></d:propfind>`, "Depth", "0")
+	if res.StatusCode != 207 || !strings.Contains(body, "<c:calendar-home-set><d:href>/caldav/alice/</d:href></c:calendar-home-set>") || !strings.Contains(body, "404 Not Found") {
+		t.Fatalf("principal: %d %s", res.StatusCode, body)
+	}
+	if res, _ = do("PROPFIND", "/caldav/bob/todos/", ""); res.StatusCode != 403 {
+		t.Fatalf("other user: expected 403 got %d", res.StatusCode)
+	}
+
+	vtodo := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//test//EN\r\nBEGIN:VTODO\r\nUID:task-1\r\nSUMMARY:Buy milk\r\nDUE:20250701T090000Z\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	if res, body = do(http.MethodPut, "/caldav/alice/todos/task-1.ics", vtodo, "If-None-Match", "*", "Content-Type", "text/calendar"); res.StatusCode != 201 {
+		t.Fatalf("create: expected 201 got %d %s", res.StatusCode, body)
+	}
+	if res, _ = do(http.MethodPut, "/caldav/alice/todos/task-1.ics", vtodo, "If-None-Match", "*"); res.StatusCode != 412 {
+		t.Fatalf("create again: expected 412 got %d", res.StatusCode)
+	}
+	if res, _ = do(http.MethodPut, "/caldav/alice/todos/other.ics", vtodo); res.StatusCode != 403 {
+		t.Fatalf("UID mismatch: expected 403 got %d", res.StatusCode)
+	}
+
+	res, body = do("REPORT", "/caldav/alice/todos/", `<d:sync-collection xmlns:d="DAV:"><d:sync-token/><d:sync-level>1</d:sync-level><d:prop><d:getetag/></d:prop></d:sync-collection>`)
+	token := regexp.MustCompile(`<d:sync-token>([^<]+)</d:sync-token>`).FindStringSubmatch(body)
+	if res.StatusCode != 207 || token == nil || !strings.Contains(body, "<d:href>/caldav/alice/todos/task-1.ics</d:href>") || !strings.Contains(body, `<d:getetag>&#34;1&#34;</d:getetag>`) {
+		t.Fatalf("initial sync: %d %s", res.StatusCode, body)
+	}
+
+	res, body = do(http.MethodGet, "/caldav/alice/todos/task-1.ics", "")
+	if res.StatusCode != 200 || res.Header.Get("ETag") != `"1"` || !strings.Contains(body, "STATUS:NEEDS-ACTION\r\n") {
+		t.Fatalf("get: %d %q %s", res.StatusCode, res.Header.Get("ETag"), body)
+	}
+
+	// tick it off, as a phone would
+	done := strings.Replace(vtodo, "END:VTODO", "STATUS:COMPLETED\r\nCOMPLETED:20250701T100000Z\r\nEND:VTODO", 1)
+	if res, _ = do(http.MethodPut, "/caldav/alice/todos/task-1.ics", done, "If-Match", `"2"`); res.StatusCode != 412 {
+		t.Fatalf("stale update: expected 412 got %d", res.StatusCode)
+	}
+	if res, body = do(http.MethodPut, "/caldav/alice/todos/task-1.ics", done, "If-Match", `"1"`); res.StatusCode != 204 {
+		t.Fatalf("update: expected 204 got %d %s", res.StatusCode, body)
+	}
+
+	query := `<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:prop><d:getetag/><c:calendar-data/></d:prop>` +
+		`<c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VTODO"><c:prop-filter name="COMPLETED"><c:is-not-defined/></c:prop-filter></c:comp-filter></c:comp-filter></c:filter></c:calendar-query>`
+	if res, body = do("REPORT", "/caldav/alice/todos/", query); res.StatusCode != 207 || strings.Contains(body, "task-1") {
+		t.Fatalf("query for open todos: %d %s", res.StatusCode, body)
+	}
+	multiget := `<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:prop><c:calendar-data/></d:prop>` +
+		`<d:href>/caldav/alice/todos/task-1.ics</d:href><d:href>/caldav/alice/todos/missing.ics</d:href></c:calendar-multiget>`
+	res, body = do("REPORT", "/caldav/alice/todos/", multiget)
+	if res.StatusCode != 207 || !strings.Contains(body, "STATUS:COMPLETED") || !strings.Contains(body, "<d:href>/caldav/alice/todos/missing.ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status>") {
+		t.Fatalf("multiget: %d %s", res.StatusCode, body)
+	}
+
+	if res, _ = do(http.MethodDelete, "/caldav/alice/todos/task-1.ics", ""); res.StatusCode != 204 {
+		t.Fatalf("delete: expected 204 got %d", res.StatusCode)
+	}
+	res, body = do("REPORT", "/caldav/alice/todos/", `<d:sync-collection xmlns:d="DAV:"><d:sync-token>`+token[1]+`</d:sync-token><d:prop><d:getetag/></d:prop></d:sync-collection>`)
+	if res.StatusCode != 207 || !strings.Contains(body, "<d:href>/caldav/alice/todos/task-1.ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status>") {
+		t.Fatalf("sync after delete: %d %s", res.StatusCode, body)
+	}
+	if res, _ = do("REPORT", "/caldav/alice/todos/", `<d:sync-collection xmlns:d="DAV:"><d:sync-token>bogus</d:sync-token></d:sync-collection>`); res.StatusCode != 403 {
+		t.Fatalf("bad token: expected 403 got %d", res.StatusCode)
+	}
+}
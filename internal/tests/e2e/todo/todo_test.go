@@ -1,15 +1,33 @@
 package todo_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/danielgtaylor/huma/v2"
 	"github.com/danielgtaylor/huma/v2/humatest"
 
 	authDomain "todo-app/internal/auth/domain"
 	authRepo "todo-app/internal/auth/infrastructure/repository"
+	calendarRepo "todo-app/internal/calendar/infrastructure/repository"
+	linkRepo "todo-app/internal/link/infrastructure/repository"
+	projectRepo "todo-app/internal/project/infrastructure/repository"
+	reminderDomain "todo-app/internal/reminder/domain"
+	reminderNotifier "todo-app/internal/reminder/infrastructure/notifier"
+	reminderRepo "todo-app/internal/reminder/infrastructure/repository"
 	"todo-app/internal/server"
+	todoDomain "todo-app/internal/todo/domain"
+	todoBlob "todo-app/internal/todo/infrastructure/blob"
 	todoRepo "todo-app/internal/todo/infrastructure/repository"
+	webhookRepo "todo-app/internal/webhook/infrastructure/repository"
 )
 
 // Basic smoke test ensuring routes register & open list endpoint (adjust path as needed)
@@ -21,10 +39,22 @@ func TestTodoAPI_ListEmpty(t *testing.T) {
 	_, api := humatest.New(t, config)
 
 	deps := server.Deps{
-		JWTSecret: "test-secret",
-		AuthRepo:  authRepo.NewMemoryRepo(),
-		TokenGen:  &authRepo.JWTTokenGenerator{Secret: "test-secret"},
-		TodoRepo:  todoRepo.NewMemoryTodoRepository(),
+		JWTSecret:    "test-secret",
+		AuthRepo:     authRepo.NewMemoryRepo(),
+		TokenGen:     &authRepo.JWTTokenGenerator{Secret: "test-secret"},
+		TodoRepo:     todoRepo.NewMemoryTodoRepository(),
+		HistoryRepo:  todoRepo.NewMemoryHistoryRepository(),
+		ShareRepo:    todoRepo.NewMemoryShareRepository(),
+		CommentRepo:  todoRepo.NewMemoryCommentRepository(),
+		ReminderRepo: reminderRepo.NewMemoryReminderRepository(),
+		Notifiers: map[string]reminderDomain.Notifier{
+			reminderDomain.ChannelLog: reminderNotifier.LogNotifier{},
+		},
+		WebhookRepo:         webhookRepo.NewMemorySubscriptionRepository(),
+		WebhookDeliveryRepo: webhookRepo.NewMemoryDeliveryRepository(),
+		FeedRepo:            calendarRepo.NewMemoryFeedRepository(),
+		ProjectRepo:         projectRepo.NewMemoryProjectRepository(),
+		LinkRepo:            linkRepo.NewMemoryLinkRepository(),
 	}
 	server.Register(api, deps)
 
@@ -38,3 +68,1073 @@ func TestTodoAPI_ListEmpty(t *testing.T) {
 		t.Fatalf("expected 200 got %d", resp.Code)
 	}
 }
+
+// newTestAPI registers all routes on an in-memory test API and returns it
+// together with an Authorization header for a freshly signed token.
+func newTestAPI(t *testing.T) (humatest.TestAPI, string) {
+	t.Helper()
+	config := huma.DefaultConfig("Todo API", "1.0.0")
+	config.Components.SecuritySchemes = map[string]*huma.SecurityScheme{
+		"myAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+	}
+	_, api := humatest.New(t, config)
+	blobs, err := todoBlob.NewLocalBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("blob store: %v", err)
+	}
+
+	deps := server.Deps{
+		JWTSecret:    "test-secret",
+		AuthRepo:     authRepo.NewMemoryRepo(),
+		TokenGen:     &authRepo.JWTTokenGenerator{Secret: "test-secret"},
+		TodoRepo:     todoRepo.NewMemoryTodoRepository(),
+		HistoryRepo:  todoRepo.NewMemoryHistoryRepository(),
+		ShareRepo:    todoRepo.NewMemoryShareRepository(),
+		CommentRepo:  todoRepo.NewMemoryCommentRepository(),
+		ReminderRepo: reminderRepo.NewMemoryReminderRepository(),
+		Notifiers: map[string]reminderDomain.Notifier{
+			reminderDomain.ChannelLog: reminderNotifier.LogNotifier{},
+		},
+		WebhookRepo:         webhookRepo.NewMemorySubscriptionRepository(),
+		WebhookDeliveryRepo: webhookRepo.NewMemoryDeliveryRepository(),
+		FeedRepo:            calendarRepo.NewMemoryFeedRepository(),
+		ProjectRepo:         projectRepo.NewMemoryProjectRepository(),
+		LinkRepo:            linkRepo.NewMemoryLinkRepository(),
+		BlobStore:           blobs,
+		AttachmentLimits:    todoDomain.AttachmentLimits{MaxSize: 1 << 10, ContentTypes: []string{"text/plain", "image/*"}},
+	}
+	server.Register(api, deps)
+
+	token, err := deps.TokenGen.Generate(authDomain.AuthUser{Username: "tester"})
+	if err != nil {
+		t.Fatalf("token gen: %v", err)
+	}
+	return api, "Authorization: Bearer " + token
+}
+
+func TestTodoAPI_IfMatch(t *testing.T) {
+	api, auth := newTestAPI(t)
+
+	resp := api.Post("/todos", auth, map[string]any{"title": "Buy milk", "dueDate": "2025-07-01T00:00:00Z", "done": false})
+	if resp.Code != 200 {
+		t.Fatalf("create: expected 200 got %d", resp.Code)
+	}
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	resp = api.Get("/todos?limit=10", auth)
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil || len(list.Data) != 1 {
+		t.Fatalf("list: %v %s", err, resp.Body.String())
+	}
+	path := "/todos/" + list.Data[0].ID
+
+	resp = api.Get(path, auth)
+	if got := resp.Header().Get("ETag"); got != `"1"` {
+		t.Fatalf("expected ETag \"1\" got %q", got)
+	}
+
+	body := map[string]any{"title": "Buy oat milk", "dueDate": "2025-07-01T00:00:00Z", "done": false}
+	resp = api.Put(path, auth, `If-Match: "1"`, body)
+	if resp.Code != 200 || resp.Header().Get("ETag") != `"2"` {
+		t.Fatalf("update: expected 200 with ETag \"2\" got %d %q", resp.Code, resp.Header().Get("ETag"))
+	}
+
+	// A stale ETag must not overwrite the newer revision
+	resp = api.Put(path, auth, `If-Match: "1"`, body)
+	if resp.Code != 412 {
+		t.Fatalf("stale update: expected 412 got %d", resp.Code)
+	}
+	resp = api.Patch(path, auth, `If-Match: "1"`, map[string]any{"done": true})
+	if resp.Code != 412 {
+		t.Fatalf("stale patch: expected 412 got %d", resp.Code)
+	}
+	resp = api.Delete(path, auth, `If-Match: "1"`)
+	if resp.Code != 412 {
+		t.Fatalf("stale delete: expected 412 got %d", resp.Code)
+	}
+	resp = api.Delete(path, auth, `If-Match: "2"`)
+	if resp.Code != 200 {
+		t.Fatalf("delete: expected 200 got %d", resp.Code)
+	}
+}
+
+func TestTodoAPI_ProblemResponses(t *testing.T) {
+	api, auth := newTestAPI(t)
+
+	var problem struct {
+		Status int    `json:"status"`
+		Code   string `json:"code"`
+	}
+	resp := api.Get("/todos/does-not-exist", auth)
+	if resp.Code != 404 {
+		t.Fatalf("missing todo: expected 404 got %d", resp.Code)
+	}
+	if ct := resp.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("expected problem content type got %q", ct)
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || problem.Code != "todo_not_found" {
+		t.Fatalf("expected todo_not_found got %v %s", err, resp.Body.String())
+	}
+
+	resp = api.Put("/todos/does-not-exist", auth, map[string]any{"title": "x", "dueDate": "2025-07-01T00:00:00Z", "done": false})
+	if resp.Code != 404 {
+		t.Fatalf("update missing todo: expected 404 got %d", resp.Code)
+	}
+
+	resp = api.Post("/todos", auth, map[string]any{"title": " ", "dueDate": "2025-07-01T00:00:00Z", "done": false})
+	if resp.Code != 422 {
+		t.Fatalf("blank title: expected 422 got %d", resp.Code)
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || problem.Code != "validation_failed" {
+		t.Fatalf("expected validation_failed got %v %s", err, resp.Body.String())
+	}
+
+	resp = api.Get("/todos")
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || resp.Code != 401 || problem.Code != "unauthorized" {
+		t.Fatalf("expected 401 unauthorized got %d %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestAuthAPI_ProblemResponses(t *testing.T) {
+	api, _ := newTestAPI(t)
+	creds := map[string]any{"username": "problem-user", "password": "secret"}
+
+	resp := api.Post("/auth/register", creds)
+	if resp.Code != 200 {
+		t.Fatalf("register: expected 200 got %d", resp.Code)
+	}
+	resp = api.Post("/auth/register", creds)
+	if resp.Code != 409 {
+		t.Fatalf("duplicate register: expected 409 got %d", resp.Code)
+	}
+	resp = api.Post("/auth/login", map[string]any{"username": "problem-user", "password": "wrong"})
+	if resp.Code != 401 {
+		t.Fatalf("bad login: expected 401 got %d", resp.Code)
+	}
+	var problem struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || problem.Code != "invalid_credentials" {
+		t.Fatalf("expected invalid_credentials got %v %s", err, resp.Body.String())
+	}
+}
+
+func TestReminderAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+
+	resp := api.Post("/todos", auth, map[string]any{"title": "Pay rent", "dueDate": "2025-07-01T12:00:00Z", "done": false})
+	if resp.Code != 200 {
+		t.Fatalf("create: expected 200 got %d", resp.Code)
+	}
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	resp = api.Get("/todos?limit=10", auth)
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil || len(list.Data) != 1 {
+		t.Fatalf("list: %v %s", err, resp.Body.String())
+	}
+	path := "/todos/" + list.Data[0].ID + "/reminders"
+
+	resp = api.Post(path, auth, map[string]any{"offsetMinutes": 30, "channel": "log"})
+	if resp.Code != 200 {
+		t.Fatalf("add reminder: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	var added struct {
+		Reminder struct {
+			ID     string `json:"id"`
+			FireAt string `json:"fireAt"`
+		} `json:"reminder"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &added); err != nil || added.Reminder.FireAt != "2025-07-01T11:30:00Z" {
+		t.Fatalf("expected fireAt 30 minutes before due got %v %s", err, resp.Body.String())
+	}
+
+	resp = api.Post(path, auth, map[string]any{"offsetMinutes": 30, "channel": "email", "target": "a@example.com"})
+	if resp.Code != 422 {
+		t.Fatalf("unconfigured channel: expected 422 got %d", resp.Code)
+	}
+
+	resp = api.Delete(path+"/"+added.Reminder.ID, auth)
+	if resp.Code != 200 {
+		t.Fatalf("delete reminder: expected 200 got %d", resp.Code)
+	}
+	var problem struct {
+		Code string `json:"code"`
+	}
+	resp = api.Delete(path+"/"+added.Reminder.ID, auth)
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || resp.Code != 404 || problem.Code != "reminder_not_found" {
+		t.Fatalf("expected 404 reminder_not_found got %d %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestWebhookAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+
+	resp := api.Post("/webhooks", auth, map[string]any{"url": "https://example.com/hook", "events": []string{"todo.completed"}})
+	if resp.Code != 200 {
+		t.Fatalf("create webhook: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	var created struct {
+		Webhook struct {
+			ID     string `json:"id"`
+			Active bool   `json:"active"`
+		} `json:"webhook"`
+		Secret string `json:"secret"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &created); err != nil || created.Secret == "" || !created.Webhook.Active {
+		t.Fatalf("expected an active webhook with a secret got %v %s", err, resp.Body.String())
+	}
+
+	resp = api.Get("/webhooks/"+created.Webhook.ID, auth)
+	if resp.Code != 200 || strings.Contains(resp.Body.String(), created.Secret) {
+		t.Fatalf("get webhook: expected 200 without the secret got %d %s", resp.Code, resp.Body.String())
+	}
+
+	resp = api.Post("/todos", auth, map[string]any{"title": "Ship it", "dueDate": "2025-07-01T00:00:00Z", "done": true})
+	if resp.Code != 200 {
+		t.Fatalf("create todo: expected 200 got %d", resp.Code)
+	}
+	// only todo.completed was subscribed to, so creating queues nothing
+	var deliveries struct {
+		Meta struct {
+			Total int64 `json:"total"`
+		} `json:"meta"`
+	}
+	resp = api.Get("/webhooks/"+created.Webhook.ID+"/deliveries?limit=10", auth)
+	if err := json.Unmarshal(resp.Body.Bytes(), &deliveries); err != nil || deliveries.Meta.Total != 0 {
+		t.Fatalf("expected no deliveries got %v %s", err, resp.Body.String())
+	}
+
+	var problem struct {
+		Code string `json:"code"`
+	}
+	resp = api.Post("/webhooks/"+created.Webhook.ID+"/deliveries/missing/replay", auth)
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || resp.Code != 404 || problem.Code != "webhook_delivery_not_found" {
+		t.Fatalf("expected 404 webhook_delivery_not_found got %d %s", resp.Code, resp.Body.String())
+	}
+	resp = api.Delete("/webhooks/"+created.Webhook.ID, auth)
+	if resp.Code != 200 {
+		t.Fatalf("delete webhook: expected 200 got %d", resp.Code)
+	}
+	resp = api.Get("/webhooks/"+created.Webhook.ID, auth)
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || resp.Code != 404 || problem.Code != "webhook_not_found" {
+		t.Fatalf("expected 404 webhook_not_found got %d %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestProjectAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+
+	var created struct {
+		Project struct {
+			ID       string `json:"id"`
+			Position int    `json:"position"`
+		} `json:"project"`
+	}
+	resp := api.Post("/projects", auth, map[string]any{"name": "Groceries", "color": "#22C55E"})
+	if err := json.Unmarshal(resp.Body.Bytes(), &created); err != nil || resp.Code != 200 || !strings.Contains(resp.Body.String(), `"color":"#22c55e"`) {
+		t.Fatalf("create project: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	groceries := created.Project.ID
+	resp = api.Post("/projects", auth, map[string]any{"name": "Errands"})
+	if err := json.Unmarshal(resp.Body.Bytes(), &created); err != nil || created.Project.Position != 1 {
+		t.Fatalf("create second project: expected position 1 got %s", resp.Body.String())
+	}
+	errands := created.Project.ID
+	resp = api.Put("/projects/order", auth, map[string]any{"projectIds": []string{errands, groceries}})
+	if resp.Code != 200 || strings.Index(resp.Body.String(), errands) > strings.Index(resp.Body.String(), groceries) {
+		t.Fatalf("reorder: expected errands first got %d %s", resp.Code, resp.Body.String())
+	}
+
+	for _, body := range []map[string]any{
+		{"title": "Milk", "dueDate": "2025-07-01T00:00:00Z", "done": false, "projectId": groceries},
+		{"title": "Post office", "dueDate": "2025-07-01T00:00:00Z", "done": false, "projectId": errands},
+		{"title": "Call mom", "dueDate": "2025-07-01T00:00:00Z", "done": false},
+	} {
+		if resp = api.Post("/todos", auth, body); resp.Code != 200 {
+			t.Fatalf("create todo: expected 200 got %d %s", resp.Code, resp.Body.String())
+		}
+	}
+	resp = api.Post("/todos", auth, map[string]any{"title": "Lost", "dueDate": "2025-07-01T00:00:00Z", "done": false, "projectId": "missing"})
+	var problem struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || resp.Code != 404 || problem.Code != "project_not_found" {
+		t.Fatalf("unknown project: expected 404 project_not_found got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Get("/todos?project="+groceries+"&limit=10", auth); !strings.Contains(resp.Body.String(), `"total":1`) || !strings.Contains(resp.Body.String(), "Milk") {
+		t.Fatalf("project filter: expected Milk got %s", resp.Body.String())
+	}
+	if resp = api.Get("/todos?project=inbox&limit=10", auth); !strings.Contains(resp.Body.String(), `"total":1`) || !strings.Contains(resp.Body.String(), "Call mom") {
+		t.Fatalf("inbox filter: expected Call mom got %s", resp.Body.String())
+	}
+
+	// moving the inbox into errands, then deleting errands with its todos
+	resp = api.Post("/todos/bulk", auth, map[string]any{"action": "move", "filter": map[string]any{"project": "inbox"}, "projectId": errands})
+	if resp.Code != 200 || !strings.Contains(resp.Body.String(), `"applied":1`) {
+		t.Fatalf("bulk move: expected 1 applied got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Delete("/projects/"+errands+"?todos=delete", auth); resp.Code != 200 {
+		t.Fatalf("delete project: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Get("/todos?limit=10", auth); !strings.Contains(resp.Body.String(), `"total":1`) {
+		t.Fatalf("expected only Milk left got %s", resp.Body.String())
+	}
+	if resp = api.Delete("/projects/"+groceries, auth); resp.Code != 200 {
+		t.Fatalf("delete project: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Get("/todos?project=inbox&limit=10", auth); !strings.Contains(resp.Body.String(), "Milk") {
+		t.Fatalf("expected Milk in the inbox got %s", resp.Body.String())
+	}
+	resp = api.Get("/projects/"+groceries, auth)
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || resp.Code != 404 || problem.Code != "project_not_found" {
+		t.Fatalf("expected 404 project_not_found got %d %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestShareAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+	creds := map[string]any{"username": "bob", "password": "secret"}
+	if resp := api.Post("/auth/register", creds); resp.Code != 200 {
+		t.Fatalf("register: expected 200 got %d", resp.Code)
+	}
+	var login struct {
+		Token string `json:"token"`
+	}
+	resp := api.Post("/auth/login", creds)
+	if err := json.Unmarshal(resp.Body.Bytes(), &login); err != nil || login.Token == "" {
+		t.Fatalf("login: %v %s", err, resp.Body.String())
+	}
+	bob := "Authorization: Bearer " + login.Token
+
+	api.Post("/todos", auth, map[string]any{"title": "Book flights", "dueDate": "2025-07-01T00:00:00Z", "done": false})
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	resp = api.Get("/todos?limit=10", auth)
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil || len(list.Data) != 1 {
+		t.Fatalf("list: %v %s", err, resp.Body.String())
+	}
+	path := "/todos/" + list.Data[0].ID
+	if resp = api.Get(path, bob); resp.Code != 404 {
+		t.Fatalf("unshared todo: expected 404 got %d", resp.Code)
+	}
+	if resp = api.Get("/todos?limit=10", bob); !strings.Contains(resp.Body.String(), `"total":0`) {
+		t.Fatalf("lists only hold your own todos, got %s", resp.Body.String())
+	}
+
+	if resp = api.Post(path+"/shares", auth, map[string]any{"username": "nobody", "permission": "view"}); resp.Code != 422 {
+		t.Fatalf("unknown user: expected 422 got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Post(path+"/shares", auth, map[string]any{"username": "bob", "permission": "edit"}); resp.Code != 200 {
+		t.Fatalf("share: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Get(path+"/shares", auth); !strings.Contains(resp.Body.String(), `"username":"bob"`) {
+		t.Fatalf("list shares: expected bob got %s", resp.Body.String())
+	}
+	resp = api.Get("/todos/shared?limit=10", bob)
+	if resp.Code != 200 || !strings.Contains(resp.Body.String(), "Book flights") || !strings.Contains(resp.Body.String(), `"permission":"edit"`) {
+		t.Fatalf("shared with me: expected Book flights got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Patch(path, bob, map[string]any{"done": true}); resp.Code != 200 {
+		t.Fatalf("grantee patch: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	var problem struct {
+		Code string `json:"code"`
+	}
+	resp = api.Delete(path, bob)
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || resp.Code != 403 || problem.Code != "todo_forbidden" {
+		t.Fatalf("grantee delete: expected 403 todo_forbidden got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Post(path+"/shares", bob, map[string]any{"username": "tester", "permission": "view"}); resp.Code != 403 {
+		t.Fatalf("reshare: expected 403 got %d", resp.Code)
+	}
+
+	if resp = api.Delete(path+"/shares/bob", auth); resp.Code != 200 {
+		t.Fatalf("revoke: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Get(path, bob); resp.Code != 404 {
+		t.Fatalf("revoked share: expected 404 got %d", resp.Code)
+	}
+	resp = api.Delete(path+"/shares/bob", auth)
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || resp.Code != 404 || problem.Code != "share_not_found" {
+		t.Fatalf("revoke again: expected 404 share_not_found got %d %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestAssigneeAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+	creds := map[string]any{"username": "carol", "password": "secret"}
+	if resp := api.Post("/auth/register", creds); resp.Code != 200 {
+		t.Fatalf("register: expected 200 got %d", resp.Code)
+	}
+	var login struct {
+		Token string `json:"token"`
+	}
+	resp := api.Post("/auth/login", creds)
+	if err := json.Unmarshal(resp.Body.Bytes(), &login); err != nil || login.Token == "" {
+		t.Fatalf("login: %v %s", err, resp.Body.String())
+	}
+	carol := "Authorization: Bearer " + login.Token
+
+	if resp = api.Post("/todos", auth, map[string]any{"title": "Fix sink", "dueDate": "2025-07-01T00:00:00Z", "done": false, "assignee": "nobody"}); resp.Code != 422 {
+		t.Fatalf("unknown assignee: expected 422 got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Post("/todos", auth, map[string]any{"title": "Fix sink with @carol and @nobody", "dueDate": "2025-07-01T00:00:00Z", "done": false, "assignee": "carol"}); resp.Code != 200 {
+		t.Fatalf("create: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	resp = api.Get("/todos?limit=10", auth)
+	if body := resp.Body.String(); !strings.Contains(body, `"assignee":"carol"`) || !strings.Contains(body, `"mentions":["carol"]`) {
+		t.Fatalf("list: expected assignee and mention carol got %s", body)
+	}
+	if resp = api.Get("/todos?limit=10", carol); !strings.Contains(resp.Body.String(), `"total":0`) {
+		t.Fatalf("own list: expected no todos got %s", resp.Body.String())
+	}
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	resp = api.Get("/todos?assigned=me&limit=10", carol)
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil || len(list.Data) != 1 {
+		t.Fatalf("assigned to me: %v %s", err, resp.Body.String())
+	}
+	path := "/todos/" + list.Data[0].ID
+	if resp = api.Patch(path, carol, map[string]any{"done": true}); resp.Code != 200 {
+		t.Fatalf("assignee patch: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Patch(path, auth, map[string]any{"assignee": ""}); resp.Code != 200 {
+		t.Fatalf("unassign: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Get(path, carol); resp.Code != 404 {
+		t.Fatalf("unassigned todo: expected 404 got %d", resp.Code)
+	}
+}
+
+func TestCommentAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+	creds := map[string]any{"username": "dave", "password": "secret"}
+	if resp := api.Post("/auth/register", creds); resp.Code != 200 {
+		t.Fatalf("register: expected 200 got %d", resp.Code)
+	}
+	var login struct {
+		Token string `json:"token"`
+	}
+	resp := api.Post("/auth/login", creds)
+	if err := json.Unmarshal(resp.Body.Bytes(), &login); err != nil || login.Token == "" {
+		t.Fatalf("login: %v %s", err, resp.Body.String())
+	}
+	dave := "Authorization: Bearer " + login.Token
+
+	api.Post("/todos", auth, map[string]any{"title": "Paint fence", "dueDate": "2025-07-01T00:00:00Z", "done": false})
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	resp = api.Get("/todos?limit=10", auth)
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil || len(list.Data) != 1 {
+		t.Fatalf("list: %v %s", err, resp.Body.String())
+	}
+	path := "/todos/" + list.Data[0].ID + "/comments"
+	if resp = api.Post(path, dave, map[string]any{"body": "Which color?"}); resp.Code != 404 {
+		t.Fatalf("comment on unshared todo: expected 404 got %d", resp.Code)
+	}
+	api.Post("/todos/"+list.Data[0].ID+"/shares", auth, map[string]any{"username": "dave", "permission": "view"})
+
+	var created struct {
+		Comment struct {
+			ID string `json:"id"`
+		} `json:"comment"`
+	}
+	resp = api.Post(path, dave, map[string]any{"body": "Which color?"})
+	if err := json.Unmarshal(resp.Body.Bytes(), &created); err != nil || resp.Code != 200 || created.Comment.ID == "" {
+		t.Fatalf("comment: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Post(path, auth, map[string]any{"body": "Green", "parentId": created.Comment.ID}); resp.Code != 200 || !strings.Contains(resp.Body.String(), `"parentId":"`+created.Comment.ID+`"`) {
+		t.Fatalf("reply: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Get(path+"?limit=10", auth); !strings.Contains(resp.Body.String(), `"total":2`) || !strings.Contains(resp.Body.String(), `"author":"dave"`) {
+		t.Fatalf("list comments: expected 2 got %s", resp.Body.String())
+	}
+	if resp = api.Get("/todos?limit=10", auth); !strings.Contains(resp.Body.String(), `"commentCount":2`) {
+		t.Fatalf("todo list: expected commentCount 2 got %s", resp.Body.String())
+	}
+
+	comment := path + "/" + created.Comment.ID
+	var problem struct {
+		Code string `json:"code"`
+	}
+	resp = api.Patch(comment, auth, map[string]any{"body": "Which shade?"})
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || resp.Code != 403 || problem.Code != "comment_forbidden" {
+		t.Fatalf("edit by other: expected 403 comment_forbidden got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Patch(comment, dave, map[string]any{"body": "Which shade?"}); resp.Code != 200 || !strings.Contains(resp.Body.String(), `"editedAt"`) {
+		t.Fatalf("edit: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Delete(comment, dave); resp.Code != 200 {
+		t.Fatalf("delete: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	resp = api.Get(comment, auth)
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || resp.Code != 404 || problem.Code != "comment_not_found" {
+		t.Fatalf("deleted comment: expected 404 comment_not_found got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Get(path+"?limit=10", auth); !strings.Contains(resp.Body.String(), `"total":0`) {
+		t.Fatalf("replies are deleted with their comment, got %s", resp.Body.String())
+	}
+}
+
+// multipartFile builds a multipart form with one file in its file field,
+// returning the body and its Content-Type header.
+func multipartFile(t *testing.T, filename, contentType, content string) (*bytes.Buffer, string) {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="file"; filename="`+filename+`"`)
+	header.Set("Content-Type", contentType)
+	part, err := w.CreatePart(header)
+	if err != nil {
+		t.Fatalf("multipart: %v", err)
+	}
+	_, _ = part.Write([]byte(content))
+	if err := w.Close(); err != nil {
+		t.Fatalf("multipart: %v", err)
+	}
+	return &body, "Content-Type: " + w.FormDataContentType()
+}
+
+func TestAttachmentAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+	api.Post("/todos", auth, map[string]any{"title": "Expense report", "dueDate": "2025-07-01T00:00:00Z", "done": false})
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	resp := api.Get("/todos?limit=10", auth)
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil || len(list.Data) != 1 {
+		t.Fatalf("list: %v %s", err, resp.Body.String())
+	}
+	todo := "/todos/" + list.Data[0].ID
+	path := todo + "/attachments"
+
+	var problem struct {
+		Code string `json:"code"`
+	}
+	body, contentType := multipartFile(t, "page.html", "text/html", "<p>hi</p>")
+	resp = api.Post(path, auth, contentType, body)
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || resp.Code != 415 || problem.Code != "attachment_type_unsupported" {
+		t.Fatalf("html: expected 415 attachment_type_unsupported got %d %s", resp.Code, resp.Body.String())
+	}
+	body, contentType = multipartFile(t, "big.txt", "text/plain", strings.Repeat("x", 1<<10+1))
+	resp = api.Post(path, auth, contentType, body)
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || resp.Code != 413 || problem.Code != "attachment_too_large" {
+		t.Fatalf("too large: expected 413 attachment_too_large got %d %s", resp.Code, resp.Body.String())
+	}
+
+	var created struct {
+		Attachment struct {
+			ID          string `json:"id"`
+			ContentType string `json:"contentType"`
+			Size        int64  `json:"size"`
+		} `json:"attachment"`
+	}
+	body, contentType = multipartFile(t, "receipt.txt", "text/plain", "Total: 12.50")
+	resp = api.Post(path, auth, contentType, body)
+	if err := json.Unmarshal(resp.Body.Bytes(), &created); err != nil || resp.Code != 200 || created.Attachment.Size != 12 {
+		t.Fatalf("upload: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Get(todo, auth); !strings.Contains(resp.Body.String(), `"filename":"receipt.txt"`) {
+		t.Fatalf("todo: expected the attachment got %s", resp.Body.String())
+	}
+	file := path + "/" + created.Attachment.ID
+	resp = api.Get(file, auth)
+	if resp.Code != 200 || resp.Body.String() != "Total: 12.50" {
+		t.Fatalf("download: expected the file got %d %s", resp.Code, resp.Body.String())
+	}
+	if got := resp.Header().Get("Content-Type"); got != "text/plain" {
+		t.Fatalf("download: expected text/plain got %q", got)
+	}
+	if got := resp.Header().Get("Content-Disposition"); got != `attachment; filename=receipt.txt` {
+		t.Fatalf("download: expected an attachment disposition got %q", got)
+	}
+
+	if resp = api.Delete(file, auth); resp.Code != 200 {
+		t.Fatalf("delete: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	resp = api.Get(file, auth)
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || resp.Code != 404 || problem.Code != "attachment_not_found" {
+		t.Fatalf("deleted: expected 404 attachment_not_found got %d %s", resp.Code, resp.Body.String())
+	}
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func TestAttachmentAPI_OversizedUploadIsNotRead(t *testing.T) {
+	api, auth := newTestAPI(t)
+	api.Post("/todos", auth, map[string]any{"title": "Scans", "dueDate": "2025-07-01T00:00:00Z", "done": false})
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	resp := api.Get("/todos?limit=10", auth)
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil || len(list.Data) != 1 {
+		t.Fatalf("list: %v %s", err, resp.Body.String())
+	}
+	path := "/todos/" + list.Data[0].ID + "/attachments"
+
+	// a 64 MiB file, streamed without a Content-Length
+	head, contentType := multipartFile(t, "scan.txt", "text/plain", "")
+	boundary := strings.TrimPrefix(contentType, "Content-Type: multipart/form-data; boundary=")
+	part := strings.TrimSuffix(head.String(), "\r\n--"+boundary+"--\r\n")
+	body := &countingReader{r: io.MultiReader(
+		strings.NewReader(part),
+		io.LimitReader(neverEnding('x'), 64<<20),
+		strings.NewReader("\r\n--"+boundary+"--\r\n"),
+	)}
+	req := httptest.NewRequest(http.MethodPost, path, body)
+	req.Header.Set("Authorization", strings.TrimPrefix(auth, "Authorization: "))
+	req.Header.Set("Content-Type", strings.TrimPrefix(contentType, "Content-Type: "))
+	w := httptest.NewRecorder()
+	api.Adapter().ServeHTTP(w, req)
+
+	var problem struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || w.Code != 413 || problem.Code != "attachment_too_large" {
+		t.Fatalf("expected 413 attachment_too_large got %d %s", w.Code, w.Body.String())
+	}
+	if body.n > 1<<20 {
+		t.Fatalf("expected the upload to be cut off early, %d bytes were read", body.n)
+	}
+	if resp = api.Get("/todos/"+list.Data[0].ID, auth); strings.Contains(resp.Body.String(), "scan.txt") {
+		t.Fatalf("expected no attachment got %s", resp.Body.String())
+	}
+}
+
+// neverEnding is an endless stream of one byte.
+type neverEnding byte
+
+func (b neverEnding) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(b)
+	}
+	return len(p), nil
+}
+
+func TestShareLinkAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+	for _, body := range []map[string]any{
+		{"title": "Milk", "dueDate": "2025-07-01T00:00:00Z", "done": false, "tags": []string{"shop"}},
+		{"title": "Eggs", "dueDate": "2025-07-01T00:00:00Z", "done": false, "tags": []string{"shop"}},
+		{"title": "Diary", "dueDate": "2025-07-01T00:00:00Z", "done": false},
+	} {
+		api.Post("/todos", auth, body)
+	}
+
+	var created struct {
+		Link struct {
+			ID string `json:"id"`
+		} `json:"link"`
+		Path string `json:"path"`
+	}
+	resp := api.Post("/links", auth, map[string]any{"filter": map[string]any{"tag": "shop"}, "password": "open sesame"})
+	if err := json.Unmarshal(resp.Body.Bytes(), &created); err != nil || resp.Code != 200 || created.Path == "" {
+		t.Fatalf("create link: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	if strings.Contains(resp.Body.String(), "open sesame") || strings.Contains(resp.Body.String(), "passwordHash") {
+		t.Fatalf("the password must not be returned: %s", resp.Body.String())
+	}
+
+	var problem struct {
+		Code string `json:"code"`
+	}
+	resp = api.Get(created.Path + "?limit=10")
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || resp.Code != 401 || problem.Code != "share_link_password" {
+		t.Fatalf("no password: expected 401 share_link_password got %d %s", resp.Code, resp.Body.String())
+	}
+	resp = api.Get(created.Path+"?limit=10", "X-Link-Password: open sesame")
+	body := resp.Body.String()
+	if resp.Code != 200 || !strings.Contains(body, `"total":2`) || !strings.Contains(body, "Milk") || strings.Contains(body, "Diary") {
+		t.Fatalf("read link: expected Milk and Eggs got %d %s", resp.Code, body)
+	}
+	if strings.Contains(body, "tester") || strings.Contains(body, `"id"`) {
+		t.Fatalf("the link must not show owner data: %s", body)
+	}
+
+	if resp = api.Get("/links", auth); !strings.Contains(resp.Body.String(), `"passwordProtected":true`) {
+		t.Fatalf("list links: expected the link got %s", resp.Body.String())
+	}
+	if resp = api.Delete("/links/"+created.Link.ID, auth); resp.Code != 200 {
+		t.Fatalf("revoke: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	resp = api.Get(created.Path, "X-Link-Password: open sesame")
+	if err := json.Unmarshal(resp.Body.Bytes(), &problem); err != nil || resp.Code != 404 || problem.Code != "share_link_not_found" {
+		t.Fatalf("revoked link: expected 404 share_link_not_found got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Post("/links", auth, map[string]any{"expiresAt": "2030-01-01T00:00:00Z"}); resp.Code != 422 {
+		t.Fatalf("no todo or filter: expected 422 got %d", resp.Code)
+	}
+}
+
+func TestBulkAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+
+	for _, title := range []string{"Old milk", "Old bread", "Keep"} {
+		resp := api.Post("/todos", auth, map[string]any{"title": title, "dueDate": "2025-07-01T00:00:00Z", "done": title != "Keep", "tags": []string{"kitchen"}})
+		if resp.Code != 200 {
+			t.Fatalf("create: expected 200 got %d %s", resp.Code, resp.Body.String())
+		}
+	}
+
+	resp := api.Post("/todos/bulk", auth, map[string]any{"action": "delete", "filter": map[string]any{"done": true}})
+	var report struct {
+		Results []struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+			Code   string `json:"code"`
+		} `json:"results"`
+		Summary struct {
+			Applied int `json:"applied"`
+			Failed  int `json:"failed"`
+		} `json:"summary"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &report); err != nil || resp.Code != 200 || report.Summary.Applied != 2 {
+		t.Fatalf("bulk delete: expected 2 applied got %d %s", resp.Code, resp.Body.String())
+	}
+
+	resp = api.Get("/todos?limit=10&tag=kitchen", auth)
+	var list struct {
+		Data []struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &list); err != nil || len(list.Data) != 1 || list.Data[0].Title != "Keep" {
+		t.Fatalf("expected only the open todo left got %v %s", err, resp.Body.String())
+	}
+
+	resp = api.Post("/todos/bulk", auth, map[string]any{"action": "retag", "ids": []string{list.Data[0].ID, "missing"}, "addTags": []string{"urgent"}})
+	if err := json.Unmarshal(resp.Body.Bytes(), &report); err != nil || resp.Code != 200 {
+		t.Fatalf("bulk retag: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	if report.Results[0].Status != "applied" || report.Results[1].Status != "failed" || report.Results[1].Code != "todo_not_found" {
+		t.Fatalf("unexpected per-item results %s", resp.Body.String())
+	}
+
+	resp = api.Post("/todos/bulk", auth, map[string]any{"action": "complete"})
+	if resp.Code != 422 {
+		t.Fatalf("no selection: expected 422 got %d", resp.Code)
+	}
+}
+
+func TestBatchAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+
+	resp := api.Post("/batch", auth, map[string]any{"ops": []map[string]any{
+		{"method": "POST", "path": "/todos", "body": map[string]any{"title": "First", "dueDate": "2025-07-01T00:00:00Z", "done": false}},
+		{"method": "POST", "path": "/todos", "body": map[string]any{"title": "Second", "dueDate": "2025-07-01T00:00:00Z", "done": false}},
+	}})
+	var batch struct {
+		Committed bool `json:"committed"`
+		Results   []struct {
+			Status int    `json:"status"`
+			ETag   string `json:"etag"`
+			Body   struct {
+				Todo struct {
+					ID string `json:"id"`
+				} `json:"todo"`
+				Code string `json:"code"`
+			} `json:"body"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &batch); err != nil || resp.Code != 200 || !batch.Committed || len(batch.Results) != 2 {
+		t.Fatalf("batch create: expected committed got %d %s", resp.Code, resp.Body.String())
+	}
+	first, second := batch.Results[0].Body.Todo.ID, batch.Results[1].Body.Todo.ID
+
+	// the second patch is stale, so the delete before it is rolled back too
+	resp = api.Post("/batch", auth, map[string]any{"ops": []map[string]any{
+		{"method": "DELETE", "path": "/todos/" + first},
+		{"method": "PATCH", "path": "/todos/" + second, "ifMatch": `"7"`, "body": map[string]any{"done": true}},
+	}})
+	batch.Results = nil
+	if err := json.Unmarshal(resp.Body.Bytes(), &batch); err != nil || resp.Code != 412 || batch.Committed {
+		t.Fatalf("stale batch: expected 412 got %d %s", resp.Code, resp.Body.String())
+	}
+	if batch.Results[0].Status != 424 || batch.Results[1].Body.Code != "todo_version_conflict" {
+		t.Fatalf("unexpected per-operation results %s", resp.Body.String())
+	}
+	resp = api.Get("/todos/"+first, auth)
+	if resp.Code != 200 {
+		t.Fatalf("rolled back delete: expected the todo to still exist got %d", resp.Code)
+	}
+
+	resp = api.Post("/batch", auth, map[string]any{"ops": []map[string]any{{"method": "PUT", "path": "/webhooks/x"}}})
+	if resp.Code != 422 {
+		t.Fatalf("unknown operation: expected 422 got %d", resp.Code)
+	}
+}
+
+func TestSearchAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+
+	for _, title := range []string{"Buy milk", "Milk & cookies <3", "Walk dog"} {
+		if resp := api.Post("/todos", auth, map[string]any{"title": title, "dueDate": "2025-07-01T09:00:00Z", "done": false}); resp.Code != 200 {
+			t.Fatalf("create: expected 200 got %d", resp.Code)
+		}
+	}
+	resp := api.Get("/todos/search?q=cookies+MILK&limit=10", auth)
+	var out struct {
+		Data []struct {
+			Highlight string `json:"highlight"`
+		} `json:"data"`
+		Meta struct {
+			Total int64 `json:"total"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &out); err != nil || resp.Code != 200 || out.Meta.Total != 2 {
+		t.Fatalf("search: expected 2 results got %d %s", resp.Code, resp.Body.String())
+	}
+	if out.Data[0].Highlight != "<mark>Milk</mark> &amp; <mark>cookies</mark> &lt;3" || out.Data[1].Highlight != "Buy <mark>milk</mark>" {
+		t.Fatalf("unexpected ranking or highlights: %s", resp.Body.String())
+	}
+
+	if resp = api.Get("/todos/search?q=+%2B+", auth); resp.Code != 422 {
+		t.Fatalf("search without words: expected 422 got %d", resp.Code)
+	}
+	// regular expression syntax is taken literally by the title filter
+	if resp = api.Get("/todos?title=(a%2B)%2B$&limit=10", auth); resp.Code != 200 || !strings.Contains(resp.Body.String(), `"total":0`) {
+		t.Fatalf("title filter: expected no match got %d %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestAllDayAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+
+	// the two zones are 25 hours apart, so their dates always differ
+	kiritimati, _ := time.LoadLocation("Pacific/Kiritimati")
+	today := time.Now().In(kiritimati).Format("2006-01-02")
+	resp := api.Post("/todos", auth, map[string]any{"title": "Ship release", "dueDate": today + "T00:00:00+14:00", "allDay": true, "timeZone": "Pacific/Kiritimati", "done": false})
+	if resp.Code != 200 {
+		t.Fatalf("create: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	resp = api.Get("/todos?due=today&timeZone=Pacific/Kiritimati&limit=10", auth)
+	if !strings.Contains(resp.Body.String(), `"dueDate":"`+today+`T00:00:00Z","allDay":true,"timeZone":"Pacific/Kiritimati"`) {
+		t.Fatalf("due today in Kiritimati: expected the todo got %s", resp.Body.String())
+	}
+	if resp = api.Get("/todos?due=today&timeZone=Pacific/Pago_Pago&limit=10", auth); !strings.Contains(resp.Body.String(), `"total":0`) {
+		t.Fatalf("due today in Pago Pago: expected nothing got %s", resp.Body.String())
+	}
+	if resp = api.Get("/todos?due=overdue&timeZone=Mars/Olympus&limit=10", auth); resp.Code != 422 {
+		t.Fatalf("unknown time zone: expected 422 got %d", resp.Code)
+	}
+}
+
+func TestQuickAddAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+
+	body := map[string]any{"text": "pay rent every month on the 1st #bills !high 9am", "timeZone": "Asia/Taipei"}
+	resp := api.Post("/todos/quick?dryRun=true", auth, body)
+	var out struct {
+		Interpretation struct {
+			Title      string    `json:"title"`
+			DueDate    time.Time `json:"dueDate"`
+			Recurrence string    `json:"recurrence"`
+			Phrases    []struct {
+				Text string `json:"text"`
+				Kind string `json:"kind"`
+			} `json:"phrases"`
+		} `json:"interpretation"`
+		Todo struct {
+			ID    string   `json:"id"`
+			Title string   `json:"title"`
+			Tags  []string `json:"tags"`
+		} `json:"todo"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &out); err != nil || resp.Code != 200 {
+		t.Fatalf("preview: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	in := out.Interpretation
+	if in.Title != "pay rent" || in.Recurrence != "FREQ=MONTHLY;BYMONTHDAY=1" || len(in.Phrases) != 4 || in.Phrases[0].Kind != "recurrence" {
+		t.Fatalf("unexpected interpretation: %s", resp.Body.String())
+	}
+	if _, offset := in.DueDate.Zone(); offset != 8*3600 || in.DueDate.Day() != 1 || in.DueDate.Hour() != 9 {
+		t.Fatalf("expected the 1st at 9am in Taipei got %v", in.DueDate)
+	}
+	if resp = api.Get("/todos?limit=10", auth); !strings.Contains(resp.Body.String(), `"total":0`) {
+		t.Fatalf("preview created a todo: %s", resp.Body.String())
+	}
+
+	resp = api.Post("/todos/quick", auth, body)
+	if err := json.Unmarshal(resp.Body.Bytes(), &out); err != nil || resp.Code != 200 {
+		t.Fatalf("quick add: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Get("/todos/"+out.Todo.ID, auth); resp.Code != 200 || !strings.Contains(resp.Body.String(), `"bills"`) {
+		t.Fatalf("get quick-added todo: got %d %s", resp.Code, resp.Body.String())
+	}
+
+	if resp = api.Post("/todos/quick", auth, map[string]any{"text": "#bills !high"}); resp.Code != 422 {
+		t.Fatalf("quick add without a title: expected 422 got %d", resp.Code)
+	}
+}
+
+func TestCSVAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+
+	csv := "Task,Due,Labels\nBuy milk,2025-07-01,home\nFile taxes,2025-04-15,\"work,admin\"\n,2025-01-01,\n"
+	resp := api.Post("/todos/import?dryRun=true&mapping=Task=title,Due=dueDate,Labels=tags", auth, "Content-Type: text/csv", strings.NewReader(csv))
+	var report struct {
+		DryRun  bool `json:"dryRun"`
+		Summary struct {
+			Created int `json:"created"`
+			Failed  int `json:"failed"`
+		} `json:"summary"`
+		Rows []struct {
+			Line  int    `json:"line"`
+			Field string `json:"field"`
+		} `json:"rows"`
+	}
+	if err := json.Unmarshal(resp.Body.Bytes(), &report); err != nil || resp.Code != 200 || !report.DryRun || report.Summary.Created != 2 || report.Summary.Failed != 1 {
+		t.Fatalf("dry run: expected 2 created and 1 failed got %d %s", resp.Code, resp.Body.String())
+	}
+	if report.Rows[2].Line != 4 || report.Rows[2].Field != "title" {
+		t.Fatalf("expected line 4 to fail on title got %s", resp.Body.String())
+	}
+	resp = api.Get("/todos?limit=10", auth)
+	if strings.Contains(resp.Body.String(), "Buy milk") {
+		t.Fatalf("dry run created todos: %s", resp.Body.String())
+	}
+
+	resp = api.Post("/todos/import?mapping=Task=title,Due=dueDate,Labels=tags", auth, "Content-Type: text/csv", strings.NewReader(csv))
+	if resp.Code != 200 {
+		t.Fatalf("import: expected 200 got %d %s", resp.Code, resp.Body.String())
+	}
+
+	resp = api.Get("/todos/export.csv?tag=work", auth)
+	if resp.Code != 200 || !strings.HasPrefix(resp.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("export: expected CSV got %d %q", resp.Code, resp.Header().Get("Content-Type"))
+	}
+	lines := strings.Split(strings.TrimSpace(resp.Body.String()), "\n")
+	if len(lines) != 2 || lines[0] != "id,title,dueDate,done,tags,recurrence,version,owner" || !strings.Contains(lines[1], `File taxes,2025-04-15T00:00:00Z,false,"work,admin"`) {
+		t.Fatalf("unexpected export %q", lines)
+	}
+}
+
+func TestTodoTxtAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+
+	txt := "(A) Call mom +family @phone due:2025-07-01\nx 2025-06-30 Pay rent +home pri:B\n"
+	resp := api.Post("/todos/import.txt", auth, "Content-Type: text/plain", strings.NewReader(txt))
+	if resp.Code != 200 || !strings.Contains(resp.Body.String(), `"created":2`) {
+		t.Fatalf("import: expected 2 created got %d %s", resp.Code, resp.Body.String())
+	}
+
+	resp = api.Get("/todos/export.txt", auth)
+	if resp.Code != 200 || !strings.HasPrefix(resp.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("export: expected todo.txt got %d %q", resp.Code, resp.Header().Get("Content-Type"))
+	}
+	exported := resp.Body.String()
+	if !strings.Contains(exported, "(A) Call mom +family @phone due:2025-07-01 id:") || !strings.Contains(exported, "x 2025-06-30 Pay rent +home pri:B id:") {
+		t.Fatalf("unexpected export %q", exported)
+	}
+
+	// importing the export again finds nothing to change
+	resp = api.Post("/todos/import.txt", auth, "Content-Type: text/plain", strings.NewReader(exported))
+	if resp.Code != 200 || !strings.Contains(resp.Body.String(), `"unchanged":2`) {
+		t.Fatalf("re-import: expected 2 unchanged got %d %s", resp.Code, resp.Body.String())
+	}
+	edited := strings.Replace(exported, "(A) Call mom", "(C) Call mom", 1)
+	resp = api.Post("/todos/import.txt", auth, "Content-Type: text/plain", strings.NewReader(edited))
+	if resp.Code != 200 || !strings.Contains(resp.Body.String(), `"updated":1`) {
+		t.Fatalf("edited import: expected 1 updated got %d %s", resp.Code, resp.Body.String())
+	}
+	resp = api.Get("/todos?limit=10", auth)
+	if !strings.Contains(resp.Body.String(), `"priority":"C"`) {
+		t.Fatalf("expected the priority to be updated: %s", resp.Body.String())
+	}
+}
+
+func TestMarkdownAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+
+	doc := "## Release\n\n- [ ] Ship it #work due:2025-07-01\n  - [x] Tag the commit\n  - [ ] Announce\n- [x] Fix #123\n"
+	resp := api.Post("/todos/import.md", auth, "Content-Type: text/markdown", strings.NewReader(doc))
+	if resp.Code != 200 || !strings.Contains(resp.Body.String(), `"created":2`) {
+		t.Fatalf("import: expected 2 created got %d %s", resp.Code, resp.Body.String())
+	}
+	resp = api.Get("/todos?tag=work&limit=10", auth)
+	if !strings.Contains(resp.Body.String(), `"label":"1/2"`) {
+		t.Fatalf("expected the nested items as checklist: %s", resp.Body.String())
+	}
+
+	resp = api.Get("/todos/export.md?groupBy=tag", auth)
+	if resp.Code != 200 || !strings.HasPrefix(resp.Header().Get("Content-Type"), "text/markdown") {
+		t.Fatalf("export: expected Markdown got %d %q", resp.Code, resp.Header().Get("Content-Type"))
+	}
+	want := "## work\n\n- [ ] Ship it #work due:2025-07-01\n  - [x] Tag the commit\n  - [ ] Announce\n\n## Untagged\n\n- [x] Fix #123\n"
+	if resp.Body.String() != want {
+		t.Fatalf("unexpected export %q", resp.Body.String())
+	}
+}
+
+func TestCalendarFeedAPI(t *testing.T) {
+	api, auth := newTestAPI(t)
+
+	resp := api.Post("/todos", auth, map[string]any{"title": "Buy milk", "dueDate": "2025-07-01T09:00:00Z", "done": false})
+	if resp.Code != 200 {
+		t.Fatalf("create: expected 200 got %d", resp.Code)
+	}
+	var feed struct {
+		Path string `json:"path"`
+	}
+	resp = api.Get("/calendar/feed", auth)
+	if err := json.Unmarshal(resp.Body.Bytes(), &feed); err != nil || resp.Code != 200 || feed.Path == "" {
+		t.Fatalf("get feed: %d %s", resp.Code, resp.Body.String())
+	}
+
+	// the feed is read without a token, as calendar apps do
+	resp = api.Get(feed.Path + "?events=true")
+	if resp.Code != 200 || !strings.HasPrefix(resp.Header().Get("Content-Type"), "text/calendar") {
+		t.Fatalf("read feed: expected 200 text/calendar got %d %q", resp.Code, resp.Header().Get("Content-Type"))
+	}
+	body := resp.Body.String()
+	for _, want := range []string{"BEGIN:VTODO\r\n", "SUMMARY:Buy milk\r\n", "DUE:20250701T090000Z\r\n", "STATUS:NEEDS-ACTION\r\n", "BEGIN:VEVENT\r\n"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("feed lacks %q:\n%s", want, body)
+		}
+	}
+
+	var rotated struct {
+		Path string `json:"path"`
+	}
+	resp = api.Post("/calendar/feed/rotate", auth)
+	if err := json.Unmarshal(resp.Body.Bytes(), &rotated); err != nil || resp.Code != 200 || rotated.Path == feed.Path {
+		t.Fatalf("rotate: %d %s", resp.Code, resp.Body.String())
+	}
+	resp = api.Get(feed.Path)
+	if resp.Code != 404 || !strings.Contains(resp.Body.String(), "calendar_feed_not_found") {
+		t.Fatalf("old feed: expected 404 got %d %s", resp.Code, resp.Body.String())
+	}
+	if resp = api.Get(rotated.Path); resp.Code != 200 {
+		t.Fatalf("new feed: expected 200 got %d", resp.Code)
+	}
+}
@@ -1,24 +1,46 @@
 package tests
 
 import (
+	"bufio"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	authDomain "todo-app/internal/auth/domain"
 	authRepo "todo-app/internal/auth/infrastructure/repository"
+	calendarRepo "todo-app/internal/calendar/infrastructure/repository"
+	linkRepo "todo-app/internal/link/infrastructure/repository"
+	projectRepo "todo-app/internal/project/infrastructure/repository"
+	reminderDomain "todo-app/internal/reminder/domain"
+	reminderNotifier "todo-app/internal/reminder/infrastructure/notifier"
+	reminderRepo "todo-app/internal/reminder/infrastructure/repository"
 	"todo-app/internal/server"
 	todoRepo "todo-app/internal/todo/infrastructure/repository"
+	todoUsecase "todo-app/internal/todo/usecase"
+	webhookRepo "todo-app/internal/webhook/infrastructure/repository"
 )
 
 func TestTodoList_HTTP(t *testing.T) {
 	deps := server.Deps{
-		JWTSecret: "test-secret",
-		AuthRepo:  authRepo.NewMemoryRepo(),
-		TokenGen:  &authRepo.JWTTokenGenerator{Secret: "test-secret"},
-		TodoRepo:  todoRepo.NewMemoryTodoRepository(),
+		JWTSecret:    "test-secret",
+		AuthRepo:     authRepo.NewMemoryRepo(),
+		TokenGen:     &authRepo.JWTTokenGenerator{Secret: "test-secret"},
+		TodoRepo:     todoRepo.NewMemoryTodoRepository(),
+		HistoryRepo:  todoRepo.NewMemoryHistoryRepository(),
+		ShareRepo:    todoRepo.NewMemoryShareRepository(),
+		CommentRepo:  todoRepo.NewMemoryCommentRepository(),
+		ReminderRepo: reminderRepo.NewMemoryReminderRepository(),
+		Notifiers: map[string]reminderDomain.Notifier{
+			reminderDomain.ChannelLog: reminderNotifier.LogNotifier{},
+		},
+		WebhookRepo:         webhookRepo.NewMemorySubscriptionRepository(),
+		WebhookDeliveryRepo: webhookRepo.NewMemoryDeliveryRepository(),
+		FeedRepo:            calendarRepo.NewMemoryFeedRepository(),
+		ProjectRepo:         projectRepo.NewMemoryProjectRepository(),
+		LinkRepo:            linkRepo.NewMemoryLinkRepository(),
 	}
-	h := server.NewRouter(deps)
+	h := server.NewHandler(deps)
 	ts := httptest.NewServer(h)
 	defer ts.Close()
 	// need auth token header
@@ -37,3 +59,101 @@ func TestTodoList_HTTP(t *testing.T) {
 		t.Fatalf("expected 200 got %d", res.StatusCode)
 	}
 }
+
+func TestTodoEvents_SSE(t *testing.T) {
+	deps := server.Deps{
+		JWTSecret:    "test-secret",
+		AuthRepo:     authRepo.NewMemoryRepo(),
+		TokenGen:     &authRepo.JWTTokenGenerator{Secret: "test-secret"},
+		TodoRepo:     todoRepo.NewMemoryTodoRepository(),
+		HistoryRepo:  todoRepo.NewMemoryHistoryRepository(),
+		ShareRepo:    todoRepo.NewMemoryShareRepository(),
+		CommentRepo:  todoRepo.NewMemoryCommentRepository(),
+		ReminderRepo: reminderRepo.NewMemoryReminderRepository(),
+		Notifiers: map[string]reminderDomain.Notifier{
+			reminderDomain.ChannelLog: reminderNotifier.LogNotifier{},
+		},
+		WebhookRepo:         webhookRepo.NewMemorySubscriptionRepository(),
+		WebhookDeliveryRepo: webhookRepo.NewMemoryDeliveryRepository(),
+		FeedRepo:            calendarRepo.NewMemoryFeedRepository(),
+		ProjectRepo:         projectRepo.NewMemoryProjectRepository(),
+		LinkRepo:            linkRepo.NewMemoryLinkRepository(),
+		Events:              todoUsecase.NewBroker(10),
+	}
+	ts := httptest.NewServer(server.NewHandler(deps))
+	defer ts.Close()
+	defer deps.Events.Close()
+	token, err := deps.TokenGen.Generate(authDomain.AuthUser{Username: "streamer"})
+	if err != nil {
+		t.Fatalf("token gen: %v", err)
+	}
+
+	// readEvents reads the lines of the next n SSE messages
+	readEvents := func(body *bufio.Reader, n int) []string {
+		var lines []string
+		for n > 0 {
+			line, err := body.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read stream: %v", err)
+			}
+			line = strings.TrimSpace(line)
+			if line == "" {
+				n--
+				continue
+			}
+			lines = append(lines, line)
+		}
+		return lines
+	}
+	stream := func(lastEventID string) (*http.Response, *bufio.Reader) {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+"/todos/events", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("open stream: %v", err)
+		}
+		if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("expected text/event-stream got %q", ct)
+		}
+		return res, bufio.NewReader(res.Body)
+	}
+
+	create := func(title string) {
+		req, _ := http.NewRequest(http.MethodPost, ts.URL+"/todos", strings.NewReader(`{"title":"`+title+`","dueDate":"2025-07-01T00:00:00Z","done":false}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil || res.StatusCode != 200 {
+			t.Fatalf("create: %v %v", err, res)
+		}
+		res.Body.Close()
+	}
+
+	res, body := stream("")
+	readEvents(body, 1) // initial heartbeat
+	create("Stream me")
+	lines := readEvents(body, 1)
+	if len(lines) != 3 || lines[0] != "id: 1" || lines[1] != "event: created" || !strings.Contains(lines[2], `"title":"Stream me"`) {
+		t.Fatalf("unexpected created event %q", lines)
+	}
+	res.Body.Close()
+
+	// a change made while disconnected is replayed on reconnect
+	create("Missed")
+	res, body = stream("1")
+	lines = readEvents(body, 2)
+	if len(lines) != 6 || lines[3] != "id: 2" || !strings.Contains(lines[5], `"title":"Missed"`) {
+		t.Fatalf("expected the missed event to be replayed got %q", lines)
+	}
+	res.Body.Close()
+
+	res, body = stream("99")
+	lines = readEvents(body, 1)
+	if len(lines) < 2 || lines[len(lines)-2] != "event: reset" {
+		t.Fatalf("expected reset for an unknown Last-Event-ID got %q", lines)
+	}
+	res.Body.Close()
+}
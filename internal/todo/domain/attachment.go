@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// MaxAttachments is the most files a todo can have attached.
+const MaxAttachments = 20
+
+// Attachment describes a file attached to a todo. The metadata is stored
+// with the todo, the content in a BlobStore under the attachment's ID.
+type Attachment struct {
+	ID          string    `json:"id" example:"3d6f0a1b-2c4e-4f8a-9b7d-6e5c4b3a2f1e" doc:"Unique identifier for the attachment"`
+	Filename    string    `json:"filename" example:"receipt.pdf" doc:"Name of the file as uploaded"`
+	ContentType string    `json:"contentType" example:"application/pdf" doc:"Media type of the file"`
+	Size        int64     `json:"size" example:"48213" doc:"Size of the file in bytes"`
+	UploadedBy  string    `json:"uploadedBy" example:"bob" doc:"User who attached the file"`
+	UploadedAt  time.Time `json:"uploadedAt" doc:"When the file was attached"`
+}
+
+// AttachmentLimits bounds the files that can be attached to todos.
+type AttachmentLimits struct {
+	// MaxSize is the largest file in bytes; zero allows any size.
+	MaxSize int64
+	// ContentTypes are the media types files may have, such as
+	// "application/pdf", or "image/*" for every image type; none allows
+	// every type.
+	ContentTypes []string
+}
+
+// Allows reports whether files of the media type can be attached.
+func (l AttachmentLimits) Allows(contentType string) bool {
+	if len(l.ContentTypes) == 0 {
+		return true
+	}
+	for _, allowed := range l.ContentTypes {
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok {
+			if strings.HasPrefix(contentType, prefix+"/") {
+				return true
+			}
+		} else if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// BlobStore keeps the content of attachments under a key.
+type BlobStore interface {
+	// Put stores content under key, replacing what was stored there.
+	Put(key string, content io.Reader) error
+	// Open returns the content stored under key, or
+	// ErrAttachmentNotFound. The caller closes it.
+	Open(key string) (io.ReadCloser, error)
+	// Delete removes the content stored under key; deleting a key that
+	// holds nothing is not an error.
+	Delete(key string) error
+}
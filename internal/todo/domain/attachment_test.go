@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachmentLimits_Allows(t *testing.T) {
+	limits := AttachmentLimits{ContentTypes: []string{"application/pdf", "image/*"}}
+	assert.True(t, limits.Allows("application/pdf"))
+	assert.True(t, limits.Allows("image/png"))
+	assert.False(t, limits.Allows("image"))
+	assert.False(t, limits.Allows("imagex/png"))
+	assert.False(t, limits.Allows("text/html"))
+	assert.True(t, AttachmentLimits{}.Allows("text/html"), "no types allow every type")
+}
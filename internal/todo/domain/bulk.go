@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// BulkAction names an operation applied to many todos at once.
+type BulkAction string
+
+const (
+	BulkComplete   BulkAction = "complete"
+	BulkReopen     BulkAction = "reopen"
+	BulkDelete     BulkAction = "delete"
+	BulkRetag      BulkAction = "retag"
+	BulkReschedule BulkAction = "reschedule"
+	BulkMove       BulkAction = "move"
+)
+
+// MaxBulkItems caps how many todos a single bulk operation may touch.
+const MaxBulkItems = 1000
+
+// BulkChange is the change a bulk update makes to every selected todo.
+// Zero fields leave the todo alone. DueDate and Shift are exclusive; Shift
+// leaves todos without a due date alone.
+type BulkChange struct {
+	Done       *bool
+	AddTags    []string
+	RemoveTags []string
+	DueDate    *time.Time
+	Shift      time.Duration
+	// ProjectID moves the todos to the project with this ID, or to the
+	// inbox when it is empty.
+	ProjectID *string
+	// At is when the change is made, the completion time of todos it
+	// completes.
+	At time.Time
+}
+
+// Apply makes the change to the todo and reports whether anything changed.
+// Removed tags are dropped first and added ones appended after the tags
+// that are left, unless the todo already carries them.
+func (c BulkChange) Apply(t *Todo) bool {
+	changed := false
+	if c.Done != nil && t.Done != *c.Done {
+		t.SetDone(*c.Done, c.At)
+		changed = true
+	}
+	if len(c.AddTags) > 0 || len(c.RemoveTags) > 0 {
+		tags := make([]string, 0, len(t.Tags)+len(c.AddTags))
+		for _, tag := range t.Tags {
+			if !slices.Contains(c.RemoveTags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		for _, tag := range c.AddTags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		if !slices.Equal(tags, t.Tags) {
+			t.Tags = tags
+			changed = true
+		}
+	}
+	if c.DueDate != nil && !t.DueDate.Equal(c.due(t)) {
+		t.DueDate = c.due(t)
+		changed = true
+	}
+	if c.ProjectID != nil && t.ProjectID != *c.ProjectID {
+		t.ProjectID = *c.ProjectID
+		changed = true
+	}
+	if c.Shift != 0 && !t.DueDate.IsZero() {
+		t.DueDate = t.DueDate.Add(c.Shift)
+		if t.AllDay {
+			t.DueDate = AllDayDate(t.DueDate)
+		}
+		changed = true
+	}
+	return changed
+}
+
+// due returns the due date a reschedule gives the todo: all-day todos
+// keep being due on a date.
+func (c BulkChange) due(t *Todo) time.Time {
+	if t.AllDay {
+		return AllDayDate(*c.DueDate)
+	}
+	return *c.DueDate
+}
+
+// BulkStatus is the outcome of a bulk operation for one todo.
+type BulkStatus string
+
+const (
+	// BulkApplied means the todo was changed.
+	BulkApplied BulkStatus = "applied"
+	// BulkSkipped means the todo was already in the requested state.
+	BulkSkipped BulkStatus = "skipped"
+	// BulkFailed means the todo could not be changed, see Err.
+	BulkFailed BulkStatus = "failed"
+)
+
+// BulkResult reports what a bulk operation did to one todo.
+type BulkResult struct {
+	ID     string
+	Status BulkStatus
+	// Todo is the todo after the operation; nil when it failed.
+	Todo *Todo
+	Err  error
+}
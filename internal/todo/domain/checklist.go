@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"sort"
+	"strconv"
+)
+
+// ChecklistItem is one step of a todo that is really a small checklist.
+type ChecklistItem struct {
+	ID       string `json:"id" example:"4f1c2d3e-5a6b-4c7d-8e9f-0a1b2c3d4e5f" doc:"Unique identifier for the checklist item"`
+	Text     string `json:"text" example:"Milk" doc:"Text of the checklist item"`
+	Checked  bool   `json:"checked" example:"false" doc:"Whether the checklist item is done"`
+	Position int    `json:"position" example:"0" doc:"Zero-based position of the item in the checklist"`
+}
+
+// ChecklistProgress is derived from the checklist; it is never stored.
+type ChecklistProgress struct {
+	Checked int    `json:"checked" example:"3" doc:"Number of checked items"`
+	Total   int    `json:"total" example:"5" doc:"Number of items"`
+	Label   string `json:"label" example:"3/5" doc:"Progress as checked/total"`
+}
+
+// UpdateProgress recomputes Progress from the checklist, clearing it when
+// the todo has no checklist items.
+func (t *Todo) UpdateProgress() {
+	if len(t.Checklist) == 0 {
+		t.Progress = nil
+		return
+	}
+	p := &ChecklistProgress{Total: len(t.Checklist)}
+	for _, item := range t.Checklist {
+		if item.Checked {
+			p.Checked++
+		}
+	}
+	p.Label = strconv.Itoa(p.Checked) + "/" + strconv.Itoa(p.Total)
+	t.Progress = p
+}
+
+// ChecklistIndex returns the index of the item with the given ID, or -1.
+func (t *Todo) ChecklistIndex(itemID string) int {
+	for i, item := range t.Checklist {
+		if item.ID == itemID {
+			return i
+		}
+	}
+	return -1
+}
+
+// SortChecklist orders the checklist by position and renumbers positions
+// so they run 0..n-1 without gaps.
+func (t *Todo) SortChecklist() {
+	sort.SliceStable(t.Checklist, func(i, j int) bool { return t.Checklist[i].Position < t.Checklist[j].Position })
+	for i := range t.Checklist {
+		t.Checklist[i].Position = i
+	}
+}
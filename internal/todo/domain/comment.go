@@ -0,0 +1,37 @@
+package domain
+
+import "time"
+
+// MaxCommentLength is the most characters the body of a comment can have.
+const MaxCommentLength = 10000
+
+// Comment is a remark on a todo by a user who can see it. Comments are
+// threaded one level deep: a reply names the comment that started its
+// thread as ParentID.
+type Comment struct {
+	ID        string     `json:"id" readOnly:"true" example:"5f0c3a8e-6b1d-4e2a-9c7f-1d2e3f4a5b6c" doc:"Unique identifier for the comment"`
+	TodoID    string     `json:"todoId" readOnly:"true" example:"123e4567-e89b-12d3-a456-426614174000" doc:"ID of the todo item the comment is on"`
+	ParentID  string     `json:"parentId,omitempty" readOnly:"true" example:"9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d" doc:"Comment that started the thread the comment replies to; empty for comments that start one"`
+	Author    string     `json:"author" readOnly:"true" example:"bob" doc:"User who wrote the comment"`
+	Body      string     `json:"body" example:"The store closes at 8" doc:"Text of the comment"`
+	CreatedAt time.Time  `json:"createdAt" readOnly:"true" doc:"When the comment was written"`
+	EditedAt  *time.Time `json:"editedAt,omitempty" readOnly:"true" doc:"When the comment was last edited; omitted if it never was"`
+}
+
+// CommentRepository stores the comments of todos.
+type CommentRepository interface {
+	// Save creates the comment, or replaces the stored one with the same
+	// ID.
+	Save(comment *Comment) error
+	// FindByID returns a comment on a todo.
+	FindByID(todoID, id string) (*Comment, error)
+	// FindByTodo lists the comments on a todo, oldest first.
+	FindByTodo(todoID string, page, limit int) (list []*Comment, total int64, err error)
+	// CountByTodos counts the comments on each of the todos; todos without
+	// comments are left out.
+	CountByTodos(todoIDs []string) (map[string]int, error)
+	// Delete removes a comment on a todo along with the replies to it.
+	Delete(todoID, id string) error
+	// DeleteByTodo removes every comment on a todo.
+	DeleteByTodo(todoID string) error
+}
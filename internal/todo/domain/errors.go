@@ -0,0 +1,53 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when no todo exists with the requested ID.
+	ErrNotFound = errors.New("todo not found")
+	// ErrChecklistItemNotFound is returned when a todo has no checklist item
+	// with the requested ID.
+	ErrChecklistItemNotFound = errors.New("checklist item not found")
+	// ErrNotRecurring is returned for series operations on a todo that has
+	// no recurrence.
+	ErrNotRecurring = errors.New("todo is not recurring")
+	// ErrSeriesEnded is returned when a recurring todo has no further
+	// occurrences to move to.
+	ErrSeriesEnded = errors.New("recurring series has no further occurrences")
+	// ErrConflict is returned when a todo cannot be stored because one with
+	// the same ID already exists.
+	ErrConflict = errors.New("todo already exists")
+	// ErrVersionConflict is returned when a write carries a version that no
+	// longer matches the stored todo, i.e. somebody else changed it first.
+	ErrVersionConflict = errors.New("todo version conflict")
+	// ErrForbidden is returned when a user a todo is shared with tries
+	// something the share does not allow, such as deleting it.
+	ErrForbidden = errors.New("not allowed on a todo shared with you")
+	// ErrShareNotFound is returned when a todo is not shared with the user.
+	ErrShareNotFound = errors.New("share not found")
+	// ErrCommentNotFound is returned when a todo has no comment with the
+	// requested ID.
+	ErrCommentNotFound = errors.New("comment not found")
+	// ErrNotCommentAuthor is returned when a user tries to edit or delete
+	// a comment somebody else wrote.
+	ErrNotCommentAuthor = errors.New("only the author can change a comment")
+	// ErrAttachmentNotFound is returned when a todo has no attachment with
+	// the requested ID.
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrAttachmentTooLarge is returned for files over the attachment size
+	// limit.
+	ErrAttachmentTooLarge = errors.New("attachment is too large")
+	// ErrAttachmentType is returned for files of a media type that cannot
+	// be attached.
+	ErrAttachmentType = errors.New("attachment type is not allowed")
+)
+
+// ValidationError reports a todo field that does not satisfy the domain rules.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// Event describes a change that TodoUseCase has written. Before is nil for
+// creates and After is nil for purges.
+type Event struct {
+	Action HistoryAction
+	Actor  string
+	At     time.Time
+	Before *Todo
+	After  *Todo
+}
+
+// Todo returns the state the event leaves behind, or the last known state
+// for purges.
+func (e Event) Todo() *Todo {
+	if e.After != nil {
+		return e.After
+	}
+	return e.Before
+}
+
+// EventHandler is notified of todo changes after they are written. Handlers
+// run synchronously, so slow work belongs in a goroutine of their own.
+type EventHandler interface {
+	HandleTodoEvent(e Event)
+}
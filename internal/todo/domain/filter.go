@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// Due filters select todos by due date.
+const (
+	DueOverdue  = "overdue"
+	DueToday    = "today"
+	DueTomorrow = "tomorrow"
+)
+
+// Inbox is the project filter that matches todos filed under no project.
+const Inbox = "inbox"
+
+// TodoFilter selects live todos for lists and bulk operations. Zero fields
+// do not filter.
+type TodoFilter struct {
+	// Title matches todos whose title contains it.
+	Title string
+	Done  *bool
+	// Tag matches todos carrying the tag.
+	Tag string
+	// Owner matches todos created by the user.
+	Owner string
+	// Assignee matches todos assigned to the user.
+	Assignee string
+	// Project matches todos filed under the project with this ID, or under
+	// none with Inbox.
+	Project string
+	// Due matches todos due DueToday or DueTomorrow, or open todos whose
+	// due date has passed with DueOverdue. Days are those of Now's
+	// location, the caller's time zone; an all-day todo is due on its date
+	// wherever the caller is.
+	Due string
+	// Now is the caller's current time, in their location; zero means the
+	// current time in UTC.
+	Now time.Time
+}
+
+// DueSpan is a range [From, To) of due dates. A zero From leaves it open
+// below, though todos without a due date are never in it.
+type DueSpan struct {
+	From, To time.Time
+}
+
+// Contains reports whether due is in the span.
+func (s DueSpan) Contains(due time.Time) bool {
+	return !due.IsZero() && !due.Before(s.From) && due.Before(s.To)
+}
+
+// DueSpans returns the due dates the Due filter matches, of timed todos
+// and of all-day todos, whose due dates are UTC midnights. ok is false
+// when the filter has no Due.
+func (f TodoFilter) DueSpans() (timed, allDay DueSpan, ok bool) {
+	now := f.Now
+	if now.IsZero() {
+		now = time.Now().UTC()
+	}
+	today, date := midnight(now), AllDayDate(now)
+	switch f.Due {
+	case DueToday:
+		return DueSpan{today, today.AddDate(0, 0, 1)}, DueSpan{date, date.AddDate(0, 0, 1)}, true
+	case DueTomorrow:
+		return DueSpan{today.AddDate(0, 0, 1), today.AddDate(0, 0, 2)}, DueSpan{date.AddDate(0, 0, 1), date.AddDate(0, 0, 2)}, true
+	case DueOverdue:
+		return DueSpan{To: now}, DueSpan{To: date}, true
+	}
+	return DueSpan{}, DueSpan{}, false
+}
+
+// Matches reports whether the todo passes the filter.
+func (f TodoFilter) Matches(t *Todo) bool {
+	if f.Title != "" && !strings.Contains(t.Title, f.Title) {
+		return false
+	}
+	if f.Done != nil && t.Done != *f.Done {
+		return false
+	}
+	if f.Tag != "" && !slices.Contains(t.Tags, f.Tag) {
+		return false
+	}
+	if f.Owner != "" && t.Owner != f.Owner {
+		return false
+	}
+	if f.Assignee != "" && t.Assignee != f.Assignee {
+		return false
+	}
+	if f.Project != "" && t.ProjectID != f.project() {
+		return false
+	}
+	if timed, allDay, ok := f.DueSpans(); ok {
+		span := timed
+		if t.AllDay {
+			span = allDay
+		}
+		if !span.Contains(t.DueDate) || f.Due == DueOverdue && t.Done {
+			return false
+		}
+	}
+	return true
+}
+
+// project returns the project ID todos must have to match Project.
+func (f TodoFilter) project() string {
+	if f.Project == Inbox {
+		return ""
+	}
+	return f.Project
+}
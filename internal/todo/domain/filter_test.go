@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoFilter_Due(t *testing.T) {
+	taipei := mustLoad(t, "Asia/Taipei")
+	la := mustLoad(t, "America/Los_Angeles")
+
+	friday := &Todo{Title: "Friday"}
+	require.NoError(t, friday.SetDue(time.Date(2025, 7, 4, 0, 0, 0, 0, taipei), true, "Asia/Taipei"))
+	assert.Equal(t, time.Date(2025, 7, 4, 0, 0, 0, 0, time.UTC), friday.DueDate)
+	// 09:00 in Taipei is Thursday 18:00 in Los Angeles
+	meeting := &Todo{Title: "Meeting"}
+	require.NoError(t, meeting.SetDue(time.Date(2025, 7, 4, 9, 0, 0, 0, taipei), false, "Asia/Taipei"))
+	undated := &Todo{Title: "Someday"}
+	done := &Todo{Title: "Done", DueDate: time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC), Done: true}
+	late := &Todo{Title: "Late", DueDate: time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)}
+	todos := []*Todo{friday, meeting, undated, done, late}
+
+	cases := []struct {
+		name string
+		due  string
+		now  time.Time
+		want []string
+	}{
+		{"today in Taipei", DueToday, time.Date(2025, 7, 4, 8, 0, 0, 0, taipei), []string{"Friday", "Meeting"}},
+		{"today in Los Angeles", DueToday, time.Date(2025, 7, 3, 12, 0, 0, 0, la), []string{"Meeting"}},
+		{"tomorrow in Los Angeles", DueTomorrow, time.Date(2025, 7, 3, 12, 0, 0, 0, la), []string{"Friday"}},
+		{"overdue in Taipei", DueOverdue, time.Date(2025, 7, 4, 10, 0, 0, 0, taipei), []string{"Meeting", "Late"}},
+		// the all-day todo is only overdue once Friday is over where the caller is
+		{"overdue in Los Angeles", DueOverdue, time.Date(2025, 7, 5, 1, 0, 0, 0, la), []string{"Friday", "Meeting", "Late"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter := TodoFilter{Due: tc.due, Now: tc.now}
+			var got []string
+			for _, todo := range todos {
+				if filter.Matches(todo) {
+					got = append(got, todo.Title)
+				}
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestTodo_SetDue(t *testing.T) {
+	todo := &Todo{}
+	require.NoError(t, todo.SetDue(time.Time{}, true, ""))
+	assert.False(t, todo.AllDay, "a todo without a due date is not all-day")
+
+	var verr *ValidationError
+	require.ErrorAs(t, todo.SetDue(time.Now(), false, "Mars/Olympus"), &verr)
+	assert.Equal(t, "timeZone", verr.Field)
+}
+
+func TestTodo_NextDueAllDay(t *testing.T) {
+	la := mustLoad(t, "America/Los_Angeles")
+	todo := &Todo{}
+	require.NoError(t, todo.SetDue(time.Date(2025, 7, 7, 0, 0, 0, 0, la), true, "America/Los_Angeles")) // Monday
+	rec, err := NewRecurrence("FREQ=WEEKLY;BYDAY=MO", "America/Los_Angeles", todo.DueDate)
+	require.NoError(t, err)
+	todo.Recurrence = rec
+
+	next, ok, err := todo.NextDue()
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC), next)
+}
+
+func TestTodo_NextDueInTodoZone(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+	todo := &Todo{}
+	// a Saturday 09:00 the day before DST ends
+	require.NoError(t, todo.SetDue(time.Date(2025, 11, 1, 9, 0, 0, 0, ny), false, "America/New_York"))
+	rec, err := NewRecurrence("FREQ=WEEKLY;BYDAY=SA", "", todo.DueDate)
+	require.NoError(t, err)
+	todo.Recurrence = rec
+
+	next, ok, err := todo.NextDue()
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2025, 11, 8, 9, 0, 0, 0, ny), next.In(ny))
+
+	// a zone of the rule's own wins
+	rec.TimeZone = "UTC"
+	next, _, err = todo.NextDue()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 11, 8, 13, 0, 0, 0, time.UTC), next.UTC())
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// HistoryAction names the kind of change a history entry records.
+type HistoryAction string
+
+const (
+	HistoryCreated  HistoryAction = "created"
+	HistoryUpdated  HistoryAction = "updated"
+	HistoryDeleted  HistoryAction = "deleted"
+	HistoryRestored HistoryAction = "restored"
+	HistoryPurged   HistoryAction = "purged"
+)
+
+// HistoryEntry records who changed a todo, when, and how.
+type HistoryEntry struct {
+	ID      string        `json:"id" example:"0b7f0b9e-8f0e-4a47-9d6e-1f1f7b3c2a10" doc:"Unique identifier for the history entry"`
+	TodoID  string        `json:"todoId" example:"123e4567-e89b-12d3-a456-426614174000" doc:"ID of the todo item that changed"`
+	Actor   string        `json:"actor" example:"alice" doc:"Username of the user who made the change"`
+	Action  HistoryAction `json:"action" enum:"created,updated,deleted,restored,purged" doc:"Kind of change"`
+	At      time.Time     `json:"at" example:"2023-10-10T10:00:00Z" doc:"When the change happened"`
+	Version int64         `json:"version" example:"2" doc:"Revision of the todo item after the change"`
+	Changes []FieldChange `json:"changes" doc:"Fields that changed, with their values before and after"`
+	// Owner is who the todo belongs to, so that the changes to the todos
+	// of one user can be looked up. It is not part of the API.
+	Owner string `json:"-"`
+}
+
+// FieldChange is the before/after value of a single todo field. Field uses
+// the JSON name of the field.
+type FieldChange struct {
+	Field  string `json:"field" example:"dueDate" doc:"JSON name of the changed field"`
+	Before any    `json:"before" doc:"Value before the change, null if unset"`
+	After  any    `json:"after" doc:"Value after the change, null if unset"`
+}
+
+// HistoryRepository stores history entries. Entries are append-only.
+type HistoryRepository interface {
+	Append(entry *HistoryEntry) error
+	// FindByTodoID lists a todo's history, most recent first.
+	FindByTodoID(todoID string, page, limit int) (list []*HistoryEntry, total int64, err error)
+	// FindSince lists up to limit entries of the todos of owner made at or
+	// after since, oldest first.
+	FindSince(owner string, since time.Time, limit int) ([]*HistoryEntry, error)
+}
+
+// untracked fields are bookkeeping or derived values that never show up
+// in a diff.
+var untracked = map[string]bool{"id": true, "version": true, "progress": true, "completedAt": true, "mentions": true, "commentCount": true}
+
+// Diff compares two snapshots of a todo field by field. A nil snapshot
+// stands for "did not exist", so creates and purges list every field.
+func Diff(before, after *Todo) []FieldChange {
+	changes := []FieldChange{}
+	typ := reflect.TypeOf(Todo{})
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || untracked[name] {
+			continue
+		}
+		var b, a any
+		if before != nil {
+			b = fieldValue(reflect.ValueOf(before).Elem().Field(i))
+		}
+		if after != nil {
+			a = fieldValue(reflect.ValueOf(after).Elem().Field(i))
+		}
+		if sameValue(b, a) {
+			continue
+		}
+		changes = append(changes, FieldChange{Field: name, Before: b, After: a})
+	}
+	return changes
+}
+
+// fieldValue unwraps pointers and normalises empty values to nil so that
+// "unset" compares equal however it is represented.
+func fieldValue(v reflect.Value) any {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0 {
+		return nil
+	}
+	if t, ok := v.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return nil
+		}
+		return t.UTC()
+	}
+	return v.Interface()
+}
+
+func sameValue(a, b any) bool {
+	ta, okA := a.(time.Time)
+	tb, okB := b.(time.Time)
+	if okA && okB {
+		return ta.Equal(tb)
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// mentionPattern matches @username where the @ does not follow a word
+// character, so e-mail addresses are not mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@(\w[\w.-]*)`)
+
+// ParseMentions returns the usernames mentioned in text as @username, in
+// order of first mention. Punctuation ending a sentence is not part of the
+// name.
+func ParseMentions(text string) []string {
+	var names []string
+	for _, m := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		name := strings.TrimRight(m[1], ".-")
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
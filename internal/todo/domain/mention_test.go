@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMentions(t *testing.T) {
+	cases := []struct {
+		text string
+		want []string
+	}{
+		{"Review the budget", nil},
+		{"@bob review the budget", []string{"bob"}},
+		{"Ask @carol and @bob, then @carol again.", []string{"carol", "bob"}},
+		{"Send it to alice@example.com", nil},
+		{"Ping (@dev.team) about @jane.doe.", []string{"dev.team", "jane.doe"}},
+		{"Just an @ sign", nil},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, ParseMentions(c.text), c.text)
+	}
+}
@@ -0,0 +1,563 @@
+package domain
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of the phrases ParseQuickAdd understands.
+const (
+	PhraseDue        = "due"
+	PhraseTime       = "time"
+	PhraseRecurrence = "recurrence"
+	PhraseTag        = "tag"
+	PhrasePriority   = "priority"
+)
+
+// QuickAdd is what ParseQuickAdd made of a line typed to add a todo.
+type QuickAdd struct {
+	Title string
+	// DueDate is in the location of the time the line was parsed at; it is
+	// zero when the line names no date, time or recurrence.
+	DueDate time.Time
+	// AllDay is set when the line names a day but no time of it.
+	AllDay bool
+	// Rule is an RRULE, empty unless the line says how the todo repeats.
+	Rule     string
+	Tags     []string
+	Priority string
+	// Phrases are the parts of the line that were understood, in order.
+	Phrases []QuickAddPhrase
+}
+
+// QuickAddPhrase is a part of a quick-add line and what it was read as.
+type QuickAddPhrase struct {
+	Text string
+	Kind string
+}
+
+// ParseQuickAdd reads a line such as "pay rent every month on the 1st
+// #bills !high tomorrow 9am" into the fields of a todo. Dates and times
+// are relative to now and in its location. It understands:
+//
+//   - #tags, and priorities as !high, !medium, !low, !1 to !3 or !A to !Z
+//   - dates: today, tonight, tomorrow, weekdays ("friday", "next friday"),
+//     "next week", "in 3 days", "in 2 hours", "the 15th", "july 4",
+//     "4 july 2026" and 2026-07-04
+//   - times: 9am, 9:30 pm, 21:00, noon and "at 9"
+//   - recurrence: daily, weekly, monthly, yearly, "every day", "every other
+//     week", "every 3 months", "every monday and thursday", "every weekday",
+//     "every 1st" and "every month on the 15th"
+//
+// The words of other phrases make up the title. A phrase that names a date,
+// time or recurrence when one was already found is left in the title.
+func ParseQuickAdd(text string, now time.Time) QuickAdd {
+	p := &quickParser{words: strings.Fields(text), now: now, today: midnight(now)}
+	var title []string
+	for i := 0; i < len(p.words); {
+		n, kind := 0, ""
+		// a preposition is part of the date or time it introduces
+		if prep := p.word(i); slices.Contains(prepositions, prep) {
+			if n, kind = p.match(i+1, prep); n > 0 {
+				n++
+			}
+		}
+		if n == 0 {
+			n, kind = p.match(i, "")
+		}
+		if n == 0 {
+			title = append(title, p.words[i])
+			i++
+			continue
+		}
+		p.out.Phrases = append(p.out.Phrases, QuickAddPhrase{Text: strings.Join(p.words[i:i+n], " "), Kind: kind})
+		i += n
+	}
+	p.out.Title = strings.Join(title, " ")
+	p.resolve()
+	return p.out
+}
+
+var prepositions = []string{"on", "at", "by", "due"}
+
+type quickParser struct {
+	words []string
+	now   time.Time
+	today time.Time
+	out   QuickAdd
+
+	// date is the day due, at midnight; zero when none was named
+	date time.Time
+	// clock is the time of day due, in minutes, if clockOK
+	clock   int
+	clockOK bool
+	// exact is set by phrases naming both, such as "in 2 hours"
+	exact time.Time
+
+	freq       Frequency
+	interval   int
+	byDay      []time.Weekday
+	byMonthDay int
+}
+
+// word returns the i-th word, lower-cased and without trailing punctuation,
+// or "" past the end.
+func (p *quickParser) word(i int) string {
+	if i < 0 || i >= len(p.words) {
+		return ""
+	}
+	return strings.ToLower(strings.TrimRight(p.words[i], ",.;"))
+}
+
+// match tries the phrases at word i and returns how many words the one
+// that matched takes, and its kind. prep is the preposition before it.
+func (p *quickParser) match(i int, prep string) (int, string) {
+	w := p.word(i)
+	if w == "" {
+		return 0, ""
+	}
+	if prep == "" {
+		if tag := strings.TrimRight(w, "!?)"); len(tag) > 1 && tag[0] == '#' && strings.Trim(tag[1:], "0123456789") != "" {
+			original := strings.TrimRight(p.words[i], ",.;!?)")
+			p.out.Tags = append(p.out.Tags, original[1:])
+			return 1, PhraseTag
+		}
+		if priority, ok := quickPriority(w); ok {
+			if p.out.Priority != "" {
+				return 0, ""
+			}
+			p.out.Priority = priority
+			return 1, PhrasePriority
+		}
+		if n := p.matchRecurrence(i); n > 0 {
+			return n, PhraseRecurrence
+		}
+	}
+	if n := p.matchTime(i, prep == "at"); n > 0 {
+		return n, PhraseTime
+	}
+	if n := p.matchDate(i, prep != ""); n > 0 {
+		return n, PhraseDue
+	}
+	return 0, ""
+}
+
+func quickPriority(w string) (string, bool) {
+	switch w {
+	case "!high", "!urgent", "!1", "!!!":
+		return "A", true
+	case "!medium", "!med", "!2", "!!":
+		return "B", true
+	case "!low", "!3":
+		return "C", true
+	}
+	if len(w) == 2 && w[0] == '!' && w[1] >= 'a' && w[1] <= 'z' {
+		return strings.ToUpper(w[1:]), true
+	}
+	return "", false
+}
+
+var (
+	clockAmPm = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm|a\.m\.|p\.m\.)?$`)
+	clock24   = regexp.MustCompile(`^(\d{1,2}):(\d{2})$`)
+	isoDate   = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	ordinal   = regexp.MustCompile(`^(\d{1,2})(st|nd|rd|th)?$`)
+)
+
+// matchTime matches 9am, 9:30 pm, 21:00 and noon; a bare hour only after
+// "at".
+func (p *quickParser) matchTime(i int, afterAt bool) int {
+	if p.clockOK || !p.exact.IsZero() {
+		return 0
+	}
+	w := p.word(i)
+	if w == "noon" {
+		p.setClock(12, 0)
+		return 1
+	}
+	if m := clock24.FindStringSubmatch(w); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		if hour > 23 || minute > 59 {
+			return 0
+		}
+		// "9:30 pm" is a twelve-hour time after all
+		if next := p.word(i + 1); hour >= 1 && hour <= 12 && slices.Contains([]string{"am", "pm", "a.m.", "p.m."}, next) {
+			p.setClock(twelveHour(hour, next), minute)
+			return 2
+		}
+		p.setClock(hour, minute)
+		return 1
+	}
+	m := clockAmPm.FindStringSubmatch(w)
+	if m == nil {
+		return 0
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	suffix, n := m[3], 1
+	if suffix == "" {
+		if next := p.word(i + 1); slices.Contains([]string{"am", "pm", "a.m.", "p.m."}, next) {
+			suffix, n = next, 2
+		}
+	}
+	switch {
+	case minute > 59:
+		return 0
+	case suffix != "" && hour >= 1 && hour <= 12:
+		p.setClock(twelveHour(hour, suffix), minute)
+	case suffix == "" && afterAt && hour <= 23:
+		p.setClock(hour, minute)
+	default:
+		return 0
+	}
+	return n
+}
+
+func twelveHour(hour int, suffix string) int {
+	hour %= 12
+	if strings.HasPrefix(suffix, "p") {
+		hour += 12
+	}
+	return hour
+}
+
+func (p *quickParser) setClock(hour, minute int) {
+	p.clock, p.clockOK = hour*60+minute, true
+}
+
+var quickWeekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var quickMonths = map[string]time.Month{
+	"january": time.January, "jan": time.January, "february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March, "april": time.April, "apr": time.April, "may": time.May,
+	"june": time.June, "jun": time.June, "july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August, "september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October, "november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+var quickUnits = map[string]string{
+	"minute": "minute", "minutes": "minute", "min": "minute", "mins": "minute",
+	"hour": "hour", "hours": "hour", "hr": "hour", "hrs": "hour",
+	"day": "day", "days": "day", "week": "week", "weeks": "week",
+	"month": "month", "months": "month", "year": "year", "years": "year",
+}
+
+// matchDate matches the dates ParseQuickAdd lists. An ordinal such as "1st"
+// only counts after a preposition or "the".
+func (p *quickParser) matchDate(i int, afterPrep bool) int {
+	if !p.date.IsZero() || !p.exact.IsZero() {
+		return 0
+	}
+	w := p.word(i)
+	switch w {
+	case "today":
+		p.date = p.today
+		return 1
+	case "tonight":
+		p.date = p.today
+		if !p.clockOK {
+			p.setClock(20, 0)
+		}
+		return 1
+	case "tomorrow", "tmr", "tmrw":
+		p.date = p.today.AddDate(0, 0, 1)
+		return 1
+	case "next", "this":
+		next := p.word(i + 1)
+		if wd, ok := quickWeekdays[next]; ok {
+			p.date = p.coming(wd)
+			if w == "next" {
+				p.date = p.date.AddDate(0, 0, 7)
+			}
+			return 2
+		}
+		if w == "next" && next == "week" {
+			p.date = p.coming(time.Monday)
+			if p.date.Equal(p.today) {
+				p.date = p.date.AddDate(0, 0, 7)
+			}
+			return 2
+		}
+		return 0
+	case "in":
+		return p.matchIn(i + 1)
+	case "the":
+		if day, ok := p.ordinalDay(i + 1); ok && strings.ContainsAny(p.word(i+1), "snrt") {
+			p.date = p.nextMonthDay(day)
+			return 2
+		}
+		return 0
+	}
+	if wd, ok := quickWeekdays[w]; ok {
+		p.date = p.coming(wd)
+		return 1
+	}
+	if isoDate.MatchString(w) {
+		date, err := time.ParseInLocation("2006-01-02", w, p.now.Location())
+		if err != nil {
+			return 0
+		}
+		p.date = date
+		return 1
+	}
+	if month, ok := quickMonths[w]; ok {
+		day, ok := p.ordinalDay(i + 1)
+		if !ok {
+			return 0
+		}
+		return 2 + p.monthDate(month, day, i+2)
+	}
+	if day, ok := p.ordinalDay(i); ok {
+		j := i + 1
+		if p.word(j) == "of" {
+			j++
+		}
+		if month, ok := quickMonths[p.word(j)]; ok {
+			return j - i + 1 + p.monthDate(month, day, j+1)
+		}
+		if afterPrep && strings.ContainsAny(w, "snrt") {
+			p.date = p.nextMonthDay(day)
+			return 1
+		}
+	}
+	return 0
+}
+
+// matchIn matches what follows "in": "3 days", "a week", "2 hours".
+func (p *quickParser) matchIn(i int) int {
+	count, n := 1, 1
+	switch w := p.word(i); w {
+	case "a", "an":
+	default:
+		c, err := strconv.Atoi(w)
+		if err != nil || c <= 0 || c > 1000 {
+			return 0
+		}
+		count = c
+	}
+	switch quickUnits[p.word(i+n)] {
+	case "minute":
+		p.exact = p.now.Add(time.Duration(count) * time.Minute)
+	case "hour":
+		p.exact = p.now.Add(time.Duration(count) * time.Hour)
+	case "day":
+		p.date = p.today.AddDate(0, 0, count)
+	case "week":
+		p.date = p.today.AddDate(0, 0, 7*count)
+	case "month":
+		p.date = p.today.AddDate(0, count, 0)
+	case "year":
+		p.date = p.today.AddDate(count, 0, 0)
+	default:
+		return 0
+	}
+	if !p.exact.IsZero() && p.clockOK {
+		// a time of day was already named
+		p.exact = time.Time{}
+		return 0
+	}
+	return 1 + n + 1
+}
+
+// ordinalDay reads a day of the month such as 1, 1st or 22nd at word i.
+func (p *quickParser) ordinalDay(i int) (int, bool) {
+	m := ordinal.FindStringSubmatch(p.word(i))
+	if m == nil {
+		return 0, false
+	}
+	day, _ := strconv.Atoi(m[1])
+	return day, day >= 1 && day <= 31
+}
+
+// monthDate sets the date to day of month, in the year at word i if there
+// is one, else in the next year that day has not passed yet. It returns
+// how many words the year took.
+func (p *quickParser) monthDate(month time.Month, day, i int) int {
+	if year, err := strconv.Atoi(p.word(i)); err == nil && year >= 1970 && year <= 9999 {
+		p.date = time.Date(year, month, day, 0, 0, 0, 0, p.now.Location())
+		return 1
+	}
+	date := time.Date(p.today.Year(), month, day, 0, 0, 0, 0, p.now.Location())
+	if date.Before(p.today) {
+		date = date.AddDate(1, 0, 0)
+	}
+	p.date = date
+	return 0
+}
+
+// coming returns the next day that is wd, today included.
+func (p *quickParser) coming(wd time.Weekday) time.Time {
+	return p.today.AddDate(0, 0, (int(wd)-int(p.today.Weekday())+7)%7)
+}
+
+// nextMonthDay returns the next day that is the given day of a month,
+// today included, skipping months too short to have it.
+func (p *quickParser) nextMonthDay(day int) time.Time {
+	for months := 0; ; months++ {
+		date := time.Date(p.today.Year(), p.today.Month()+time.Month(months), day, 0, 0, 0, 0, p.now.Location())
+		if date.Day() == day && !date.Before(p.today) {
+			return date
+		}
+	}
+}
+
+// matchRecurrence matches daily, weekly, monthly, yearly and "every ..."
+// phrases, with an "on ..." that pins the day.
+func (p *quickParser) matchRecurrence(i int) int {
+	if p.freq != "" {
+		return 0
+	}
+	w := p.word(i)
+	freqs := map[string]Frequency{"daily": Daily, "weekly": Weekly, "monthly": Monthly, "yearly": Yearly, "annually": Yearly}
+	if freq, ok := freqs[w]; ok {
+		p.freq, p.interval = freq, 1
+		return 1 + p.matchRecurrenceDay(i+1)
+	}
+	if w != "every" {
+		return 0
+	}
+	j, interval := i+1, 1
+	if p.word(j) == "other" {
+		j, interval = j+1, 2
+	} else if n, err := strconv.Atoi(p.word(j)); err == nil && n > 1 && n <= 1000 {
+		j, interval = j+1, n
+	}
+	unitFreqs := map[string]Frequency{"day": Daily, "week": Weekly, "month": Monthly, "year": Yearly}
+	switch next := p.word(j); {
+	case unitFreqs[quickUnits[next]] != "":
+		p.freq, p.interval = unitFreqs[quickUnits[next]], interval
+		j++
+	case next == "weekday" || next == "weekdays":
+		p.freq, p.interval = Weekly, interval
+		p.byDay = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+		return j + 1 - i
+	case next == "weekend" || next == "weekends":
+		p.freq, p.interval = Weekly, interval
+		p.byDay = []time.Weekday{time.Saturday, time.Sunday}
+		return j + 1 - i
+	case quickWeekdays[next] != 0 || next == "sunday" || next == "sun":
+		p.freq, p.interval = Weekly, interval
+		return j + p.matchWeekdays(j) - i
+	default:
+		if day, ok := p.ordinalDay(j); ok && interval == 1 && next != strconv.Itoa(day) {
+			p.freq, p.interval, p.byMonthDay = Monthly, 1, day
+			return j + 1 - i
+		}
+		return 0
+	}
+	return j + p.matchRecurrenceDay(j) - i
+}
+
+// matchRecurrenceDay matches "on the 15th" for monthly and "on monday"
+// for weekly rules.
+func (p *quickParser) matchRecurrenceDay(i int) int {
+	if p.word(i) != "on" {
+		return 0
+	}
+	switch p.freq {
+	case Monthly:
+		j := i + 1
+		if p.word(j) == "the" {
+			j++
+		}
+		if day, ok := p.ordinalDay(j); ok {
+			p.byMonthDay = day
+			return j + 1 - i
+		}
+	case Weekly:
+		if n := p.matchWeekdays(i + 1); n > 0 {
+			return n + 1
+		}
+	}
+	return 0
+}
+
+// matchWeekdays reads a list of weekdays such as "mon, wed and fri" into
+// byDay and returns how many words it took.
+func (p *quickParser) matchWeekdays(i int) int {
+	j := i
+	for {
+		wd, ok := quickWeekdays[p.word(j)]
+		if !ok {
+			break
+		}
+		if !slices.Contains(p.byDay, wd) {
+			p.byDay = append(p.byDay, wd)
+		}
+		j++
+		if p.word(j) == "and" {
+			if _, ok := quickWeekdays[p.word(j+1)]; ok {
+				j++
+			}
+		}
+	}
+	return j - i
+}
+
+// resolve works out the due date and rule from the phrases found.
+func (p *quickParser) resolve() {
+	if p.freq != "" {
+		rule := "FREQ=" + string(p.freq)
+		if p.interval > 1 {
+			rule += ";INTERVAL=" + strconv.Itoa(p.interval)
+		}
+		if len(p.byDay) > 0 {
+			days := make([]string, 0, len(p.byDay))
+			for _, wd := range p.byDay {
+				days = append(days, strings.ToUpper(wd.String()[:2]))
+			}
+			rule += ";BYDAY=" + strings.Join(days, ",")
+		}
+		if p.byMonthDay > 0 {
+			rule += ";BYMONTHDAY=" + strconv.Itoa(p.byMonthDay)
+		}
+		p.out.Rule = rule
+	}
+	p.out.AllDay = p.exact.IsZero() && !p.clockOK && (!p.date.IsZero() || p.freq != "")
+	switch {
+	case !p.exact.IsZero():
+		p.out.DueDate = p.exact
+	case !p.date.IsZero():
+		p.out.DueDate = p.at(p.date)
+	case p.freq != "" || p.clockOK:
+		// the first day the rule allows whose time has not passed yet
+		for day := p.today; ; day = day.AddDate(0, 0, 1) {
+			if p.allows(day) && (!p.clockOK || p.at(day).After(p.now)) {
+				p.out.DueDate = p.at(day)
+				return
+			}
+		}
+	}
+}
+
+// at returns the time named on the given day, or its start.
+func (p *quickParser) at(day time.Time) time.Time {
+	if !p.clockOK {
+		return day
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), p.clock/60, p.clock%60, 0, 0, day.Location())
+}
+
+func (p *quickParser) allows(day time.Time) bool {
+	if len(p.byDay) > 0 && !slices.Contains(p.byDay, day.Weekday()) {
+		return false
+	}
+	return p.byMonthDay == 0 || day.Day() == p.byMonthDay
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	taipei := mustLoad(t, "Asia/Taipei")
+	now := time.Date(2025, 7, 2, 14, 30, 0, 0, taipei) // Wednesday
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2025, month, day, hour, minute, 0, 0, taipei)
+	}
+	cases := []struct {
+		text     string
+		title    string
+		due      time.Time
+		rule     string
+		tags     []string
+		priority string
+	}{
+		{
+			text: "pay rent every month on the 1st #bills !high 9am", title: "pay rent",
+			due: at(8, 1, 9, 0), rule: "FREQ=MONTHLY;BYMONTHDAY=1", tags: []string{"bills"}, priority: "A",
+		},
+		{text: "pay rent monthly #bills tomorrow 9am", title: "pay rent", due: at(7, 3, 9, 0), rule: "FREQ=MONTHLY", tags: []string{"bills"}},
+		{text: "call mom at 5pm", title: "call mom", due: at(7, 2, 17, 0)},
+		{text: "call mom at 17", title: "call mom", due: at(7, 2, 17, 0)},
+		{text: "standup 9:15 am", title: "standup", due: at(7, 3, 9, 15)},
+		{text: "report by friday", title: "report", due: at(7, 4, 0, 0)},
+		{text: "review next fri at 10:30", title: "review", due: at(7, 11, 10, 30)},
+		{text: "plan next week", title: "plan", due: at(7, 7, 0, 0)},
+		{text: "gym every monday and thursday 7am", title: "gym", due: at(7, 3, 7, 0), rule: "FREQ=WEEKLY;BYDAY=MO,TH"},
+		{text: "water plants every other day", title: "water plants", due: at(7, 2, 0, 0), rule: "FREQ=DAILY;INTERVAL=2"},
+		{text: "timesheet every weekday at 6pm", title: "timesheet", due: at(7, 2, 18, 0), rule: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+		{text: "invoice every 15th", title: "invoice", due: at(7, 15, 0, 0), rule: "FREQ=MONTHLY;BYMONTHDAY=15"},
+		{text: "party on the 1st", title: "party", due: at(8, 1, 0, 0)},
+		{text: "renew passport on july 4 2026", title: "renew passport", due: time.Date(2026, 7, 4, 0, 0, 0, 0, taipei)},
+		{text: "send card 4th of march", title: "send card", due: time.Date(2026, 3, 4, 0, 0, 0, 0, taipei)},
+		{text: "book flights in 3 days", title: "book flights", due: at(7, 5, 0, 0)},
+		{text: "check oven in 2 hours", title: "check oven", due: at(7, 2, 16, 30)},
+		{text: "sync due 2025-08-10 21:00", title: "sync", due: at(8, 10, 21, 0)},
+		{text: "movie tonight", title: "movie", due: at(7, 2, 20, 0)},
+		{text: "fix the 2 bugs", title: "fix the 2 bugs"},
+		{text: "close issue #123 today", title: "close issue #123", due: at(7, 2, 0, 0)},
+		{text: "dentist tomorrow friday", title: "dentist friday", due: at(7, 3, 0, 0)},
+		{text: "every so often !low", title: "every so often", priority: "C"},
+		{text: "taxes !b #Home, #money", title: "taxes", priority: "B", tags: []string{"Home", "money"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.text, func(t *testing.T) {
+			got := ParseQuickAdd(tc.text, now)
+			assert.Equal(t, tc.title, got.Title)
+			assert.True(t, tc.due.Equal(got.DueDate), "due %v, got %v", tc.due, got.DueDate)
+			assert.Equal(t, tc.rule, got.Rule)
+			assert.Equal(t, tc.tags, got.Tags)
+			assert.Equal(t, tc.priority, got.Priority)
+		})
+	}
+}
+
+func TestParseQuickAdd_Phrases(t *testing.T) {
+	now := time.Date(2025, 7, 2, 14, 30, 0, 0, time.UTC)
+	got := ParseQuickAdd("pay rent every month on the 1st #bills !high tomorrow at 9am", now)
+	assert.Equal(t, []QuickAddPhrase{
+		{Text: "every month on the 1st", Kind: PhraseRecurrence},
+		{Text: "#bills", Kind: PhraseTag},
+		{Text: "!high", Kind: PhrasePriority},
+		{Text: "tomorrow", Kind: PhraseDue},
+		{Text: "at 9am", Kind: PhraseTime},
+	}, got.Phrases)
+	assert.Equal(t, time.Date(2025, 7, 3, 9, 0, 0, 0, time.UTC), got.DueDate)
+	assert.False(t, got.AllDay)
+
+	assert.True(t, ParseQuickAdd("report by friday", now).AllDay)
+	assert.True(t, ParseQuickAdd("water plants every other day", now).AllDay)
+	assert.False(t, ParseQuickAdd("check oven in 2 hours", now).AllDay)
+	assert.False(t, ParseQuickAdd("report", now).AllDay)
+}
@@ -0,0 +1,350 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence makes a todo repeat. When an occurrence is completed the next
+// one is generated with its due date computed from Rule, evaluated as wall
+// clock time in TimeZone so that "every Monday 09:00" stays 09:00 across DST.
+// Without a TimeZone of its own, the rule of a todo is evaluated in the
+// zone the todo's due date was set in.
+type Recurrence struct {
+	Rule     string    `json:"rule" example:"FREQ=WEEKLY;INTERVAL=1;BYDAY=MO,TH" doc:"RFC 5545 RRULE; supports FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, BYDAY, BYMONTHDAY, COUNT and UNTIL"`
+	TimeZone string    `json:"timeZone,omitempty" example:"Asia/Taipei" doc:"IANA time zone the rule is evaluated in; defaults to the time zone of the due date, or UTC"`
+	Start    time.Time `json:"start" readOnly:"true" example:"2023-10-09T09:00:00+08:00" doc:"Due date of the first occurrence of the series (DTSTART)"`
+	Index    int       `json:"index" readOnly:"true" example:"1" doc:"1-based number of this occurrence in the series"`
+	NextID   string    `json:"nextId,omitempty" readOnly:"true" doc:"ID of the todo item generated as the next occurrence"`
+}
+
+// Frequency is the FREQ part of a recurrence rule.
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// WeekdayNum is a BYDAY entry: a weekday, optionally with an ordinal such
+// as 1MO (first Monday) or -1FR (last Friday) in monthly and yearly rules.
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// RRule is a parsed recurrence rule.
+type RRule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Count      int
+	Until      time.Time
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// ParseRRule parses the RRULE value of RFC 5545, with or without the
+// "RRULE:" prefix. A floating or date-only UNTIL is read in loc.
+func ParseRRule(rule string, loc *time.Location) (*RRule, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	r := &RRule{Interval: 1}
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, invalidRule("expected KEY=VALUE, got " + part)
+		}
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			switch f := Frequency(strings.ToUpper(value)); f {
+			case Daily, Weekly, Monthly, Yearly:
+				r.Freq = f
+			default:
+				return nil, invalidRule("unsupported FREQ " + value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, invalidRule("INTERVAL must be a positive integer")
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, invalidRule("COUNT must be a positive integer")
+			}
+			r.Count = n
+		case "UNTIL":
+			until, err := parseUntil(value, loc)
+			if err != nil {
+				return nil, invalidRule("UNTIL must look like 20060102 or 20060102T150405Z")
+			}
+			r.Until = until
+		case "BYDAY":
+			for _, day := range strings.Split(strings.ToUpper(value), ",") {
+				if len(day) < 2 {
+					return nil, invalidRule("invalid BYDAY " + day)
+				}
+				wd, ok := weekdays[day[len(day)-2:]]
+				if !ok {
+					return nil, invalidRule("invalid BYDAY " + day)
+				}
+				n := 0
+				if prefix := day[:len(day)-2]; prefix != "" {
+					var err error
+					n, err = strconv.Atoi(prefix)
+					if err != nil || n == 0 || n < -5 || n > 5 {
+						return nil, invalidRule("invalid BYDAY " + day)
+					}
+				}
+				r.ByDay = append(r.ByDay, WeekdayNum{Weekday: wd, N: n})
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, invalidRule("invalid BYMONTHDAY " + v)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		default:
+			return nil, invalidRule("unsupported rule part " + key)
+		}
+	}
+	if r.Freq == "" {
+		return nil, invalidRule("FREQ is required")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, invalidRule("COUNT and UNTIL cannot be combined")
+	}
+	for _, d := range r.ByDay {
+		if d.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return nil, invalidRule("BYDAY ordinals are only allowed with MONTHLY or YEARLY")
+		}
+	}
+	return r, nil
+}
+
+func invalidRule(msg string) error {
+	return &ValidationError{Field: "recurrence.rule", Message: msg}
+}
+
+func parseUntil(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", value, loc); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("20060102", value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// a date-only UNTIL includes the whole day
+	return t.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
+
+// maxSearchDays bounds the search for the next occurrence so that rules
+// that can never match again (e.g. BYMONTHDAY=31 with FREQ=YEARLY on
+// a February start) terminate.
+const maxSearchDays = 366 * 30
+
+// Next returns the first occurrence strictly after the given one, for
+// a series that started at start. index is the 1-based number of the
+// given occurrence and is checked against COUNT. All calendar arithmetic
+// happens in loc, and the wall clock time of start is kept for every
+// occurrence. ok is false when the series has ended.
+func (r *RRule) Next(start, after time.Time, index int, loc *time.Location) (next time.Time, ok bool) {
+	if r.Count > 0 && index >= r.Count {
+		return time.Time{}, false
+	}
+	start = start.In(loc)
+	after = after.In(loc)
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	day := time.Date(after.Year(), after.Month(), after.Day(), 0, 0, 0, 0, time.UTC)
+	if day.Before(first) {
+		day = first
+	}
+	for i := 0; i < maxSearchDays; i, day = i+1, day.AddDate(0, 0, 1) {
+		if !r.matches(first, day) {
+			continue
+		}
+		candidate := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(), 0, loc)
+		if !candidate.After(after) {
+			continue
+		}
+		if !r.Until.IsZero() && candidate.After(r.Until) {
+			return time.Time{}, false
+		}
+		return candidate, true
+	}
+	return time.Time{}, false
+}
+
+// matches reports whether the calendar day (as a UTC midnight) is an
+// occurrence day of a series whose first day is first.
+func (r *RRule) matches(first, day time.Time) bool {
+	switch r.Freq {
+	case Daily:
+		return daysBetween(first, day)%r.Interval == 0 && r.matchesByDay(day) && r.matchesByMonthDay(day)
+	case Weekly:
+		weeks := daysBetween(weekStart(first), weekStart(day)) / 7
+		if weeks%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 {
+			return day.Weekday() == first.Weekday()
+		}
+		return r.matchesByDay(day)
+	case Monthly:
+		months := (day.Year()-first.Year())*12 + int(day.Month()) - int(first.Month())
+		if months%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			return day.Day() == first.Day()
+		}
+		return r.matchesByDay(day) && r.matchesByMonthDay(day)
+	case Yearly:
+		if (day.Year()-first.Year())%r.Interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			return day.Month() == first.Month() && day.Day() == first.Day()
+		}
+		return day.Month() == first.Month() && r.matchesByDay(day) && r.matchesByMonthDay(day)
+	}
+	return false
+}
+
+func (r *RRule) matchesByDay(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, d := range r.ByDay {
+		if d.Weekday != day.Weekday() {
+			continue
+		}
+		if d.N == 0 {
+			return true
+		}
+		// ordinal within the month, counted from the start or the end
+		if d.N > 0 && (day.Day()-1)/7+1 == d.N {
+			return true
+		}
+		if d.N < 0 && (daysIn(day)-day.Day())/7+1 == -d.N {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RRule) matchesByMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	for _, n := range r.ByMonthDay {
+		if n > 0 && day.Day() == n {
+			return true
+		}
+		if n < 0 && day.Day() == daysIn(day)+n+1 {
+			return true
+		}
+	}
+	return false
+}
+
+func daysBetween(a, b time.Time) int {
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// weekStart returns the Monday of the day's week (RFC 5545's default WKST).
+func weekStart(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func daysIn(day time.Time) int {
+	return time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// NewRecurrence validates rule and timeZone and starts a series whose
+// first occurrence is due at start.
+func NewRecurrence(rule, timeZone string, start time.Time) (*Recurrence, error) {
+	if start.IsZero() {
+		return nil, &ValidationError{Field: "dueDate", Message: "is required for a recurring todo"}
+	}
+	loc, err := LoadLocation(timeZone)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ParseRRule(rule, loc); err != nil {
+		return nil, err
+	}
+	return &Recurrence{
+		Rule:     strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:"),
+		TimeZone: timeZone,
+		Start:    start,
+		Index:    1,
+	}, nil
+}
+
+// NextDue returns the due date of the occurrence after the one due at
+// after. ok is false when the series has no further occurrences.
+func (rec *Recurrence) NextDue(after time.Time) (next time.Time, ok bool, err error) {
+	loc, err := LoadLocation(rec.TimeZone)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return rec.nextDue(after, loc)
+}
+
+// NextDue returns the due date of the todo's next occurrence, as
+// Recurrence.NextDue does, in the todo's time zone when the rule has none.
+// The rule of an all-day todo is evaluated in UTC, where its due dates are
+// midnight, so that they stay on their dates.
+func (t *Todo) NextDue() (next time.Time, ok bool, err error) {
+	if t.AllDay {
+		return t.Recurrence.nextDue(t.DueDate, time.UTC)
+	}
+	zone := t.Recurrence.TimeZone
+	if zone == "" {
+		zone = t.TimeZone
+	}
+	loc, err := LoadLocation(zone)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return t.Recurrence.nextDue(t.DueDate, loc)
+}
+
+func (rec *Recurrence) nextDue(after time.Time, loc *time.Location) (next time.Time, ok bool, err error) {
+	rule, err := ParseRRule(rec.Rule, loc)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	next, ok = rule.Next(rec.Start, after, rec.Index, loc)
+	return next, ok, nil
+}
+
+// LoadLocation resolves an IANA time zone name, treating "" as UTC.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, &ValidationError{Field: "timeZone", Message: "unknown time zone " + name}
+	}
+	return loc, nil
+}
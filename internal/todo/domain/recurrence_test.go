@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	require.NoError(t, err)
+	return loc
+}
+
+func TestRRuleNext(t *testing.T) {
+	taipei := mustLoad(t, "Asia/Taipei")
+	cases := []struct {
+		name  string
+		rule  string
+		start time.Time
+		after time.Time
+		index int
+		want  time.Time
+		ok    bool
+	}{
+		{
+			name:  "daily",
+			rule:  "FREQ=DAILY",
+			start: time.Date(2025, 7, 1, 9, 0, 0, 0, taipei),
+			after: time.Date(2025, 7, 1, 9, 0, 0, 0, taipei),
+			want:  time.Date(2025, 7, 2, 9, 0, 0, 0, taipei), ok: true,
+		},
+		{
+			name:  "every other week on monday and thursday",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			start: time.Date(2025, 7, 7, 9, 0, 0, 0, taipei), // Monday
+			after: time.Date(2025, 7, 10, 9, 0, 0, 0, taipei),
+			want:  time.Date(2025, 7, 21, 9, 0, 0, 0, taipei), ok: true,
+		},
+		{
+			name:  "monthly on the 31st skips short months",
+			rule:  "FREQ=MONTHLY",
+			start: time.Date(2025, 1, 31, 9, 0, 0, 0, taipei),
+			after: time.Date(2025, 1, 31, 9, 0, 0, 0, taipei),
+			want:  time.Date(2025, 3, 31, 9, 0, 0, 0, taipei), ok: true,
+		},
+		{
+			name:  "monthly on the last friday",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR",
+			start: time.Date(2025, 7, 25, 17, 0, 0, 0, taipei),
+			after: time.Date(2025, 7, 25, 17, 0, 0, 0, taipei),
+			want:  time.Date(2025, 8, 29, 17, 0, 0, 0, taipei), ok: true,
+		},
+		{
+			name:  "monthly on the first",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=1",
+			start: time.Date(2025, 7, 1, 9, 0, 0, 0, taipei),
+			after: time.Date(2025, 7, 1, 9, 0, 0, 0, taipei),
+			want:  time.Date(2025, 8, 1, 9, 0, 0, 0, taipei), ok: true,
+		},
+		{
+			name:  "yearly on leap day",
+			rule:  "FREQ=YEARLY",
+			start: time.Date(2024, 2, 29, 9, 0, 0, 0, taipei),
+			after: time.Date(2024, 2, 29, 9, 0, 0, 0, taipei),
+			want:  time.Date(2028, 2, 29, 9, 0, 0, 0, taipei), ok: true,
+		},
+		{
+			name:  "count exhausted",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: time.Date(2025, 7, 1, 9, 0, 0, 0, taipei),
+			after: time.Date(2025, 7, 3, 9, 0, 0, 0, taipei),
+			index: 3,
+		},
+		{
+			name:  "until reached",
+			rule:  "FREQ=WEEKLY;UNTIL=20250714",
+			start: time.Date(2025, 7, 7, 9, 0, 0, 0, taipei),
+			after: time.Date(2025, 7, 14, 9, 0, 0, 0, taipei),
+			index: 2,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rule, err := ParseRRule(c.rule, taipei)
+			require.NoError(t, err)
+			index := c.index
+			if index == 0 {
+				index = 1
+			}
+			got, ok := rule.Next(c.start, c.after, index, taipei)
+			assert.Equal(t, c.ok, ok)
+			if c.ok {
+				assert.True(t, c.want.Equal(got), "want %v got %v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestRRuleNext_KeepsWallClockAcrossDST(t *testing.T) {
+	ny := mustLoad(t, "America/New_York")
+	rule, err := ParseRRule("RRULE:FREQ=WEEKLY;BYDAY=SA", ny)
+	require.NoError(t, err)
+
+	// DST ends on 2025-11-02; the 09:00 local time must not drift to 08:00
+	start := time.Date(2025, 11, 1, 9, 0, 0, 0, ny)
+	next, ok := rule.Next(start, start, 1, ny)
+	require.True(t, ok)
+	assert.Equal(t, 9, next.In(ny).Hour())
+	assert.Equal(t, 8, next.In(ny).Day())
+	assert.Equal(t, 7*24*time.Hour+time.Hour, next.Sub(start))
+
+	// and the rule is evaluated in its own zone, not the due date's
+	next, ok = rule.Next(start.UTC(), start.UTC(), 1, ny)
+	require.True(t, ok)
+	assert.Equal(t, 9, next.In(ny).Hour())
+}
+
+func TestParseRRule_Invalid(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=DAILY;COUNT=2;UNTIL=20250101",
+		"FREQ=DAILY;BYSETPOS=1",
+	} {
+		_, err := ParseRRule(rule, time.UTC)
+		var invalid *ValidationError
+		assert.ErrorAs(t, err, &invalid, rule)
+	}
+}
@@ -1,9 +1,63 @@
 package domain
 
+import "time"
+
+// TodoRepository stores todos. Apart from the trash methods, all lookups and
+// writes only see live todos; trashed ones behave as if they did not exist.
 type TodoRepository interface {
 	Save(todo *Todo) error
-	FindAll(page, limit int, title string) (list []*Todo, total int64, err error)
-	DeleteByID(id string) error
+	FindAll(page, limit int, filter TodoFilter) (list []*Todo, total int64, err error)
 	FindByID(id string) (*Todo, error)
+	// Search finds the live todos matching a full-text query on their title
+	// and tags, best matches first.
+	Search(query SearchQuery, page, limit int) (hits []SearchHit, total int64, err error)
+	// FindByIDs returns the live todos among the given IDs, in no particular
+	// order; missing IDs are left out.
+	FindByIDs(ids []string) ([]*Todo, error)
+	// UpdateByID is a compare-and-swap on todo.Version: the write only
+	// happens when the stored version matches (zero skips the check), and
+	// on success todo.Version is set to the new stored version.
 	UpdateByID(todo *Todo) error
+
+	// UpdateMany applies change to the given todos in a single write. Each
+	// todo is only changed while it is live and its stored version still
+	// matches todo.Version; the IDs of the todos that were changed, and had
+	// their version incremented, are returned.
+	UpdateMany(todos []*Todo, change BulkChange) (changed []string, err error)
+	// TrashMany moves the given todos to the trash in a single write, under
+	// the same version rule as UpdateMany.
+	TrashMany(todos []*Todo, at time.Time) (trashed []string, err error)
+
+	// TrashByID moves a live todo to the trash, stamping it with at.
+	// A non-zero version must match the stored one.
+	TrashByID(id string, version int64, at time.Time) error
+	// FindTrashedByID returns a todo that is in the trash.
+	FindTrashedByID(id string) (*Todo, error)
+	// FindTrash lists the trashed todos of owner, most recently deleted
+	// first.
+	FindTrash(owner string, page, limit int) (list []*Todo, total int64, err error)
+	// RestoreByID brings a trashed todo back. A non-zero version must match.
+	RestoreByID(id string, version int64) error
+	// DeleteByID permanently removes a trashed todo. A non-zero version must
+	// match the stored one.
+	DeleteByID(id string, version int64) error
+	// FindExpiredTrash returns up to limit todos trashed before the given
+	// time, longest trashed first.
+	FindExpiredTrash(before time.Time, limit int) ([]*Todo, error)
+
+	// RunInTx runs fn in a transaction: the writes fn makes through tx are
+	// all kept if it returns nil, and all undone if it returns an error,
+	// which RunInTx then returns. fn may be run more than once when the
+	// store retries a transaction, so it must not have other side effects.
+	RunInTx(fn func(tx TodoRepository) error) error
+}
+
+// Projects looks up the projects todos are filed under. Todos are filed
+// under projects of the user who owns them.
+type Projects interface {
+	// CheckProject returns nil when todos of owner can be filed under the
+	// project, and the reason otherwise, e.g. that it is archived.
+	CheckProject(owner, id string) error
+	// HasProject reports whether owner still has the project.
+	HasProject(owner, id string) (bool, error)
 }
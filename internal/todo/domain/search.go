@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Search weights: a term found in the title counts for more than one found
+// in a tag.
+const (
+	TitleWeight = 2
+	TagWeight   = 1
+)
+
+// SearchQuery is a full-text search over the titles and tags of live todos.
+type SearchQuery struct {
+	// Terms are tokens as Tokenize returns them; todos having any of them
+	// match.
+	Terms  []string
+	Filter TodoFilter
+}
+
+// SearchHit is a todo found by a search with its relevance: the higher
+// the score, the better it matches.
+type SearchHit struct {
+	Todo  *Todo
+	Score float64
+}
+
+// Tokenize splits text into lower-case words of letters and digits, the
+// way titles and tags are indexed for search.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// Permission is what a share lets its grantee do with a todo.
+type Permission string
+
+const (
+	// PermissionView lets the grantee read the todo and its history.
+	PermissionView Permission = "view"
+	// PermissionEdit also lets the grantee change the todo, except for
+	// moving it to another project. Deleting, restoring, purging and
+	// sharing stay with the owner.
+	PermissionEdit Permission = "edit"
+)
+
+// Allows reports whether the permission covers need: editing includes
+// viewing.
+func (p Permission) Allows(need Permission) bool {
+	return p == need || p == PermissionEdit && need == PermissionView
+}
+
+// Share grants a registered user access to a todo of somebody else.
+type Share struct {
+	TodoID     string     `json:"todoId" example:"123e4567-e89b-12d3-a456-426614174000" doc:"ID of the shared todo item"`
+	Owner      string     `json:"owner" readOnly:"true" example:"alice" doc:"Owner of the todo item, who shared it"`
+	Grantee    string     `json:"username" example:"bob" doc:"User the todo item is shared with"`
+	Permission Permission `json:"permission" enum:"view,edit" example:"edit" doc:"Whether the user can only read the todo item, or also edit it"`
+	CreatedAt  time.Time  `json:"createdAt" readOnly:"true" doc:"When the todo item was first shared with the user"`
+}
+
+// ShareRepository stores the shares of todos; a todo has at most one share
+// per grantee.
+type ShareRepository interface {
+	// Save creates the share, or changes the permission of the existing
+	// share of the todo with the same grantee.
+	Save(share *Share) error
+	// Find returns the share of a todo with the grantee.
+	Find(todoID, grantee string) (*Share, error)
+	// FindByTodo lists the shares of a todo, oldest first.
+	FindByTodo(todoID string) ([]*Share, error)
+	// FindByGrantee lists the shares a user was granted, newest first.
+	FindByGrantee(grantee string, page, limit int) (list []*Share, total int64, err error)
+	Delete(todoID, grantee string) error
+	// DeleteByTodo removes every share of a todo.
+	DeleteByTodo(todoID string) error
+}
+
+// Users tells whether a username belongs to a registered user.
+type Users interface {
+	Exists(username string) (bool, error)
+}
@@ -1,10 +1,143 @@
 package domain
 
-import "time"
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Todo struct {
 	ID      string    `json:"id" example:"123e4567-e89b-12d3-a456-426614174000" doc:"Unique identifier for the todo item"`
 	Title   string    `json:"title" example:"Buy milk" doc:"Title of the todo item"`
 	DueDate time.Time `json:"dueDate" example:"2023-10-10T10:00:00Z" doc:"Due date of the todo item"`
-	Done    bool      `json:"done" example:"false" doc:"Completion status of the todo item"`
+	// AllDay and TimeZone are kept in step with DueDate by SetDue.
+	AllDay   bool   `json:"allDay,omitempty" example:"false" doc:"Whether the todo item is due any time on its date; dueDate is then midnight UTC of that date, the same date in every time zone"`
+	TimeZone string `json:"timeZone,omitempty" example:"Asia/Taipei" doc:"IANA time zone the due date was set in, for showing it as entered"`
+	Done     bool   `json:"done" example:"false" doc:"Completion status of the todo item"`
+	// CompletedAt is kept in step with Done by SetDone.
+	CompletedAt *time.Time `json:"completedAt,omitempty" readOnly:"true" example:"2023-10-09T18:30:00Z" doc:"When the todo item was completed"`
+	Version     int64      `json:"version" example:"1" doc:"Revision of the todo item, incremented on every change"`
+	Owner       string     `json:"owner,omitempty" readOnly:"true" example:"alice" doc:"User who created the todo item"`
+	Tags        []string   `json:"tags,omitempty" example:"[\"home\"]" doc:"Labels of the todo item"`
+	Priority    string     `json:"priority,omitempty" example:"A" doc:"Priority of the todo item, from A (highest) to Z; empty for none"`
+	ProjectID   string     `json:"projectId,omitempty" example:"0b7e6f1c-8a3d-4c55-9d2e-3f1a2b4c5d6e" doc:"Project the todo item is filed under; empty for the inbox"`
+	Assignee    string     `json:"assignee,omitempty" example:"bob" doc:"Registered user who is to do the todo item; empty for none"`
+	// Mentions is derived from the title by the use case.
+	Mentions []string `json:"mentions,omitempty" readOnly:"true" example:"[\"carol\"]" doc:"Registered users mentioned in the title as @username"`
+
+	Checklist []ChecklistItem    `json:"checklist,omitempty" doc:"Checklist items of the todo item, ordered by position"`
+	Progress  *ChecklistProgress `json:"progress,omitempty" readOnly:"true" doc:"Checked and total checklist items; omitted when there is no checklist"`
+
+	Recurrence *Recurrence `json:"recurrence,omitempty" doc:"Repeat rule; completing the todo generates its next occurrence"`
+
+	Attachments []Attachment `json:"attachments,omitempty" readOnly:"true" doc:"Files attached to the todo item, oldest first"`
+
+	// CommentCount is filled in by the use case for lists; it is not stored.
+	CommentCount int `json:"commentCount,omitempty" readOnly:"true" example:"2" doc:"Number of comments on the todo item, in lists; omitted when there are none"`
+
+	DeletedAt *time.Time `json:"deletedAt,omitempty" example:"2023-10-11T10:00:00Z" doc:"When the todo item was moved to the trash"`
+}
+
+// Clone returns a deep copy, so the copy's slices can be changed without
+// touching the original.
+func (t *Todo) Clone() *Todo {
+	c := *t
+	if t.Tags != nil {
+		c.Tags = append([]string(nil), t.Tags...)
+	}
+	if t.Mentions != nil {
+		c.Mentions = append([]string(nil), t.Mentions...)
+	}
+	if t.Checklist != nil {
+		c.Checklist = append([]ChecklistItem(nil), t.Checklist...)
+	}
+	if t.Attachments != nil {
+		c.Attachments = append([]Attachment(nil), t.Attachments...)
+	}
+	if t.Progress != nil {
+		p := *t.Progress
+		c.Progress = &p
+	}
+	if t.Recurrence != nil {
+		r := *t.Recurrence
+		c.Recurrence = &r
+	}
+	if t.CompletedAt != nil {
+		d := *t.CompletedAt
+		c.CompletedAt = &d
+	}
+	if t.DeletedAt != nil {
+		d := *t.DeletedAt
+		c.DeletedAt = &d
+	}
+	return &c
+}
+
+// SetDone completes or reopens the todo. Completing stamps CompletedAt with
+// at, unless the todo already was done; reopening clears it.
+func (t *Todo) SetDone(done bool, at time.Time) {
+	switch {
+	case done && !t.Done:
+		at = at.UTC()
+		t.CompletedAt = &at
+	case !done:
+		t.CompletedAt = nil
+	}
+	t.Done = done
+}
+
+// SetDue sets when the todo is due. An all-day due date keeps only its
+// date, see AllDayDate; a todo without a due date is never all-day.
+// timeZone must be an IANA time zone name, or empty for none.
+func (t *Todo) SetDue(due time.Time, allDay bool, timeZone string) error {
+	if _, err := LoadLocation(timeZone); err != nil {
+		return err
+	}
+	allDay = allDay && !due.IsZero()
+	if allDay {
+		due = AllDayDate(due)
+	}
+	t.DueDate, t.AllDay, t.TimeZone = due, allDay, timeZone
+	return nil
+}
+
+// AllDayDate returns midnight UTC of the date t has in its own location.
+// All-day todos are due at this time, so that their date reads the same
+// wherever they are read, and filters can compare them as UTC dates.
+func AllDayDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// NormalizePriority upper-cases a priority and checks that it is a single
+// letter; the empty priority means none.
+func NormalizePriority(priority string) (string, error) {
+	priority = strings.ToUpper(strings.TrimSpace(priority))
+	if priority != "" && (len(priority) != 1 || priority[0] < 'A' || priority[0] > 'Z') {
+		return "", &ValidationError{Field: "priority", Message: "must be a letter from A to Z"}
+	}
+	return priority, nil
+}
+
+// MaxTags is the number of tags a todo can carry.
+const MaxTags = 20
+
+// NormalizeTags trims the tags, drops empty ones and duplicates, keeping the
+// first occurrence of each, and checks the result against the tag rules.
+func NormalizeTags(tags []string) ([]string, error) {
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || slices.Contains(out, tag) {
+			continue
+		}
+		if len(tag) > 50 || strings.HasPrefix(tag, "$") || strings.ContainsAny(tag, ",\r\n") {
+			return nil, &ValidationError{Field: "tags", Message: "tags must be at most 50 characters, not start with $ and not contain commas or line breaks"}
+		}
+		out = append(out, tag)
+	}
+	if len(out) > MaxTags {
+		return nil, &ValidationError{Field: "tags", Message: "at most " + strconv.Itoa(MaxTags) + " tags are allowed"}
+	}
+	return out, nil
 }
@@ -0,0 +1,53 @@
+package blob
+
+import (
+	"errors"
+	"io"
+	"todo-app/internal/todo/domain"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GridFSBlobStore keeps blobs in a MongoDB GridFS bucket, as files whose ID
+// is the key.
+type GridFSBlobStore struct {
+	bucket *gridfs.Bucket
+}
+
+// NewGridFSBlobStore creates the store on the "attachments" bucket of db.
+func NewGridFSBlobStore(db *mongo.Database) (*GridFSBlobStore, error) {
+	bucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName("attachments"))
+	if err != nil {
+		return nil, err
+	}
+	return &GridFSBlobStore{bucket: bucket}, nil
+}
+
+// Put replaces what was stored under key: GridFS files cannot be
+// overwritten, so an old file with the same ID is deleted first.
+func (s *GridFSBlobStore) Put(key string, content io.Reader) error {
+	if err := s.Delete(key); err != nil {
+		return err
+	}
+	return s.bucket.UploadFromStreamWithID(key, key, content)
+}
+
+func (s *GridFSBlobStore) Open(key string) (io.ReadCloser, error) {
+	stream, err := s.bucket.OpenDownloadStream(key)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return nil, domain.ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (s *GridFSBlobStore) Delete(key string) error {
+	if err := s.bucket.Delete(key); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+		return err
+	}
+	return nil
+}
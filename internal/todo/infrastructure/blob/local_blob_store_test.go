@@ -0,0 +1,34 @@
+package blob
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"todo-app/internal/todo/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalBlobStore(t *testing.T) {
+	store, err := NewLocalBlobStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Put("a", strings.NewReader("first")))
+	require.NoError(t, store.Put("a", strings.NewReader("second")))
+	r, err := store.Open("a")
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "second", string(content))
+
+	require.NoError(t, store.Delete("a"))
+	_, err = store.Open("a")
+	assert.ErrorIs(t, err, domain.ErrAttachmentNotFound)
+	assert.NoError(t, store.Delete("a"), "deleting nothing is not an error")
+
+	for _, key := range []string{"", "..", "../a", "a/b", `a\b`, ".upload-1"} {
+		assert.Error(t, store.Put(key, strings.NewReader("x")), key)
+	}
+}
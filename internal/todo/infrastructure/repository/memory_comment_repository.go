@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"todo-app/internal/todo/domain"
+)
+
+type MemoryCommentRepository struct {
+	mu       sync.RWMutex
+	comments map[string]*domain.Comment
+	// seq orders comments written at the same instant as they were added.
+	seq  map[string]int
+	next int
+}
+
+func NewMemoryCommentRepository() *MemoryCommentRepository {
+	return &MemoryCommentRepository{comments: map[string]*domain.Comment{}, seq: map[string]int{}}
+}
+
+func (r *MemoryCommentRepository) Save(comment *domain.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *comment
+	if _, ok := r.comments[comment.ID]; !ok {
+		r.seq[comment.ID] = r.next
+		r.next++
+	}
+	r.comments[comment.ID] = &stored
+	return nil
+}
+
+func (r *MemoryCommentRepository) FindByID(todoID, id string) (*domain.Comment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	comment, ok := r.comments[id]
+	if !ok || comment.TodoID != todoID {
+		return nil, domain.ErrCommentNotFound
+	}
+	c := *comment
+	return &c, nil
+}
+
+func (r *MemoryCommentRepository) FindByTodo(todoID string, page, limit int) ([]*domain.Comment, int64, error) {
+	r.mu.RLock()
+	list := []*domain.Comment{}
+	for _, comment := range r.comments {
+		if comment.TodoID == todoID {
+			c := *comment
+			list = append(list, &c)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return r.seq[list[i].ID] < r.seq[list[j].ID]
+	})
+	r.mu.RUnlock()
+	total := int64(len(list))
+	if page < 0 || limit <= 0 || page*limit >= len(list) {
+		return []*domain.Comment{}, total, nil
+	}
+	return list[page*limit : min(len(list), (page+1)*limit)], total, nil
+}
+
+func (r *MemoryCommentRepository) CountByTodos(todoIDs []string) (map[string]int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	wanted := make(map[string]bool, len(todoIDs))
+	for _, id := range todoIDs {
+		wanted[id] = true
+	}
+	counts := map[string]int{}
+	for _, comment := range r.comments {
+		if wanted[comment.TodoID] {
+			counts[comment.TodoID]++
+		}
+	}
+	return counts, nil
+}
+
+func (r *MemoryCommentRepository) Delete(todoID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if comment, ok := r.comments[id]; !ok || comment.TodoID != todoID {
+		return domain.ErrCommentNotFound
+	}
+	for key, comment := range r.comments {
+		if key == id || comment.ParentID == id {
+			delete(r.comments, key)
+			delete(r.seq, key)
+		}
+	}
+	return nil
+}
+
+func (r *MemoryCommentRepository) DeleteByTodo(todoID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, comment := range r.comments {
+		if comment.TodoID == todoID {
+			delete(r.comments, key)
+			delete(r.seq, key)
+		}
+	}
+	return nil
+}
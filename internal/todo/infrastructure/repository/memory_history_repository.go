@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+	"todo-app/internal/todo/domain"
+)
+
+type MemoryHistoryRepository struct {
+	mu      sync.RWMutex
+	entries map[string][]*domain.HistoryEntry
+}
+
+func NewMemoryHistoryRepository() *MemoryHistoryRepository {
+	return &MemoryHistoryRepository{entries: map[string][]*domain.HistoryEntry{}}
+}
+
+func (r *MemoryHistoryRepository) Append(entry *domain.HistoryEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *entry
+	r.entries[entry.TodoID] = append(r.entries[entry.TodoID], &stored)
+	return nil
+}
+
+func (r *MemoryHistoryRepository) FindByTodoID(todoID string, page, limit int) (list []*domain.HistoryEntry, total int64, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	all := r.entries[todoID]
+	total = int64(len(all))
+	list = []*domain.HistoryEntry{}
+	if page < 0 || limit <= 0 {
+		return list, total, nil
+	}
+	// entries are appended in order, so walk backwards for most recent first
+	for i := len(all) - 1 - page*limit; i >= 0 && len(list) < limit; i-- {
+		copy := *all[i]
+		list = append(list, &copy)
+	}
+	return list, total, nil
+}
+
+func (r *MemoryHistoryRepository) FindSince(owner string, since time.Time, limit int) ([]*domain.HistoryEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := []*domain.HistoryEntry{}
+	for _, entries := range r.entries {
+		for _, entry := range entries {
+			if entry.Owner == owner && !entry.At.Before(since) {
+				copy := *entry
+				list = append(list, &copy)
+			}
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].At.Before(list[j].At) })
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"todo-app/internal/todo/domain"
+)
+
+// shareKey identifies the share of a todo with one grantee.
+type shareKey struct {
+	todoID, grantee string
+}
+
+type MemoryShareRepository struct {
+	mu     sync.RWMutex
+	shares map[shareKey]*domain.Share
+}
+
+func NewMemoryShareRepository() *MemoryShareRepository {
+	return &MemoryShareRepository{shares: map[shareKey]*domain.Share{}}
+}
+
+func (r *MemoryShareRepository) Save(share *domain.Share) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := shareKey{share.TodoID, share.Grantee}
+	if stored, ok := r.shares[key]; ok {
+		stored.Permission = share.Permission
+		share.CreatedAt = stored.CreatedAt
+		return nil
+	}
+	stored := *share
+	r.shares[key] = &stored
+	return nil
+}
+
+func (r *MemoryShareRepository) Find(todoID, grantee string) (*domain.Share, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	share, ok := r.shares[shareKey{todoID, grantee}]
+	if !ok {
+		return nil, domain.ErrShareNotFound
+	}
+	c := *share
+	return &c, nil
+}
+
+func (r *MemoryShareRepository) FindByTodo(todoID string) ([]*domain.Share, error) {
+	list := r.find(func(share *domain.Share) bool { return share.TodoID == todoID })
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list, nil
+}
+
+func (r *MemoryShareRepository) FindByGrantee(grantee string, page, limit int) ([]*domain.Share, int64, error) {
+	list := r.find(func(share *domain.Share) bool { return share.Grantee == grantee })
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	total := int64(len(list))
+	if page < 0 || limit <= 0 || page*limit >= len(list) {
+		return []*domain.Share{}, total, nil
+	}
+	return list[page*limit : min(len(list), (page+1)*limit)], total, nil
+}
+
+func (r *MemoryShareRepository) find(match func(*domain.Share) bool) []*domain.Share {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := []*domain.Share{}
+	for _, share := range r.shares {
+		if match(share) {
+			c := *share
+			list = append(list, &c)
+		}
+	}
+	return list
+}
+
+func (r *MemoryShareRepository) Delete(todoID, grantee string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := shareKey{todoID, grantee}
+	if _, ok := r.shares[key]; !ok {
+		return domain.ErrShareNotFound
+	}
+	delete(r.shares, key)
+	return nil
+}
+
+func (r *MemoryShareRepository) DeleteByTodo(todoID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key := range r.shares {
+		if key.todoID == todoID {
+			delete(r.shares, key)
+		}
+	}
+	return nil
+}
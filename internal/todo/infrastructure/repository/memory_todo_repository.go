@@ -1,9 +1,7 @@
 package repository
 
 import (
-	"errors"
 	"sort"
-	"strings"
 	"sync"
 	"time"
 	"todo-app/internal/todo/domain"
@@ -12,82 +10,302 @@ import (
 type MemoryTodoRepository struct {
 	mu    sync.RWMutex
 	items map[string]*domain.Todo
+	index *searchIndex
+	// journal is only set on the repository RunInTx hands to a transaction.
+	// It keeps each todo as it was before the transaction first wrote to
+	// it, nil for todos the transaction created, so the writes can be
+	// rolled back.
+	journal map[string]*domain.Todo
 }
 
 func NewMemoryTodoRepository() *MemoryTodoRepository {
-	return &MemoryTodoRepository{items: map[string]*domain.Todo{}}
+	return &MemoryTodoRepository{items: map[string]*domain.Todo{}, index: newSearchIndex()}
 }
 
 func (r *MemoryTodoRepository) Save(todo *domain.Todo) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	// emulate createdAt ordering by using time
-	if todo.DueDate.IsZero() {
-		// keep same semantics; just allow empty
+	if _, ok := r.items[todo.ID]; ok {
+		return domain.ErrConflict
 	}
-	r.items[todo.ID] = &domain.Todo{ID: todo.ID, Title: todo.Title, DueDate: todo.DueDate, Done: todo.Done}
+	r.touch(todo.ID)
+	r.items[todo.ID] = todo.Clone()
+	r.index.put(todo)
 	return nil
 }
 
-func (r *MemoryTodoRepository) FindAll(page, limit int, title string) (list []*domain.Todo, total int64, err error) {
+func (r *MemoryTodoRepository) FindAll(page, limit int, filter domain.TodoFilter) (list []*domain.Todo, total int64, err error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	res := make([]*domain.Todo, 0)
 	for _, v := range r.items {
-		if title != "" && !strings.Contains(v.Title, title) {
+		if v.DeletedAt != nil || !filter.Matches(v) {
 			continue
 		}
-		copy := *v
-		res = append(res, &copy)
+		res = append(res, v.Clone())
 	}
-	if page < 0 || limit <= 0 {
-		return []*domain.Todo{}, int64(len(res)), nil
+	// stable order just by ID for determinism
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return paginate(res, page, limit), int64(len(res)), nil
+}
+
+func (r *MemoryTodoRepository) Search(query domain.SearchQuery, page, limit int) ([]domain.SearchHit, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	hits := make([]domain.SearchHit, 0)
+	for id, score := range r.index.scores(query.Terms) {
+		v := r.items[id]
+		if v.DeletedAt != nil || !query.Filter.Matches(v) {
+			continue
+		}
+		hits = append(hits, domain.SearchHit{Todo: v.Clone(), Score: score})
 	}
-	start := page * limit
-	if start >= len(res) {
-		return []*domain.Todo{}, int64(len(res)), nil
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Todo.ID < hits[j].Todo.ID
+	})
+	total := int64(len(hits))
+	if page < 0 || limit <= 0 || page*limit >= len(hits) {
+		return []domain.SearchHit{}, total, nil
 	}
-	end := start + limit
-	if end > len(res) {
-		end = len(res)
+	return hits[page*limit : min(page*limit+limit, len(hits))], total, nil
+}
+
+func (r *MemoryTodoRepository) FindByID(id string) (*domain.Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	v, ok := r.items[id]
+	if !ok || v.DeletedAt != nil {
+		return nil, domain.ErrNotFound
 	}
-	res = res[start:end]
-	// stable order just by ID for determinism
-	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
-	return res, int64(len(res)), nil
+	return v.Clone(), nil
+}
+
+func (r *MemoryTodoRepository) FindByIDs(ids []string) ([]*domain.Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]*domain.Todo, 0, len(ids))
+	for _, id := range ids {
+		if v, ok := r.items[id]; ok && v.DeletedAt == nil {
+			res = append(res, v.Clone())
+		}
+	}
+	return res, nil
+}
+
+// UpdateMany changes all todos under one lock, so no other write can
+// interleave with the batch.
+func (r *MemoryTodoRepository) UpdateMany(todos []*domain.Todo, change domain.BulkChange) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changed := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		v, err := r.live(todo.ID, todo.Version)
+		if err != nil {
+			continue
+		}
+		change.Apply(v)
+		v.Version++
+		r.index.put(v)
+		changed = append(changed, v.ID)
+	}
+	return changed, nil
 }
 
-func (r *MemoryTodoRepository) DeleteByID(id string) error {
+func (r *MemoryTodoRepository) TrashMany(todos []*domain.Todo, at time.Time) ([]string, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if _, ok := r.items[id]; !ok {
-		return errors.New("there is no document with the given ID")
+	trashed := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		v, err := r.live(todo.ID, todo.Version)
+		if err != nil {
+			continue
+		}
+		v.DeletedAt = &at
+		v.Version++
+		trashed = append(trashed, v.ID)
 	}
-	delete(r.items, id)
+	return trashed, nil
+}
+
+func (r *MemoryTodoRepository) UpdateByID(todo *domain.Todo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, err := r.live(todo.ID, todo.Version)
+	if err != nil {
+		return err
+	}
+	todo.Version = v.Version + 1
+	todo.DeletedAt = nil
+	r.items[todo.ID] = todo.Clone()
+	r.index.put(todo)
 	return nil
 }
 
-func (r *MemoryTodoRepository) FindByID(id string) (*domain.Todo, error) {
+func (r *MemoryTodoRepository) TrashByID(id string, version int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, err := r.live(id, version)
+	if err != nil {
+		return err
+	}
+	v.DeletedAt = &at
+	v.Version++
+	return nil
+}
+
+func (r *MemoryTodoRepository) FindTrashedByID(id string) (*domain.Todo, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	v, ok := r.items[id]
-	if !ok {
-		return nil, errors.New("there is no document with the given ID")
+	if !ok || v.DeletedAt == nil {
+		return nil, domain.ErrNotFound
 	}
-	copy := *v
-	return &copy, nil
+	return v.Clone(), nil
 }
 
-func (r *MemoryTodoRepository) UpdateByID(todo *domain.Todo) error {
+func (r *MemoryTodoRepository) FindTrash(owner string, page, limit int) (list []*domain.Todo, total int64, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]*domain.Todo, 0)
+	for _, v := range r.items {
+		if v.DeletedAt == nil || v.Owner != owner {
+			continue
+		}
+		res = append(res, v.Clone())
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].DeletedAt.After(*res[j].DeletedAt) })
+	return paginate(res, page, limit), int64(len(res)), nil
+}
+
+func (r *MemoryTodoRepository) RestoreByID(id string, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, err := r.trashed(id, version)
+	if err != nil {
+		return err
+	}
+	v.DeletedAt = nil
+	v.Version++
+	return nil
+}
+
+func (r *MemoryTodoRepository) DeleteByID(id string, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.trashed(id, version); err != nil {
+		return err
+	}
+	delete(r.items, id)
+	r.index.remove(id)
+	return nil
+}
+
+func (r *MemoryTodoRepository) FindExpiredTrash(before time.Time, limit int) ([]*domain.Todo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	res := make([]*domain.Todo, 0)
+	for _, v := range r.items {
+		if v.DeletedAt != nil && v.DeletedAt.Before(before) {
+			res = append(res, v.Clone())
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].DeletedAt.Before(*res[j].DeletedAt) })
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+// RunInTx holds the write lock for the whole of fn, which works on the
+// same todos through a journaling repository; when fn fails, the journal
+// puts every todo it wrote to back the way it was.
+func (r *MemoryTodoRepository) RunInTx(fn func(tx domain.TodoRepository) error) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if _, ok := r.items[todo.ID]; !ok {
-		return nil
+	tx := &MemoryTodoRepository{items: r.items, index: r.index, journal: map[string]*domain.Todo{}}
+	if err := fn(tx); err != nil {
+		tx.rollback()
+		return err
 	}
-	r.items[todo.ID] = &domain.Todo{ID: todo.ID, Title: todo.Title, DueDate: todo.DueDate, Done: todo.Done}
 	return nil
 }
 
+// touch records the todo in the journal before its first write in a
+// transaction. Callers must hold the write lock.
+func (r *MemoryTodoRepository) touch(id string) {
+	if r.journal == nil {
+		return
+	}
+	if _, ok := r.journal[id]; ok {
+		return
+	}
+	var before *domain.Todo
+	if v, ok := r.items[id]; ok {
+		before = v.Clone()
+	}
+	r.journal[id] = before
+}
+
+func (r *MemoryTodoRepository) rollback() {
+	for id, before := range r.journal {
+		if before == nil {
+			delete(r.items, id)
+			r.index.remove(id)
+		} else {
+			r.items[id] = before
+			r.index.put(before)
+		}
+	}
+}
+
+// live returns the stored live todo, checking a non-zero version.
+// Callers must hold the write lock.
+func (r *MemoryTodoRepository) live(id string, version int64) (*domain.Todo, error) {
+	r.touch(id)
+	v, ok := r.items[id]
+	if !ok || v.DeletedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+	if version != 0 && v.Version != version {
+		return nil, domain.ErrVersionConflict
+	}
+	return v, nil
+}
+
+// trashed returns the stored trashed todo, checking a non-zero version.
+// Callers must hold the write lock.
+func (r *MemoryTodoRepository) trashed(id string, version int64) (*domain.Todo, error) {
+	r.touch(id)
+	v, ok := r.items[id]
+	if !ok || v.DeletedAt == nil {
+		return nil, domain.ErrNotFound
+	}
+	if version != 0 && v.Version != version {
+		return nil, domain.ErrVersionConflict
+	}
+	return v, nil
+}
+
+// paginate returns the page-th slice of limit items, or an empty slice if
+// the page is out of range.
+func paginate(list []*domain.Todo, page, limit int) []*domain.Todo {
+	if page < 0 || limit <= 0 {
+		return []*domain.Todo{}
+	}
+	start := page * limit
+	if start >= len(list) {
+		return []*domain.Todo{}
+	}
+	end := start + limit
+	if end > len(list) {
+		end = len(list)
+	}
+	return list[start:end]
+}
+
 // helper to seed
 func (r *MemoryTodoRepository) seed(title string) *domain.Todo {
 	t := &domain.Todo{ID: time.Now().Format("20060102150405.000000"), Title: title, DueDate: time.Now().Add(24 * time.Hour)}
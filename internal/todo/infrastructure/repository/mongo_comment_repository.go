@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo-app/internal/todo/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoCommentRepository implements domain.CommentRepository using
+// MongoDB.
+type MongoCommentRepository struct {
+	collection *mongo.Collection
+}
+
+// commentDocument is the persisted shape of a comment in the
+// "todo_comments" collection.
+type commentDocument struct {
+	ID        string     `bson:"_id"`
+	TodoID    string     `bson:"todoId"`
+	ParentID  string     `bson:"parentId,omitempty"`
+	Author    string     `bson:"author"`
+	Body      string     `bson:"body"`
+	CreatedAt time.Time  `bson:"createdAt"`
+	EditedAt  *time.Time `bson:"editedAt,omitempty"`
+}
+
+func (d *commentDocument) toDomain() *domain.Comment {
+	return &domain.Comment{
+		ID:        d.ID,
+		TodoID:    d.TodoID,
+		ParentID:  d.ParentID,
+		Author:    d.Author,
+		Body:      d.Body,
+		CreatedAt: d.CreatedAt,
+		EditedAt:  d.EditedAt,
+	}
+}
+
+// NewMongoCommentRepository creates the repository and ensures an index
+// for listing and counting the comments on a todo.
+func NewMongoCommentRepository(db *mongo.Database) *MongoCommentRepository {
+	coll := db.Collection("todo_comments")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, _ = coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "todoId", Value: 1}, {Key: "createdAt", Value: 1}},
+		Options: options.Index().SetName("todoId_createdAt"),
+	})
+	return &MongoCommentRepository{collection: coll}
+}
+
+func (r *MongoCommentRepository) Save(comment *domain.Comment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	doc := commentDocument{
+		ID:        comment.ID,
+		TodoID:    comment.TodoID,
+		ParentID:  comment.ParentID,
+		Author:    comment.Author,
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt,
+		EditedAt:  comment.EditedAt,
+	}
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": comment.ID}, doc, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *MongoCommentRepository) FindByID(todoID, id string) (*domain.Comment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var doc commentDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "todoId": todoID}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc.toDomain(), nil
+}
+
+func (r *MongoCommentRepository) FindByTodo(todoID string, page, limit int) ([]*domain.Comment, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	filter := bson.M{"todoId": todoID}
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}).SetSkip(int64(page*limit)).SetLimit(int64(limit)))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+	list := []*domain.Comment{}
+	for cursor.Next(ctx) {
+		var doc commentDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, 0, err
+		}
+		list = append(list, doc.toDomain())
+	}
+	return list, total, cursor.Err()
+}
+
+func (r *MongoCommentRepository) CountByTodos(todoIDs []string) (map[string]int, error) {
+	counts := map[string]int{}
+	if len(todoIDs) == 0 {
+		return counts, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"todoId": bson.M{"$in": todoIDs}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$todoId", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var row struct {
+			TodoID string `bson:"_id"`
+			Count  int    `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, err
+		}
+		counts[row.TodoID] = row.Count
+	}
+	return counts, cursor.Err()
+}
+
+func (r *MongoCommentRepository) Delete(todoID, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "todoId": todoID})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrCommentNotFound
+	}
+	_, err = r.collection.DeleteMany(ctx, bson.M{"todoId": todoID, "parentId": id})
+	return err
+}
+
+func (r *MongoCommentRepository) DeleteByTodo(todoID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := r.collection.DeleteMany(ctx, bson.M{"todoId": todoID})
+	return err
+}
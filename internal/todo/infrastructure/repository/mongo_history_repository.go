@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+	"todo-app/internal/todo/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoHistoryRepository implements domain.HistoryRepository using MongoDB.
+type MongoHistoryRepository struct {
+	collection *mongo.Collection
+}
+
+// historyDocument is the persisted shape of a history entry. Field values
+// are stored as JSON so they read back exactly as the API rendered them.
+type historyDocument struct {
+	ID      string                  `bson:"_id"`
+	TodoID  string                  `bson:"todoId"`
+	Actor   string                  `bson:"actor"`
+	Action  string                  `bson:"action"`
+	At      time.Time               `bson:"at"`
+	Version int64                   `bson:"version"`
+	Changes []historyChangeDocument `bson:"changes"`
+	Owner   string                  `bson:"owner"`
+}
+
+type historyChangeDocument struct {
+	Field  string `bson:"field"`
+	Before string `bson:"before"`
+	After  string `bson:"after"`
+}
+
+// NewMongoHistoryRepository creates the repository and ensures indexes for
+// listing a todo's history newest first and the changes to a user's todos
+// since a time.
+func NewMongoHistoryRepository(db *mongo.Database) *MongoHistoryRepository {
+	coll := db.Collection("todo_history")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, _ = coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "todoId", Value: 1}, {Key: "at", Value: -1}},
+		Options: options.Index().SetName("todoId_at"),
+	})
+	// syncs used to read the changes to all todos through the "at" index
+	_, _ = coll.Indexes().DropOne(ctx, "at")
+	_, _ = coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "owner", Value: 1}, {Key: "at", Value: 1}},
+		Options: options.Index().SetName("owner_at"),
+	})
+	return &MongoHistoryRepository{collection: coll}
+}
+
+func (r *MongoHistoryRepository) Append(entry *domain.HistoryEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	doc := historyDocument{
+		ID:      entry.ID,
+		TodoID:  entry.TodoID,
+		Actor:   entry.Actor,
+		Action:  string(entry.Action),
+		At:      entry.At,
+		Version: entry.Version,
+		Changes: make([]historyChangeDocument, 0, len(entry.Changes)),
+		Owner:   entry.Owner,
+	}
+	for _, c := range entry.Changes {
+		before, err := json.Marshal(c.Before)
+		if err != nil {
+			return err
+		}
+		after, err := json.Marshal(c.After)
+		if err != nil {
+			return err
+		}
+		doc.Changes = append(doc.Changes, historyChangeDocument{Field: c.Field, Before: string(before), After: string(after)})
+	}
+	_, err := r.collection.InsertOne(ctx, doc)
+	return err
+}
+
+func (r *MongoHistoryRepository) FindByTodoID(todoID string, page, limit int) (list []*domain.HistoryEntry, total int64, err error) {
+	skip := int64(page * limit)
+	qLimit := int64(limit)
+	filter := bson.M{"todoId": todoID}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	total, err = r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	cursor, err := r.collection.Find(ctx, filter, &options.FindOptions{
+		Sort:  bson.D{{Key: "at", Value: -1}, {Key: "version", Value: -1}},
+		Skip:  &skip,
+		Limit: &qLimit,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	list, err = decodeHistory(ctx, cursor)
+	return list, total, err
+}
+
+func (r *MongoHistoryRepository) FindSince(owner string, since time.Time, limit int) ([]*domain.HistoryEntry, error) {
+	qLimit := int64(limit)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cursor, err := r.collection.Find(ctx, bson.M{"owner": owner, "at": bson.M{"$gte": since}}, &options.FindOptions{
+		Sort:  bson.D{{Key: "at", Value: 1}, {Key: "version", Value: 1}},
+		Limit: &qLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	return decodeHistory(ctx, cursor)
+}
+
+func decodeHistory(ctx context.Context, cursor *mongo.Cursor) ([]*domain.HistoryEntry, error) {
+	list := []*domain.HistoryEntry{}
+	for cursor.Next(ctx) {
+		var doc historyDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		entry := &domain.HistoryEntry{
+			ID:      doc.ID,
+			TodoID:  doc.TodoID,
+			Actor:   doc.Actor,
+			Action:  domain.HistoryAction(doc.Action),
+			At:      doc.At,
+			Version: doc.Version,
+			Changes: make([]domain.FieldChange, 0, len(doc.Changes)),
+			Owner:   doc.Owner,
+		}
+		for _, c := range doc.Changes {
+			change := domain.FieldChange{Field: c.Field}
+			_ = json.Unmarshal([]byte(c.Before), &change.Before)
+			_ = json.Unmarshal([]byte(c.After), &change.After)
+			entry.Changes = append(entry.Changes, change)
+		}
+		list = append(list, entry)
+	}
+	return list, cursor.Err()
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo-app/internal/todo/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoShareRepository implements domain.ShareRepository using MongoDB.
+type MongoShareRepository struct {
+	collection *mongo.Collection
+}
+
+// shareDocument is the persisted shape of a share in the "todo_shares"
+// collection.
+type shareDocument struct {
+	TodoID     string    `bson:"todoId"`
+	Owner      string    `bson:"owner"`
+	Grantee    string    `bson:"grantee"`
+	Permission string    `bson:"permission"`
+	CreatedAt  time.Time `bson:"createdAt"`
+}
+
+func (d *shareDocument) toDomain() *domain.Share {
+	return &domain.Share{
+		TodoID:     d.TodoID,
+		Owner:      d.Owner,
+		Grantee:    d.Grantee,
+		Permission: domain.Permission(d.Permission),
+		CreatedAt:  d.CreatedAt,
+	}
+}
+
+// NewMongoShareRepository creates the repository and ensures a unique
+// index on the todo and grantee of a share, and one for listing the shares
+// of a user.
+func NewMongoShareRepository(db *mongo.Database) *MongoShareRepository {
+	coll := db.Collection("todo_shares")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, _ = coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "todoId", Value: 1}, {Key: "grantee", Value: 1}},
+		Options: options.Index().SetName("todoId_grantee").SetUnique(true),
+	})
+	_, _ = coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "grantee", Value: 1}, {Key: "createdAt", Value: -1}},
+		Options: options.Index().SetName("grantee_createdAt"),
+	})
+	return &MongoShareRepository{collection: coll}
+}
+
+func (r *MongoShareRepository) Save(share *domain.Share) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var doc shareDocument
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"todoId": share.TodoID, "grantee": share.Grantee}, bson.M{
+		"$set":         bson.M{"permission": string(share.Permission)},
+		"$setOnInsert": bson.M{"owner": share.Owner, "createdAt": share.CreatedAt},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)).Decode(&doc)
+	if err != nil {
+		return err
+	}
+	share.CreatedAt = doc.CreatedAt
+	return nil
+}
+
+func (r *MongoShareRepository) Find(todoID, grantee string) (*domain.Share, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var doc shareDocument
+	err := r.collection.FindOne(ctx, bson.M{"todoId": todoID, "grantee": grantee}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrShareNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc.toDomain(), nil
+}
+
+func (r *MongoShareRepository) FindByTodo(todoID string) ([]*domain.Share, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cursor, err := r.collection.Find(ctx, bson.M{"todoId": todoID}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	return decodeShares(ctx, cursor)
+}
+
+func (r *MongoShareRepository) FindByGrantee(grantee string, page, limit int) ([]*domain.Share, int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	filter := bson.M{"grantee": grantee}
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetSkip(int64(page*limit)).SetLimit(int64(limit)))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+	list, err := decodeShares(ctx, cursor)
+	return list, total, err
+}
+
+func decodeShares(ctx context.Context, cursor *mongo.Cursor) ([]*domain.Share, error) {
+	list := []*domain.Share{}
+	for cursor.Next(ctx) {
+		var doc shareDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		list = append(list, doc.toDomain())
+	}
+	return list, cursor.Err()
+}
+
+func (r *MongoShareRepository) Delete(todoID, grantee string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := r.collection.DeleteOne(ctx, bson.M{"todoId": todoID, "grantee": grantee})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrShareNotFound
+	}
+	return nil
+}
+
+func (r *MongoShareRepository) DeleteByTodo(todoID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := r.collection.DeleteMany(ctx, bson.M{"todoId": todoID})
+	return err
+}
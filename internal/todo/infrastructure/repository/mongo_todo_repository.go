@@ -2,10 +2,13 @@ package repository
 
 import (
 	"context"
-	"errors"
+	"log"
+	"regexp"
+	"strings"
 	"time"
 	"todo-app/internal/todo/domain"
 
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -13,129 +16,593 @@ import (
 
 type MongoTodoRepository struct {
 	collection *mongo.Collection
+	// txCtx carries the session of the transaction this repository was
+	// handed to by RunInTx; it is nil outside of transactions.
+	txCtx context.Context
 }
 
-func NewMongoTodoRepository(db *mongo.Database) *MongoTodoRepository {
+// todoDocument is the persisted shape of a todo in the "todos" collection.
+type todoDocument struct {
+	ID          string                  `bson:"_id"`
+	Title       string                  `bson:"title"`
+	DueDate     time.Time               `bson:"dueDate"`
+	AllDay      bool                    `bson:"allDay,omitempty"`
+	TimeZone    string                  `bson:"timeZone,omitempty"`
+	Done        bool                    `bson:"done"`
+	CompletedAt *time.Time              `bson:"completedAt,omitempty"`
+	Version     int64                   `bson:"version"`
+	Owner       string                  `bson:"owner,omitempty"`
+	Tags        []string                `bson:"tags,omitempty"`
+	Priority    string                  `bson:"priority,omitempty"`
+	ProjectID   string                  `bson:"projectId,omitempty"`
+	Assignee    string                  `bson:"assignee,omitempty"`
+	Mentions    []string                `bson:"mentions,omitempty"`
+	Checklist   []checklistItemDocument `bson:"checklist,omitempty"`
+	Recurrence  *recurrenceDocument     `bson:"recurrence,omitempty"`
+	Attachments []attachmentDocument    `bson:"attachments,omitempty"`
+	DeletedAt   *time.Time              `bson:"deletedAt,omitempty"`
+}
+
+type checklistItemDocument struct {
+	ID       string `bson:"id"`
+	Text     string `bson:"text"`
+	Checked  bool   `bson:"checked"`
+	Position int    `bson:"position"`
+}
+
+type attachmentDocument struct {
+	ID          string    `bson:"id"`
+	Filename    string    `bson:"filename"`
+	ContentType string    `bson:"contentType"`
+	Size        int64     `bson:"size"`
+	UploadedBy  string    `bson:"uploadedBy"`
+	UploadedAt  time.Time `bson:"uploadedAt"`
+}
+
+type recurrenceDocument struct {
+	Rule     string    `bson:"rule"`
+	TimeZone string    `bson:"timeZone,omitempty"`
+	Start    time.Time `bson:"start"`
+	Index    int       `bson:"index"`
+	NextID   string    `bson:"nextId,omitempty"`
+}
+
+func newRecurrenceDocument(rec *domain.Recurrence) *recurrenceDocument {
+	if rec == nil {
+		return nil
+	}
+	doc := recurrenceDocument(*rec)
+	return &doc
+}
+
+func newChecklistDocuments(items []domain.ChecklistItem) []checklistItemDocument {
+	docs := make([]checklistItemDocument, 0, len(items))
+	for _, item := range items {
+		docs = append(docs, checklistItemDocument(item))
+	}
+	return docs
+}
+
+func newAttachmentDocuments(attachments []domain.Attachment) []attachmentDocument {
+	docs := make([]attachmentDocument, 0, len(attachments))
+	for _, a := range attachments {
+		docs = append(docs, attachmentDocument(a))
+	}
+	return docs
+}
+
+func (d *todoDocument) toDomain() *domain.Todo {
+	todo := &domain.Todo{
+		ID:          d.ID,
+		Title:       d.Title,
+		DueDate:     d.DueDate,
+		AllDay:      d.AllDay,
+		TimeZone:    d.TimeZone,
+		Done:        d.Done,
+		CompletedAt: d.CompletedAt,
+		Version:     d.Version,
+		Owner:       d.Owner,
+		Tags:        d.Tags,
+		Priority:    d.Priority,
+		ProjectID:   d.ProjectID,
+		Assignee:    d.Assignee,
+		Mentions:    d.Mentions,
+		DeletedAt:   d.DeletedAt,
+	}
+	if todo.Version == 0 {
+		// stored before versioning and not migrated yet; a todo's first
+		// version is 1, and 0 would read as "any version" in If-Match
+		todo.Version = 1
+	}
+	for _, item := range d.Checklist {
+		todo.Checklist = append(todo.Checklist, domain.ChecklistItem(item))
+	}
+	for _, a := range d.Attachments {
+		todo.Attachments = append(todo.Attachments, domain.Attachment(a))
+	}
+	if d.Recurrence != nil {
+		rec := domain.Recurrence(*d.Recurrence)
+		todo.Recurrence = &rec
+	}
+	return todo
+}
 
+// Live todos have no deletedAt; trashed ones carry the time they were
+// trashed, which the trash sweeper uses to find the ones to purge.
+var (
+	liveFilter    = bson.M{"deletedAt": nil}
+	trashedFilter = bson.M{"deletedAt": bson.M{"$ne": nil}}
+)
+
+// NewMongoTodoRepository creates the repository, gives todos stored before
+// versioning their first version, and ensures the index the trash sweeper
+// looks up expired todos with, and the text index searches use.
+func NewMongoTodoRepository(db *mongo.Database) *MongoTodoRepository {
+	coll := db.Collection("todos")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// writes match and bump the stored version, so todos without one could
+	// never be updated conditionally
+	if _, err := coll.UpdateMany(ctx, bson.M{"version": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"version": 1}}); err != nil {
+		log.Printf("todos: version unversioned todos: %v", err)
+	}
+	// trashed todos used to expire through a TTL index, which purged them
+	// behind the use case's back and left their comments and files behind
+	_, _ = coll.Indexes().DropOne(ctx, "ttl_deletedAt")
+	if _, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "deletedAt", Value: 1}},
+		Options: options.Index().SetName("deletedAt"),
+	}); err != nil {
+		log.Printf("todos: ensure trash index: %v", err)
+	}
+	// search terms are whole words as domain.Tokenize splits them, so the
+	// index does no language-specific stemming
+	if _, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "title", Value: "text"}, {Key: "tags", Value: "text"}},
+		Options: options.Index().SetName("text_title_tags").SetDefaultLanguage("none").
+			SetWeights(bson.D{{Key: "title", Value: domain.TitleWeight}, {Key: "tags", Value: domain.TagWeight}}),
+	}); err != nil {
+		log.Printf("todos: ensure text index: %v", err)
+	}
 	return &MongoTodoRepository{
-		collection: db.Collection("todos"),
+		collection: coll,
 	}
 }
 
 func (r *MongoTodoRepository) Save(todo *domain.Todo) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := r.context(10 * time.Second)
 	defer cancel()
 	_, err := r.collection.InsertOne(ctx, bson.M{
-		"_id":       todo.ID,
-		"title":     todo.Title,
-		"dueDate":   todo.DueDate,
-		"done":      todo.Done,
-		"createdAt": time.Now(),
-		"updatedAt": time.Now(),
+		"_id":         todo.ID,
+		"title":       todo.Title,
+		"dueDate":     todo.DueDate,
+		"allDay":      todo.AllDay,
+		"timeZone":    todo.TimeZone,
+		"done":        todo.Done,
+		"completedAt": todo.CompletedAt,
+		"version":     todo.Version,
+		"owner":       todo.Owner,
+		"tags":        todo.Tags,
+		"priority":    todo.Priority,
+		"projectId":   todo.ProjectID,
+		"assignee":    todo.Assignee,
+		"mentions":    todo.Mentions,
+		"checklist":   newChecklistDocuments(todo.Checklist),
+		"recurrence":  newRecurrenceDocument(todo.Recurrence),
+		"attachments": newAttachmentDocuments(todo.Attachments),
+		"createdAt":   time.Now(),
+		"updatedAt":   time.Now(),
 	})
+	if mongo.IsDuplicateKeyError(err) {
+		return domain.ErrConflict
+	}
 	return err
 }
 
-func (r *MongoTodoRepository) FindAll(page, limit int, title string) (list []*domain.Todo, total int64, err error) {
+func (r *MongoTodoRepository) FindAll(page, limit int, filter domain.TodoFilter) (list []*domain.Todo, total int64, err error) {
+	// Sort by createdAt in descending order
+	return r.find(listFilter(filter), bson.D{{Key: "createdAt", Value: -1}}, page, limit)
+}
+
+// listFilter translates a todo filter into a query on live todos.
+func listFilter(f domain.TodoFilter) bson.M {
+	filter := bson.M{"deletedAt": nil}
+	if f.Title != "" {
+		filter["title"] = bson.M{"$regex": regexp.QuoteMeta(f.Title), "$options": "i"}
+	}
+	if f.Done != nil {
+		filter["done"] = *f.Done
+	}
+	if f.Tag != "" {
+		filter["tags"] = f.Tag
+	}
+	if f.Owner != "" {
+		filter["owner"] = f.Owner
+	}
+	if f.Assignee != "" {
+		filter["assignee"] = f.Assignee
+	}
+	if f.Project == domain.Inbox {
+		// todos stored before projects existed have no projectId
+		filter["projectId"] = bson.M{"$in": bson.A{nil, ""}}
+	} else if f.Project != "" {
+		filter["projectId"] = f.Project
+	}
+	if timed, allDay, ok := f.DueSpans(); ok {
+		// an expression rather than $or, which searches cannot combine
+		// with $text
+		due := bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$allDay", true}}, dueRange(allDay), dueRange(timed)}}
+		if f.Due == domain.DueOverdue {
+			due = bson.M{"$and": bson.A{due, bson.M{"$eq": bson.A{"$done", false}}}}
+		}
+		filter["$expr"] = due
+	}
+	return filter
+}
+
+// dueRange tests that the due date is in the span. Todos without a due
+// date are stored with the zero time, which an open span leaves out.
+func dueRange(s domain.DueSpan) bson.M {
+	from := bson.M{"$gt": bson.A{"$dueDate", time.Time{}}}
+	if !s.From.IsZero() {
+		from = bson.M{"$gte": bson.A{"$dueDate", s.From}}
+	}
+	return bson.M{"$and": bson.A{from, bson.M{"$lt": bson.A{"$dueDate", s.To}}}}
+}
+
+// Search uses the text index, which matches todos having any of the terms
+// and scores them by the index weights.
+func (r *MongoTodoRepository) Search(query domain.SearchQuery, page, limit int) ([]domain.SearchHit, int64, error) {
+	filter := listFilter(query.Filter)
+	filter["$text"] = bson.M{"$search": strings.Join(query.Terms, " ")}
+	score := bson.M{"$meta": "textScore"}
 	skip := int64(page * limit)
 	qLimit := int64(limit)
-	filter := bson.M{}
-	if title != "" {
-		filter["title"] = bson.M{"$regex": title, "$options": "i"}
-	}
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := r.context(5 * time.Second)
 	defer cancel()
 	cursor, err := r.collection.Find(ctx, filter, &options.FindOptions{
-		Sort:  bson.D{{Key: "createdAt", Value: -1}}, // Sort by createdAt in descending order
-		Skip:  &skip,
-		Limit: &qLimit,
+		Projection: bson.M{"score": score},
+		Sort:       bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}},
+		Skip:       &skip,
+		Limit:      &qLimit,
 	})
 	if err != nil {
 		return nil, 0, err
 	}
 	defer cursor.Close(ctx)
-
-	ctx2, cancel2 := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel2()
-	total, err = r.collection.CountDocuments(ctx2, filter)
+	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
-
-	var todos []*domain.Todo
+	hits := make([]domain.SearchHit, 0)
 	for cursor.Next(ctx) {
 		var item struct {
-			ID      string    `bson:"_id"`
-			Title   string    `bson:"title"`
-			DueDate time.Time `bson:"dueDate"`
-			Done    bool      `bson:"done"`
+			todoDocument `bson:",inline"`
+			Score        float64 `bson:"score"`
 		}
 		if err := cursor.Decode(&item); err != nil {
 			return nil, total, err
 		}
-		todos = append(todos, &domain.Todo{
-			ID:      item.ID,
-			Title:   item.Title,
-			DueDate: item.DueDate,
-			Done:    item.Done,
-		})
+		hits = append(hits, domain.SearchHit{Todo: item.toDomain(), Score: item.Score})
 	}
-	return todos, total, nil
+	return hits, total, cursor.Err()
+}
+
+func (r *MongoTodoRepository) FindByIDs(ids []string) ([]*domain.Todo, error) {
+	list, _, err := r.find(bson.M{"_id": bson.M{"$in": ids}, "deletedAt": nil}, bson.D{{Key: "_id", Value: 1}}, 0, len(ids))
+	return list, err
+}
+
+func (r *MongoTodoRepository) FindByID(id string) (*domain.Todo, error) {
+	return r.findOne(versioned(id, 0, liveFilter))
+}
+
+func (r *MongoTodoRepository) FindTrashedByID(id string) (*domain.Todo, error) {
+	return r.findOne(versioned(id, 0, trashedFilter))
 }
 
-func (r *MongoTodoRepository) DeleteByID(id string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *MongoTodoRepository) findOne(filter bson.M) (*domain.Todo, error) {
+	ctx, cancel := r.context(5 * time.Second)
 	defer cancel()
+	var item todoDocument
+	err := r.collection.FindOne(ctx, filter).Decode(&item)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return item.toDomain(), nil
+}
 
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+func (r *MongoTodoRepository) UpdateByID(todo *domain.Todo) error {
+	ctx, cancel := r.context(5 * time.Second)
+	defer cancel()
+
+	var updated todoDocument
+	err := r.collection.FindOneAndUpdate(ctx, versioned(todo.ID, todo.Version, liveFilter), bson.M{
+		"$set": bson.M{
+			"title":       todo.Title,
+			"dueDate":     todo.DueDate,
+			"allDay":      todo.AllDay,
+			"timeZone":    todo.TimeZone,
+			"updatedAt":   time.Now(),
+			"done":        todo.Done,
+			"completedAt": todo.CompletedAt,
+			"tags":        todo.Tags,
+			"priority":    todo.Priority,
+			"projectId":   todo.ProjectID,
+			"assignee":    todo.Assignee,
+			"mentions":    todo.Mentions,
+			"checklist":   newChecklistDocuments(todo.Checklist),
+			"recurrence":  newRecurrenceDocument(todo.Recurrence),
+			"attachments": newAttachmentDocuments(todo.Attachments),
+		},
+		"$inc": bson.M{"version": 1},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return r.missOrConflict(ctx, todo.ID, liveFilter)
+	}
 	if err != nil {
 		return err
 	}
+	todo.Version = updated.Version
+	return nil
+}
 
-	if result.DeletedCount == 0 {
-		return errors.New("there is no document with the given ID")
+// UpdateMany runs one UpdateMany over all (id, version) pairs. The update
+// is a pipeline so that tags and shifted due dates are computed from each
+// document's own values, the same way BulkChange.Apply does it.
+func (r *MongoTodoRepository) UpdateMany(todos []*domain.Todo, change domain.BulkChange) ([]string, error) {
+	set := bson.M{"updatedAt": time.Now(), "version": bson.M{"$add": bson.A{"$version", 1}}}
+	if change.Done != nil {
+		// every todo in a bulk write changes, so all of them are completed
+		// or reopened here
+		set["done"] = *change.Done
+		set["completedAt"] = nil
+		if *change.Done {
+			set["completedAt"] = change.At.UTC()
+		}
 	}
-	return err
+	if len(change.AddTags) > 0 || len(change.RemoveTags) > 0 {
+		set["tags"] = bson.M{"$let": bson.M{
+			"vars": bson.M{"kept": bson.M{"$filter": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$tags", bson.A{}}},
+				"cond":  bson.M{"$not": bson.A{bson.M{"$in": bson.A{"$$this", bson.M{"$literal": change.RemoveTags}}}}},
+			}}},
+			"in": bson.M{"$concatArrays": bson.A{"$$kept", bson.M{"$filter": bson.M{
+				"input": bson.M{"$literal": change.AddTags},
+				"cond":  bson.M{"$not": bson.A{bson.M{"$in": bson.A{"$$this", "$$kept"}}}},
+			}}}},
+		}}
+	}
+	if change.ProjectID != nil {
+		set["projectId"] = *change.ProjectID
+	}
+	// all-day todos stay due at midnight UTC, as BulkChange.Apply keeps them
+	if change.DueDate != nil {
+		set["dueDate"] = bson.M{"$cond": bson.A{"$allDay", domain.AllDayDate(*change.DueDate), *change.DueDate}}
+	}
+	// todos without a due date are left alone; a missing or zero dueDate
+	// sorts before every real date
+	if change.Shift != 0 {
+		shifted := bson.M{"$add": bson.A{"$dueDate", change.Shift.Milliseconds()}}
+		shifted = bson.M{"$cond": bson.A{"$allDay", bson.M{"$dateTrunc": bson.M{"date": shifted, "unit": "day"}}, shifted}}
+		set["dueDate"] = bson.M{"$cond": bson.A{bson.M{"$gt": bson.A{"$dueDate", time.Time{}}}, shifted, "$dueDate"}}
+	}
+	return r.writeMany(todos, set)
 }
 
-func (r *MongoTodoRepository) FindByID(id string) (*domain.Todo, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *MongoTodoRepository) TrashMany(todos []*domain.Todo, at time.Time) ([]string, error) {
+	return r.writeMany(todos, bson.M{
+		"deletedAt": at,
+		"updatedAt": time.Now(),
+		"version":   bson.M{"$add": bson.A{"$version", 1}},
+	})
+}
+
+// writeMany sets the given fields, in a single pipeline UpdateMany, on the
+// live todos whose (id, version) pair is still stored. Each write is tagged
+// with a fresh bulkOp value, so that when some pairs no longer match, the
+// changed documents can be told apart from ones changed by somebody else.
+func (r *MongoTodoRepository) writeMany(todos []*domain.Todo, set bson.M) ([]string, error) {
+	if len(todos) == 0 {
+		return []string{}, nil
+	}
+	ctx, cancel := r.context(10 * time.Second)
 	defer cancel()
-	var item struct {
-		ID      string    `bson:"_id"`
-		Title   string    `bson:"title"`
-		DueDate time.Time `bson:"dueDate"`
-		Done    bool      `bson:"done"`
+
+	op := uuid.New().String()
+	pairs := make(bson.A, 0, len(todos))
+	ids := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		pairs = append(pairs, bson.M{"_id": todo.ID, "version": todo.Version})
+		ids = append(ids, todo.ID)
 	}
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&item)
+	set["bulkOp"] = op
+	update := mongo.Pipeline{{{Key: "$set", Value: set}}}
+	result, err := r.collection.UpdateMany(ctx, bson.M{"$or": pairs, "deletedAt": nil}, update)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == int64(len(todos)) {
+		return ids, nil
+	}
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}, "bulkOp": op},
+		options.Find().SetProjection(bson.M{"_id": 1}))
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, errors.New("there is no document with the given ID")
-		}
 		return nil, err
 	}
-	return &domain.Todo{
-		ID:      item.ID,
-		Title:   item.Title,
-		DueDate: item.DueDate,
-		Done:    item.Done,
-	}, nil
+	defer cursor.Close(ctx)
+	changed := make([]string, 0, result.MatchedCount)
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID string `bson:"_id"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		changed = append(changed, doc.ID)
+	}
+	return changed, cursor.Err()
 }
 
-func (r *MongoTodoRepository) UpdateByID(todo *domain.Todo) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (r *MongoTodoRepository) TrashByID(id string, version int64, at time.Time) error {
+	ctx, cancel := r.context(5 * time.Second)
 	defer cancel()
 
-	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": todo.ID}, bson.M{
-		"$set": bson.M{
-			"title":     todo.Title,
-			"dueDate":   todo.DueDate,
-			"updatedAt": time.Now(),
-			"done":      todo.Done,
-		},
+	result, err := r.collection.UpdateOne(ctx, versioned(id, version, liveFilter), bson.M{
+		"$set": bson.M{"deletedAt": at, "updatedAt": time.Now()},
+		"$inc": bson.M{"version": 1},
 	})
-	if err == mongo.ErrNoDocuments {
-		return nil // No error if no document found
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return r.missOrConflict(ctx, id, liveFilter)
+	}
+	return nil
+}
+
+func (r *MongoTodoRepository) FindTrash(owner string, page, limit int) (list []*domain.Todo, total int64, err error) {
+	return r.find(bson.M{"deletedAt": bson.M{"$ne": nil}, "owner": owner}, bson.D{{Key: "deletedAt", Value: -1}}, page, limit)
+}
+
+func (r *MongoTodoRepository) RestoreByID(id string, version int64) error {
+	ctx, cancel := r.context(5 * time.Second)
+	defer cancel()
+
+	result, err := r.collection.UpdateOne(ctx, versioned(id, version, trashedFilter), bson.M{
+		"$unset": bson.M{"deletedAt": ""},
+		"$set":   bson.M{"updatedAt": time.Now()},
+		"$inc":   bson.M{"version": 1},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return r.missOrConflict(ctx, id, trashedFilter)
+	}
+	return nil
+}
+
+func (r *MongoTodoRepository) DeleteByID(id string, version int64) error {
+	ctx, cancel := r.context(5 * time.Second)
+	defer cancel()
+
+	result, err := r.collection.DeleteOne(ctx, versioned(id, version, trashedFilter))
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return r.missOrConflict(ctx, id, trashedFilter)
 	}
 	return err
 }
+
+func (r *MongoTodoRepository) FindExpiredTrash(before time.Time, limit int) ([]*domain.Todo, error) {
+	ctx, cancel := r.context(10 * time.Second)
+	defer cancel()
+	cursor, err := r.collection.Find(ctx, bson.M{"deletedAt": bson.M{"$ne": nil, "$lt": before}},
+		options.Find().SetSort(bson.D{{Key: "deletedAt", Value: 1}}).SetLimit(int64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	var docs []todoDocument
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	list := make([]*domain.Todo, 0, len(docs))
+	for _, d := range docs {
+		list = append(list, d.toDomain())
+	}
+	return list, nil
+}
+
+// RunInTx runs fn in a multi-document transaction, retrying it on
+// transient errors as the driver advises. It needs a replica set.
+func (r *MongoTodoRepository) RunInTx(fn func(tx domain.TodoRepository) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	session, err := r.collection.Database().Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (any, error) {
+		return nil, fn(&MongoTodoRepository{collection: r.collection, txCtx: sc})
+	})
+	return err
+}
+
+// context returns the context for one database call, bound to the session
+// when the repository belongs to a transaction.
+func (r *MongoTodoRepository) context(timeout time.Duration) (context.Context, context.CancelFunc) {
+	parent := r.txCtx
+	if parent == nil {
+		parent = context.Background()
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// find runs a paged query and counts the total number of matches.
+func (r *MongoTodoRepository) find(filter bson.M, sort bson.D, page, limit int) (list []*domain.Todo, total int64, err error) {
+	skip := int64(page * limit)
+	qLimit := int64(limit)
+	ctx, cancel := r.context(5 * time.Second)
+	defer cancel()
+	cursor, err := r.collection.Find(ctx, filter, &options.FindOptions{
+		Sort:  sort,
+		Skip:  &skip,
+		Limit: &qLimit,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	ctx2, cancel2 := r.context(5 * time.Second)
+	defer cancel2()
+	total, err = r.collection.CountDocuments(ctx2, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var todos []*domain.Todo
+	for cursor.Next(ctx) {
+		var item todoDocument
+		if err := cursor.Decode(&item); err != nil {
+			return nil, total, err
+		}
+		todos = append(todos, item.toDomain())
+	}
+	return todos, total, nil
+}
+
+// versioned builds a filter for the todo with the given ID in the given
+// state, pinned to version unless it is zero.
+func versioned(id string, version int64, state bson.M) bson.M {
+	filter := bson.M{"_id": id}
+	for k, v := range state {
+		filter[k] = v
+	}
+	if version != 0 {
+		filter["version"] = version
+	}
+	return filter
+}
+
+// missOrConflict tells apart the two reasons a versioned write can match no
+// document: the todo is gone (or not in the expected state), or it exists
+// with a different version.
+func (r *MongoTodoRepository) missOrConflict(ctx context.Context, id string, state bson.M) error {
+	n, err := r.collection.CountDocuments(ctx, versioned(id, 0, state))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return domain.ErrVersionConflict
+	}
+	return domain.ErrNotFound
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTodoDocument_UnversionedReadsAsFirstVersion(t *testing.T) {
+	legacy := todoDocument{ID: "a", Title: "Stored before versioning"}
+	assert.Equal(t, int64(1), legacy.toDomain().Version)
+	current := todoDocument{ID: "b", Title: "Edited", Version: 3}
+	assert.Equal(t, int64(3), current.toDomain().Version)
+}
@@ -0,0 +1,66 @@
+package repository
+
+import "todo-app/internal/todo/domain"
+
+// searchIndex is an inverted index from search terms to the todos having
+// them, weighted by where and how often each term occurs. It plays the part
+// of the text index used by the Mongo repository. Callers synchronise.
+type searchIndex struct {
+	// postings maps a term to the weight it has in each todo, by ID.
+	postings map[string]map[string]float64
+	// terms lists the terms each todo is indexed under, to remove it again.
+	terms map[string][]string
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{postings: map[string]map[string]float64{}, terms: map[string][]string{}}
+}
+
+// put indexes the todo, replacing what it was indexed under before.
+func (x *searchIndex) put(todo *domain.Todo) {
+	x.remove(todo.ID)
+	weights := map[string]float64{}
+	for _, term := range domain.Tokenize(todo.Title) {
+		weights[term] += domain.TitleWeight
+	}
+	for _, tag := range todo.Tags {
+		for _, term := range domain.Tokenize(tag) {
+			weights[term] += domain.TagWeight
+		}
+	}
+	terms := make([]string, 0, len(weights))
+	for term, weight := range weights {
+		if x.postings[term] == nil {
+			x.postings[term] = map[string]float64{}
+		}
+		x.postings[term][todo.ID] = weight
+		terms = append(terms, term)
+	}
+	x.terms[todo.ID] = terms
+}
+
+func (x *searchIndex) remove(id string) {
+	for _, term := range x.terms[id] {
+		delete(x.postings[term], id)
+		if len(x.postings[term]) == 0 {
+			delete(x.postings, term)
+		}
+	}
+	delete(x.terms, id)
+}
+
+// scores sums the weights of the terms per todo having any of them.
+func (x *searchIndex) scores(terms []string) map[string]float64 {
+	scores := map[string]float64{}
+	seen := map[string]bool{}
+	for _, term := range terms {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		for id, weight := range x.postings[term] {
+			scores[id] += weight
+		}
+	}
+	return scores
+}
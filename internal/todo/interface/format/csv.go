@@ -0,0 +1,218 @@
+// Package format reads and writes todos in the file formats people keep
+// them in outside the app.
+package format
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/usecase"
+)
+
+// CSVColumns are the columns of an export, in order. An import reads the
+// id, title, dueDate, done and tags columns and ignores the others.
+var CSVColumns = []string{"id", "title", "dueDate", "done", "tags", "recurrence", "version", "owner"}
+
+// csvFields are the fields an import can map columns to.
+var csvFields = []string{"id", "title", "dueDate", "done", "tags"}
+
+// CSVWriter writes todos as CSV rows under a header of CSVColumns.
+type CSVWriter struct {
+	w *csv.Writer
+}
+
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+func (cw *CSVWriter) WriteHeader() error {
+	return cw.w.Write(CSVColumns)
+}
+
+func (cw *CSVWriter) Write(todo *domain.Todo) error {
+	rule := ""
+	if todo.Recurrence != nil {
+		rule = todo.Recurrence.Rule
+	}
+	due := ""
+	if !todo.DueDate.IsZero() {
+		due = todo.DueDate.Format(time.RFC3339)
+	}
+	return cw.w.Write([]string{
+		todo.ID,
+		escapeFormula(todo.Title),
+		due,
+		strconv.FormatBool(todo.Done),
+		escapeFormula(strings.Join(todo.Tags, ",")),
+		rule,
+		strconv.FormatInt(todo.Version, 10),
+		escapeFormula(todo.Owner),
+	})
+}
+
+// Flush writes out buffered rows and reports any error writing them.
+func (cw *CSVWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// ReadCSV reads import rows from CSV whose first record is a header.
+// Columns are matched to fields by their header, ignoring case, unless
+// mapping names the field for a header; columns that match no field are
+// ignored. Empty cells leave the field out of the row. Rows whose cells
+// cannot be parsed carry the error in ImportRow.Err.
+func ReadCSV(r io.Reader, mapping map[string]string) ([]usecase.ImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, &domain.ValidationError{Field: "body", Message: "CSV has no header row"}
+	}
+	if err != nil {
+		return nil, &domain.ValidationError{Field: "body", Message: err.Error()}
+	}
+	columns, err := mapColumns(header, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []usecase.ImportRow
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, &domain.ValidationError{Field: "body", Message: err.Error()}
+		}
+		if len(rows) == usecase.MaxImportRows {
+			return nil, &domain.ValidationError{Field: "body", Message: "at most " + strconv.Itoa(usecase.MaxImportRows) + " rows can be imported at once"}
+		}
+		line, _ := cr.FieldPos(0)
+		rows = append(rows, parseCSVRow(line, record, columns))
+	}
+}
+
+// mapColumns returns the field each column is read into, "" for columns
+// that are ignored.
+func mapColumns(header []string, mapping map[string]string) ([]string, error) {
+	for column, field := range mapping {
+		if fieldName(field) == "" {
+			return nil, &domain.ValidationError{Field: "mapping", Message: "column " + strconv.Quote(column) + " is mapped to unknown field " + strconv.Quote(field)}
+		}
+	}
+	columns := make([]string, len(header))
+	seen := map[string]bool{}
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF"))
+		field, ok := mapping[name]
+		if !ok {
+			field = name
+		}
+		field = fieldName(field)
+		if field == "" {
+			continue
+		}
+		if seen[field] {
+			return nil, &domain.ValidationError{Field: "mapping", Message: "more than one column is read into " + field}
+		}
+		seen[field] = true
+		columns[i] = field
+	}
+	if !seen["id"] && !seen["title"] {
+		return nil, &domain.ValidationError{Field: "mapping", Message: "no column is read into id or title"}
+	}
+	return columns, nil
+}
+
+// fieldName returns the canonical name of an import field, or "".
+func fieldName(name string) string {
+	for _, field := range csvFields {
+		if strings.EqualFold(name, field) {
+			return field
+		}
+	}
+	return ""
+}
+
+func parseCSVRow(line int, record []string, columns []string) usecase.ImportRow {
+	row := usecase.ImportRow{Line: line}
+	for i, cell := range record {
+		if i >= len(columns) || columns[i] == "" {
+			continue
+		}
+		cell = unescapeFormula(strings.TrimSpace(cell))
+		if cell == "" {
+			continue
+		}
+		switch columns[i] {
+		case "id":
+			row.ID = cell
+		case "title":
+			row.Title = &cell
+		case "dueDate":
+			due, err := parseDate(cell)
+			if err != nil {
+				row.Err = &domain.ValidationError{Field: "dueDate", Message: "unrecognised date " + strconv.Quote(cell)}
+				return row
+			}
+			row.DueDate = &due
+		case "done":
+			done, err := parseDone(cell)
+			if err != nil {
+				row.Err = &domain.ValidationError{Field: "done", Message: "unrecognised value " + strconv.Quote(cell)}
+				return row
+			}
+			row.Done = &done
+		case "tags":
+			tags := strings.Split(cell, ",")
+			row.Tags = &tags
+		}
+	}
+	return row
+}
+
+// dateLayouts are tried in order; the ones without a zone are read as UTC.
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+func parseDate(s string) (time.Time, error) {
+	var err error
+	for _, layout := range dateLayouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func parseDone(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "true", "yes", "y", "x", "1", "done":
+		return true, nil
+	case "false", "no", "n", "0", "open":
+		return false, nil
+	}
+	return false, errors.New("not a boolean")
+}
+
+// escapeFormula keeps spreadsheets from evaluating a cell as a formula by
+// prefixing it with an apostrophe, which unescapeFormula removes again.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func unescapeFormula(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
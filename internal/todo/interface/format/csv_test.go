@@ -0,0 +1,62 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/todo/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSV_RoundTrip(t *testing.T) {
+	due := time.Date(2025, 7, 1, 9, 30, 0, 0, time.UTC)
+	todos := []*domain.Todo{
+		{ID: "a", Title: "Buy milk, eggs", DueDate: due, Done: true, Tags: []string{"home", "shop"}, Version: 3},
+		{ID: "b", Title: "=SUM(A1:A2)", Version: 1, Recurrence: &domain.Recurrence{Rule: "FREQ=DAILY"}},
+	}
+	var buf bytes.Buffer
+	w := NewCSVWriter(&buf)
+	require.NoError(t, w.WriteHeader())
+	for _, todo := range todos {
+		require.NoError(t, w.Write(todo))
+	}
+	require.NoError(t, w.Flush())
+	assert.Contains(t, buf.String(), "'=SUM(A1:A2)")
+
+	rows, err := ReadCSV(&buf, nil)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, 2, rows[0].Line)
+	assert.Equal(t, "a", rows[0].ID)
+	assert.Equal(t, "Buy milk, eggs", *rows[0].Title)
+	assert.True(t, due.Equal(*rows[0].DueDate))
+	assert.True(t, *rows[0].Done)
+	assert.Equal(t, []string{"home", "shop"}, *rows[0].Tags)
+	assert.Equal(t, "=SUM(A1:A2)", *rows[1].Title)
+	assert.Nil(t, rows[1].DueDate)
+	assert.Nil(t, rows[1].Tags)
+}
+
+func TestReadCSV_Mapping(t *testing.T) {
+	in := "Task,When,Status,Notes\nCall mom,2025-07-01,yes,ignored\nPay rent,next week,no,\n"
+	rows, err := ReadCSV(strings.NewReader(in), map[string]string{"Task": "title", "When": "dueDate", "Status": "done"})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "Call mom", *rows[0].Title)
+	assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), *rows[0].DueDate)
+	assert.True(t, *rows[0].Done)
+	var invalid *domain.ValidationError
+	require.ErrorAs(t, rows[1].Err, &invalid)
+	assert.Equal(t, "dueDate", invalid.Field)
+	assert.Equal(t, 3, rows[1].Line)
+
+	_, err = ReadCSV(strings.NewReader(in), map[string]string{"Task": "subject"})
+	assert.ErrorAs(t, err, &invalid)
+	_, err = ReadCSV(strings.NewReader(in), nil)
+	assert.ErrorAs(t, err, &invalid, "no id or title column")
+	_, err = ReadCSV(strings.NewReader(""), nil)
+	assert.ErrorAs(t, err, &invalid)
+}
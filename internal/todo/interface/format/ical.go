@@ -0,0 +1,330 @@
+package format
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/usecase"
+	"unicode/utf8"
+)
+
+// ICalProdID identifies the app as the producer of iCalendar data.
+const ICalProdID = "-//todo-app//Todo API//EN"
+
+// icalTime is the UTC date-time form of iCalendar, icalDate its date form.
+const (
+	icalTime = "20060102T150405Z"
+	icalDate = "20060102"
+)
+
+// ICalOptions tune what an ICalWriter writes.
+type ICalOptions struct {
+	// Name is shown by calendar apps as the calendar's name.
+	Name string
+	// Events adds a VEVENT at the due date of every todo that has one, for
+	// calendar apps that do not show tasks.
+	Events bool
+	// Stamp is the DTSTAMP of every component, when the data was produced.
+	Stamp time.Time
+}
+
+// ICalWriter writes todos as an iCalendar (RFC 5545) calendar, a VTODO per
+// todo. The UID of a VTODO is the todo's ID, and of its VEVENT the ID with
+// "-due" appended, so that calendar apps can track them across refreshes.
+type ICalWriter struct {
+	w    *bufio.Writer
+	opts ICalOptions
+	err  error
+}
+
+func NewICalWriter(w io.Writer, opts ICalOptions) *ICalWriter {
+	if opts.Stamp.IsZero() {
+		opts.Stamp = time.Now()
+	}
+	return &ICalWriter{w: bufio.NewWriter(w), opts: opts}
+}
+
+// WriteHeader opens the calendar.
+func (iw *ICalWriter) WriteHeader() error {
+	iw.line("BEGIN", "VCALENDAR")
+	iw.line("VERSION", "2.0")
+	iw.line("PRODID", ICalProdID)
+	iw.line("CALSCALE", "GREGORIAN")
+	if iw.opts.Name != "" {
+		iw.line("X-WR-CALNAME", escapeText(iw.opts.Name))
+	}
+	return iw.err
+}
+
+func (iw *ICalWriter) Write(todo *domain.Todo) error {
+	stamp := iw.opts.Stamp.UTC().Format(icalTime)
+	iw.line("BEGIN", "VTODO")
+	iw.line("UID", escapeText(todo.ID))
+	iw.line("DTSTAMP", stamp)
+	iw.line("SEQUENCE", strconv.FormatInt(max(todo.Version-1, 0), 10))
+	iw.line("SUMMARY", escapeText(todo.Title))
+	if !todo.DueDate.IsZero() {
+		iw.line(icalDue("DUE", todo))
+	}
+	if todo.Done {
+		iw.line("STATUS", "COMPLETED")
+		if todo.CompletedAt != nil {
+			iw.line("COMPLETED", todo.CompletedAt.UTC().Format(icalTime))
+		}
+	} else {
+		iw.line("STATUS", "NEEDS-ACTION")
+	}
+	if len(todo.Tags) > 0 {
+		tags := make([]string, len(todo.Tags))
+		for i, tag := range todo.Tags {
+			tags[i] = escapeText(tag)
+		}
+		iw.line("CATEGORIES", strings.Join(tags, ","))
+	}
+	iw.line("END", "VTODO")
+	if iw.opts.Events && !todo.DueDate.IsZero() {
+		// without DTEND the event takes no time, it only marks the due date
+		iw.line("BEGIN", "VEVENT")
+		iw.line("UID", escapeText(todo.ID+"-due"))
+		iw.line("DTSTAMP", stamp)
+		iw.line("SEQUENCE", strconv.FormatInt(max(todo.Version-1, 0), 10))
+		iw.line("SUMMARY", escapeText(todo.Title))
+		iw.line(icalDue("DTSTART", todo))
+		iw.line("TRANSP", "TRANSPARENT")
+		iw.line("END", "VEVENT")
+	}
+	return iw.err
+}
+
+// icalDue writes the due date of an all-day todo as a DATE, and others as a
+// UTC DATE-TIME.
+func icalDue(name string, todo *domain.Todo) (string, string) {
+	if todo.AllDay {
+		return name + ";VALUE=DATE", todo.DueDate.UTC().Format(icalDate)
+	}
+	return name, todo.DueDate.UTC().Format(icalTime)
+}
+
+// Close ends the calendar and flushes it.
+func (iw *ICalWriter) Close() error {
+	iw.line("END", "VCALENDAR")
+	if iw.err == nil {
+		iw.err = iw.w.Flush()
+	}
+	return iw.err
+}
+
+// line writes a content line, folded so that no line is longer than 75
+// octets, without splitting characters.
+func (iw *ICalWriter) line(name, value string) {
+	if iw.err != nil {
+		return
+	}
+	s := name + ":" + value
+	for limit := 75; len(s) > limit; limit = 74 {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		if _, iw.err = iw.w.WriteString(s[:cut] + "\r\n "); iw.err != nil {
+			return
+		}
+		s = s[cut:]
+	}
+	_, iw.err = iw.w.WriteString(s + "\r\n")
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escapeText escapes a TEXT value.
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// ReadICal reads an import row from every VTODO of iCalendar data: UID is
+// read into the ID, SUMMARY into the title, DUE into the due date,
+// CATEGORIES into the tags, and STATUS and COMPLETED into done. Other
+// components and properties are ignored. Rows whose properties cannot be
+// parsed carry the error in ImportRow.Err.
+func ReadICal(r io.Reader) ([]usecase.ImportRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var rows []usecase.ImportRow
+	var stack []string
+	var row *usecase.ImportRow
+	for _, raw := range unfoldLines(string(data)) {
+		if raw.text == "" {
+			continue
+		}
+		name, params, value, ok := splitContentLine(raw.text)
+		if !ok {
+			return nil, &domain.ValidationError{Field: "body", Message: "line " + strconv.Itoa(raw.line) + " is not a content line"}
+		}
+		if len(stack) == 0 && (name != "BEGIN" || !strings.EqualFold(value, "VCALENDAR")) {
+			return nil, &domain.ValidationError{Field: "body", Message: "iCalendar data must start with BEGIN:VCALENDAR"}
+		}
+		switch {
+		case name == "BEGIN":
+			stack = append(stack, strings.ToUpper(value))
+			if len(stack) == 2 && stack[1] == "VTODO" {
+				if len(rows) == usecase.MaxImportRows {
+					return nil, &domain.ValidationError{Field: "body", Message: "at most " + strconv.Itoa(usecase.MaxImportRows) + " rows can be imported at once"}
+				}
+				rows = append(rows, usecase.ImportRow{Line: raw.line})
+				row = &rows[len(rows)-1]
+			}
+		case name == "END":
+			if len(stack) == 0 || stack[len(stack)-1] != strings.ToUpper(value) {
+				return nil, &domain.ValidationError{Field: "body", Message: "line " + strconv.Itoa(raw.line) + " ends a component that is not open"}
+			}
+			stack = stack[:len(stack)-1]
+			row = nil
+		case row != nil && len(stack) == 2 && row.Err == nil:
+			// properties of nested components, such as alarms, are skipped
+			readTodoProperty(row, name, params, value)
+		}
+	}
+	if len(stack) > 0 {
+		return nil, &domain.ValidationError{Field: "body", Message: stack[len(stack)-1] + " is not ended"}
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return nil, &domain.ValidationError{Field: "body", Message: "iCalendar data is empty"}
+	}
+	return rows, nil
+}
+
+func readTodoProperty(row *usecase.ImportRow, name string, params map[string]string, value string) {
+	switch name {
+	case "UID":
+		row.ID = unescapeText(value)
+	case "SUMMARY":
+		title := unescapeText(value)
+		row.Title = &title
+	case "DUE":
+		due, err := parseICalTime(value, params)
+		if err != nil {
+			row.Err = &domain.ValidationError{Field: "dueDate", Message: "unrecognised DUE " + strconv.Quote(value)}
+			return
+		}
+		allDay := isICalDate(value, params)
+		row.DueDate, row.AllDay = &due, &allDay
+	case "STATUS":
+		done := strings.EqualFold(value, "COMPLETED")
+		if row.Done == nil || done {
+			row.Done = &done
+		}
+	case "COMPLETED":
+		done := true
+		row.Done = &done
+	case "CATEGORIES":
+		tags := []string{}
+		if row.Tags != nil {
+			tags = *row.Tags
+		}
+		for _, tag := range splitEscaped(value, ',') {
+			tags = append(tags, unescapeText(tag))
+		}
+		row.Tags = &tags
+	}
+}
+
+type contentLine struct {
+	line int
+	text string
+}
+
+// unfoldLines joins folded lines and numbers them by the line they start on.
+func unfoldLines(data string) []contentLine {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	var lines []contentLine
+	for i, text := range strings.Split(data, "\n") {
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+		lines = append(lines, contentLine{line: i + 1, text: strings.TrimSuffix(text, "\r")})
+	}
+	return lines
+}
+
+// splitContentLine splits "NAME;PARAM=value:value" into its parts. Names
+// and parameter names are upper-cased; quoted parameter values may hold
+// colons and semicolons.
+func splitContentLine(text string) (name string, params map[string]string, value string, ok bool) {
+	params = map[string]string{}
+	quoted := false
+	start, key := 0, ""
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '=' && name != "" && key == "":
+			key = strings.ToUpper(text[start:i])
+			start = i + 1
+		case c == ';' || c == ':':
+			part := text[start:i]
+			if name == "" {
+				name = strings.ToUpper(part)
+			} else if key != "" {
+				params[key] = strings.Trim(part, `"`)
+				key = ""
+			}
+			start = i + 1
+			if c == ':' {
+				return name, params, text[i+1:], name != ""
+			}
+		}
+	}
+	return "", nil, "", false
+}
+
+// parseICalTime reads a DATE or DATE-TIME value. Date-times in UTC end in Z,
+// others are in the zone named by TZID, and floating ones are read as UTC.
+func parseICalTime(value string, params map[string]string) (time.Time, error) {
+	if isICalDate(value, params) {
+		return time.Parse(icalDate, value)
+	}
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse(icalTime, value)
+	}
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		var err error
+		if loc, err = time.LoadLocation(strings.TrimPrefix(tzid, "/")); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.ParseInLocation("20060102T150405", value, loc)
+}
+
+func isICalDate(value string, params map[string]string) bool {
+	return params["VALUE"] == "DATE" || len(value) == len(icalDate)
+}
+
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func unescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
+
+// splitEscaped splits s at every sep that is not escaped with a backslash.
+func splitEscaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
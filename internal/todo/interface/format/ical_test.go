@@ -0,0 +1,107 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/todo/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestICalWriter(t *testing.T) {
+	due := time.Date(2025, 7, 1, 9, 30, 0, 0, time.FixedZone("CEST", 2*3600))
+	completed := time.Date(2025, 6, 30, 18, 0, 0, 0, time.UTC)
+	todos := []*domain.Todo{
+		{ID: "a", Title: "Buy milk; eggs, bread", DueDate: due, Done: true, CompletedAt: &completed, Tags: []string{"home", "a,b"}, Version: 3},
+		{ID: "b", Title: strings.Repeat("ü", 50), Version: 1},
+		{ID: "c", Title: "Ship release", DueDate: time.Date(2025, 7, 4, 0, 0, 0, 0, time.UTC), AllDay: true, Version: 1},
+	}
+	var buf bytes.Buffer
+	w := NewICalWriter(&buf, ICalOptions{Name: "Todos", Events: true, Stamp: completed})
+	require.NoError(t, w.WriteHeader())
+	for _, todo := range todos {
+		require.NoError(t, w.Write(todo))
+	}
+	require.NoError(t, w.Close())
+
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	assert.Contains(t, out, "BEGIN:VTODO\r\nUID:a\r\nDTSTAMP:20250630T180000Z\r\nSEQUENCE:2\r\n"+
+		"SUMMARY:Buy milk\\; eggs\\, bread\r\nDUE:20250701T073000Z\r\nSTATUS:COMPLETED\r\nCOMPLETED:20250630T180000Z\r\n"+
+		"CATEGORIES:home,a\\,b\r\nEND:VTODO\r\n")
+	assert.Contains(t, out, "BEGIN:VEVENT\r\nUID:a-due\r\n")
+	assert.Contains(t, out, "DTSTART:20250701T073000Z\r\n")
+	assert.Contains(t, out, "UID:b\r\n")
+	assert.Contains(t, out, "STATUS:NEEDS-ACTION\r\n")
+	// b has no due date, so neither DUE nor an event
+	assert.Equal(t, 2, strings.Count(out, "BEGIN:VEVENT"))
+	assert.Equal(t, 1, strings.Count(out, "DUE:"))
+	assert.Contains(t, out, "DUE;VALUE=DATE:20250704\r\n")
+	assert.Contains(t, out, "DTSTART;VALUE=DATE:20250704\r\n")
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	unfolded := strings.ReplaceAll(out, "\r\n ", "")
+	assert.Contains(t, unfolded, "SUMMARY:"+strings.Repeat("ü", 50)+"\r\n")
+}
+
+func TestReadICal(t *testing.T) {
+	data := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VTODO\r\nUID:a\r\nSUMMARY:Buy milk\\; eggs\\, bre\r\n ad\r\nDUE;TZID=Europe/Berlin:20250701T093000\r\n" +
+		"STATUS:COMPLETED\r\nCATEGORIES:home,a\\,b\r\nCATEGORIES:shop\r\n" +
+		"BEGIN:VALARM\r\nSUMMARY:Alarm\r\nEND:VALARM\r\nEND:VTODO\r\n" +
+		"BEGIN:VEVENT\r\nUID:e\r\nSUMMARY:Meeting\r\nEND:VEVENT\r\n" +
+		"BEGIN:VTODO\r\nUID:b\r\nSUMMARY:Plan trip\r\nDUE;VALUE=DATE:20250801\r\nSTATUS:NEEDS-ACTION\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:c\r\nDUE:tomorrow\r\nEND:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+	rows, err := ReadICal(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	assert.Equal(t, 3, rows[0].Line)
+	assert.Equal(t, "a", rows[0].ID)
+	assert.Equal(t, "Buy milk; eggs, bread", *rows[0].Title)
+	assert.True(t, time.Date(2025, 7, 1, 7, 30, 0, 0, time.UTC).Equal(*rows[0].DueDate))
+	assert.True(t, *rows[0].Done)
+	assert.Equal(t, []string{"home", "a,b", "shop"}, *rows[0].Tags)
+	assert.False(t, *rows[0].AllDay)
+
+	assert.Equal(t, "Plan trip", *rows[1].Title)
+	assert.Equal(t, time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), *rows[1].DueDate)
+	assert.True(t, *rows[1].AllDay)
+	assert.False(t, *rows[1].Done)
+	assert.Nil(t, rows[1].Tags)
+
+	var invalid *domain.ValidationError
+	require.ErrorAs(t, rows[2].Err, &invalid)
+	assert.Equal(t, "dueDate", invalid.Field)
+
+	_, err = ReadICal(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nEND:VCALENDAR\r\n"))
+	assert.ErrorAs(t, err, &invalid)
+	_, err = ReadICal(strings.NewReader("hello"))
+	assert.ErrorAs(t, err, &invalid)
+}
+
+func TestICal_RoundTrip(t *testing.T) {
+	completed := time.Date(2025, 6, 30, 18, 0, 0, 0, time.UTC)
+	todo := &domain.Todo{ID: "a", Title: "Line one\nline; two, " + strings.Repeat("x", 80), DueDate: completed, Done: true, CompletedAt: &completed, Tags: []string{"x,y", `back\slash`}, Version: 1}
+	var buf bytes.Buffer
+	w := NewICalWriter(&buf, ICalOptions{})
+	require.NoError(t, w.WriteHeader())
+	require.NoError(t, w.Write(todo))
+	require.NoError(t, w.Close())
+
+	rows, err := ReadICal(&buf)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, todo.ID, rows[0].ID)
+	assert.Equal(t, todo.Title, *rows[0].Title)
+	assert.True(t, todo.DueDate.Equal(*rows[0].DueDate))
+	assert.True(t, *rows[0].Done)
+	assert.Equal(t, todo.Tags, *rows[0].Tags)
+}
@@ -0,0 +1,290 @@
+package format
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/usecase"
+)
+
+// Markdown groupings: by the day todos are due, or by tag.
+const (
+	GroupByDue = "due"
+	GroupByTag = "tag"
+)
+
+// maxMarkdownLine caps the length of a Markdown line.
+const maxMarkdownLine = 64 << 10
+
+// WriteMarkdown writes todos as GitHub-style task lists under a "##"
+// heading per due day (UTC) or per tag; a todo with several tags is listed
+// under each. Todos without a due date or tag come last. Every item carries
+// its tags as #hashtags and its due date as a due: key, so that importing
+// the document restores them, and checklist items are nested under it.
+func WriteMarkdown(w io.Writer, todos []*domain.Todo, groupBy string) error {
+	groups := map[string][]*domain.Todo{}
+	var keys []string
+	add := func(key string, todo *domain.Todo) {
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], todo)
+	}
+	for _, todo := range todos {
+		switch {
+		case groupBy == GroupByTag:
+			for _, tag := range todo.Tags {
+				add(tag, todo)
+			}
+			if len(todo.Tags) == 0 {
+				add("", todo)
+			}
+		case todo.DueDate.IsZero():
+			add("", todo)
+		default:
+			add(todo.DueDate.UTC().Format(todoTxtDate), todo)
+		}
+	}
+	// dates sort by day in this form; the empty key goes last
+	slices.SortFunc(keys, func(a, b string) int {
+		if a == "" || b == "" {
+			return len(b) - len(a)
+		}
+		return strings.Compare(a, b)
+	})
+
+	bw := bufio.NewWriter(w)
+	for i, key := range keys {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		bw.WriteString("## " + markdownHeading(key, groupBy) + "\n\n")
+		for _, todo := range groups[key] {
+			bw.WriteString(markdownItem(todo) + "\n")
+			for _, item := range todo.Checklist {
+				bw.WriteString("  - " + checkbox(item.Checked) + " " + escapeMarkdown(strings.Join(strings.Fields(item.Text), " ")) + "\n")
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+func markdownHeading(key, groupBy string) string {
+	switch {
+	case key != "":
+		return escapeMarkdown(key)
+	case groupBy == GroupByTag:
+		return "Untagged"
+	}
+	return "No due date"
+}
+
+func markdownItem(todo *domain.Todo) string {
+	title := escapeMarkdown(strings.Join(strings.Fields(todo.Title), " "))
+	// words an import would read as tags; issue references stay links
+	title = hashtag.ReplaceAllStringFunc(title, func(match string) string {
+		if _, ok := hashtagTag(match); ok {
+			return strings.Replace(match, "#", `\#`, 1)
+		}
+		return match
+	})
+	parts := []string{"-", checkbox(todo.Done), title}
+	for _, tag := range todo.Tags {
+		parts = append(parts, "#"+strings.Join(strings.Fields(tag), "_"))
+	}
+	if !todo.DueDate.IsZero() {
+		parts = append(parts, "due:"+formatTodoTxtDue(todo.DueDate))
+	}
+	return strings.Join(parts, " ")
+}
+
+func checkbox(checked bool) string {
+	if checked {
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+// markdownSpecial are the characters escaped in text, so that titles read
+// the same once rendered.
+const markdownSpecial = "\\`*_[]<>"
+
+func escapeMarkdown(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(markdownSpecial, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// unescapeMarkdown removes backslash escapes of ASCII punctuation, as
+// CommonMark reads them.
+func unescapeMarkdown(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", s[i+1]) >= 0 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+var (
+	// taskItem matches a task list item: its indentation, and whether it
+	// is checked, followed by its text.
+	taskItem = regexp.MustCompile(`^([ \t]*)(?:[-*+]|\d{1,9}[.)])[ \t]+\[([ xX])\](?:[ \t]+(.*))?$`)
+	// listItem matches any list item, to tell the nesting of task items.
+	listItem = regexp.MustCompile(`^([ \t]*)(?:[-*+]|\d{1,9}[.)])(?:[ \t]|$)`)
+	// inlineDue matches the due dates of "due: 2025-07-01", of the Obsidian
+	// Tasks plugin ("📅 2025-07-01") and of TaskPaper ("@due(2025-07-01)").
+	inlineDue = regexp.MustCompile(`(?:\bdue:[ \t]*|📅[ \t]*|@due\()(\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}(?::\d{2})?(?:Z|[+-]\d{2}:\d{2})?)?)\)?`)
+	// hashtag matches a #tag at the start of a word.
+	hashtag = regexp.MustCompile(`(^|[ \t])#([^\s#]+)`)
+)
+
+// ReadMarkdown reads import rows from the task list items of a Markdown
+// document, such as "- [ ] Buy milk #home due:2025-07-01". Each task item
+// that is not nested in another one is a todo: its #hashtags become tags
+// and an inline due date its due date. Task items nested in it, at any
+// depth, become its checklist. Other content, including code blocks, is
+// skipped.
+func ReadMarkdown(r io.Reader) ([]usecase.ImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxMarkdownLine)
+	var rows []usecase.ImportRow
+	// parents are the list items the current line may be nested in, by
+	// indentation; todo is the index of the row a task item started, or -1
+	type parent struct {
+		indent int
+		todo   int
+	}
+	var parents []parent
+	fence := ""
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\uFEFF")
+		}
+		trimmed := strings.TrimSpace(text)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		if trimmed == "" {
+			continue
+		}
+		m := listItem.FindStringSubmatch(text)
+		if m == nil {
+			if indentation(text) == 0 {
+				// a paragraph or heading ends the list
+				parents = parents[:0]
+			}
+			continue
+		}
+		indent := indentation(m[1])
+		for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+			parents = parents[:len(parents)-1]
+		}
+		task := taskItem.FindStringSubmatch(text)
+		owner := -1
+		for _, p := range parents {
+			if p.todo >= 0 {
+				owner = p.todo
+				break
+			}
+		}
+		switch {
+		case task == nil:
+		case owner >= 0:
+			item := domain.ChecklistItem{Text: unescapeMarkdown(strings.TrimSpace(task[3])), Checked: task[2] != " "}
+			rows[owner].Checklist = append(rows[owner].Checklist, item)
+		default:
+			if len(rows) == usecase.MaxImportRows {
+				return nil, &domain.ValidationError{Field: "body", Message: "at most " + strconv.Itoa(usecase.MaxImportRows) + " rows can be imported at once"}
+			}
+			rows = append(rows, parseMarkdownTask(line, task[3], task[2] != " "))
+			owner = len(rows) - 1
+		}
+		if task == nil {
+			owner = -1
+		}
+		parents = append(parents, parent{indent: indent, todo: owner})
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, &domain.ValidationError{Field: "body", Message: "lines can be at most " + strconv.Itoa(maxMarkdownLine) + " bytes long"}
+		}
+		return nil, err
+	}
+	return rows, nil
+}
+
+func parseMarkdownTask(line int, text string, done bool) usecase.ImportRow {
+	row := usecase.ImportRow{Line: line, Done: &done}
+	if m := inlineDue.FindStringSubmatch(text); m != nil {
+		due, err := parseDate(m[1])
+		if err != nil {
+			row.Err = &domain.ValidationError{Field: "dueDate", Message: "unrecognised date " + strconv.Quote(m[1])}
+			return row
+		}
+		row.DueDate = &due
+		text = strings.Replace(text, m[0], "", 1)
+	}
+	var tags []string
+	text = hashtag.ReplaceAllStringFunc(text, func(match string) string {
+		tag, ok := hashtagTag(match)
+		if !ok {
+			return match
+		}
+		tags = append(tags, tag)
+		return hashtag.FindStringSubmatch(match)[1]
+	})
+	if tags != nil {
+		row.Tags = &tags
+	}
+	title := unescapeMarkdown(strings.Join(strings.Fields(text), " "))
+	row.Title = &title
+	return row
+}
+
+// hashtagTag returns the tag of a hashtag match. "#123" refers to an issue
+// or pull request, so it is no tag.
+func hashtagTag(match string) (string, bool) {
+	tag := strings.TrimRight(hashtag.FindStringSubmatch(match)[2], ".,;:!?)")
+	if strings.Trim(tag, "0123456789") == "" {
+		return "", false
+	}
+	return tag, true
+}
+
+// indentation counts leading whitespace, with tabs to the next multiple of
+// four as CommonMark does.
+func indentation(s string) int {
+	n := 0
+	for _, r := range s {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 4 - n%4
+		default:
+			return n
+		}
+	}
+	return n
+}
@@ -0,0 +1,96 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/todo/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	day := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	todos := []*domain.Todo{
+		{ID: "a", Title: "Buy *milk*", DueDate: day.Add(9 * time.Hour), Tags: []string{"home", "shop"},
+			Checklist: []domain.ChecklistItem{{Text: "Oat", Checked: true}, {Text: "Soy"}}},
+		{ID: "b", Title: "File taxes", Done: true},
+		{ID: "c", Title: "Call mom", DueDate: day.AddDate(0, 0, -1), Tags: []string{"home"}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, todos, GroupByDue))
+	assert.Equal(t, "## 2025-06-30\n\n- [ ] Call mom #home due:2025-06-30\n\n"+
+		"## 2025-07-01\n\n- [ ] Buy \\*milk\\* #home #shop due:2025-07-01T09:00:00Z\n  - [x] Oat\n  - [ ] Soy\n\n"+
+		"## No due date\n\n- [x] File taxes\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, WriteMarkdown(&buf, todos, GroupByTag))
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "## home\n\n- [ ] Buy"))
+	assert.Contains(t, out, "- [ ] Call mom #home due:2025-06-30\n\n## shop\n\n- [ ] Buy")
+	assert.True(t, strings.HasSuffix(out, "## Untagged\n\n- [x] File taxes\n"))
+}
+
+func TestReadMarkdown(t *testing.T) {
+	doc := "# Sprint 12\n\n" +
+		"Some notes about #42 and the plan.\n\n" +
+		"- [ ] Ship the release #work due: 2025-07-01\n" +
+		"  - [x] Tag the commit\n" +
+		"  - Notes\n" +
+		"    - [ ] Write \\*changelog\\*\n" +
+		"- [X] Fix #123 📅 2025-06-30\n" +
+		"* [ ] Plan trip @due(2025-08-01 09:30) #travel, #fun\n" +
+		"- not a task\n" +
+		"  - [ ] Book hotel\n" +
+		"```\n- [ ] in a code block\n```\n" +
+		"1. [ ] Water plants due:tomorrow-ish\n" +
+		"2. [ ] Broken due:2025-13-45\n"
+	rows, err := ReadMarkdown(strings.NewReader(doc))
+	require.NoError(t, err)
+	require.Len(t, rows, 6)
+
+	assert.Equal(t, 5, rows[0].Line)
+	assert.Equal(t, "Ship the release", *rows[0].Title)
+	assert.Equal(t, []string{"work"}, *rows[0].Tags)
+	assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), *rows[0].DueDate)
+	assert.False(t, *rows[0].Done)
+	assert.Equal(t, []domain.ChecklistItem{{Text: "Tag the commit", Checked: true}, {Text: "Write *changelog*"}}, rows[0].Checklist)
+
+	assert.Equal(t, "Fix #123", *rows[1].Title)
+	assert.True(t, *rows[1].Done)
+	assert.Nil(t, rows[1].Tags)
+	assert.Equal(t, time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), *rows[1].DueDate)
+
+	assert.Equal(t, "Plan trip", *rows[2].Title)
+	assert.Equal(t, []string{"travel", "fun"}, *rows[2].Tags)
+	assert.Equal(t, time.Date(2025, 8, 1, 9, 30, 0, 0, time.UTC), *rows[2].DueDate)
+
+	// a task under a plain item is a todo of its own
+	assert.Equal(t, "Book hotel", *rows[3].Title)
+
+	// "due:tomorrow-ish" is no date, so it stays in the title
+	assert.Equal(t, "Water plants due:tomorrow-ish", *rows[4].Title)
+	assert.Nil(t, rows[4].DueDate)
+	var invalid *domain.ValidationError
+	require.ErrorAs(t, rows[5].Err, &invalid)
+	assert.Equal(t, "dueDate", invalid.Field)
+}
+
+func TestMarkdown_RoundTrip(t *testing.T) {
+	todo := &domain.Todo{ID: "a", Title: "Fix [bug] in `main_loop` #7 of #golang", DueDate: time.Date(2025, 7, 1, 9, 30, 0, 0, time.UTC), Done: true,
+		Tags: []string{"work", "q3"}, Checklist: []domain.ChecklistItem{{Text: "Write a test", Checked: true}}}
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, []*domain.Todo{todo}, GroupByDue))
+
+	rows, err := ReadMarkdown(&buf)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, todo.Title, *rows[0].Title)
+	assert.True(t, todo.DueDate.Equal(*rows[0].DueDate))
+	assert.True(t, *rows[0].Done)
+	assert.Equal(t, todo.Tags, *rows[0].Tags)
+	assert.Equal(t, todo.Checklist, rows[0].Checklist)
+}
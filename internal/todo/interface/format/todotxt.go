@@ -0,0 +1,205 @@
+package format
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/usecase"
+)
+
+// todoTxtDate is the date form of todo.txt.
+const todoTxtDate = "2006-01-02"
+
+// maxTodoTxtLine caps the length of a todo.txt line.
+const maxTodoTxtLine = 64 << 10
+
+// TodoTxtWriter writes todos as todo.txt lines
+// (https://github.com/todotxt/todo.txt): completed todos start with "x" and
+// their completion date, open ones with their priority. Tags become +project
+// tokens, except tags starting with "@", which are contexts. The due date,
+// the priority of a completed todo and the ID follow as due:, pri: and id:
+// keys, so that importing the file again updates the same todos. Title
+// words that would read back as one of these are escaped with a leading
+// backslash.
+type TodoTxtWriter struct {
+	w *bufio.Writer
+}
+
+func NewTodoTxtWriter(w io.Writer) *TodoTxtWriter {
+	return &TodoTxtWriter{w: bufio.NewWriter(w)}
+}
+
+func (tw *TodoTxtWriter) Write(todo *domain.Todo) error {
+	var parts []string
+	switch {
+	case todo.Done:
+		parts = append(parts, "x")
+		if todo.CompletedAt != nil {
+			parts = append(parts, todo.CompletedAt.UTC().Format(todoTxtDate))
+		}
+	case todo.Priority != "":
+		parts = append(parts, "("+todo.Priority+")")
+	}
+	// a line holds a single todo
+	for i, word := range strings.Fields(todo.Title) {
+		parts = append(parts, escapeTodoTxtWord(word, i == 0 && leadIsMarker(todo)))
+	}
+	for _, tag := range todo.Tags {
+		tag = strings.Join(strings.Fields(tag), "_")
+		if len(tag) > 1 && tag[0] == '@' {
+			parts = append(parts, tag)
+			continue
+		}
+		parts = append(parts, "+"+tag)
+	}
+	if !todo.DueDate.IsZero() {
+		parts = append(parts, "due:"+formatTodoTxtDue(todo.DueDate))
+	}
+	if todo.Done && todo.Priority != "" {
+		// todo.txt drops the priority of completed tasks; keep it as a key
+		parts = append(parts, "pri:"+todo.Priority)
+	}
+	parts = append(parts, "id:"+todo.ID)
+	_, err := tw.w.WriteString(strings.Join(parts, " ") + "\n")
+	return err
+}
+
+// Flush writes out buffered lines and reports any error writing them.
+func (tw *TodoTxtWriter) Flush() error {
+	return tw.w.Flush()
+}
+
+// leadIsMarker reports whether the first word of the todo's title would be
+// read back as part of the line's start: as the "x" of a completed todo or
+// a priority when nothing comes before it, or as the completion date of a
+// completed todo that has none.
+func leadIsMarker(todo *domain.Todo) bool {
+	if todo.Done {
+		return todo.CompletedAt == nil
+	}
+	return todo.Priority == ""
+}
+
+// escapeTodoTxtWord prefixes a title word with a backslash when it would
+// be read back as a tag, a context or a key, or, when lead is set, as the
+// marker the line starts with. Words that start with a backslash are
+// escaped too, as ReadTodoTxt drops the first one.
+func escapeTodoTxtWord(word string, lead bool) string {
+	key, value, _ := strings.Cut(word, ":")
+	switch {
+	case strings.HasPrefix(word, `\`),
+		len(word) > 1 && (word[0] == '+' || word[0] == '@'),
+		value != "" && (key == "due" || key == "pri" || key == "id"),
+		lead && (word == "x" || isTodoTxtPriority(word) || isTodoTxtDate(word)):
+		return `\` + word
+	}
+	return word
+}
+
+// formatTodoTxtDue writes due dates at midnight UTC as plain dates, the
+// way todo.txt tools write them, and others with their time.
+func formatTodoTxtDue(due time.Time) string {
+	due = due.UTC()
+	if due.Equal(due.Truncate(24 * time.Hour)) {
+		return due.Format(todoTxtDate)
+	}
+	return due.Format(time.RFC3339)
+}
+
+// ReadTodoTxt reads import rows from a todo.txt file, one per non-empty
+// line. Each line describes the whole todo, so fields it does not have
+// are cleared: a line without due: removes the due date. The text that is
+// left once the markers and the due:, pri: and id: keys are taken out is
+// the title; a creation date stays at its start, as todos have no field
+// for it. A word escaped with a leading backslash is a title word, whatever
+// it looks like. Lines whose keys cannot be parsed carry the error in
+// ImportRow.Err.
+func ReadTodoTxt(r io.Reader) ([]usecase.ImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxTodoTxtLine)
+	var rows []usecase.ImportRow
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if line == 1 {
+			text = strings.TrimPrefix(text, "\uFEFF")
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if len(rows) == usecase.MaxImportRows {
+			return nil, &domain.ValidationError{Field: "body", Message: "at most " + strconv.Itoa(usecase.MaxImportRows) + " rows can be imported at once"}
+		}
+		rows = append(rows, parseTodoTxtLine(line, text))
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, &domain.ValidationError{Field: "body", Message: "lines can be at most " + strconv.Itoa(maxTodoTxtLine) + " bytes long"}
+		}
+		return nil, err
+	}
+	return rows, nil
+}
+
+func parseTodoTxtLine(line int, text string) usecase.ImportRow {
+	words := strings.Fields(text)
+	done, priority := false, ""
+	var completed *time.Time
+	switch {
+	case words[0] == "x":
+		done, words = true, words[1:]
+		if len(words) > 0 {
+			if at, err := time.Parse(todoTxtDate, words[0]); err == nil {
+				completed, words = &at, words[1:]
+			}
+		}
+	case isTodoTxtPriority(words[0]):
+		priority, words = words[0][1:2], words[1:]
+	}
+
+	row := usecase.ImportRow{Line: line, Done: &done, CompletedAt: completed}
+	var due time.Time
+	tags := []string{}
+	title := make([]string, 0, len(words))
+	for _, word := range words {
+		key, value, _ := strings.Cut(word, ":")
+		switch {
+		case strings.HasPrefix(word, `\`):
+			title = append(title, word[1:])
+		case len(word) > 1 && word[0] == '+':
+			tags = append(tags, word[1:])
+		case len(word) > 1 && word[0] == '@':
+			tags = append(tags, word)
+		case key == "due" && value != "":
+			at, err := parseDate(value)
+			if err != nil {
+				row.Err = &domain.ValidationError{Field: "dueDate", Message: "unrecognised date " + strconv.Quote(value)}
+				return row
+			}
+			due = at
+		case key == "pri" && value != "":
+			priority = value
+		case key == "id" && value != "":
+			row.ID = value
+		default:
+			title = append(title, word)
+		}
+	}
+	joined := strings.Join(title, " ")
+	row.Title, row.DueDate, row.Tags, row.Priority = &joined, &due, &tags, &priority
+	return row
+}
+
+// isTodoTxtDate reports whether word is a date such as "2025-07-01".
+func isTodoTxtDate(word string) bool {
+	_, err := time.Parse(todoTxtDate, word)
+	return err == nil
+}
+
+// isTodoTxtPriority reports whether word is a priority such as "(A)".
+func isTodoTxtPriority(word string) bool {
+	return len(word) == 3 && word[0] == '(' && word[1] >= 'A' && word[1] <= 'Z' && word[2] == ')'
+}
@@ -0,0 +1,126 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/todo/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoTxtWriter(t *testing.T) {
+	completed := time.Date(2025, 6, 30, 18, 0, 0, 0, time.UTC)
+	todos := []*domain.Todo{
+		{ID: "a", Title: "Call mom", Priority: "A", DueDate: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), Tags: []string{"family", "@phone"}},
+		{ID: "b", Title: "File\ntaxes", Done: true, CompletedAt: &completed, Priority: "B", DueDate: time.Date(2025, 7, 1, 9, 30, 0, 0, time.UTC), Tags: []string{"big project"}},
+	}
+	var buf bytes.Buffer
+	w := NewTodoTxtWriter(&buf)
+	for _, todo := range todos {
+		require.NoError(t, w.Write(todo))
+	}
+	require.NoError(t, w.Flush())
+	assert.Equal(t, "(A) Call mom +family @phone due:2025-07-01 id:a\n"+
+		"x 2025-06-30 File taxes +big_project due:2025-07-01T09:30:00Z pri:B id:b\n", buf.String())
+}
+
+func TestReadTodoTxt(t *testing.T) {
+	data := "\uFEFF(A) 2025-06-01 Call mom @phone +family due:2025-07-01\n" +
+		"\n" +
+		"x 2025-06-30 2025-06-01 Pay rent see http://bank.example pri:c id:rent\n" +
+		"x Water plants\n" +
+		"Plan trip due:someday\n"
+	rows, err := ReadTodoTxt(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	assert.Equal(t, 1, rows[0].Line)
+	assert.Equal(t, "", rows[0].ID)
+	assert.Equal(t, "2025-06-01 Call mom", *rows[0].Title)
+	assert.Equal(t, "A", *rows[0].Priority)
+	assert.False(t, *rows[0].Done)
+	assert.Nil(t, rows[0].CompletedAt)
+	assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), *rows[0].DueDate)
+	assert.Equal(t, []string{"@phone", "family"}, *rows[0].Tags)
+
+	assert.Equal(t, 3, rows[1].Line)
+	assert.Equal(t, "rent", rows[1].ID)
+	assert.Equal(t, "2025-06-01 Pay rent see http://bank.example", *rows[1].Title)
+	assert.Equal(t, "c", *rows[1].Priority)
+	assert.True(t, *rows[1].Done)
+	assert.Equal(t, time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), *rows[1].CompletedAt)
+	assert.True(t, rows[1].DueDate.IsZero())
+	assert.Empty(t, *rows[1].Tags)
+
+	assert.Equal(t, "Water plants", *rows[2].Title)
+	assert.True(t, *rows[2].Done)
+	assert.Nil(t, rows[2].CompletedAt)
+
+	var invalid *domain.ValidationError
+	require.ErrorAs(t, rows[3].Err, &invalid)
+	assert.Equal(t, "dueDate", invalid.Field)
+}
+
+func TestTodoTxt_RoundTrip(t *testing.T) {
+	completed := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	todo := &domain.Todo{ID: "a", Title: "2025-06-01 Pay rent", DueDate: time.Date(2025, 7, 1, 9, 30, 0, 0, time.UTC), Done: true, CompletedAt: &completed, Priority: "B", Tags: []string{"home", "@desk", "+plus"}}
+	var buf bytes.Buffer
+	w := NewTodoTxtWriter(&buf)
+	require.NoError(t, w.Write(todo))
+	require.NoError(t, w.Flush())
+	line := buf.String()
+
+	rows, err := ReadTodoTxt(&buf)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, todo.ID, rows[0].ID)
+	assert.Equal(t, todo.Title, *rows[0].Title)
+	assert.True(t, todo.DueDate.Equal(*rows[0].DueDate))
+	assert.True(t, *rows[0].Done)
+	assert.Equal(t, completed, *rows[0].CompletedAt)
+	assert.Equal(t, todo.Priority, *rows[0].Priority)
+	assert.Equal(t, todo.Tags, *rows[0].Tags)
+
+	// and the line comes out the same again
+	var again bytes.Buffer
+	w = NewTodoTxtWriter(&again)
+	require.NoError(t, w.Write(&domain.Todo{ID: rows[0].ID, Title: *rows[0].Title, DueDate: *rows[0].DueDate, Done: true, CompletedAt: rows[0].CompletedAt, Priority: *rows[0].Priority, Tags: *rows[0].Tags}))
+	require.NoError(t, w.Flush())
+	assert.Equal(t, line, again.String())
+}
+
+func TestTodoTxt_RoundTripEscapesTitleWords(t *testing.T) {
+	completed := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	todos := []*domain.Todo{
+		{ID: "a", Title: "x ray results"},
+		{ID: "b", Title: "(B) is not a priority"},
+		{ID: "c", Title: "Vote +1 and @home due:friday id:7 pri:A"},
+		{ID: "d", Title: `Back up C:\ and \\server`},
+		{ID: "e", Title: "2025-06-01 is no completion date", Done: true},
+		{ID: "f", Title: "x marks the spot", Done: true, CompletedAt: &completed, Priority: "C"},
+	}
+	var buf bytes.Buffer
+	w := NewTodoTxtWriter(&buf)
+	for _, todo := range todos {
+		require.NoError(t, w.Write(todo))
+	}
+	require.NoError(t, w.Flush())
+	assert.Equal(t, `\x ray results id:a`+"\n", strings.SplitAfter(buf.String(), "\n")[0])
+
+	rows, err := ReadTodoTxt(&buf)
+	require.NoError(t, err)
+	require.Len(t, rows, len(todos))
+	for i, todo := range todos {
+		row := rows[i]
+		assert.Equal(t, todo.ID, row.ID)
+		assert.Equal(t, todo.Title, *row.Title)
+		assert.Equal(t, todo.Done, *row.Done, todo.Title)
+		assert.Equal(t, todo.Priority, *row.Priority, todo.Title)
+		assert.Empty(t, *row.Tags, todo.Title)
+		assert.True(t, row.DueDate.IsZero(), todo.Title)
+		assert.Equal(t, todo.CompletedAt, row.CompletedAt, todo.Title)
+	}
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"time"
+	"todo-app/internal/api/middleware"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/usecase"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+const (
+	// uploadOverhead is what a multipart form may add around the file it
+	// carries: boundaries and part headers.
+	uploadOverhead = 64 << 10
+	// uploadReadTimeout is how long a client has to send an upload.
+	uploadReadTimeout = time.Minute
+	// uploadMaxMemory is how much of a form is kept in memory; the rest of
+	// the file goes to a temporary file until the handler is done.
+	uploadMaxMemory = 32 << 10
+)
+
+func registerAttachments(grp huma.API, handler *TodoHandler, security []map[string][]string) {
+	// huma neither limits nor times out multipart bodies, so the upload
+	// reads its form itself
+	var maxBodyBytes int64
+	if maxSize := handler.uc.AttachmentLimits().MaxSize; maxSize > 0 {
+		maxBodyBytes = maxSize + uploadOverhead
+	}
+	huma.Register(grp, huma.Operation{
+		OperationID: "upload-todo-attachment",
+		Summary:     "Attach a file to a todo item",
+		Description: "Uploads the file in the file field of a multipart form. The file has to be within the configured size limit and of an allowed type; " +
+			"when its part has no specific Content-Type, the type is detected from the content.",
+		Method:          http.MethodPost,
+		Path:            "/{id}/attachments",
+		Security:        security,
+		MaxBodyBytes:    maxBodyBytes,
+		BodyReadTimeout: uploadReadTimeout,
+		Middlewares:     huma.Middlewares{readUpload(grp)},
+	}, handler.UploadAttachment)
+	huma.Register(grp, huma.Operation{
+		OperationID: "download-todo-attachment",
+		Summary:     "Download a file attached to a todo item",
+		Method:      http.MethodGet,
+		Path:        "/{id}/attachments/{attachmentId}",
+		Security:    security,
+		Responses: map[string]*huma.Response{
+			"200": {Description: "Content of the file, with the media type it was attached with", Content: map[string]*huma.MediaType{"application/octet-stream": {}}},
+		},
+	}, handler.DownloadAttachment)
+	huma.Register(grp, huma.Operation{
+		OperationID: "delete-todo-attachment",
+		Summary:     "Delete a file attached to a todo item",
+		Method:      http.MethodDelete,
+		Path:        "/{id}/attachments/{attachmentId}",
+		Security:    security,
+	}, handler.DeleteAttachment)
+}
+
+func (h *TodoHandler) UploadAttachment(ctx context.Context, input *UploadAttachmentInput) (*AttachmentOutput, error) {
+	file := input.RawBody.Data().File
+	defer file.Close()
+	attachment, err := h.uc.AddAttachment(middleware.UserID(ctx), input.ID, usecase.AttachmentUpload{
+		Filename:    file.Filename,
+		ContentType: file.ContentType,
+		Size:        file.Size,
+		Content:     file,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &AttachmentOutput{}
+	resp.Body.Attachment = attachment
+	return resp, nil
+}
+
+func (h *TodoHandler) DownloadAttachment(ctx context.Context, input *AttachmentInput) (*huma.StreamResponse, error) {
+	attachment, content, err := h.uc.OpenAttachment(middleware.UserID(ctx), input.ID, input.AttachmentID)
+	if err != nil {
+		return nil, err
+	}
+	return &huma.StreamResponse{Body: func(hctx huma.Context) {
+		defer content.Close()
+		// files are always downloaded, never shown inline, so an uploaded
+		// page cannot run in the API's origin
+		hctx.SetHeader("Content-Type", attachment.ContentType)
+		hctx.SetHeader("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+		hctx.SetHeader("Content-Length", strconv.FormatInt(attachment.Size, 10))
+		hctx.SetHeader("X-Content-Type-Options", "nosniff")
+		if _, err := io.Copy(hctx.BodyWriter(), content); err != nil {
+			// the status is already sent; a cut-off file is all we can do
+			log.Printf("todo attachments: download %s: %v", attachment.ID, err)
+		}
+	}}, nil
+}
+
+func (h *TodoHandler) DeleteAttachment(ctx context.Context, input *AttachmentInput) (*DeleteAttachmentOutput, error) {
+	if err := h.uc.DeleteAttachment(middleware.UserID(ctx), input.ID, input.AttachmentID); err != nil {
+		return nil, err
+	}
+	resp := &DeleteAttachmentOutput{}
+	resp.Body.Message = "Attachment deleted successfully"
+	return resp, nil
+}
+
+// readUpload reads the multipart form of an upload within the operation's
+// MaxBodyBytes and BodyReadTimeout, answering 413 as soon as the body turns
+// out to be larger instead of spooling all of it to disk first.
+func readUpload(api huma.API) func(ctx huma.Context, next func(huma.Context)) {
+	return func(ctx huma.Context, next func(huma.Context)) {
+		op := ctx.Operation()
+		tooLarge := func() {
+			huma.WriteErr(api, ctx, http.StatusRequestEntityTooLarge, "request body is too large", domain.ErrAttachmentTooLarge)
+		}
+		if op.MaxBodyBytes > 0 {
+			if n, err := strconv.ParseInt(ctx.Header("Content-Length"), 10, 64); err == nil && n > op.MaxBodyBytes {
+				tooLarge()
+				return
+			}
+		}
+		_, params, err := mime.ParseMediaType(ctx.Header("Content-Type"))
+		if err != nil || params["boundary"] == "" {
+			// not a form; huma turns it away
+			next(ctx)
+			return
+		}
+		_ = ctx.SetReadDeadline(time.Now().Add(op.BodyReadTimeout))
+		body := io.NopCloser(ctx.BodyReader())
+		if op.MaxBodyBytes > 0 {
+			body = http.MaxBytesReader(nil, body, op.MaxBodyBytes)
+		}
+		form, err := multipart.NewReader(body, params["boundary"]).ReadForm(uploadMaxMemory)
+		var maxBytes *http.MaxBytesError
+		if errors.As(err, &maxBytes) {
+			tooLarge()
+			return
+		}
+		if form != nil {
+			defer form.RemoveAll()
+		}
+		next(&uploadContext{humaContext: ctx, form: form, err: err})
+	}
+}
+
+// humaContext is embedded under this name, since huma.Context has a
+// Context method.
+type humaContext = huma.Context
+
+// uploadContext hands huma the form readUpload already read.
+type uploadContext struct {
+	humaContext
+	form *multipart.Form
+	err  error
+}
+
+func (c *uploadContext) GetMultipartForm() (*multipart.Form, error) {
+	return c.form, c.err
+}
+
+// Unwrap lets adapters reach their own context, e.g. humachi.Unwrap.
+func (c *uploadContext) Unwrap() huma.Context {
+	return c.humaContext
+}
@@ -0,0 +1,169 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"todo-app/internal/api/middleware"
+	"todo-app/internal/api/problem"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/usecase"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func registerBatch(api huma.API, handler *TodoHandler, security []map[string][]string) {
+	huma.Register(api, huma.Operation{
+		OperationID: "batch",
+		Summary:     "Run several todo operations atomically",
+		Description: "Runs creates, updates, patches, deletes, restores and purges of todo items in order, as a single transaction: " +
+			"either all of them take effect, or none does. The response has the status of the failing operation when the batch is rolled back.",
+		Method:   http.MethodPost,
+		Path:     "/batch",
+		Security: security,
+	}, handler.Batch)
+}
+
+func (h *TodoHandler) Batch(ctx context.Context, input *BatchInput) (*BatchOutput, error) {
+	ops := make([]usecase.BatchOp, len(input.Body.Ops))
+	for i, req := range input.Body.Ops {
+		op, err := decodeBatchOp(req)
+		if err != nil {
+			if errors.Is(err, domain.ErrVersionConflict) {
+				return batchFailed(len(ops), &usecase.BatchError{Index: i, Err: err}), nil
+			}
+			return nil, huma.Error422UnprocessableEntity("validation failed", &huma.ErrorDetail{
+				Message:  err.Error(),
+				Location: fmt.Sprintf("body.ops[%d]", i),
+				Value:    req.Method + " " + req.Path,
+			})
+		}
+		ops[i] = op
+	}
+	todos, err := h.uc.Batch(middleware.UserID(ctx), ops)
+	var failed *usecase.BatchError
+	if errors.As(err, &failed) {
+		return batchFailed(len(ops), failed), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp := &BatchOutput{Status: http.StatusOK}
+	resp.Body.Committed = true
+	resp.Body.Results = make([]BatchResponse, len(ops))
+	for i, todo := range todos {
+		result := BatchResponse{Status: http.StatusOK}
+		switch ops[i].Action {
+		case usecase.BatchDelete:
+			result.Body = map[string]string{"message": "Todo item deleted successfully"}
+		case usecase.BatchPurge:
+			result.Body = map[string]string{"message": "Todo item permanently deleted"}
+		default:
+			result.ETag = etag(todo.Version)
+			result.Body = map[string]*domain.Todo{"todo": todo}
+		}
+		resp.Body.Results[i] = result
+	}
+	return resp, nil
+}
+
+// batchFailed reports a rolled back batch: the failing operation gets its
+// problem, every other one a 424.
+func batchFailed(n int, failed *usecase.BatchError) *BatchOutput {
+	p := problem.FromError(failed.Err)
+	if p == nil {
+		p = problem.New(http.StatusInternalServerError, "internal error").(*problem.Problem)
+	}
+	resp := &BatchOutput{Status: p.Status}
+	resp.Body.Results = make([]BatchResponse, n)
+	for i := range resp.Body.Results {
+		if i == failed.Index {
+			resp.Body.Results[i] = BatchResponse{Status: p.Status, Body: p}
+			continue
+		}
+		dep := problem.New(http.StatusFailedDependency, fmt.Sprintf("not applied because operation %d failed", failed.Index))
+		resp.Body.Results[i] = BatchResponse{Status: http.StatusFailedDependency, Body: dep}
+	}
+	return resp
+}
+
+// decodeBatchOp maps a sub-request onto the todo operation its method and
+// path name, decoding its body like the single request would.
+func decodeBatchOp(req BatchRequest) (usecase.BatchOp, error) {
+	var op usecase.BatchOp
+	version, err := parseIfMatch(req.IfMatch)
+	if err != nil {
+		return op, err
+	}
+	op.Version = version
+	segments := strings.Split(strings.Trim(req.Path, "/"), "/")
+	if segments[0] != "todos" {
+		return op, errors.New("path must start with /todos")
+	}
+	switch {
+	case len(segments) == 1 && req.Method == http.MethodPost:
+		var in CreateTodoInput
+		if err := decodeBatchBody(req.Body, &in.Body); err != nil {
+			return op, err
+		}
+		op.Action, op.Title, op.DueDate, op.Done = usecase.BatchCreate, in.Body.Title, in.Body.DueDate, in.Body.Done
+		if len(in.Body.Tags) > 0 {
+			op.Options = append(op.Options, usecase.WithTags(in.Body.Tags))
+		}
+		if in.Body.Priority != "" {
+			op.Options = append(op.Options, usecase.WithPriority(in.Body.Priority))
+		}
+		if in.Body.AllDay || in.Body.TimeZone != "" {
+			op.Options = append(op.Options, usecase.WithDueZone(in.Body.AllDay, in.Body.TimeZone))
+		}
+		if rec := in.Body.Recurrence; rec != nil {
+			op.Options = append(op.Options, usecase.WithRecurrence(rec.Rule, rec.TimeZone))
+		}
+		if in.Body.ProjectID != "" {
+			op.Options = append(op.Options, usecase.WithProject(in.Body.ProjectID))
+		}
+		if in.Body.Assignee != "" {
+			op.Options = append(op.Options, usecase.WithAssignee(in.Body.Assignee))
+		}
+	case len(segments) == 2 && segments[1] != "trash" && req.Method == http.MethodPut:
+		var in UpdateTodoInput
+		if err := decodeBatchBody(req.Body, &in.Body); err != nil {
+			return op, err
+		}
+		op.Action, op.ID, op.Title, op.DueDate, op.Done = usecase.BatchUpdate, segments[1], in.Body.Title, in.Body.DueDate, in.Body.Done
+		op.Options = []usecase.TodoOption{usecase.WithDueZone(in.Body.AllDay, in.Body.TimeZone)}
+	case len(segments) == 2 && segments[1] != "trash" && req.Method == http.MethodPatch:
+		var in PatchTodoInput
+		if err := decodeBatchBody(req.Body, &in.Body); err != nil {
+			return op, err
+		}
+		op.Action, op.ID = usecase.BatchPatch, segments[1]
+		op.Patch = usecase.TodoPatch{
+			Title: in.Body.Title, DueDate: in.Body.DueDate, AllDay: in.Body.AllDay, TimeZone: in.Body.TimeZone,
+			Done: in.Body.Done, Tags: in.Body.Tags, Priority: in.Body.Priority, ProjectID: in.Body.ProjectID,
+			Assignee: in.Body.Assignee,
+		}
+	case len(segments) == 2 && segments[1] != "trash" && req.Method == http.MethodDelete:
+		op.Action, op.ID = usecase.BatchDelete, segments[1]
+	case len(segments) == 3 && segments[1] == "trash" && req.Method == http.MethodDelete:
+		op.Action, op.ID = usecase.BatchPurge, segments[2]
+	case len(segments) == 4 && segments[1] == "trash" && segments[3] == "restore" && req.Method == http.MethodPost:
+		op.Action, op.ID = usecase.BatchRestore, segments[2]
+	default:
+		return op, errors.New("no todo operation for " + req.Method + " " + req.Path)
+	}
+	return op, nil
+}
+
+func decodeBatchBody(raw json.RawMessage, body any) error {
+	if len(raw) == 0 {
+		return errors.New("body is required")
+	}
+	if err := json.Unmarshal(raw, body); err != nil {
+		return fmt.Errorf("invalid body: %w", err)
+	}
+	return nil
+}
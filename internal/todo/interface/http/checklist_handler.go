@@ -0,0 +1,103 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"todo-app/internal/api/middleware"
+	"todo-app/internal/todo/domain"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func registerChecklist(grp huma.API, handler *TodoHandler, security []map[string][]string) {
+	huma.Register(grp, huma.Operation{
+		OperationID: "add-checklist-item",
+		Summary:     "Add an item to a todo's checklist",
+		Method:      http.MethodPost,
+		Path:        "/{id}/checklist",
+		Security:    security,
+	}, handler.AddChecklistItem)
+	huma.Register(grp, huma.Operation{
+		OperationID: "reorder-checklist",
+		Summary:     "Reorder a todo's checklist",
+		Method:      http.MethodPut,
+		Path:        "/{id}/checklist/order",
+		Security:    security,
+	}, handler.ReorderChecklist)
+	huma.Register(grp, huma.Operation{
+		OperationID: "edit-checklist-item",
+		Summary:     "Edit a checklist item",
+		Method:      http.MethodPatch,
+		Path:        "/{id}/checklist/{itemId}",
+		Security:    security,
+	}, handler.EditChecklistItem)
+	huma.Register(grp, huma.Operation{
+		OperationID: "toggle-checklist-item",
+		Summary:     "Toggle the checked state of a checklist item",
+		Method:      http.MethodPost,
+		Path:        "/{id}/checklist/{itemId}/toggle",
+		Security:    security,
+	}, handler.ToggleChecklistItem)
+	huma.Register(grp, huma.Operation{
+		OperationID: "remove-checklist-item",
+		Summary:     "Remove an item from a todo's checklist",
+		Method:      http.MethodDelete,
+		Path:        "/{id}/checklist/{itemId}",
+		Security:    security,
+	}, handler.RemoveChecklistItem)
+}
+
+func (h *TodoHandler) AddChecklistItem(ctx context.Context, input *AddChecklistItemInput) (*ChecklistOutput, error) {
+	version, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := h.uc.AddChecklistItem(middleware.UserID(ctx), input.ID, input.Body.Text, input.Body.Checked, input.Body.Position, version)
+	return checklistOutput(todo, err)
+}
+
+func (h *TodoHandler) EditChecklistItem(ctx context.Context, input *EditChecklistItemInput) (*ChecklistOutput, error) {
+	version, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := h.uc.EditChecklistItem(middleware.UserID(ctx), input.ID, input.ItemID, input.Body.Text, input.Body.Checked, version)
+	return checklistOutput(todo, err)
+}
+
+func (h *TodoHandler) ToggleChecklistItem(ctx context.Context, input *ChecklistItemInput) (*ChecklistOutput, error) {
+	version, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := h.uc.ToggleChecklistItem(middleware.UserID(ctx), input.ID, input.ItemID, version)
+	return checklistOutput(todo, err)
+}
+
+func (h *TodoHandler) ReorderChecklist(ctx context.Context, input *ReorderChecklistInput) (*ChecklistOutput, error) {
+	version, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := h.uc.ReorderChecklist(middleware.UserID(ctx), input.ID, input.Body.ItemIDs, version)
+	return checklistOutput(todo, err)
+}
+
+func (h *TodoHandler) RemoveChecklistItem(ctx context.Context, input *ChecklistItemInput) (*ChecklistOutput, error) {
+	version, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := h.uc.RemoveChecklistItem(middleware.UserID(ctx), input.ID, input.ItemID, version)
+	return checklistOutput(todo, err)
+}
+
+func checklistOutput(todo *domain.Todo, err error) (*ChecklistOutput, error) {
+	if err != nil {
+		return nil, err
+	}
+	resp := &ChecklistOutput{}
+	resp.ETag = etag(todo.Version)
+	resp.Body.Todo = todo
+	return resp, nil
+}
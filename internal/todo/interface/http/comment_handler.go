@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"todo-app/internal/api/middleware"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func registerComments(grp huma.API, handler *TodoHandler, security []map[string][]string) {
+	huma.Register(grp, huma.Operation{
+		OperationID: "add-todo-comment",
+		Summary:     "Comment on a todo item",
+		Description: "Anybody who can see the todo item can comment on it. With parentId the comment replies to another one and joins its thread.",
+		Method:      http.MethodPost,
+		Path:        "/{id}/comments",
+		Security:    security,
+	}, handler.AddComment)
+	huma.Register(grp, huma.Operation{
+		OperationID: "list-todo-comments",
+		Summary:     "List the comments on a todo item",
+		Method:      http.MethodGet,
+		Path:        "/{id}/comments",
+		Security:    security,
+	}, handler.ListComments)
+	huma.Register(grp, huma.Operation{
+		OperationID: "get-todo-comment",
+		Summary:     "Get a comment on a todo item",
+		Method:      http.MethodGet,
+		Path:        "/{id}/comments/{commentId}",
+		Security:    security,
+	}, handler.GetComment)
+	huma.Register(grp, huma.Operation{
+		OperationID: "edit-todo-comment",
+		Summary:     "Edit a comment on a todo item",
+		Description: "Only the author can edit a comment.",
+		Method:      http.MethodPatch,
+		Path:        "/{id}/comments/{commentId}",
+		Security:    security,
+	}, handler.EditComment)
+	huma.Register(grp, huma.Operation{
+		OperationID: "delete-todo-comment",
+		Summary:     "Delete a comment on a todo item",
+		Description: "Only the author can delete a comment; the replies to it are deleted too.",
+		Method:      http.MethodDelete,
+		Path:        "/{id}/comments/{commentId}",
+		Security:    security,
+	}, handler.DeleteComment)
+}
+
+func (h *TodoHandler) AddComment(ctx context.Context, input *AddCommentInput) (*CommentOutput, error) {
+	comment, err := h.uc.AddComment(middleware.UserID(ctx), input.ID, input.Body.ParentID, input.Body.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp := &CommentOutput{}
+	resp.Body.Comment = comment
+	return resp, nil
+}
+
+func (h *TodoHandler) ListComments(ctx context.Context, input *ListCommentsInput) (*ListCommentsOutput, error) {
+	comments, total, err := h.uc.ListComments(middleware.UserID(ctx), input.ID, input.Page, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListCommentsOutput{}
+	resp.Body.Data = comments
+	resp.Body.Meta = ListResponseMeta{
+		Page:  input.Page,
+		Limit: input.Limit,
+		Total: total,
+	}
+	return resp, nil
+}
+
+func (h *TodoHandler) GetComment(ctx context.Context, input *CommentInput) (*CommentOutput, error) {
+	comment, err := h.uc.GetComment(middleware.UserID(ctx), input.ID, input.CommentID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &CommentOutput{}
+	resp.Body.Comment = comment
+	return resp, nil
+}
+
+func (h *TodoHandler) EditComment(ctx context.Context, input *EditCommentInput) (*CommentOutput, error) {
+	comment, err := h.uc.EditComment(middleware.UserID(ctx), input.ID, input.CommentID, input.Body.Body)
+	if err != nil {
+		return nil, err
+	}
+	resp := &CommentOutput{}
+	resp.Body.Comment = comment
+	return resp, nil
+}
+
+func (h *TodoHandler) DeleteComment(ctx context.Context, input *CommentInput) (*DeleteCommentOutput, error) {
+	if err := h.uc.DeleteComment(middleware.UserID(ctx), input.ID, input.CommentID); err != nil {
+		return nil, err
+	}
+	resp := &DeleteCommentOutput{}
+	resp.Body.Message = "Comment deleted successfully"
+	return resp, nil
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"todo-app/internal/api/middleware"
+	"todo-app/internal/api/problem"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/interface/format"
+	"todo-app/internal/todo/usecase"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// exportPageSize is how many todos an export loads at a time.
+const exportPageSize = 500
+
+func registerCSV(grp huma.API, handler *TodoHandler, security []map[string][]string) {
+	huma.Register(grp, huma.Operation{
+		OperationID: "export-todos-csv",
+		Summary:     "Export todo items as CSV",
+		Description: "Streams the todo items matching the list filters as CSV, one row per todo item.",
+		Method:      http.MethodGet,
+		Path:        "/export.csv",
+		Security:    security,
+		Responses: map[string]*huma.Response{
+			"200": {Description: "CSV with a header row", Content: map[string]*huma.MediaType{"text/csv": {}}},
+		},
+	}, handler.ExportCSV)
+	huma.Register(grp, huma.Operation{
+		OperationID: "import-todos-csv",
+		Summary:     "Import todo items from CSV",
+		Description: "Creates or updates a todo item per CSV row: rows whose id names a todo item update it, other rows create one. " +
+			"Empty cells leave the field alone. Failing rows are reported and do not stop the others.",
+		Method:       http.MethodPost,
+		Path:         "/import",
+		Security:     security,
+		MaxBodyBytes: 5 << 20,
+	}, handler.ImportCSV)
+}
+
+func (h *TodoHandler) ExportCSV(ctx context.Context, input *ExportTodosInput) (*huma.StreamResponse, error) {
+	filter, err := input.filter(middleware.UserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return h.export(filter, "text/csv; charset=utf-8", "todos.csv", func(out io.Writer) (todoWriter, error) {
+		w := format.NewCSVWriter(out)
+		return w, w.WriteHeader()
+	})
+}
+
+// todoWriter writes todos in an export format.
+type todoWriter interface {
+	Write(todo *domain.Todo) error
+	Flush() error
+}
+
+// export streams the todos matching filter as a file, a page at a time.
+func (h *TodoHandler) export(filter domain.TodoFilter, contentType, filename string, newWriter func(io.Writer) (todoWriter, error)) (*huma.StreamResponse, error) {
+	// load the first page up front, so failures still get a proper status
+	first, _, err := h.uc.GetAllTodos(0, exportPageSize, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &huma.StreamResponse{Body: func(hctx huma.Context) {
+		hctx.SetHeader("Content-Type", contentType)
+		hctx.SetHeader("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w, err := newWriter(hctx.BodyWriter())
+		if err != nil {
+			return
+		}
+		todos := first
+		for page := 1; ; page++ {
+			for _, todo := range todos {
+				if err := w.Write(todo); err != nil {
+					return
+				}
+			}
+			if err := w.Flush(); err != nil || len(todos) < exportPageSize {
+				return
+			}
+			if todos, _, err = h.uc.GetAllTodos(page, exportPageSize, filter); err != nil {
+				// the status is already sent; a cut-off file is all we can do
+				log.Printf("todo export: load page %d: %v", page, err)
+				return
+			}
+		}
+	}}, nil
+}
+
+func (h *TodoHandler) ImportCSV(ctx context.Context, input *ImportTodosInput) (*ImportTodosOutput, error) {
+	mapping := map[string]string{}
+	for _, pair := range input.Mapping {
+		column, field, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, huma.Error422UnprocessableEntity("validation failed", &huma.ErrorDetail{
+				Message:  "must be a column=field pair",
+				Location: "query.mapping",
+				Value:    pair,
+			})
+		}
+		mapping[strings.TrimSpace(column)] = strings.TrimSpace(field)
+	}
+	rows, err := format.ReadCSV(bytes.NewReader(input.RawBody), mapping)
+	if err != nil {
+		return nil, err
+	}
+	return h.importRows(ctx, rows, input.DryRun)
+}
+
+// importRows imports rows read from a file and reports on every row.
+func (h *TodoHandler) importRows(ctx context.Context, rows []usecase.ImportRow, dryRun bool) (*ImportTodosOutput, error) {
+	results, err := h.uc.ImportTodos(middleware.UserID(ctx), rows, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ImportTodosOutput{}
+	resp.Body.DryRun = dryRun
+	resp.Body.Rows = make([]ImportRowResult, 0, len(results))
+	for _, r := range results {
+		row := ImportRowResult{Line: r.Line, ID: r.ID, Status: string(r.Status)}
+		switch r.Status {
+		case usecase.ImportCreated:
+			resp.Body.Summary.Created++
+		case usecase.ImportUpdated:
+			resp.Body.Summary.Updated++
+		case usecase.ImportUnchanged:
+			resp.Body.Summary.Unchanged++
+		case usecase.ImportFailed:
+			resp.Body.Summary.Failed++
+			row.Code, row.Error = "error", r.Err.Error()
+			if p := problem.FromError(r.Err); p != nil {
+				row.Code = p.Code
+			}
+			var invalid *domain.ValidationError
+			if errors.As(r.Err, &invalid) {
+				row.Field, row.Error = invalid.Field, invalid.Message
+			}
+		}
+		resp.Body.Rows = append(resp.Body.Rows, row)
+	}
+	return resp, nil
+}
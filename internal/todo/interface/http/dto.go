@@ -1,32 +1,246 @@
 package http
 
 import (
+	"encoding/json"
 	"time"
 	"todo-app/internal/todo/domain"
+
+	"github.com/danielgtaylor/huma/v2"
 )
 
 type (
+	RecurrenceBody struct {
+		Rule     string `json:"rule" doc:"RFC 5545 RRULE, e.g. FREQ=WEEKLY;BYDAY=MO" example:"FREQ=WEEKLY;BYDAY=MO"`
+		TimeZone string `json:"timeZone,omitempty" doc:"IANA time zone the rule is evaluated in; defaults to the time zone of the due date, or UTC" example:"Asia/Taipei"`
+	}
 	ListQueryParams struct {
 		Page  int `query:"page" doc:"Page number for pagination" example:"0"`
 		Limit int `query:"limit" doc:"Number of items per page" example:"10"`
 	}
+	TodoFilterParams struct {
+		Title    string `query:"title" doc:"Filter todos by title" example:"groceries"`
+		Done     string `query:"done" enum:"true,false" doc:"Filter todos by completion status"`
+		Tag      string `query:"tag" doc:"Filter todos carrying the tag" example:"home"`
+		Project  string `query:"project" doc:"Filter todos filed under the project with this ID, or under none with inbox" example:"inbox"`
+		Due      string `query:"due" enum:"overdue,today,tomorrow" doc:"Filter todos due today or tomorrow, or open todos whose due date has passed, by the days of timeZone"`
+		TimeZone string `query:"timeZone" doc:"IANA time zone of the caller, whose days the due filter uses; defaults to UTC" example:"America/Los_Angeles"`
+		Assigned string `query:"assigned" enum:"me" doc:"List the todos assigned to the caller, whoever owns them, instead of the caller's own"`
+	}
 	ListTodosInput struct {
 		ListQueryParams
-		Title string `query:"title" doc:"Filter todos by title" example:"groceries"`
+		TodoFilterParams
+	}
+	SearchTodosInput struct {
+		ListQueryParams
+		TodoFilterParams
+		Q string `query:"q" required:"true" maxLength:"200" doc:"Words to search for in titles and tags" example:"milk eggs"`
+	}
+	ExportTodosInput struct {
+		TodoFilterParams
+	}
+	ImportTodosInput struct {
+		DryRun  bool     `query:"dryRun" doc:"Check every row and report what would happen, without changing anything"`
+		Mapping []string `query:"mapping" doc:"Column=field pairs reading a column into one of the fields id, title, dueDate, done and tags; by default columns are read into the field of the same name" example:"Task=title,Due=dueDate"`
+		RawBody []byte   `contentType:"text/csv"`
+	}
+	ExportMarkdownInput struct {
+		TodoFilterParams
+		GroupBy string `query:"groupBy" enum:"due,tag" default:"due" doc:"Group the todo items under a heading per due date or per tag"`
+	}
+	ImportMarkdownInput struct {
+		DryRun  bool   `query:"dryRun" doc:"Check every task item and report what would happen, without changing anything"`
+		RawBody []byte `contentType:"text/markdown"`
+	}
+	ImportTodoTxtInput struct {
+		DryRun  bool   `query:"dryRun" doc:"Check every line and report what would happen, without changing anything"`
+		RawBody []byte `contentType:"text/plain"`
+	}
+	TodoFilterBody struct {
+		Title    string `json:"title,omitempty" doc:"Select todos whose title contains this" example:"groceries"`
+		Done     *bool  `json:"done,omitempty" doc:"Select todos with this completion status" example:"true"`
+		Tag      string `json:"tag,omitempty" doc:"Select todos carrying this tag" example:"home"`
+		Project  string `json:"project,omitempty" doc:"Select todos filed under the project with this ID, or under none with inbox" example:"inbox"`
+		Due      string `json:"due,omitempty" enum:"overdue,today,tomorrow" doc:"Select todos due today or tomorrow, or open todos whose due date has passed, by the days of timeZone"`
+		TimeZone string `json:"timeZone,omitempty" doc:"IANA time zone of the caller, whose days the due filter uses; defaults to UTC" example:"America/Los_Angeles"`
+	}
+	BulkTodosInput struct {
+		Body struct {
+			Action       string          `json:"action" enum:"complete,reopen,delete,retag,reschedule,move" doc:"Operation to apply to every selected todo item"`
+			IDs          []string        `json:"ids,omitempty" maxItems:"1000" doc:"IDs of the todo items to change; give either ids or filter"`
+			Filter       *TodoFilterBody `json:"filter,omitempty" doc:"Selects every live todo item matching it, as the list endpoint does; give either ids or filter"`
+			AddTags      []string        `json:"addTags,omitempty" doc:"retag: tags to add" example:"[\"urgent\"]"`
+			RemoveTags   []string        `json:"removeTags,omitempty" doc:"retag: tags to remove" example:"[\"someday\"]"`
+			DueDate      *time.Time      `json:"dueDate,omitempty" doc:"reschedule: new due date" example:"2023-10-10T10:00:00Z"`
+			ShiftMinutes int             `json:"shiftMinutes,omitempty" doc:"reschedule: minutes to move each due date by; negative moves it earlier" example:"1440"`
+			ProjectID    *string         `json:"projectId,omitempty" doc:"move: project to move the todo items to; empty for the inbox" example:"0b7e6f1c-8a3d-4c55-9d2e-3f1a2b4c5d6e"`
+		}
+	}
+	BatchRequest struct {
+		Method  string          `json:"method" enum:"POST,PUT,PATCH,DELETE" doc:"Method of the todo operation" example:"PATCH"`
+		Path    string          `json:"path" doc:"Path of the todo operation: /todos, /todos/{id}, /todos/trash/{id} or /todos/trash/{id}/restore" example:"/todos/123e4567-e89b-12d3-a456-426614174000"`
+		IfMatch string          `json:"ifMatch,omitempty" doc:"If-Match header of the operation" example:"\"3\""`
+		Body    json.RawMessage `json:"body,omitempty" doc:"Request body of the operation, as for the single request"`
+	}
+	BatchInput struct {
+		Body struct {
+			Ops []BatchRequest `json:"ops" minItems:"1" maxItems:"100" doc:"Operations to run in order"`
+		}
 	}
 	CreateTodoInput struct {
 		Body struct {
-			Title   string    `json:"title" doc:"Title of the todo item" example:"Buy groceries"`
-			DueDate time.Time `json:"dueDate" doc:"Due date for the todo item" example:"2023-10-10T10:00:00Z"`
-			Done    bool      `json:"done" doc:"Completion status of the todo item" example:"false"`
+			Title      string          `json:"title" doc:"Title of the todo item" example:"Buy groceries"`
+			DueDate    time.Time       `json:"dueDate" doc:"Due date for the todo item" example:"2023-10-10T10:00:00Z"`
+			AllDay     bool            `json:"allDay,omitempty" doc:"Whether the todo item is due any time on the date dueDate is written with" example:"false"`
+			TimeZone   string          `json:"timeZone,omitempty" doc:"IANA time zone the due date is set in" example:"Asia/Taipei"`
+			Done       bool            `json:"done" doc:"Completion status of the todo item" example:"false"`
+			Tags       []string        `json:"tags,omitempty" doc:"Labels of the todo item" example:"[\"home\"]"`
+			Priority   string          `json:"priority,omitempty" doc:"Priority of the todo item, from A (highest) to Z" example:"A"`
+			Recurrence *RecurrenceBody `json:"recurrence,omitempty" doc:"Makes the todo the first occurrence of a recurring series"`
+			ProjectID  string          `json:"projectId,omitempty" doc:"Project of yours to file the todo item under; the inbox when omitted" example:"0b7e6f1c-8a3d-4c55-9d2e-3f1a2b4c5d6e"`
+			Assignee   string          `json:"assignee,omitempty" doc:"Registered user to assign the todo item to" example:"bob"`
 		}
 	}
-	UpdateTodoInput struct {
+	QuickAddInput struct {
+		DryRun bool `query:"dryRun" doc:"Return how the text is read without creating the todo item, for a preview"`
+		Body   struct {
+			Text     string `json:"text" minLength:"1" maxLength:"1000" doc:"Todo item as typed, with its due date, recurrence, #tags and !priority in words" example:"pay rent every month on the 1st #bills !high 9am"`
+			TimeZone string `json:"timeZone,omitempty" doc:"IANA time zone of the user, which dates and times in the text are in; defaults to UTC" example:"Asia/Taipei"`
+		}
+	}
+	ListTrashInput struct {
+		ListQueryParams
+	}
+	TrashItemInput struct {
+		ID      string `path:"id" doc:"ID of the trashed todo item"`
+		IfMatch string `header:"If-Match" doc:"ETag of the trashed todo item the client last saw; fails with 412 if it changed since"`
+	}
+	AddChecklistItemInput struct {
+		ID      string `path:"id" doc:"ID of the todo item"`
+		IfMatch string `header:"If-Match" doc:"ETag of the todo item the client last saw; fails with 412 if it changed since"`
+		Body    struct {
+			Text     string `json:"text" doc:"Text of the checklist item" example:"Milk"`
+			Checked  bool   `json:"checked,omitempty" doc:"Whether the item starts out checked" example:"false"`
+			Position *int   `json:"position,omitempty" doc:"Zero-based position to insert at; appended when omitted" example:"0"`
+		}
+	}
+	EditChecklistItemInput struct {
+		ID      string `path:"id" doc:"ID of the todo item"`
+		ItemID  string `path:"itemId" doc:"ID of the checklist item"`
+		IfMatch string `header:"If-Match" doc:"ETag of the todo item the client last saw; fails with 412 if it changed since"`
+		Body    struct {
+			Text    *string `json:"text,omitempty" doc:"New text of the checklist item" example:"Oat milk"`
+			Checked *bool   `json:"checked,omitempty" doc:"New checked state of the checklist item" example:"true"`
+		}
+	}
+	ChecklistItemInput struct {
+		ID      string `path:"id" doc:"ID of the todo item"`
+		ItemID  string `path:"itemId" doc:"ID of the checklist item"`
+		IfMatch string `header:"If-Match" doc:"ETag of the todo item the client last saw; fails with 412 if it changed since"`
+	}
+	ReorderChecklistInput struct {
+		ID      string `path:"id" doc:"ID of the todo item"`
+		IfMatch string `header:"If-Match" doc:"ETag of the todo item the client last saw; fails with 412 if it changed since"`
+		Body    struct {
+			ItemIDs []string `json:"itemIds" doc:"IDs of all checklist items in their new order"`
+		}
+	}
+	SetRecurrenceInput struct {
+		ID      string `path:"id" doc:"ID of the todo item"`
+		IfMatch string `header:"If-Match" doc:"ETag of the todo item the client last saw; fails with 412 if it changed since"`
+		Body    RecurrenceBody
+	}
+	RecurrenceActionInput struct {
+		ID      string `path:"id" doc:"ID of the todo item"`
+		IfMatch string `header:"If-Match" doc:"ETag of the todo item the client last saw; fails with 412 if it changed since"`
+	}
+	TodoEventsInput struct {
+		LastEventID int `header:"Last-Event-ID" doc:"ID of the last event the client received; missed events still in the replay buffer are sent first"`
+	}
+	GetHistoryInput struct {
+		ListQueryParams
+		ID string `path:"id" doc:"ID of the todo item"`
+	}
+	ListSharedInput struct {
+		ListQueryParams
+	}
+	ShareTodoInput struct {
 		ID   string `path:"id" doc:"ID of the todo item"`
 		Body struct {
-			Title   string    `json:"title" doc:"Title of the todo item" example:"Buy groceries"`
-			DueDate time.Time `json:"dueDate" doc:"Due date for the todo item" example:"2023-10-10T10:00:00Z"`
-			Done    bool      `json:"done" doc:"Completion status of the todo item" example:"false"`
+			Username   string `json:"username" doc:"Registered user to share the todo item with" example:"bob"`
+			Permission string `json:"permission" enum:"view,edit" doc:"Whether the user can only read the todo item, or also edit it" example:"edit"`
+		}
+	}
+	ListSharesInput struct {
+		ID string `path:"id" doc:"ID of the todo item"`
+	}
+	RevokeShareInput struct {
+		ID       string `path:"id" doc:"ID of the todo item"`
+		Username string `path:"username" doc:"User to stop sharing the todo item with"`
+	}
+	AddCommentInput struct {
+		ID   string `path:"id" doc:"ID of the todo item"`
+		Body struct {
+			Body     string `json:"body" maxLength:"10000" doc:"Text of the comment" example:"The store closes at 8"`
+			ParentID string `json:"parentId,omitempty" doc:"Comment to reply to; the reply joins the thread that comment is in" example:"9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"`
+		}
+	}
+	ListCommentsInput struct {
+		ListQueryParams
+		ID string `path:"id" doc:"ID of the todo item"`
+	}
+	CommentInput struct {
+		ID        string `path:"id" doc:"ID of the todo item"`
+		CommentID string `path:"commentId" doc:"ID of the comment"`
+	}
+	AttachmentForm struct {
+		File huma.FormFile `form:"file" required:"true" doc:"File to attach"`
+	}
+	UploadAttachmentInput struct {
+		ID      string `path:"id" doc:"ID of the todo item"`
+		RawBody huma.MultipartFormFiles[AttachmentForm]
+	}
+	AttachmentInput struct {
+		ID           string `path:"id" doc:"ID of the todo item"`
+		AttachmentID string `path:"attachmentId" doc:"ID of the attachment"`
+	}
+	EditCommentInput struct {
+		ID        string `path:"id" doc:"ID of the todo item"`
+		CommentID string `path:"commentId" doc:"ID of the comment"`
+		Body      struct {
+			Body string `json:"body" maxLength:"10000" doc:"New text of the comment" example:"The store closes at 9"`
+		}
+	}
+	GetTodoByIDInput struct {
+		ID string `path:"id" doc:"ID of the todo item"`
+	}
+	DeleteTodoInput struct {
+		ID      string `path:"id" doc:"ID of the todo item"`
+		IfMatch string `header:"If-Match" doc:"ETag of the todo item the client last saw; the delete fails with 412 if it changed since"`
+	}
+	UpdateTodoInput struct {
+		ID      string `path:"id" doc:"ID of the todo item"`
+		IfMatch string `header:"If-Match" doc:"ETag of the todo item the client last saw; the update fails with 412 if it changed since"`
+		Body    struct {
+			Title    string    `json:"title" doc:"Title of the todo item" example:"Buy groceries"`
+			DueDate  time.Time `json:"dueDate" doc:"Due date for the todo item" example:"2023-10-10T10:00:00Z"`
+			AllDay   bool      `json:"allDay,omitempty" doc:"Whether the todo item is due any time on the date dueDate is written with" example:"false"`
+			TimeZone string    `json:"timeZone,omitempty" doc:"IANA time zone the due date is set in" example:"Asia/Taipei"`
+			Done     bool      `json:"done" doc:"Completion status of the todo item" example:"false"`
+		}
+	}
+	PatchTodoInput struct {
+		ID      string `path:"id" doc:"ID of the todo item"`
+		IfMatch string `header:"If-Match" doc:"ETag of the todo item the client last saw; the update fails with 412 if it changed since"`
+		Body    struct {
+			Title     *string    `json:"title,omitempty" doc:"New title of the todo item" example:"Buy groceries"`
+			DueDate   *time.Time `json:"dueDate,omitempty" doc:"New due date for the todo item" example:"2023-10-10T10:00:00Z"`
+			AllDay    *bool      `json:"allDay,omitempty" doc:"Whether the todo item is due any time on its date" example:"true"`
+			TimeZone  *string    `json:"timeZone,omitempty" doc:"IANA time zone the due date is set in; empty to clear it" example:"Asia/Taipei"`
+			Done      *bool      `json:"done,omitempty" doc:"New completion status of the todo item" example:"true"`
+			Tags      *[]string  `json:"tags,omitempty" doc:"New labels of the todo item, replacing the old ones" example:"[\"home\"]"`
+			Priority  *string    `json:"priority,omitempty" doc:"New priority of the todo item, from A (highest) to Z; empty to clear it" example:"B"`
+			ProjectID *string    `json:"projectId,omitempty" doc:"Project of the owner to move the todo item to; empty for the inbox" example:"0b7e6f1c-8a3d-4c55-9d2e-3f1a2b4c5d6e"`
+			Assignee  *string    `json:"assignee,omitempty" doc:"Registered user to assign the todo item to; empty to unassign it" example:"bob"`
 		}
 	}
 )
@@ -42,14 +256,53 @@ type (
 			Message string `json:"message" example:"Todo item created successfully" doc:"Confirmation message"`
 		}
 	}
+	QuickAddPhrase struct {
+		Text string `json:"text" example:"every month on the 1st" doc:"Words of the text"`
+		Kind string `json:"kind" enum:"due,time,recurrence,tag,priority" example:"recurrence" doc:"What the words were read as"`
+	}
+	QuickAddInterpretation struct {
+		Title      string           `json:"title" example:"pay rent" doc:"Words left for the title"`
+		DueDate    *time.Time       `json:"dueDate,omitempty" example:"2025-08-01T09:00:00+08:00" doc:"Due date read from the text, in the time zone of the request"`
+		AllDay     bool             `json:"allDay,omitempty" example:"false" doc:"Whether the text names a day but no time of it"`
+		Recurrence string           `json:"recurrence,omitempty" example:"FREQ=MONTHLY;BYMONTHDAY=1" doc:"RRULE read from the text"`
+		Tags       []string         `json:"tags,omitempty" example:"[\"bills\"]" doc:"Tags read from the text"`
+		Priority   string           `json:"priority,omitempty" example:"A" doc:"Priority read from the text"`
+		Phrases    []QuickAddPhrase `json:"phrases" doc:"Parts of the text that were understood, in order"`
+	}
+	QuickAddOutput struct {
+		Body struct {
+			Interpretation QuickAddInterpretation `json:"interpretation" doc:"How the text was read"`
+			Todo           *domain.Todo           `json:"todo" doc:"Todo item created, or that would be created on a dry run"`
+		}
+	}
 	ListTodosOutput struct {
 		Body struct {
 			Data []*domain.Todo   `json:"data" doc:"List of todo items"`
 			Meta ListResponseMeta `json:"meta" doc:"Pagination metadata"`
 		}
 	}
+	SearchResult struct {
+		Todo        *domain.Todo `json:"todo" doc:"Todo item that matched"`
+		Score       float64      `json:"score" example:"2" doc:"Relevance of the match; results are ordered by it, highest first"`
+		Highlight   string       `json:"highlight" example:"Buy <mark>milk</mark> &amp; eggs" doc:"Title as HTML, with the words that matched in <mark> elements"`
+		MatchedTags []string     `json:"matchedTags,omitempty" example:"[\"home\"]" doc:"Tags that matched"`
+	}
+	SearchTodosOutput struct {
+		Body struct {
+			Data []SearchResult   `json:"data" doc:"Matching todo items, best first"`
+			Meta ListResponseMeta `json:"meta" doc:"Pagination metadata"`
+		}
+	}
+
+	GetHistoryOutput struct {
+		Body struct {
+			Data []*domain.HistoryEntry `json:"data" doc:"Changes to the todo item, most recent first"`
+			Meta ListResponseMeta       `json:"meta" doc:"Pagination metadata"`
+		}
+	}
 
 	GetTodoByIDOutput struct {
+		ETag string `header:"ETag" doc:"Current revision of the todo item, for use in If-Match"`
 		Body struct {
 			Todo *domain.Todo `json:"todo" doc:"Todo item"`
 		}
@@ -61,8 +314,161 @@ type (
 		}
 	}
 	UpdateTodoOutput struct {
+		ETag string `header:"ETag" doc:"New revision of the todo item"`
 		Body struct {
 			Message string `json:"message" example:"Todo item updated successfully" doc:"Confirmation message"`
 		}
 	}
+	RestoreTodoOutput struct {
+		ETag string `header:"ETag" doc:"New revision of the todo item"`
+		Body struct {
+			Todo *domain.Todo `json:"todo" doc:"Restored todo item"`
+		}
+	}
+	PurgeTodoOutput struct {
+		Body struct {
+			Message string `json:"message" example:"Todo item permanently deleted" doc:"Confirmation message"`
+		}
+	}
+	RecurrenceOutput struct {
+		ETag string `header:"ETag" doc:"New revision of the todo item"`
+		Body struct {
+			Todo *domain.Todo `json:"todo" doc:"Updated todo item"`
+		}
+	}
+	ChecklistOutput struct {
+		ETag string `header:"ETag" doc:"New revision of the todo item"`
+		Body struct {
+			Todo *domain.Todo `json:"todo" doc:"Todo item with its updated checklist"`
+		}
+	}
+	BulkItemResult struct {
+		ID     string       `json:"id" doc:"ID of the todo item"`
+		Status string       `json:"status" enum:"applied,skipped,failed" doc:"applied: changed; skipped: already in the requested state; failed: see code"`
+		Code   string       `json:"code,omitempty" example:"todo_version_conflict" doc:"Error code, as in problem responses, when the item failed"`
+		Error  string       `json:"error,omitempty" example:"todo version conflict" doc:"Error message when the item failed"`
+		Todo   *domain.Todo `json:"todo,omitempty" doc:"Todo item after the operation, unless it failed"`
+	}
+	BulkSummary struct {
+		Applied int `json:"applied" example:"48" doc:"Number of todo items changed"`
+		Skipped int `json:"skipped" example:"1" doc:"Number of todo items already in the requested state"`
+		Failed  int `json:"failed" example:"1" doc:"Number of todo items that could not be changed"`
+	}
+	BulkTodosOutput struct {
+		Body struct {
+			Results []BulkItemResult `json:"results" doc:"Outcome per selected todo item, in selection order"`
+			Summary BulkSummary      `json:"summary" doc:"Number of todo items per outcome"`
+		}
+	}
+	BatchResponse struct {
+		Status int    `json:"status" example:"200" doc:"Status the operation would have answered with on its own; 424 for operations rolled back or not run because another one failed"`
+		ETag   string `json:"etag,omitempty" doc:"ETag of the todo item after the operation"`
+		Body   any    `json:"body" doc:"Response body of the operation, or a problem when it failed"`
+	}
+	BatchOutput struct {
+		Status int
+		Body   struct {
+			Committed bool            `json:"committed" doc:"Whether the operations took effect; all of them do, or none"`
+			Results   []BatchResponse `json:"results" doc:"Outcome per operation, in request order"`
+		}
+	}
+	ImportRowResult struct {
+		Line   int    `json:"line" example:"2" doc:"Line of the row in the file"`
+		ID     string `json:"id,omitempty" doc:"ID of the todo item the row created or updated"`
+		Status string `json:"status" enum:"created,updated,unchanged,failed" doc:"What importing the row did, or would do in a dry run"`
+		Code   string `json:"code,omitempty" example:"validation_failed" doc:"Error code, as in problem responses, when the row failed"`
+		Field  string `json:"field,omitempty" example:"dueDate" doc:"Field that failed validation"`
+		Error  string `json:"error,omitempty" example:"unrecognised date \"tomorrow\"" doc:"Error message when the row failed"`
+	}
+	ImportSummary struct {
+		Created   int `json:"created" doc:"Number of rows that created a todo item"`
+		Updated   int `json:"updated" doc:"Number of rows that updated a todo item"`
+		Unchanged int `json:"unchanged" doc:"Number of rows that matched their todo item already"`
+		Failed    int `json:"failed" doc:"Number of rows that failed"`
+	}
+	ImportTodosOutput struct {
+		Body struct {
+			DryRun  bool              `json:"dryRun" doc:"Whether nothing was changed"`
+			Summary ImportSummary     `json:"summary" doc:"Number of rows per outcome"`
+			Rows    []ImportRowResult `json:"rows" doc:"Outcome per row, in file order"`
+		}
+	}
+	PatchTodoOutput struct {
+		ETag string `header:"ETag" doc:"New revision of the todo item"`
+		Body struct {
+			Todo *domain.Todo `json:"todo" doc:"Updated todo item"`
+		}
+	}
+	SharedTodo struct {
+		Todo       *domain.Todo      `json:"todo" doc:"Todo item shared with the caller"`
+		Owner      string            `json:"owner" example:"alice" doc:"User who shared the todo item"`
+		Permission domain.Permission `json:"permission" enum:"view,edit" example:"edit" doc:"Whether the caller can only read the todo item, or also edit it"`
+		SharedAt   time.Time         `json:"sharedAt" doc:"When the todo item was shared with the caller"`
+	}
+	ListSharedOutput struct {
+		Body struct {
+			Data []SharedTodo     `json:"data" doc:"Todo items shared with the caller, most recently shared first"`
+			Meta ListResponseMeta `json:"meta" doc:"Pagination metadata"`
+		}
+	}
+	ShareOutput struct {
+		Body struct {
+			Share *domain.Share `json:"share" doc:"Share of the todo item"`
+		}
+	}
+	ListSharesOutput struct {
+		Body struct {
+			Data []*domain.Share `json:"data" doc:"Users the todo item is shared with, oldest share first"`
+		}
+	}
+	RevokeShareOutput struct {
+		Body struct {
+			Message string `json:"message" example:"Share revoked successfully" doc:"Confirmation message"`
+		}
+	}
+	CommentOutput struct {
+		Body struct {
+			Comment *domain.Comment `json:"comment" doc:"Comment on the todo item"`
+		}
+	}
+	ListCommentsOutput struct {
+		Body struct {
+			Data []*domain.Comment `json:"data" doc:"Comments on the todo item, oldest first"`
+			Meta ListResponseMeta  `json:"meta" doc:"Pagination metadata"`
+		}
+	}
+	AttachmentOutput struct {
+		Body struct {
+			Attachment *domain.Attachment `json:"attachment" doc:"File attached to the todo item"`
+		}
+	}
+	DeleteAttachmentOutput struct {
+		Body struct {
+			Message string `json:"message" example:"Attachment deleted successfully" doc:"Confirmation message"`
+		}
+	}
+	DeleteCommentOutput struct {
+		Body struct {
+			Message string `json:"message" example:"Comment deleted successfully" doc:"Confirmation message"`
+		}
+	}
+)
+
+// Server-Sent Events of GET /todos/events. Each type is its own SSE event
+// name, see registerEvents.
+type (
+	TodoEventData struct {
+		Todo  *domain.Todo `json:"todo" doc:"State of the todo item after the change; the last state for deletes"`
+		Actor string       `json:"actor" doc:"User who made the change"`
+		At    time.Time    `json:"at" doc:"When the change was made"`
+	}
+	TodoCreatedEvent TodoEventData
+	TodoUpdatedEvent TodoEventData
+	TodoDeletedEvent TodoEventData
+	HeartbeatEvent   struct {
+		At time.Time `json:"at"`
+	}
+	ResetEvent struct {
+		Reason string `json:"reason" example:"events since Last-Event-ID are no longer available" doc:"Why the client has to reload its todos"`
+	}
 )
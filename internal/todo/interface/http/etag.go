@@ -0,0 +1,29 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+
+	"todo-app/internal/todo/domain"
+)
+
+// etag renders a todo version as a strong entity tag.
+func etag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// parseIfMatch turns an If-Match header into the version a write must
+// match. An empty header or "*" yields 0, which skips the version check.
+// A value that is not one of our ETags can never match.
+func parseIfMatch(header string) (int64, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, domain.ErrVersionConflict
+	}
+	return version, nil
+}
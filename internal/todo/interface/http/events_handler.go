@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"time"
+	"todo-app/internal/api/middleware"
+	"todo-app/internal/todo/usecase"
+
+	"github.com/danielgtaylor/huma/v2"
+	"github.com/danielgtaylor/huma/v2/sse"
+)
+
+// heartbeatInterval keeps idle streams from being cut by proxies.
+var heartbeatInterval = 15 * time.Second
+
+// retryMillis is the reconnection delay suggested to clients.
+const retryMillis = 3000
+
+func registerEvents(grp huma.API, handler *TodoHandler, security []map[string][]string) {
+	sse.Register(grp, huma.Operation{
+		OperationID: "stream-todo-events",
+		Summary:     "Stream changes to your todo items",
+		Description: "Server-Sent Events for todo items you created. Reconnect with Last-Event-ID to receive what you missed; a reset event means the gap is too large and the list has to be reloaded.",
+		Method:      http.MethodGet,
+		Path:        "/events",
+		Security:    security,
+	}, map[string]any{
+		string(usecase.StreamCreated): TodoCreatedEvent{},
+		string(usecase.StreamUpdated): TodoUpdatedEvent{},
+		string(usecase.StreamDeleted): TodoDeletedEvent{},
+		"heartbeat":                   HeartbeatEvent{},
+		"reset":                       ResetEvent{},
+	}, handler.Events)
+}
+
+func (h *TodoHandler) Events(ctx context.Context, input *TodoEventsInput, send sse.Sender) {
+	replay, complete, events, cancel := h.broker.Subscribe(middleware.UserID(ctx), input.LastEventID)
+	defer cancel()
+
+	if !complete {
+		if send(sse.Message{Retry: retryMillis, Data: &ResetEvent{Reason: "events since Last-Event-ID are no longer available"}}) != nil {
+			return
+		}
+	} else if send(sse.Message{Retry: retryMillis, Data: &HeartbeatEvent{At: time.Now()}}) != nil {
+		// the first message confirms the stream and sets the retry delay
+		return
+	}
+	for _, event := range replay {
+		if send(streamMessage(event)) != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				// dropped for lagging behind or shutting down; the client
+				// reconnects and resumes from the replay buffer
+				return
+			}
+			if send(streamMessage(event)) != nil {
+				return
+			}
+		case now := <-heartbeat.C:
+			if send(sse.Message{Data: &HeartbeatEvent{At: now}}) != nil {
+				return
+			}
+		}
+	}
+}
+
+func streamMessage(event usecase.StreamEvent) sse.Message {
+	data := TodoEventData{Todo: event.Todo, Actor: event.Actor, At: event.At}
+	msg := sse.Message{ID: event.ID}
+	switch event.Type {
+	case usecase.StreamCreated:
+		msg.Data = TodoCreatedEvent(data)
+	case usecase.StreamUpdated:
+		msg.Data = TodoUpdatedEvent(data)
+	default:
+		msg.Data = TodoDeletedEvent(data)
+	}
+	return msg
+}
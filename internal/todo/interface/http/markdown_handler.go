@@ -0,0 +1,68 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"todo-app/internal/api/middleware"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/interface/format"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func registerMarkdown(grp huma.API, handler *TodoHandler, security []map[string][]string) {
+	huma.Register(grp, huma.Operation{
+		OperationID: "export-todos-markdown",
+		Summary:     "Export todo items as a Markdown task list",
+		Description: "Renders the todo items matching the list filters as GitHub-style task lists, under a heading per due date or tag.",
+		Method:      http.MethodGet,
+		Path:        "/export.md",
+		Security:    security,
+		Responses: map[string]*huma.Response{
+			"200": {Description: "Markdown document", Content: map[string]*huma.MediaType{"text/markdown": {}}},
+		},
+	}, handler.ExportMarkdown)
+	huma.Register(grp, huma.Operation{
+		OperationID: "import-todos-markdown",
+		Summary:     "Import todo items from a Markdown task list",
+		Description: "Creates a todo item per task list item of the document; task items nested in it become its checklist. " +
+			"Failing items are reported and do not stop the others.",
+		Method:       http.MethodPost,
+		Path:         "/import.md",
+		Security:     security,
+		MaxBodyBytes: 5 << 20,
+	}, handler.ImportMarkdown)
+}
+
+func (h *TodoHandler) ExportMarkdown(ctx context.Context, input *ExportMarkdownInput) (*huma.StreamResponse, error) {
+	// grouping needs every todo before the first heading can be written
+	filter, err := input.filter(middleware.UserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	var todos []*domain.Todo
+	for page := 0; ; page++ {
+		list, _, err := h.uc.GetAllTodos(page, exportPageSize, filter)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, list...)
+		if len(list) < exportPageSize {
+			break
+		}
+	}
+	return &huma.StreamResponse{Body: func(hctx huma.Context) {
+		hctx.SetHeader("Content-Type", "text/markdown; charset=utf-8")
+		hctx.SetHeader("Content-Disposition", `attachment; filename="todos.md"`)
+		_ = format.WriteMarkdown(hctx.BodyWriter(), todos, input.GroupBy)
+	}}, nil
+}
+
+func (h *TodoHandler) ImportMarkdown(ctx context.Context, input *ImportMarkdownInput) (*ImportTodosOutput, error) {
+	rows, err := format.ReadMarkdown(bytes.NewReader(input.RawBody))
+	if err != nil {
+		return nil, err
+	}
+	return h.importRows(ctx, rows, input.DryRun)
+}
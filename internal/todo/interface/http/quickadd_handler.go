@@ -0,0 +1,46 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"todo-app/internal/api/middleware"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func registerQuickAdd(grp huma.API, handler *TodoHandler, security []map[string][]string) {
+	huma.Register(grp, huma.Operation{
+		OperationID: "quick-add-todo",
+		Summary:     "Create a todo item from a line of text",
+		Description: "Reads the title, due date and time, recurrence, #tags and !priority of a todo item from text such as " +
+			"\"pay rent every month on the 1st #bills !high\", in the time zone given, and creates it. " +
+			"The response says how the text was read; with dryRun it is only read, so that clients can preview it.",
+		Method:   http.MethodPost,
+		Path:     "/quick",
+		Security: security,
+	}, handler.QuickAdd)
+}
+
+func (h *TodoHandler) QuickAdd(ctx context.Context, input *QuickAddInput) (*QuickAddOutput, error) {
+	result, err := h.uc.QuickAdd(middleware.UserID(ctx), input.Body.Text, input.Body.TimeZone, input.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	resp := &QuickAddOutput{}
+	resp.Body.Interpretation = QuickAddInterpretation{
+		Title:      result.Title,
+		AllDay:     result.AllDay,
+		Recurrence: result.Rule,
+		Tags:       result.Tags,
+		Priority:   result.Priority,
+		Phrases:    make([]QuickAddPhrase, 0, len(result.Phrases)),
+	}
+	if !result.DueDate.IsZero() {
+		resp.Body.Interpretation.DueDate = &result.DueDate
+	}
+	for _, phrase := range result.Phrases {
+		resp.Body.Interpretation.Phrases = append(resp.Body.Interpretation.Phrases, QuickAddPhrase{Text: phrase.Text, Kind: phrase.Kind})
+	}
+	resp.Body.Todo = result.Todo
+	return resp, nil
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"todo-app/internal/api/middleware"
+	"todo-app/internal/todo/domain"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func registerRecurrence(grp huma.API, handler *TodoHandler, security []map[string][]string) {
+	huma.Register(grp, huma.Operation{
+		OperationID: "set-recurrence",
+		Summary:     "Make a todo item recurring",
+		Description: "Starts a new series at the todo's current due date. Completing the todo generates the next occurrence.",
+		Method:      http.MethodPut,
+		Path:        "/{id}/recurrence",
+		Security:    security,
+	}, handler.SetRecurrence)
+	huma.Register(grp, huma.Operation{
+		OperationID: "end-recurrence",
+		Summary:     "End a recurring series",
+		Description: "Removes the recurrence so completing the todo no longer generates a next occurrence.",
+		Method:      http.MethodDelete,
+		Path:        "/{id}/recurrence",
+		Security:    security,
+	}, handler.EndSeries)
+	huma.Register(grp, huma.Operation{
+		OperationID: "skip-occurrence",
+		Summary:     "Skip to the next occurrence of a recurring todo",
+		Description: "Moves the todo's due date to its next occurrence without completing it.",
+		Method:      http.MethodPost,
+		Path:        "/{id}/recurrence/skip",
+		Security:    security,
+	}, handler.SkipOccurrence)
+}
+
+func (h *TodoHandler) SetRecurrence(ctx context.Context, input *SetRecurrenceInput) (*RecurrenceOutput, error) {
+	version, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := h.uc.SetRecurrence(middleware.UserID(ctx), input.ID, input.Body.Rule, input.Body.TimeZone, version)
+	return recurrenceOutput(todo, err)
+}
+
+func (h *TodoHandler) EndSeries(ctx context.Context, input *RecurrenceActionInput) (*RecurrenceOutput, error) {
+	version, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := h.uc.EndSeries(middleware.UserID(ctx), input.ID, version)
+	return recurrenceOutput(todo, err)
+}
+
+func (h *TodoHandler) SkipOccurrence(ctx context.Context, input *RecurrenceActionInput) (*RecurrenceOutput, error) {
+	version, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := h.uc.SkipOccurrence(middleware.UserID(ctx), input.ID, version)
+	return recurrenceOutput(todo, err)
+}
+
+func recurrenceOutput(todo *domain.Todo, err error) (*RecurrenceOutput, error) {
+	if err != nil {
+		return nil, err
+	}
+	resp := &RecurrenceOutput{}
+	resp.ETag = etag(todo.Version)
+	resp.Body.Todo = todo
+	return resp, nil
+}
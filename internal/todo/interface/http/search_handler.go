@@ -0,0 +1,39 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"todo-app/internal/api/middleware"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func registerSearch(grp huma.API, handler *TodoHandler, security []map[string][]string) {
+	huma.Register(grp, huma.Operation{
+		OperationID: "search-todos",
+		Summary:     "Search todo items",
+		Description: "Finds the todo items whose title or tags have any word of the query, best matches first. " +
+			"Words match whole and regardless of case; the list filters narrow the results down.",
+		Method:   http.MethodGet,
+		Path:     "/search",
+		Security: security,
+	}, handler.Search)
+}
+
+func (h *TodoHandler) Search(ctx context.Context, input *SearchTodosInput) (*SearchTodosOutput, error) {
+	filter, err := input.filter(middleware.UserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	results, total, err := h.uc.SearchTodos(input.Q, filter, input.Page, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := &SearchTodosOutput{}
+	resp.Body.Data = make([]SearchResult, 0, len(results))
+	for _, r := range results {
+		resp.Body.Data = append(resp.Body.Data, SearchResult{Todo: r.Todo, Score: r.Score, Highlight: r.Highlight, MatchedTags: r.MatchedTags})
+	}
+	resp.Body.Meta = ListResponseMeta{Page: input.Page, Limit: input.Limit, Total: total}
+	return resp, nil
+}
@@ -0,0 +1,101 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"todo-app/internal/api/middleware"
+	"todo-app/internal/todo/domain"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+// registerSharedWithMe registers the static path of the shares; it has to
+// go before /{id}.
+func registerSharedWithMe(grp huma.API, handler *TodoHandler, security []map[string][]string) {
+	huma.Register(grp, huma.Operation{
+		OperationID: "list-shared-todos",
+		Summary:     "List todo items other users shared with you",
+		Method:      http.MethodGet,
+		Path:        "/shared",
+		Security:    security,
+	}, handler.ListShared)
+}
+
+func registerShares(grp huma.API, handler *TodoHandler, security []map[string][]string) {
+	huma.Register(grp, huma.Operation{
+		OperationID: "share-todo",
+		Summary:     "Share a todo item with another user",
+		Description: "Lets a registered user read the todo item, or also edit it. Sharing with the same user again changes the permission. " +
+			"Only the owner can share a todo item, delete it or move it to another project.",
+		Method:   http.MethodPost,
+		Path:     "/{id}/shares",
+		Security: security,
+	}, handler.Share)
+	huma.Register(grp, huma.Operation{
+		OperationID: "list-todo-shares",
+		Summary:     "List who a todo item is shared with",
+		Method:      http.MethodGet,
+		Path:        "/{id}/shares",
+		Security:    security,
+	}, handler.ListShares)
+	huma.Register(grp, huma.Operation{
+		OperationID: "revoke-todo-share",
+		Summary:     "Stop sharing a todo item with a user",
+		Description: "The owner can revoke any share of the todo item; the user it is shared with can give up their own.",
+		Method:      http.MethodDelete,
+		Path:        "/{id}/shares/{username}",
+		Security:    security,
+	}, handler.RevokeShare)
+}
+
+func (h *TodoHandler) ListShared(ctx context.Context, input *ListSharedInput) (*ListSharedOutput, error) {
+	list, total, err := h.uc.SharedWithMe(middleware.UserID(ctx), input.Page, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListSharedOutput{}
+	resp.Body.Data = make([]SharedTodo, 0, len(list))
+	for _, shared := range list {
+		resp.Body.Data = append(resp.Body.Data, SharedTodo{
+			Todo:       shared.Todo,
+			Owner:      shared.Share.Owner,
+			Permission: shared.Share.Permission,
+			SharedAt:   shared.Share.CreatedAt,
+		})
+	}
+	resp.Body.Meta = ListResponseMeta{
+		Page:  input.Page,
+		Limit: input.Limit,
+		Total: total,
+	}
+	return resp, nil
+}
+
+func (h *TodoHandler) Share(ctx context.Context, input *ShareTodoInput) (*ShareOutput, error) {
+	share, err := h.uc.ShareTodo(middleware.UserID(ctx), input.ID, input.Body.Username, domain.Permission(input.Body.Permission))
+	if err != nil {
+		return nil, err
+	}
+	resp := &ShareOutput{}
+	resp.Body.Share = share
+	return resp, nil
+}
+
+func (h *TodoHandler) ListShares(ctx context.Context, input *ListSharesInput) (*ListSharesOutput, error) {
+	shares, err := h.uc.ListShares(middleware.UserID(ctx), input.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListSharesOutput{}
+	resp.Body.Data = shares
+	return resp, nil
+}
+
+func (h *TodoHandler) RevokeShare(ctx context.Context, input *RevokeShareInput) (*RevokeShareOutput, error) {
+	if err := h.uc.RevokeShare(middleware.UserID(ctx), input.ID, input.Username); err != nil {
+		return nil, err
+	}
+	resp := &RevokeShareOutput{}
+	resp.Body.Message = "Share revoked successfully"
+	return resp, nil
+}
@@ -3,17 +3,22 @@ package http
 import (
 	"context"
 	"net/http"
+	"time"
+	"todo-app/internal/api/middleware"
+	"todo-app/internal/api/problem"
+	"todo-app/internal/todo/domain"
 	"todo-app/internal/todo/usecase"
 
 	"github.com/danielgtaylor/huma/v2"
 )
 
 type TodoHandler struct {
-	uc *usecase.TodoUseCase
+	uc     *usecase.TodoUseCase
+	broker *usecase.Broker
 }
 
-func NewTodoHandler(api huma.API, uc *usecase.TodoUseCase) {
-	handler := &TodoHandler{uc: uc}
+func NewTodoHandler(api huma.API, uc *usecase.TodoUseCase, broker *usecase.Broker) {
+	handler := &TodoHandler{uc: uc, broker: broker}
 
 	grp := huma.NewGroup(api, "/todos")
 	myAuthSecurity := []map[string][]string{
@@ -33,6 +38,42 @@ func NewTodoHandler(api huma.API, uc *usecase.TodoUseCase) {
 		Path:        "",
 		Security:    myAuthSecurity,
 	}, handler.List)
+	huma.Register(grp, huma.Operation{
+		OperationID: "bulk-todos",
+		Summary:     "Apply an operation to many todo items at once",
+		Description: "Completes, reopens, deletes, retags or reschedules the todo items selected by ID or by a list filter, and reports the outcome per item.",
+		Method:      http.MethodPost,
+		Path:        "/bulk",
+		Security:    myAuthSecurity,
+	}, handler.Bulk)
+	huma.Register(grp, huma.Operation{
+		OperationID: "list-trash",
+		Summary:     "List todo items in the trash",
+		Method:      http.MethodGet,
+		Path:        "/trash",
+		Security:    myAuthSecurity,
+	}, handler.ListTrash)
+	huma.Register(grp, huma.Operation{
+		OperationID: "restore-todo",
+		Summary:     "Restore a todo item from the trash",
+		Method:      http.MethodPost,
+		Path:        "/trash/{id}/restore",
+		Security:    myAuthSecurity,
+	}, handler.Restore)
+	huma.Register(grp, huma.Operation{
+		OperationID: "purge-todo",
+		Summary:     "Permanently delete a todo item from the trash",
+		Method:      http.MethodDelete,
+		Path:        "/trash/{id}",
+		Security:    myAuthSecurity,
+	}, handler.Purge)
+	// static paths go before /{id} for routers that match in order
+	registerSearch(grp, handler, myAuthSecurity)
+	registerQuickAdd(grp, handler, myAuthSecurity)
+	registerCSV(grp, handler, myAuthSecurity)
+	registerTodoTxt(grp, handler, myAuthSecurity)
+	registerMarkdown(grp, handler, myAuthSecurity)
+	registerSharedWithMe(grp, handler, myAuthSecurity)
 	huma.Register(grp, huma.Operation{
 		OperationID: "get-todo-by-id",
 		Summary:     "Get a todo item by ID",
@@ -40,9 +81,16 @@ func NewTodoHandler(api huma.API, uc *usecase.TodoUseCase) {
 		Path:        "/{id}",
 		Security:    myAuthSecurity,
 	}, handler.GetByID)
+	huma.Register(grp, huma.Operation{
+		OperationID: "get-todo-history",
+		Summary:     "List the change history of a todo item",
+		Method:      http.MethodGet,
+		Path:        "/{id}/history",
+		Security:    myAuthSecurity,
+	}, handler.History)
 	huma.Register(grp, huma.Operation{
 		OperationID: "delete-todo-by-id",
-		Summary:     "Delete a todo item by ID",
+		Summary:     "Move a todo item to the trash by ID",
 		Method:      http.MethodDelete,
 		Path:        "/{id}",
 		Security:    myAuthSecurity,
@@ -54,19 +102,56 @@ func NewTodoHandler(api huma.API, uc *usecase.TodoUseCase) {
 		Path:        "/{id}",
 		Security:    myAuthSecurity,
 	}, handler.UpdateByID)
+	huma.Register(grp, huma.Operation{
+		OperationID: "patch-todo-by-id",
+		Summary:     "Partially update a todo item by ID",
+		Method:      http.MethodPatch,
+		Path:        "/{id}",
+		Security:    myAuthSecurity,
+	}, handler.PatchByID)
+	registerBatch(api, handler, myAuthSecurity)
+	registerEvents(grp, handler, myAuthSecurity)
+	registerChecklist(grp, handler, myAuthSecurity)
+	registerRecurrence(grp, handler, myAuthSecurity)
+	registerShares(grp, handler, myAuthSecurity)
+	registerComments(grp, handler, myAuthSecurity)
+	registerAttachments(grp, handler, myAuthSecurity)
 }
 
 func (h *TodoHandler) Create(ctx context.Context, input *CreateTodoInput) (*CreateTodoOutput, error) {
-	err := h.uc.CreateTodo(input.Body.Title, input.Body.DueDate, input.Body.Done)
+	var opts []usecase.TodoOption
+	if len(input.Body.Tags) > 0 {
+		opts = append(opts, usecase.WithTags(input.Body.Tags))
+	}
+	if input.Body.Priority != "" {
+		opts = append(opts, usecase.WithPriority(input.Body.Priority))
+	}
+	if input.Body.AllDay || input.Body.TimeZone != "" {
+		opts = append(opts, usecase.WithDueZone(input.Body.AllDay, input.Body.TimeZone))
+	}
+	if rec := input.Body.Recurrence; rec != nil {
+		opts = append(opts, usecase.WithRecurrence(rec.Rule, rec.TimeZone))
+	}
+	if input.Body.ProjectID != "" {
+		opts = append(opts, usecase.WithProject(input.Body.ProjectID))
+	}
+	if input.Body.Assignee != "" {
+		opts = append(opts, usecase.WithAssignee(input.Body.Assignee))
+	}
+	err := h.uc.CreateTodo(middleware.UserID(ctx), input.Body.Title, input.Body.DueDate, input.Body.Done, opts...)
 	if err != nil {
-		return nil, huma.Error400BadRequest("Failed to create todo", err)
+		return nil, err
 	}
 	resp := &CreateTodoOutput{}
 	resp.Body.Message = "Todo item created successfully"
 	return resp, nil
 }
 func (h *TodoHandler) List(ctx context.Context, input *ListTodosInput) (*ListTodosOutput, error) {
-	todos, total, err := h.uc.GetAllTodos(input.Page, input.Limit, input.Title)
+	filter, err := input.filter(middleware.UserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	todos, total, err := h.uc.GetAllTodos(input.Page, input.Limit, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -80,22 +165,113 @@ func (h *TodoHandler) List(ctx context.Context, input *ListTodosInput) (*ListTod
 	return resp, nil
 
 }
-func (h *TodoHandler) GetByID(ctx context.Context, input *struct {
-	ID string `path:"id" doc:"ID of the todo item"`
-}) (*GetTodoByIDOutput, error) {
-	todo, err := h.uc.GetTodoByID(input.ID)
+func (h *TodoHandler) Bulk(ctx context.Context, input *BulkTodosInput) (*BulkTodosOutput, error) {
+	body := input.Body
+	if (len(body.IDs) > 0) == (body.Filter != nil) {
+		return nil, huma.Error422UnprocessableEntity("validation failed", &huma.ErrorDetail{
+			Message:  "give either ids or filter",
+			Location: "body.ids",
+		})
+	}
+	sel := usecase.BulkSelection{IDs: body.IDs}
+	if f := body.Filter; f != nil {
+		sel.Filter = domain.TodoFilter{Title: f.Title, Done: f.Done, Tag: f.Tag, Owner: middleware.UserID(ctx), Project: f.Project}
+		if err := dueFilter(&sel.Filter, f.Due, f.TimeZone); err != nil {
+			return nil, err
+		}
+	}
+	change := domain.BulkChange{
+		AddTags:    body.AddTags,
+		RemoveTags: body.RemoveTags,
+		DueDate:    body.DueDate,
+		Shift:      time.Duration(body.ShiftMinutes) * time.Minute,
+		ProjectID:  body.ProjectID,
+	}
+	results, err := h.uc.BulkTodos(middleware.UserID(ctx), sel, domain.BulkAction(body.Action), change)
+	if err != nil {
+		return nil, err
+	}
+	resp := &BulkTodosOutput{}
+	resp.Body.Results = make([]BulkItemResult, 0, len(results))
+	for _, r := range results {
+		item := BulkItemResult{ID: r.ID, Status: string(r.Status), Todo: r.Todo}
+		switch r.Status {
+		case domain.BulkApplied:
+			resp.Body.Summary.Applied++
+		case domain.BulkSkipped:
+			resp.Body.Summary.Skipped++
+		case domain.BulkFailed:
+			resp.Body.Summary.Failed++
+			item.Code = "error"
+			if p := problem.FromError(r.Err); p != nil {
+				item.Code = p.Code
+			}
+			item.Error = r.Err.Error()
+		}
+		resp.Body.Results = append(resp.Body.Results, item)
+	}
+	return resp, nil
+}
+
+// filter selects the todos of owner, or those assigned to owner with
+// assigned=me; todos shared with the caller are listed apart.
+func (p TodoFilterParams) filter(owner string) (domain.TodoFilter, error) {
+	filter := domain.TodoFilter{Title: p.Title, Tag: p.Tag, Owner: owner, Project: p.Project}
+	if p.Assigned == "me" {
+		filter.Owner, filter.Assignee = "", owner
+	}
+	if p.Done != "" {
+		done := p.Done == "true"
+		filter.Done = &done
+	}
+	return filter, dueFilter(&filter, p.Due, p.TimeZone)
+}
+
+// dueFilter sets the due filter, which runs by the days of the caller's
+// time zone.
+func dueFilter(filter *domain.TodoFilter, due, timeZone string) error {
+	loc, err := domain.LoadLocation(timeZone)
+	if err != nil {
+		return err
+	}
+	if due != "" {
+		filter.Due, filter.Now = due, time.Now().In(loc)
+	}
+	return nil
+}
+
+func (h *TodoHandler) GetByID(ctx context.Context, input *GetTodoByIDInput) (*GetTodoByIDOutput, error) {
+	todo, err := h.uc.GetTodoByID(middleware.UserID(ctx), input.ID)
 	if err != nil {
 		return nil, err
 	}
 	resp := &GetTodoByIDOutput{}
+	resp.ETag = etag(todo.Version)
 	resp.Body.Todo = todo
 	return resp, nil
 }
 
-func (h *TodoHandler) DeleteByID(ctx context.Context, input *struct {
-	ID string `path:"id" doc:"ID of the todo item"`
-}) (*DeleteTodoOutput, error) {
-	err := h.uc.DeleteTodo(input.ID)
+func (h *TodoHandler) History(ctx context.Context, input *GetHistoryInput) (*GetHistoryOutput, error) {
+	entries, total, err := h.uc.GetHistory(middleware.UserID(ctx), input.ID, input.Page, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := &GetHistoryOutput{}
+	resp.Body.Data = entries
+	resp.Body.Meta = ListResponseMeta{
+		Page:  input.Page,
+		Limit: input.Limit,
+		Total: total,
+	}
+	return resp, nil
+}
+
+func (h *TodoHandler) DeleteByID(ctx context.Context, input *DeleteTodoInput) (*DeleteTodoOutput, error) {
+	version, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	err = h.uc.DeleteTodo(middleware.UserID(ctx), input.ID, version)
 	if err != nil {
 		return nil, err
 	}
@@ -105,11 +281,85 @@ func (h *TodoHandler) DeleteByID(ctx context.Context, input *struct {
 }
 
 func (h *TodoHandler) UpdateByID(ctx context.Context, input *UpdateTodoInput) (*UpdateTodoOutput, error) {
-	err := h.uc.UpdateTodo(input.ID, input.Body.Title, input.Body.DueDate, input.Body.Done)
+	version, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := h.uc.UpdateTodo(middleware.UserID(ctx), input.ID, input.Body.Title, input.Body.DueDate, input.Body.Done, version,
+		usecase.WithDueZone(input.Body.AllDay, input.Body.TimeZone))
 	if err != nil {
 		return nil, err
 	}
 	resp := &UpdateTodoOutput{}
+	resp.ETag = etag(todo.Version)
 	resp.Body.Message = "Todo item updated successfully"
 	return resp, nil
 }
+
+func (h *TodoHandler) PatchByID(ctx context.Context, input *PatchTodoInput) (*PatchTodoOutput, error) {
+	version, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := h.uc.PatchTodo(middleware.UserID(ctx), input.ID, usecase.TodoPatch{
+		Title:     input.Body.Title,
+		DueDate:   input.Body.DueDate,
+		AllDay:    input.Body.AllDay,
+		TimeZone:  input.Body.TimeZone,
+		Done:      input.Body.Done,
+		Tags:      input.Body.Tags,
+		Priority:  input.Body.Priority,
+		ProjectID: input.Body.ProjectID,
+		Assignee:  input.Body.Assignee,
+	}, version)
+	if err != nil {
+		return nil, err
+	}
+	resp := &PatchTodoOutput{}
+	resp.ETag = etag(todo.Version)
+	resp.Body.Todo = todo
+	return resp, nil
+}
+
+func (h *TodoHandler) ListTrash(ctx context.Context, input *ListTrashInput) (*ListTodosOutput, error) {
+	todos, total, err := h.uc.GetTrash(middleware.UserID(ctx), input.Page, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListTodosOutput{}
+	resp.Body.Data = todos
+	resp.Body.Meta = ListResponseMeta{
+		Page:  input.Page,
+		Limit: input.Limit,
+		Total: total,
+	}
+	return resp, nil
+}
+
+func (h *TodoHandler) Restore(ctx context.Context, input *TrashItemInput) (*RestoreTodoOutput, error) {
+	version, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := h.uc.RestoreTodo(middleware.UserID(ctx), input.ID, version)
+	if err != nil {
+		return nil, err
+	}
+	resp := &RestoreTodoOutput{}
+	resp.ETag = etag(todo.Version)
+	resp.Body.Todo = todo
+	return resp, nil
+}
+
+func (h *TodoHandler) Purge(ctx context.Context, input *TrashItemInput) (*PurgeTodoOutput, error) {
+	version, err := parseIfMatch(input.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.uc.PurgeTodo(middleware.UserID(ctx), input.ID, version); err != nil {
+		return nil, err
+	}
+	resp := &PurgeTodoOutput{}
+	resp.Body.Message = "Todo item permanently deleted"
+	return resp, nil
+}
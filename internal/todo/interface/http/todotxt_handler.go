@@ -0,0 +1,54 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"todo-app/internal/api/middleware"
+	"todo-app/internal/todo/interface/format"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+func registerTodoTxt(grp huma.API, handler *TodoHandler, security []map[string][]string) {
+	huma.Register(grp, huma.Operation{
+		OperationID: "export-todos-todotxt",
+		Summary:     "Export todo items as todo.txt",
+		Description: "Streams the todo items matching the list filters in todo.txt format, one line per todo item.",
+		Method:      http.MethodGet,
+		Path:        "/export.txt",
+		Security:    security,
+		Responses: map[string]*huma.Response{
+			"200": {Description: "todo.txt file", Content: map[string]*huma.MediaType{"text/plain": {}}},
+		},
+	}, handler.ExportTodoTxt)
+	huma.Register(grp, huma.Operation{
+		OperationID: "import-todos-todotxt",
+		Summary:     "Import todo items from todo.txt",
+		Description: "Creates or updates a todo item per line: lines whose id: key names a todo item replace it, other lines create one. " +
+			"Failing lines are reported and do not stop the others.",
+		Method:       http.MethodPost,
+		Path:         "/import.txt",
+		Security:     security,
+		MaxBodyBytes: 5 << 20,
+	}, handler.ImportTodoTxt)
+}
+
+func (h *TodoHandler) ExportTodoTxt(ctx context.Context, input *ExportTodosInput) (*huma.StreamResponse, error) {
+	filter, err := input.filter(middleware.UserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return h.export(filter, "text/plain; charset=utf-8", "todo.txt", func(out io.Writer) (todoWriter, error) {
+		return format.NewTodoTxtWriter(out), nil
+	})
+}
+
+func (h *TodoHandler) ImportTodoTxt(ctx context.Context, input *ImportTodoTxtInput) (*ImportTodosOutput, error) {
+	rows, err := format.ReadTodoTxt(bytes.NewReader(input.RawBody))
+	if err != nil {
+		return nil, err
+	}
+	return h.importRows(ctx, rows, input.DryRun)
+}
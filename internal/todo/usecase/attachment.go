@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"bufio"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/todo/domain"
+	"unicode/utf8"
+)
+
+// maxFilenameLength is the most characters the name of an attached file
+// can have.
+const maxFilenameLength = 255
+
+// UseAttachments sets where the content of attachments is kept and which
+// files can be attached. Like Subscribe, it is meant to be called during
+// setup.
+func (uc *TodoUseCase) UseAttachments(blobs domain.BlobStore, limits domain.AttachmentLimits) {
+	uc.blobs, uc.attachmentLimits = blobs, limits
+}
+
+// AttachmentLimits returns which files can be attached.
+func (uc *TodoUseCase) AttachmentLimits() domain.AttachmentLimits {
+	return uc.attachmentLimits
+}
+
+// AttachmentUpload is a file to attach to a todo.
+type AttachmentUpload struct {
+	Filename string
+	// ContentType is the media type the client gave; when it is missing
+	// or generic, the type is sniffed from the content.
+	ContentType string
+	// Size is the size the client gave, used to turn large files away
+	// early; the size stored is what was actually read from Content.
+	Size    int64
+	Content io.Reader
+}
+
+// AddAttachment attaches a file to a todo actor can edit, within the
+// attachment limits.
+func (uc *TodoUseCase) AddAttachment(actor, todoID string, upload AttachmentUpload) (*domain.Attachment, error) {
+	if uc.blobs == nil {
+		return nil, &domain.ValidationError{Field: "file", Message: "attachments are not available"}
+	}
+	filename, err := attachmentFilename(upload.Filename)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := uc.repo.FindByID(todoID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorize(actor, todo, domain.PermissionEdit); err != nil {
+		return nil, err
+	}
+	if len(todo.Attachments) >= domain.MaxAttachments {
+		return nil, tooManyAttachments()
+	}
+	maxSize := uc.attachmentLimits.MaxSize
+	if maxSize > 0 && upload.Size > maxSize {
+		return nil, domain.ErrAttachmentTooLarge
+	}
+	content := bufio.NewReader(upload.Content)
+	contentType := attachmentType(upload.ContentType, content)
+	if !uc.attachmentLimits.Allows(contentType) {
+		return nil, domain.ErrAttachmentType
+	}
+
+	attachment := domain.Attachment{
+		ID:          generateID(),
+		Filename:    filename,
+		ContentType: contentType,
+		UploadedBy:  actor,
+		UploadedAt:  time.Now(),
+	}
+	counted := &countingReader{r: content}
+	var r io.Reader = counted
+	if maxSize > 0 {
+		// one byte more than allowed tells a file at the limit from one over it
+		r = io.LimitReader(counted, maxSize+1)
+	}
+	if err := uc.blobs.Put(attachment.ID, r); err != nil {
+		return nil, err
+	}
+	attachment.Size = counted.n
+	if maxSize > 0 && attachment.Size > maxSize {
+		uc.deleteBlob(attachment.ID)
+		return nil, domain.ErrAttachmentTooLarge
+	}
+	_, err = uc.mutate(actor, todoID, 0, func(todo *domain.Todo) error {
+		if len(todo.Attachments) >= domain.MaxAttachments {
+			return tooManyAttachments()
+		}
+		todo.Attachments = append(todo.Attachments, attachment)
+		return nil
+	})
+	if err != nil {
+		uc.deleteBlob(attachment.ID)
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// OpenAttachment returns an attachment of a todo actor can see, and its
+// content, which the caller closes.
+func (uc *TodoUseCase) OpenAttachment(actor, todoID, id string) (*domain.Attachment, io.ReadCloser, error) {
+	todo, err := uc.GetTodoByID(actor, todoID)
+	if err != nil {
+		return nil, nil, err
+	}
+	attachment := findAttachment(todo, id)
+	if attachment == nil || uc.blobs == nil {
+		return nil, nil, domain.ErrAttachmentNotFound
+	}
+	content, err := uc.blobs.Open(attachment.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attachment, content, nil
+}
+
+// DeleteAttachment removes an attachment from a todo actor can edit.
+func (uc *TodoUseCase) DeleteAttachment(actor, todoID, id string) error {
+	_, err := uc.mutate(actor, todoID, 0, func(todo *domain.Todo) error {
+		for i := range todo.Attachments {
+			if todo.Attachments[i].ID == id {
+				todo.Attachments = append(todo.Attachments[:i], todo.Attachments[i+1:]...)
+				return nil
+			}
+		}
+		return domain.ErrAttachmentNotFound
+	})
+	if err != nil {
+		return err
+	}
+	uc.deleteBlob(id)
+	return nil
+}
+
+// deleteBlob deletes the content of an attachment that is gone. The
+// attachment is no longer reachable either way, so a failure is only
+// logged.
+func (uc *TodoUseCase) deleteBlob(id string) {
+	if uc.blobs == nil {
+		return
+	}
+	if err := uc.blobs.Delete(id); err != nil {
+		log.Printf("todo attachments: delete content of %s: %v", id, err)
+	}
+}
+
+func findAttachment(todo *domain.Todo, id string) *domain.Attachment {
+	for i := range todo.Attachments {
+		if todo.Attachments[i].ID == id {
+			return &todo.Attachments[i]
+		}
+	}
+	return nil
+}
+
+func tooManyAttachments() error {
+	return &domain.ValidationError{Field: "file", Message: "a todo can have at most " + strconv.Itoa(domain.MaxAttachments) + " attachments"}
+}
+
+// attachmentFilename keeps the last element of the path a client gave as
+// the name of a file.
+func attachmentFilename(name string) (string, error) {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." {
+		return "", &domain.ValidationError{Field: "file", Message: "must have a filename"}
+	}
+	if utf8.RuneCountInString(name) > maxFilenameLength {
+		return "", &domain.ValidationError{Field: "file", Message: "filename must be at most " + strconv.Itoa(maxFilenameLength) + " characters long"}
+	}
+	return name, nil
+}
+
+// attachmentType is the media type of a file without parameters: the one
+// given, unless it is missing or generic, in which case it is sniffed from
+// the start of content.
+func attachmentType(given string, content *bufio.Reader) string {
+	mediaType, _, err := mime.ParseMediaType(given)
+	if err == nil && mediaType != "application/octet-stream" {
+		return mediaType
+	}
+	head, _ := content.Peek(512)
+	mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(head))
+	return mediaType
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/infrastructure/blob"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAttachmentUseCase(t *testing.T) (*TodoUseCase, *domain.Todo, *blob.LocalBlobStore) {
+	t.Helper()
+	uc, todo := newSharingUseCase(t)
+	blobs, err := blob.NewLocalBlobStore(t.TempDir())
+	require.NoError(t, err)
+	uc.UseAttachments(blobs, domain.AttachmentLimits{MaxSize: 16, ContentTypes: []string{"text/plain", "image/*"}})
+	return uc, todo, blobs
+}
+
+func upload(name, contentType, content string) AttachmentUpload {
+	return AttachmentUpload{Filename: name, ContentType: contentType, Size: int64(len(content)), Content: strings.NewReader(content)}
+}
+
+func TestAddAttachment(t *testing.T) {
+	uc, todo, _ := newAttachmentUseCase(t)
+	_, err := uc.ShareTodo("alice", todo.ID, "bob", domain.PermissionView)
+	require.NoError(t, err)
+	var invalid *domain.ValidationError
+
+	_, err = uc.AddAttachment("alice", todo.ID, upload("", "text/plain", "hi"))
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "file", invalid.Field)
+	_, err = uc.AddAttachment("alice", todo.ID, upload("page.html", "text/html", "<p>hi</p>"))
+	assert.ErrorIs(t, err, domain.ErrAttachmentType)
+	_, err = uc.AddAttachment("alice", todo.ID, upload("big.txt", "text/plain", strings.Repeat("x", 17)))
+	assert.ErrorIs(t, err, domain.ErrAttachmentTooLarge)
+	// a size the client understated is caught while reading
+	_, err = uc.AddAttachment("alice", todo.ID, AttachmentUpload{Filename: "big.txt", Content: strings.NewReader(strings.Repeat("x", 17))})
+	assert.ErrorIs(t, err, domain.ErrAttachmentTooLarge)
+	_, err = uc.AddAttachment("bob", todo.ID, upload("notes.txt", "text/plain", "hi"))
+	assert.ErrorIs(t, err, domain.ErrForbidden, "viewers cannot attach files")
+
+	// generic types are sniffed, and paths are dropped from the name
+	attachment, err := uc.AddAttachment("alice", todo.ID, upload(`C:\scans\notes.txt`, "application/octet-stream", "sixteen bytes!!!"))
+	require.NoError(t, err)
+	assert.Equal(t, "notes.txt", attachment.Filename)
+	assert.Equal(t, "text/plain", attachment.ContentType)
+	assert.Equal(t, int64(16), attachment.Size)
+
+	got, err := uc.GetTodoByID("bob", todo.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Attachments, 1)
+	assert.Equal(t, attachment.ID, got.Attachments[0].ID)
+	opened, content, err := uc.OpenAttachment("bob", todo.ID, attachment.ID)
+	require.NoError(t, err)
+	defer content.Close()
+	body, err := io.ReadAll(content)
+	require.NoError(t, err)
+	assert.Equal(t, "sixteen bytes!!!", string(body))
+	assert.Equal(t, "notes.txt", opened.Filename)
+	_, _, err = uc.OpenAttachment("carol", todo.ID, attachment.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestDeleteAttachment(t *testing.T) {
+	uc, todo, blobs := newAttachmentUseCase(t)
+	first, err := uc.AddAttachment("alice", todo.ID, upload("a.txt", "text/plain", "a"))
+	require.NoError(t, err)
+	second, err := uc.AddAttachment("alice", todo.ID, upload("b.txt", "text/plain", "b"))
+	require.NoError(t, err)
+
+	require.NoError(t, uc.DeleteAttachment("alice", todo.ID, first.ID))
+	assert.ErrorIs(t, uc.DeleteAttachment("alice", todo.ID, first.ID), domain.ErrAttachmentNotFound)
+	_, _, err = uc.OpenAttachment("alice", todo.ID, first.ID)
+	assert.ErrorIs(t, err, domain.ErrAttachmentNotFound)
+	_, err = blobs.Open(first.ID)
+	assert.ErrorIs(t, err, domain.ErrAttachmentNotFound)
+
+	// purged todos take the content of their attachments with them
+	require.NoError(t, uc.DeleteTodo("alice", todo.ID, 0))
+	content, err := blobs.Open(second.ID)
+	require.NoError(t, err, "trashed todos keep their attachments")
+	require.NoError(t, content.Close())
+	require.NoError(t, uc.PurgeTodo("alice", todo.ID, 0))
+	_, err = blobs.Open(second.ID)
+	assert.ErrorIs(t, err, domain.ErrAttachmentNotFound)
+}
+
+func TestAttachments_ExpiredWithTodo(t *testing.T) {
+	uc, todo, blobs := newAttachmentUseCase(t)
+	attachment, err := uc.AddAttachment("alice", todo.ID, upload("a.txt", "text/plain", "a"))
+	require.NoError(t, err)
+	require.NoError(t, uc.DeleteTodo("alice", todo.ID, 0))
+
+	// the retention runs out for everything trashed until now
+	purged, err := uc.PurgeExpiredTrash(time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	_, err = blobs.Open(attachment.ID)
+	assert.ErrorIs(t, err, domain.ErrAttachmentNotFound)
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"strconv"
+	"time"
+	"todo-app/internal/todo/domain"
+)
+
+// BatchAction names a todo operation that can be part of a batch.
+type BatchAction string
+
+const (
+	BatchCreate  BatchAction = "create"
+	BatchUpdate  BatchAction = "update"
+	BatchPatch   BatchAction = "patch"
+	BatchDelete  BatchAction = "delete"
+	BatchRestore BatchAction = "restore"
+	BatchPurge   BatchAction = "purge"
+)
+
+// MaxBatchOps caps the number of operations in one batch.
+const MaxBatchOps = 100
+
+// BatchOp is one operation of a batch. The fields used depend on Action
+// and are the parameters of the matching single-todo method: create uses
+// Title, DueDate, Done and Options; update uses ID, Title, DueDate, Done
+// and Options; patch uses ID and Patch; delete, restore and purge use ID. Version
+// is checked as by those methods.
+type BatchOp struct {
+	Action  BatchAction
+	ID      string
+	Version int64
+	Title   string
+	DueDate time.Time
+	Done    bool
+	Options []TodoOption
+	Patch   TodoPatch
+}
+
+// BatchError reports the operation that failed and made its batch roll back.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return "batch operation " + strconv.Itoa(e.Index) + ": " + e.Err.Error()
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// Batch runs the operations in order in a single transaction, so either
+// all of them take effect or, when one fails, none does and a *BatchError
+// names the failing one. Later operations see the changes of earlier ones.
+// It returns the todo each operation left behind, nil for deletes and
+// purges. History and subscribers only hear about the changes once the
+// batch has been committed.
+func (uc *TodoUseCase) Batch(actor string, ops []BatchOp) ([]*domain.Todo, error) {
+	if len(ops) == 0 || len(ops) > MaxBatchOps {
+		return nil, &domain.ValidationError{Field: "ops", Message: "must hold between 1 and " + strconv.Itoa(MaxBatchOps) + " operations"}
+	}
+	var results []*domain.Todo
+	var events []domain.Event
+	err := uc.repo.RunInTx(func(repo domain.TodoRepository) error {
+		// the transaction may be retried, so start over every time
+		results, events = make([]*domain.Todo, 0, len(ops)), nil
+		tx := &TodoUseCase{repo: repo, history: uc.history, projects: uc.projects, shares: uc.shares, users: uc.users, comments: uc.comments, blobs: uc.blobs, attachmentLimits: uc.attachmentLimits, deferred: &events}
+		for i, op := range ops {
+			todo, err := tx.run(actor, op)
+			if err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+			results = append(results, todo)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		uc.publish(event)
+	}
+	return results, nil
+}
+
+func (uc *TodoUseCase) run(actor string, op BatchOp) (*domain.Todo, error) {
+	switch op.Action {
+	case BatchCreate:
+		return uc.create(actor, op.Title, op.DueDate, op.Done, op.Options...)
+	case BatchUpdate:
+		return uc.UpdateTodo(actor, op.ID, op.Title, op.DueDate, op.Done, op.Version, op.Options...)
+	case BatchPatch:
+		return uc.PatchTodo(actor, op.ID, op.Patch, op.Version)
+	case BatchDelete:
+		return nil, uc.DeleteTodo(actor, op.ID, op.Version)
+	case BatchRestore:
+		return uc.RestoreTodo(actor, op.ID, op.Version)
+	case BatchPurge:
+		return nil, uc.PurgeTodo(actor, op.ID, op.Version)
+	}
+	return nil, &domain.ValidationError{Field: "action", Message: "unknown batch action " + strconv.Quote(string(op.Action))}
+}
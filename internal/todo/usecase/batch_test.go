@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"testing"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/infrastructure/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventLog collects the events a use case publishes.
+type eventLog []domain.Event
+
+func (l *eventLog) HandleTodoEvent(e domain.Event) {
+	*l = append(*l, e)
+}
+
+func TestBatch_CommitsAllOperations(t *testing.T) {
+	uc := NewTodoUseCase(repository.NewMemoryTodoRepository(), repository.NewMemoryHistoryRepository())
+	var events eventLog
+	uc.Subscribe(&events)
+	todos := createTodos(t, uc, "A", "B")
+	events = nil
+
+	results, err := uc.Batch("tester", []BatchOp{
+		{Action: BatchCreate, Title: "C", DueDate: parseDate("2025-07-02")},
+		{Action: BatchPatch, ID: todos[0].ID, Version: 1, Patch: TodoPatch{Done: ptr(true)}},
+		{Action: BatchDelete, ID: todos[1].ID},
+		{Action: BatchRestore, ID: todos[1].ID},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.Equal(t, "C", results[0].Title)
+	assert.True(t, results[1].Done)
+	assert.Nil(t, results[2])
+	assert.Equal(t, int64(3), results[3].Version)
+
+	_, total, err := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, events, 4)
+	assert.Equal(t, domain.HistoryRestored, events[3].Action)
+}
+
+func TestBatch_RollsBackOnFailure(t *testing.T) {
+	uc := NewTodoUseCase(repository.NewMemoryTodoRepository(), repository.NewMemoryHistoryRepository())
+	var events eventLog
+	uc.Subscribe(&events)
+	todos := createTodos(t, uc, "A", "B")
+	events = nil
+
+	_, err := uc.Batch("tester", []BatchOp{
+		{Action: BatchCreate, Title: "C", DueDate: parseDate("2025-07-02")},
+		{Action: BatchUpdate, ID: todos[0].ID, Title: "A2", DueDate: parseDate("2025-07-03"), Done: true},
+		{Action: BatchDelete, ID: todos[1].ID},
+		{Action: BatchPatch, ID: todos[0].ID, Version: 1, Patch: TodoPatch{Title: ptr("stale")}},
+	})
+	var failed *BatchError
+	require.ErrorAs(t, err, &failed)
+	assert.Equal(t, 3, failed.Index)
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+
+	list, total, err := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	for _, todo := range list {
+		assert.Equal(t, int64(1), todo.Version)
+		assert.False(t, todo.Done)
+	}
+	stored, err := uc.GetTodoByID("tester", todos[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, todos[0].Title, stored.Title)
+	trash, _, err := uc.GetTrash("tester", 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+	assert.Empty(t, events)
+	history, _, err := uc.GetHistory("tester", todos[0].ID, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	_, err = uc.Batch("tester", nil)
+	var invalid *domain.ValidationError
+	assert.ErrorAs(t, err, &invalid)
+}
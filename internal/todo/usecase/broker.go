@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+	"todo-app/internal/todo/domain"
+)
+
+// StreamEventType is the kind of change pushed to live clients.
+type StreamEventType string
+
+const (
+	StreamCreated StreamEventType = "created"
+	StreamUpdated StreamEventType = "updated"
+	StreamDeleted StreamEventType = "deleted"
+)
+
+// StreamEvent is a todo change as pushed to live clients. IDs increase by
+// one per event, so a client can resume after the last one it saw.
+type StreamEvent struct {
+	ID    int
+	Type  StreamEventType
+	Owner string
+	Actor string
+	At    time.Time
+	Todo  *domain.Todo
+}
+
+// DefaultReplaySize is the number of recent events a broker keeps for
+// clients that reconnect.
+const DefaultReplaySize = 1000
+
+// subscriberBuffer is how far a client may fall behind before it is
+// dropped; it can then reconnect and catch up from the replay buffer.
+const subscriberBuffer = 64
+
+// Broker fans todo changes out to live clients of their owner. It keeps
+// a bounded buffer of recent events so that clients reconnecting with the
+// ID of the last event they saw miss nothing, as long as that event is
+// still buffered.
+type Broker struct {
+	mu     sync.Mutex
+	seq    int
+	replay []StreamEvent // oldest first, at most size events
+	size   int
+	subs   map[*subscriber]struct{}
+	closed bool
+}
+
+type subscriber struct {
+	owner  string
+	events chan StreamEvent
+}
+
+func NewBroker(replaySize int) *Broker {
+	return &Broker{size: replaySize, subs: map[*subscriber]struct{}{}}
+}
+
+// HandleTodoEvent publishes a change to the live clients of the todo's
+// owner. Restores show up as creates and purges are not published, since
+// clients already saw the todo deleted.
+func (b *Broker) HandleTodoEvent(e domain.Event) {
+	var typ StreamEventType
+	switch e.Action {
+	case domain.HistoryCreated, domain.HistoryRestored:
+		typ = StreamCreated
+	case domain.HistoryUpdated:
+		typ = StreamUpdated
+	case domain.HistoryDeleted:
+		typ = StreamDeleted
+	default:
+		return
+	}
+	todo := e.Todo()
+	if todo.Owner == "" {
+		return
+	}
+	b.Publish(StreamEvent{Type: typ, Owner: todo.Owner, Actor: e.Actor, At: e.At, Todo: todo.Clone()})
+}
+
+// Publish assigns the event its ID, buffers it and hands it to the
+// owner's clients. Clients that are too far behind are disconnected.
+func (b *Broker) Publish(event StreamEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.seq++
+	event.ID = b.seq
+	b.replay = append(b.replay, event)
+	if len(b.replay) > b.size {
+		b.replay = b.replay[len(b.replay)-b.size:]
+	}
+	for sub := range b.subs {
+		if sub.owner != event.Owner {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			b.drop(sub)
+		}
+	}
+}
+
+// Subscribe registers a live client of owner. With a non-zero lastID the
+// buffered events of owner after it are returned for replay; complete is
+// false when the buffer no longer reaches back that far (or lastID is
+// from before a restart), in which case the client has to reload instead.
+// The events channel is closed when the client is dropped or the broker
+// closes; cancel must be called when the client goes away.
+func (b *Broker) Subscribe(owner string, lastID int) (replay []StreamEvent, complete bool, events <-chan StreamEvent, cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sub := &subscriber{owner: owner, events: make(chan StreamEvent, subscriberBuffer)}
+	if b.closed {
+		close(sub.events)
+		return nil, true, sub.events, func() {}
+	}
+	b.subs[sub] = struct{}{}
+	complete = true
+	if lastID > 0 {
+		oldest := b.seq - len(b.replay) + 1
+		complete = lastID <= b.seq && lastID >= oldest-1
+		for _, event := range b.replay {
+			if event.ID > lastID && event.Owner == owner {
+				replay = append(replay, event)
+			}
+		}
+	}
+	cancel = func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.drop(sub)
+	}
+	return replay, complete, sub.events, cancel
+}
+
+// Close disconnects every client, e.g. when the server shuts down, and
+// stops publishing.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subs {
+		b.drop(sub)
+	}
+}
+
+// drop unregisters a subscriber; b.mu must be held.
+func (b *Broker) drop(sub *subscriber) {
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.events)
+	}
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	"todo-app/internal/todo/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func publishTodo(b *Broker, owner, id string, action domain.HistoryAction) {
+	todo := &domain.Todo{ID: id, Owner: owner}
+	e := domain.Event{Action: action, Actor: owner, At: time.Now(), After: todo}
+	if action != domain.HistoryCreated {
+		e.Before = todo
+	}
+	b.HandleTodoEvent(e)
+}
+
+func TestBroker_DeliversOwnersEvents(t *testing.T) {
+	b := NewBroker(10)
+	_, complete, events, cancel := b.Subscribe("alice", 0)
+	defer cancel()
+	assert.True(t, complete)
+
+	publishTodo(b, "bob", "b1", domain.HistoryCreated)
+	publishTodo(b, "alice", "a1", domain.HistoryCreated)
+	publishTodo(b, "alice", "a1", domain.HistoryUpdated)
+	publishTodo(b, "alice", "a1", domain.HistoryPurged)
+
+	first := <-events
+	assert.Equal(t, 2, first.ID)
+	assert.Equal(t, StreamCreated, first.Type)
+	assert.Equal(t, "a1", first.Todo.ID)
+	second := <-events
+	assert.Equal(t, StreamUpdated, second.Type)
+	assert.Empty(t, events, "purges are not streamed")
+}
+
+func TestBroker_ResumesFromReplayBuffer(t *testing.T) {
+	b := NewBroker(3)
+	for _, id := range []string{"a1", "a2", "a3", "a4", "a5"} {
+		publishTodo(b, "alice", id, domain.HistoryCreated)
+	}
+
+	// events 3-5 are buffered, so resuming after 2 misses nothing
+	replay, complete, _, cancel := b.Subscribe("alice", 2)
+	cancel()
+	assert.True(t, complete)
+	require.Len(t, replay, 3)
+	assert.Equal(t, "a3", replay[0].Todo.ID)
+
+	replay, complete, _, cancel = b.Subscribe("alice", 4)
+	cancel()
+	assert.True(t, complete)
+	require.Len(t, replay, 1)
+	assert.Equal(t, 5, replay[0].ID)
+
+	_, complete, _, cancel = b.Subscribe("alice", 1)
+	cancel()
+	assert.False(t, complete, "event 2 has fallen out of the buffer")
+	_, complete, _, cancel = b.Subscribe("alice", 99)
+	cancel()
+	assert.False(t, complete, "IDs from before a restart cannot be resumed")
+}
+
+func TestBroker_DropsLaggingAndClosed(t *testing.T) {
+	b := NewBroker(10)
+	_, _, events, cancel := b.Subscribe("alice", 0)
+	defer cancel()
+	for i := 0; i <= subscriberBuffer; i++ {
+		publishTodo(b, "alice", "a1", domain.HistoryUpdated)
+	}
+	for range events {
+	}
+	// the channel was closed once the buffer overflowed
+
+	_, _, events, cancel2 := b.Subscribe("alice", 0)
+	defer cancel2()
+	b.Close()
+	_, open := <-events
+	assert.False(t, open)
+}
@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"slices"
+	"strconv"
+	"time"
+	"todo-app/internal/todo/domain"
+)
+
+// BulkSelection picks the todos a bulk operation applies to: the listed
+// IDs, or every live todo matching Filter when there are none.
+type BulkSelection struct {
+	IDs    []string
+	Filter domain.TodoFilter
+}
+
+// BulkTodos applies action to every selected todo and reports the outcome
+// per todo, in selection order. Retag takes its tags from change.AddTags
+// and change.RemoveTags, reschedule takes change.DueDate or change.Shift,
+// move takes change.ProjectID; complete, reopen and delete take nothing
+// from change.
+//
+// The selected todos are changed in a single repository write, each pinned
+// to the version it was selected at, so a todo changed concurrently is
+// reported as a version conflict rather than overwritten. Completing a
+// recurring todo is the exception: it goes through the regular per-todo
+// update, as it also generates the next occurrence.
+func (uc *TodoUseCase) BulkTodos(actor string, sel BulkSelection, action domain.BulkAction, change domain.BulkChange) ([]domain.BulkResult, error) {
+	change, err := bulkChange(action, change)
+	if err != nil {
+		return nil, err
+	}
+	report, targets, err := uc.selectBulk(actor, sel, action)
+	if err != nil {
+		return nil, err
+	}
+	if action == domain.BulkDelete {
+		err = uc.bulkTrash(actor, report, targets)
+	} else {
+		err = uc.bulkUpdate(actor, report, targets, change)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return report.results, nil
+}
+
+// bulkChange checks that change only carries what action takes, and fills
+// in the rest of the change the action makes.
+func bulkChange(action domain.BulkAction, change domain.BulkChange) (domain.BulkChange, error) {
+	retag := len(change.AddTags) > 0 || len(change.RemoveTags) > 0
+	reschedule := change.DueDate != nil || change.Shift != 0
+	move := change.ProjectID != nil
+	switch action {
+	case domain.BulkComplete, domain.BulkReopen, domain.BulkDelete:
+		if retag || reschedule || move {
+			return change, &domain.ValidationError{Field: "action", Message: string(action) + " takes no tags, due date or project"}
+		}
+		done := action == domain.BulkComplete
+		if action != domain.BulkDelete {
+			change.Done, change.At = &done, time.Now()
+		}
+	case domain.BulkRetag:
+		if reschedule || move {
+			return change, &domain.ValidationError{Field: "action", Message: "retag takes no due date or project"}
+		}
+		var err error
+		if change.AddTags, err = domain.NormalizeTags(change.AddTags); err != nil {
+			return change, err
+		}
+		if change.RemoveTags, err = domain.NormalizeTags(change.RemoveTags); err != nil {
+			return change, err
+		}
+		if len(change.AddTags) == 0 && len(change.RemoveTags) == 0 {
+			return change, &domain.ValidationError{Field: "addTags", Message: "retag needs tags to add or remove"}
+		}
+	case domain.BulkReschedule:
+		if retag || move {
+			return change, &domain.ValidationError{Field: "action", Message: "reschedule takes no tags or project"}
+		}
+		if (change.DueDate == nil) == (change.Shift == 0) {
+			return change, &domain.ValidationError{Field: "dueDate", Message: "reschedule needs either a due date or a shift"}
+		}
+	case domain.BulkMove:
+		if retag || reschedule {
+			return change, &domain.ValidationError{Field: "action", Message: "move takes no tags or due date"}
+		}
+		if !move {
+			return change, &domain.ValidationError{Field: "projectId", Message: "move needs a project, or an empty one for the inbox"}
+		}
+	default:
+		return change, &domain.ValidationError{Field: "action", Message: "unknown bulk action " + strconv.Quote(string(action))}
+	}
+	return change, nil
+}
+
+// bulkReport collects the per-todo results in selection order.
+type bulkReport struct {
+	results []domain.BulkResult
+	index   map[string]int
+}
+
+func (r *bulkReport) set(id string, status domain.BulkStatus, todo *domain.Todo, err error) {
+	r.results[r.index[id]] = domain.BulkResult{ID: id, Status: status, Todo: todo, Err: err}
+}
+
+// selectBulk resolves the selection to the live todos it names that actor
+// may apply action to. Listed IDs that do not name such a todo are already
+// reported as failed.
+func (uc *TodoUseCase) selectBulk(actor string, sel BulkSelection, action domain.BulkAction) (*bulkReport, []*domain.Todo, error) {
+	var targets []*domain.Todo
+	ids := sel.IDs
+	if len(ids) == 0 {
+		list, total, err := uc.repo.FindAll(0, domain.MaxBulkItems, sel.Filter)
+		if err != nil {
+			return nil, nil, err
+		}
+		if total > domain.MaxBulkItems {
+			return nil, nil, &domain.ValidationError{Field: "filter", Message: "matches more than " + strconv.Itoa(domain.MaxBulkItems) + " todos"}
+		}
+		targets = list
+		ids = make([]string, 0, len(list))
+		for _, todo := range list {
+			ids = append(ids, todo.ID)
+		}
+	} else if len(ids) > domain.MaxBulkItems {
+		return nil, nil, &domain.ValidationError{Field: "ids", Message: "at most " + strconv.Itoa(domain.MaxBulkItems) + " IDs are allowed"}
+	}
+	report := &bulkReport{results: make([]domain.BulkResult, 0, len(ids)), index: make(map[string]int, len(ids))}
+	for _, id := range ids {
+		if _, dup := report.index[id]; dup {
+			continue
+		}
+		report.index[id] = len(report.results)
+		report.results = append(report.results, domain.BulkResult{ID: id, Status: domain.BulkFailed, Err: domain.ErrNotFound})
+	}
+	if targets == nil {
+		unique := make([]string, 0, len(report.results))
+		for _, result := range report.results {
+			unique = append(unique, result.ID)
+		}
+		found, err := uc.repo.FindByIDs(unique)
+		if err != nil {
+			return nil, nil, err
+		}
+		targets = found
+	}
+	need := domain.PermissionEdit
+	if action == domain.BulkDelete || action == domain.BulkMove {
+		need = ownerOnly
+	}
+	allowed := targets[:0]
+	for _, todo := range targets {
+		if err := uc.authorize(actor, todo, need); err != nil {
+			report.set(todo.ID, domain.BulkFailed, nil, err)
+			continue
+		}
+		allowed = append(allowed, todo)
+	}
+	targets = allowed
+	slices.SortFunc(targets, func(a, b *domain.Todo) int { return report.index[a.ID] - report.index[b.ID] })
+	return report, targets, nil
+}
+
+func (uc *TodoUseCase) bulkUpdate(actor string, report *bulkReport, targets []*domain.Todo, change domain.BulkChange) error {
+	batch := make([]*domain.Todo, 0, len(targets))
+	afters := make(map[string]*domain.Todo, len(targets))
+	for _, before := range targets {
+		after := before.Clone()
+		if !change.Apply(after) {
+			report.set(before.ID, domain.BulkSkipped, present(after), nil)
+			continue
+		}
+		if after.ProjectID != before.ProjectID {
+			if err := uc.checkProject(after); err != nil {
+				report.set(before.ID, domain.BulkFailed, nil, err)
+				continue
+			}
+		}
+		if after.Recurrence != nil && after.Done && !before.Done {
+			todo, err := uc.mutate(actor, before.ID, before.Version, func(todo *domain.Todo) error {
+				change.Apply(todo)
+				return nil
+			})
+			if err != nil {
+				report.set(before.ID, domain.BulkFailed, nil, err)
+			} else {
+				report.set(before.ID, domain.BulkApplied, todo, nil)
+			}
+			continue
+		}
+		batch = append(batch, before)
+		afters[before.ID] = after
+	}
+	changed, err := uc.repo.UpdateMany(batch, change)
+	if err != nil {
+		return err
+	}
+	written := idSet(changed)
+	for _, before := range batch {
+		if !written[before.ID] {
+			report.set(before.ID, domain.BulkFailed, nil, uc.lostRace(before.ID))
+			continue
+		}
+		after := afters[before.ID]
+		after.Version = before.Version + 1
+		uc.record(actor, domain.HistoryUpdated, before, after)
+		report.set(before.ID, domain.BulkApplied, present(after), nil)
+	}
+	return nil
+}
+
+func (uc *TodoUseCase) bulkTrash(actor string, report *bulkReport, targets []*domain.Todo) error {
+	now := time.Now()
+	trashed, err := uc.repo.TrashMany(targets, now)
+	if err != nil {
+		return err
+	}
+	written := idSet(trashed)
+	for _, before := range targets {
+		if !written[before.ID] {
+			report.set(before.ID, domain.BulkFailed, nil, uc.lostRace(before.ID))
+			continue
+		}
+		after := before.Clone()
+		after.DeletedAt = &now
+		after.Version = before.Version + 1
+		uc.record(actor, domain.HistoryDeleted, before, after)
+		report.set(before.ID, domain.BulkApplied, present(after), nil)
+	}
+	return nil
+}
+
+// lostRace explains why a selected todo was left out of a bulk write:
+// it was deleted, or changed, since it was selected.
+func (uc *TodoUseCase) lostRace(id string) error {
+	if _, err := uc.repo.FindByID(id); err != nil {
+		return err
+	}
+	return domain.ErrVersionConflict
+}
+
+func idSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/infrastructure/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// racingRepo runs beforeWrite just before a bulk write, standing in for a
+// concurrent change made after the todos were selected.
+type racingRepo struct {
+	*repository.MemoryTodoRepository
+	beforeWrite func()
+}
+
+func (r *racingRepo) UpdateMany(todos []*domain.Todo, change domain.BulkChange) ([]string, error) {
+	r.beforeWrite()
+	return r.MemoryTodoRepository.UpdateMany(todos, change)
+}
+
+func createTodos(t *testing.T, uc *TodoUseCase, titles ...string) []*domain.Todo {
+	t.Helper()
+	for _, title := range titles {
+		require.NoError(t, uc.CreateTodo("tester", title, parseDate("2025-07-01"), false, WithTags([]string{"home"})))
+	}
+	todos, _, err := uc.GetAllTodos(0, 100, domain.TodoFilter{})
+	require.NoError(t, err)
+	return todos
+}
+
+func TestBulkTodos_SelectByIDs(t *testing.T) {
+	uc := NewTodoUseCase(repository.NewMemoryTodoRepository(), repository.NewMemoryHistoryRepository())
+	todos := createTodos(t, uc, "A", "B")
+	_, err := uc.PatchTodo("tester", todos[1].ID, TodoPatch{Done: ptr(true)}, 0)
+	require.NoError(t, err)
+
+	results, err := uc.BulkTodos("tester", BulkSelection{IDs: []string{todos[1].ID, "missing", todos[0].ID, todos[0].ID}}, domain.BulkComplete, domain.BulkChange{})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, domain.BulkSkipped, results[0].Status)
+	assert.Equal(t, domain.BulkFailed, results[1].Status)
+	assert.ErrorIs(t, results[1].Err, domain.ErrNotFound)
+	assert.Equal(t, domain.BulkApplied, results[2].Status)
+	assert.True(t, results[2].Todo.Done)
+	assert.Equal(t, int64(2), results[2].Todo.Version)
+
+	stored, err := uc.GetTodoByID("tester", todos[0].ID)
+	require.NoError(t, err)
+	assert.True(t, stored.Done)
+	history, _, err := uc.GetHistory("tester", todos[0].ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, domain.HistoryUpdated, history[0].Action)
+}
+
+func TestBulkTodos_SelectByFilter(t *testing.T) {
+	uc := NewTodoUseCase(repository.NewMemoryTodoRepository(), repository.NewMemoryHistoryRepository())
+	todos := createTodos(t, uc, "A", "B", "C")
+	_, err := uc.PatchTodo("tester", todos[0].ID, TodoPatch{Done: ptr(true)}, 0)
+	require.NoError(t, err)
+	_, err = uc.PatchTodo("tester", todos[2].ID, TodoPatch{Done: ptr(true)}, 0)
+	require.NoError(t, err)
+
+	results, err := uc.BulkTodos("tester", BulkSelection{Filter: domain.TodoFilter{Done: ptr(true)}}, domain.BulkDelete, domain.BulkChange{})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, r := range results {
+		assert.Equal(t, domain.BulkApplied, r.Status)
+		assert.NotNil(t, r.Todo.DeletedAt)
+	}
+	left, total, err := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, todos[1].ID, left[0].ID)
+	trash, _, err := uc.GetTrash("tester", 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, trash, 2)
+}
+
+func TestBulkTodos_RetagAndReschedule(t *testing.T) {
+	uc := NewTodoUseCase(repository.NewMemoryTodoRepository(), repository.NewMemoryHistoryRepository())
+	todos := createTodos(t, uc, "A", "B")
+	sel := BulkSelection{Filter: domain.TodoFilter{Tag: "home"}}
+
+	results, err := uc.BulkTodos("tester", sel, domain.BulkRetag, domain.BulkChange{AddTags: []string{" urgent", "home"}, RemoveTags: []string{"home"}})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, todo := range []*domain.Todo{todos[0], todos[1]} {
+		stored, err := uc.GetTodoByID("tester", todo.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"urgent", "home"}, stored.Tags)
+	}
+
+	results, err = uc.BulkTodos("tester", BulkSelection{IDs: []string{todos[0].ID}}, domain.BulkReschedule, domain.BulkChange{Shift: 24 * time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, parseDate("2025-07-02"), results[0].Todo.DueDate)
+
+	_, err = uc.BulkTodos("tester", sel, domain.BulkReschedule, domain.BulkChange{})
+	var invalid *domain.ValidationError
+	assert.ErrorAs(t, err, &invalid)
+	_, err = uc.BulkTodos("tester", sel, domain.BulkComplete, domain.BulkChange{AddTags: []string{"x"}})
+	assert.ErrorAs(t, err, &invalid)
+	_, err = uc.BulkTodos("tester", sel, "archive", domain.BulkChange{})
+	assert.ErrorAs(t, err, &invalid)
+}
+
+func TestBulkTodos_ShiftLeavesUndatedAlone(t *testing.T) {
+	uc := NewTodoUseCase(repository.NewMemoryTodoRepository(), repository.NewMemoryHistoryRepository())
+	dated := createTodos(t, uc, "Dated")[0]
+	require.NoError(t, uc.CreateTodo("tester", "Someday", time.Time{}, false))
+	todos, _, err := uc.GetAllTodos(0, 10, domain.TodoFilter{Title: "Someday"})
+	require.NoError(t, err)
+	undated := todos[0]
+
+	results, err := uc.BulkTodos("tester", BulkSelection{IDs: []string{dated.ID, undated.ID}}, domain.BulkReschedule, domain.BulkChange{Shift: 24 * time.Hour})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, domain.BulkApplied, results[0].Status)
+	assert.Equal(t, domain.BulkSkipped, results[1].Status)
+	stored, err := uc.GetTodoByID("tester", undated.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.DueDate.IsZero())
+	assert.Equal(t, undated.Version, stored.Version)
+}
+
+func TestBulkTodos_ConcurrentChangeIsAConflict(t *testing.T) {
+	repo := &racingRepo{MemoryTodoRepository: repository.NewMemoryTodoRepository()}
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
+	todos := createTodos(t, uc, "A", "B")
+	repo.beforeWrite = func() {
+		_, err := uc.PatchTodo("tester", todos[1].ID, TodoPatch{Title: ptr("B2")}, 0)
+		require.NoError(t, err)
+	}
+
+	results, err := uc.BulkTodos("tester", BulkSelection{IDs: []string{todos[0].ID, todos[1].ID}}, domain.BulkComplete, domain.BulkChange{})
+	require.NoError(t, err)
+	assert.Equal(t, domain.BulkApplied, results[0].Status)
+	assert.Equal(t, domain.BulkFailed, results[1].Status)
+	assert.ErrorIs(t, results[1].Err, domain.ErrVersionConflict)
+	stored, err := uc.GetTodoByID("tester", todos[1].ID)
+	require.NoError(t, err)
+	assert.False(t, stored.Done)
+	assert.Equal(t, "B2", stored.Title)
+}
+
+func TestBulkTodos_CompletingRecurringGeneratesNext(t *testing.T) {
+	uc := NewTodoUseCase(repository.NewMemoryTodoRepository(), repository.NewMemoryHistoryRepository())
+	require.NoError(t, uc.CreateTodo("tester", "Water plants", parseDate("2025-07-01"), false, WithRecurrence("FREQ=DAILY", "")))
+	todos, _, _ := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+
+	results, err := uc.BulkTodos("tester", BulkSelection{IDs: []string{todos[0].ID}}, domain.BulkComplete, domain.BulkChange{})
+	require.NoError(t, err)
+	require.Equal(t, domain.BulkApplied, results[0].Status)
+	next, err := uc.GetTodoByID("tester", results[0].Todo.Recurrence.NextID)
+	require.NoError(t, err)
+	assert.Equal(t, parseDate("2025-07-02"), next.DueDate)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"strings"
+	"todo-app/internal/todo/domain"
+)
+
+// WithChecklist gives a new todo a checklist of the given items, in order.
+// Their IDs and positions are assigned.
+func WithChecklist(items []domain.ChecklistItem) TodoOption {
+	return func(todo *domain.Todo) error {
+		checklist := make([]domain.ChecklistItem, 0, len(items))
+		for i, item := range items {
+			if err := validateItemText(item.Text); err != nil {
+				return err
+			}
+			checklist = append(checklist, domain.ChecklistItem{ID: generateID(), Text: item.Text, Checked: item.Checked, Position: i})
+		}
+		todo.Checklist = checklist
+		return nil
+	}
+}
+
+// AddChecklistItem inserts a new item at position, or appends it when
+// position is nil or past the end.
+func (uc *TodoUseCase) AddChecklistItem(actor, id, text string, checked bool, position *int, version int64) (*domain.Todo, error) {
+	if err := validateItemText(text); err != nil {
+		return nil, err
+	}
+	return uc.mutate(actor, id, version, func(todo *domain.Todo) error {
+		item := domain.ChecklistItem{ID: generateID(), Text: text, Checked: checked}
+		at := len(todo.Checklist)
+		if position != nil && *position >= 0 && *position < at {
+			at = *position
+		}
+		todo.Checklist = append(todo.Checklist, domain.ChecklistItem{})
+		copy(todo.Checklist[at+1:], todo.Checklist[at:])
+		todo.Checklist[at] = item
+		for i := range todo.Checklist {
+			todo.Checklist[i].Position = i
+		}
+		return nil
+	})
+}
+
+// EditChecklistItem changes the text and/or checked state of an item.
+func (uc *TodoUseCase) EditChecklistItem(actor, id, itemID string, text *string, checked *bool, version int64) (*domain.Todo, error) {
+	if text != nil {
+		if err := validateItemText(*text); err != nil {
+			return nil, err
+		}
+	}
+	return uc.mutate(actor, id, version, func(todo *domain.Todo) error {
+		i := todo.ChecklistIndex(itemID)
+		if i < 0 {
+			return domain.ErrChecklistItemNotFound
+		}
+		if text != nil {
+			todo.Checklist[i].Text = *text
+		}
+		if checked != nil {
+			todo.Checklist[i].Checked = *checked
+		}
+		return nil
+	})
+}
+
+// ToggleChecklistItem flips the checked state of an item.
+func (uc *TodoUseCase) ToggleChecklistItem(actor, id, itemID string, version int64) (*domain.Todo, error) {
+	return uc.mutate(actor, id, version, func(todo *domain.Todo) error {
+		i := todo.ChecklistIndex(itemID)
+		if i < 0 {
+			return domain.ErrChecklistItemNotFound
+		}
+		todo.Checklist[i].Checked = !todo.Checklist[i].Checked
+		return nil
+	})
+}
+
+// ReorderChecklist puts the items in the given order. itemIDs must list
+// every item of the checklist exactly once.
+func (uc *TodoUseCase) ReorderChecklist(actor, id string, itemIDs []string, version int64) (*domain.Todo, error) {
+	return uc.mutate(actor, id, version, func(todo *domain.Todo) error {
+		invalid := &domain.ValidationError{Field: "itemIds", Message: "must list every checklist item exactly once"}
+		if len(itemIDs) != len(todo.Checklist) {
+			return invalid
+		}
+		seen := map[string]bool{}
+		for pos, itemID := range itemIDs {
+			i := todo.ChecklistIndex(itemID)
+			if i < 0 || seen[itemID] {
+				return invalid
+			}
+			seen[itemID] = true
+			todo.Checklist[i].Position = pos
+		}
+		todo.SortChecklist()
+		return nil
+	})
+}
+
+// RemoveChecklistItem deletes an item and closes the gap it leaves.
+func (uc *TodoUseCase) RemoveChecklistItem(actor, id, itemID string, version int64) (*domain.Todo, error) {
+	return uc.mutate(actor, id, version, func(todo *domain.Todo) error {
+		i := todo.ChecklistIndex(itemID)
+		if i < 0 {
+			return domain.ErrChecklistItemNotFound
+		}
+		todo.Checklist = append(todo.Checklist[:i], todo.Checklist[i+1:]...)
+		todo.SortChecklist()
+		return nil
+	})
+}
+
+func validateItemText(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return &domain.ValidationError{Field: "text", Message: "must not be empty"}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"testing"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/infrastructure/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func checklistTexts(todo *domain.Todo) []string {
+	texts := []string{}
+	for i, item := range todo.Checklist {
+		if item.Position != i {
+			return nil
+		}
+		texts = append(texts, item.Text)
+	}
+	return texts
+}
+
+func TestChecklist(t *testing.T) {
+	repo := repository.NewMemoryTodoRepository()
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
+
+	assert.NoError(t, uc.CreateTodo("tester", "Groceries", parseDate("2025-07-01"), false))
+	todos, _, _ := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	id := todos[0].ID
+	assert.Nil(t, todos[0].Progress)
+
+	todo, err := uc.AddChecklistItem("tester", id, "Milk", false, nil, 0)
+	assert.NoError(t, err)
+	todo, err = uc.AddChecklistItem("tester", id, "Bread", true, nil, todo.Version)
+	assert.NoError(t, err)
+	first := 0
+	todo, err = uc.AddChecklistItem("tester", id, "Eggs", false, &first, todo.Version)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Eggs", "Milk", "Bread"}, checklistTexts(todo))
+	assert.Equal(t, &domain.ChecklistProgress{Checked: 1, Total: 3, Label: "1/3"}, todo.Progress)
+
+	eggs, milk, bread := todo.Checklist[0].ID, todo.Checklist[1].ID, todo.Checklist[2].ID
+
+	todo, err = uc.ToggleChecklistItem("tester", id, milk, 0)
+	assert.NoError(t, err)
+	text := "Oat milk"
+	todo, err = uc.EditChecklistItem("tester", id, milk, &text, nil, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "2/3", todo.Progress.Label)
+
+	todo, err = uc.ReorderChecklist("tester", id, []string{bread, eggs, milk}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bread", "Eggs", "Oat milk"}, checklistTexts(todo))
+
+	todo, err = uc.RemoveChecklistItem("tester", id, eggs, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bread", "Oat milk"}, checklistTexts(todo))
+
+	// progress is part of list and get responses
+	found, err := uc.GetTodoByID("tester", id)
+	assert.NoError(t, err)
+	assert.Equal(t, "2/2", found.Progress.Label)
+	todos, _, _ = uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	assert.Equal(t, "2/2", todos[0].Progress.Label)
+
+	// updating the todo itself keeps its checklist
+	_, err = uc.UpdateTodo("tester", id, "Groceries", parseDate("2025-07-01"), true, 0)
+	assert.NoError(t, err)
+	found, _ = uc.GetTodoByID("tester", id)
+	assert.Len(t, found.Checklist, 2)
+}
+
+func TestChecklist_Errors(t *testing.T) {
+	repo := repository.NewMemoryTodoRepository()
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
+
+	assert.NoError(t, uc.CreateTodo("tester", "Groceries", parseDate("2025-07-01"), false))
+	todos, _, _ := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	id := todos[0].ID
+	todo, err := uc.AddChecklistItem("tester", id, "Milk", false, nil, 0)
+	assert.NoError(t, err)
+	milk := todo.Checklist[0].ID
+
+	var invalid *domain.ValidationError
+	_, err = uc.AddChecklistItem("tester", id, " ", false, nil, 0)
+	assert.ErrorAs(t, err, &invalid)
+	_, err = uc.ToggleChecklistItem("tester", id, "missing", 0)
+	assert.ErrorIs(t, err, domain.ErrChecklistItemNotFound)
+	_, err = uc.ReorderChecklist("tester", id, []string{milk, milk}, 0)
+	assert.ErrorAs(t, err, &invalid)
+	_, err = uc.ToggleChecklistItem("tester", id, milk, todo.Version-1)
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+
+	// a failed change leaves the stored checklist untouched
+	found, _ := uc.GetTodoByID("tester", id)
+	assert.Equal(t, todo.Checklist, found.Checklist)
+	assert.Equal(t, todo.Version, found.Version)
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/todo/domain"
+	"unicode/utf8"
+)
+
+// UseComments sets where the comments on todos are kept. Like Subscribe,
+// it is meant to be called during setup.
+func (uc *TodoUseCase) UseComments(comments domain.CommentRepository) {
+	uc.comments = comments
+}
+
+// AddComment comments on a todo actor can see. A reply names the comment
+// it answers as parentID; replies to a reply join the thread of the
+// comment that started it.
+func (uc *TodoUseCase) AddComment(actor, todoID, parentID, body string) (*domain.Comment, error) {
+	if uc.comments == nil {
+		return nil, &domain.ValidationError{Field: "body", Message: "comments are not available"}
+	}
+	body, err := commentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uc.GetTodoByID(actor, todoID); err != nil {
+		return nil, err
+	}
+	if parentID != "" {
+		parent, err := uc.comments.FindByID(todoID, parentID)
+		if errors.Is(err, domain.ErrCommentNotFound) {
+			return nil, &domain.ValidationError{Field: "parentId", Message: "is not a comment on the todo"}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if parent.ParentID != "" {
+			parentID = parent.ParentID
+		}
+	}
+	comment := &domain.Comment{
+		ID:        generateID(),
+		TodoID:    todoID,
+		ParentID:  parentID,
+		Author:    actor,
+		Body:      body,
+		CreatedAt: time.Now(),
+	}
+	if err := uc.comments.Save(comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// ListComments lists the comments on a todo actor can see, oldest first.
+func (uc *TodoUseCase) ListComments(actor, todoID string, page, limit int) ([]*domain.Comment, int64, error) {
+	if _, err := uc.GetTodoByID(actor, todoID); err != nil {
+		return nil, 0, err
+	}
+	if uc.comments == nil {
+		return []*domain.Comment{}, 0, nil
+	}
+	return uc.comments.FindByTodo(todoID, page, limit)
+}
+
+// GetComment returns a comment on a todo actor can see.
+func (uc *TodoUseCase) GetComment(actor, todoID, id string) (*domain.Comment, error) {
+	if _, err := uc.GetTodoByID(actor, todoID); err != nil {
+		return nil, err
+	}
+	if uc.comments == nil {
+		return nil, domain.ErrCommentNotFound
+	}
+	return uc.comments.FindByID(todoID, id)
+}
+
+// EditComment replaces the body of a comment actor wrote.
+func (uc *TodoUseCase) EditComment(actor, todoID, id, body string) (*domain.Comment, error) {
+	body, err := commentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := uc.ownComment(actor, todoID, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	comment.Body, comment.EditedAt = body, &now
+	if err := uc.comments.Save(comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// DeleteComment deletes a comment actor wrote, along with the replies to
+// it.
+func (uc *TodoUseCase) DeleteComment(actor, todoID, id string) error {
+	if _, err := uc.ownComment(actor, todoID, id); err != nil {
+		return err
+	}
+	return uc.comments.Delete(todoID, id)
+}
+
+// ownComment returns a comment of actor on a todo they can still see;
+// only authors can change their comments, not even the owner of the todo
+// can change those of others.
+func (uc *TodoUseCase) ownComment(actor, todoID, id string) (*domain.Comment, error) {
+	comment, err := uc.GetComment(actor, todoID, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Author != actor {
+		return nil, domain.ErrNotCommentAuthor
+	}
+	return comment, nil
+}
+
+// countComments fills in the comment counts of listed todos.
+func (uc *TodoUseCase) countComments(todos []*domain.Todo) error {
+	if uc.comments == nil || len(todos) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		ids = append(ids, todo.ID)
+	}
+	counts, err := uc.comments.CountByTodos(ids)
+	if err != nil {
+		return err
+	}
+	for _, todo := range todos {
+		todo.CommentCount = counts[todo.ID]
+	}
+	return nil
+}
+
+func commentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", &domain.ValidationError{Field: "body", Message: "must not be empty"}
+	}
+	if utf8.RuneCountInString(body) > domain.MaxCommentLength {
+		return "", &domain.ValidationError{Field: "body", Message: "must be at most " + strconv.Itoa(domain.MaxCommentLength) + " characters long"}
+	}
+	return body, nil
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/infrastructure/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComments(t *testing.T) {
+	uc, todo := newSharingUseCase(t)
+	uc.UseComments(repository.NewMemoryCommentRepository())
+	_, err := uc.ShareTodo("alice", todo.ID, "bob", domain.PermissionView)
+	require.NoError(t, err)
+	var invalid *domain.ValidationError
+
+	_, err = uc.AddComment("alice", todo.ID, "", "  ")
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "body", invalid.Field)
+	_, err = uc.AddComment("alice", todo.ID, "", strings.Repeat("x", domain.MaxCommentLength+1))
+	assert.ErrorAs(t, err, &invalid)
+	_, err = uc.AddComment("carol", todo.ID, "", "Hello")
+	assert.ErrorIs(t, err, domain.ErrNotFound, "todos carol cannot see cannot be commented on")
+	_, err = uc.AddComment("alice", todo.ID, "missing", "Hello")
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "parentId", invalid.Field)
+
+	first, err := uc.AddComment("alice", todo.ID, "", " Which dates? ")
+	require.NoError(t, err)
+	assert.Equal(t, "Which dates?", first.Body)
+	reply, err := uc.AddComment("bob", todo.ID, first.ID, "June")
+	require.NoError(t, err, "viewers can comment")
+	nested, err := uc.AddComment("alice", todo.ID, reply.ID, "June it is")
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, nested.ParentID, "replies to a reply join its thread")
+	other, err := uc.AddComment("bob", todo.ID, "", "Booked")
+	require.NoError(t, err)
+
+	list, total, err := uc.ListComments("bob", todo.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+	require.Len(t, list, 4)
+	assert.Equal(t, first.ID, list[0].ID)
+	todos, _, err := uc.GetAllTodos(0, 10, domain.TodoFilter{Owner: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, 4, todos[0].CommentCount)
+	shared, _, err := uc.SharedWithMe("bob", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 4, shared[0].Todo.CommentCount)
+
+	// only authors can change their comments, the owner of the todo included
+	_, err = uc.EditComment("alice", todo.ID, reply.ID, "July")
+	assert.ErrorIs(t, err, domain.ErrNotCommentAuthor)
+	assert.ErrorIs(t, uc.DeleteComment("alice", todo.ID, other.ID), domain.ErrNotCommentAuthor)
+	edited, err := uc.EditComment("bob", todo.ID, reply.ID, "July")
+	require.NoError(t, err)
+	assert.Equal(t, "July", edited.Body)
+	require.NotNil(t, edited.EditedAt)
+	got, err := uc.GetComment("alice", todo.ID, reply.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "July", got.Body)
+
+	// deleting a comment deletes its thread
+	require.NoError(t, uc.DeleteComment("alice", todo.ID, first.ID))
+	list, total, err = uc.ListComments("alice", todo.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, other.ID, list[0].ID)
+	_, err = uc.GetComment("alice", todo.ID, reply.ID)
+	assert.ErrorIs(t, err, domain.ErrCommentNotFound)
+
+	require.NoError(t, uc.RevokeShare("bob", todo.ID, "bob"))
+	assert.ErrorIs(t, uc.DeleteComment("bob", todo.ID, other.ID), domain.ErrNotFound)
+}
+
+func TestComments_PurgedWithTodo(t *testing.T) {
+	uc, todo := newSharingUseCase(t)
+	comments := repository.NewMemoryCommentRepository()
+	uc.UseComments(comments)
+	_, err := uc.AddComment("alice", todo.ID, "", "Which dates?")
+	require.NoError(t, err)
+
+	require.NoError(t, uc.DeleteTodo("alice", todo.ID, 0))
+	_, total, err := comments.FindByTodo(todo.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total, "trashed todos keep their comments")
+	require.NoError(t, uc.PurgeTodo("alice", todo.ID, 0))
+	_, total, err = comments.FindByTodo(todo.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+}
+
+func TestComments_ExpiredWithTodo(t *testing.T) {
+	uc, todo := newSharingUseCase(t)
+	comments := repository.NewMemoryCommentRepository()
+	uc.UseComments(comments)
+	_, err := uc.AddComment("alice", todo.ID, "", "Which dates?")
+	require.NoError(t, err)
+	require.NoError(t, uc.DeleteTodo("alice", todo.ID, 0))
+
+	// the retention runs out for everything trashed until now
+	purged, err := uc.PurgeExpiredTrash(time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	_, total, err := comments.FindByTodo(todo.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+}
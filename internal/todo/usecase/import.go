@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/todo/domain"
+)
+
+// MaxImportRows caps the number of rows in one import.
+const MaxImportRows = 10000
+
+// ImportRow is one todo read from an import file. Nil fields were not in
+// the file: they are left alone when the row updates a todo, and take their
+// defaults when it creates one.
+type ImportRow struct {
+	// Line is the row's line in the file, for the report.
+	Line    int
+	ID      string
+	Title   *string
+	DueDate *time.Time
+	// AllDay is set by formats that tell dates from times.
+	AllDay *bool
+	Done   *bool
+	Tags   *[]string
+	// Priority is a letter from A to Z, or empty for none.
+	Priority *string
+	// CompletedAt is when a done todo was completed, for formats that
+	// keep it.
+	CompletedAt *time.Time
+	// Checklist is given to a todo the row creates; a row that updates a
+	// todo leaves its checklist alone.
+	Checklist []domain.ChecklistItem
+	// Err is set by the reader for rows it could not parse.
+	Err error
+}
+
+// ImportStatus is the outcome of importing one row.
+type ImportStatus string
+
+const (
+	ImportCreated   ImportStatus = "created"
+	ImportUpdated   ImportStatus = "updated"
+	ImportUnchanged ImportStatus = "unchanged"
+	ImportFailed    ImportStatus = "failed"
+)
+
+// ImportResult reports what importing one row did, or would do in a dry run.
+type ImportResult struct {
+	Line   int
+	ID     string
+	Status ImportStatus
+	Err    error
+}
+
+// ImportTodos upserts the rows by ID: a row whose ID names a live todo
+// updates it, any other row creates a todo, under the row's ID if it has
+// one. A row that fails is reported and does not stop the others. A dry
+// run checks every row the same way but writes nothing.
+func (uc *TodoUseCase) ImportTodos(actor string, rows []ImportRow, dryRun bool) ([]ImportResult, error) {
+	if len(rows) > MaxImportRows {
+		return nil, &domain.ValidationError{Field: "rows", Message: "at most " + strconv.Itoa(MaxImportRows) + " rows can be imported at once"}
+	}
+	// IDs a dry run would have created, so later rows update them
+	created := map[string]bool{}
+	results := make([]ImportResult, 0, len(rows))
+	for _, row := range rows {
+		result := ImportResult{Line: row.Line, ID: row.ID}
+		if row.Err == nil {
+			result.ID, result.Status, row.Err = uc.importRow(actor, row, dryRun, created)
+		}
+		if row.Err != nil {
+			result.Status, result.Err = ImportFailed, row.Err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (uc *TodoUseCase) importRow(actor string, row ImportRow, dryRun bool, created map[string]bool) (string, ImportStatus, error) {
+	id := strings.TrimSpace(row.ID)
+	patch := TodoPatch{Title: row.Title, DueDate: row.DueDate, AllDay: row.AllDay, Done: row.Done, Tags: row.Tags, Priority: row.Priority, CompletedAt: row.CompletedAt}
+	if created[id] {
+		// a dry run did not really create the todo an earlier row names
+		return id, ImportUpdated, patch.apply(&domain.Todo{})
+	}
+	if id != "" {
+		before, err := uc.repo.FindByID(id)
+		if err == nil {
+			if err := uc.authorize(actor, before, domain.PermissionEdit); err != nil {
+				return id, "", err
+			}
+			status, err := uc.importUpdate(actor, before, patch, dryRun)
+			return id, status, err
+		}
+		if !errors.Is(err, domain.ErrNotFound) {
+			return id, "", err
+		}
+	}
+	title, due, done, tags := "", time.Time{}, false, []string(nil)
+	if row.Title != nil {
+		title = *row.Title
+	}
+	if row.DueDate != nil {
+		due = *row.DueDate
+	}
+	if row.Done != nil {
+		done = *row.Done
+	}
+	if row.Tags != nil {
+		tags = *row.Tags
+	}
+	opts := []TodoOption{WithTags(tags)}
+	if row.AllDay != nil {
+		opts = append(opts, WithDueZone(*row.AllDay, ""))
+	}
+	if row.Priority != nil {
+		opts = append(opts, WithPriority(*row.Priority))
+	}
+	if row.CompletedAt != nil {
+		opts = append(opts, WithCompletedAt(*row.CompletedAt))
+	}
+	if len(row.Checklist) > 0 {
+		opts = append(opts, WithChecklist(row.Checklist))
+	}
+	if id != "" {
+		opts = append(opts, WithID(id))
+	}
+	if dryRun {
+		todo := &domain.Todo{DueDate: due, Done: done}
+		if err := validateTitle(title); err != nil {
+			return id, "", err
+		}
+		for _, opt := range opts {
+			if err := opt(todo); err != nil {
+				return id, "", err
+			}
+		}
+		if id != "" {
+			created[id] = true
+		}
+		return id, ImportCreated, nil
+	}
+	todo, err := uc.create(actor, title, due, done, opts...)
+	if err != nil {
+		return id, "", err
+	}
+	return todo.ID, ImportCreated, nil
+}
+
+// importUpdate changes the fields the row has, and leaves todos it would
+// not change untouched.
+func (uc *TodoUseCase) importUpdate(actor string, before *domain.Todo, patch TodoPatch, dryRun bool) (ImportStatus, error) {
+	if patch.CompletedAt != nil && before.CompletedAt != nil && sameDay(*patch.CompletedAt, *before.CompletedAt) {
+		// files only keep the day, which is no reason to lose the time
+		patch.CompletedAt = nil
+	}
+	after := before.Clone()
+	if err := patch.apply(after); err != nil {
+		return "", err
+	}
+	// the history does not track completion times, so look at them apart
+	if len(domain.Diff(before, after)) == 0 && sameTime(before.CompletedAt, after.CompletedAt) {
+		return ImportUnchanged, nil
+	}
+	if !dryRun {
+		if _, err := uc.PatchTodo(actor, before.ID, patch, before.Version); err != nil {
+			return "", err
+		}
+	}
+	return ImportUpdated, nil
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	return ay == by && am == bm && ad == bd
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/infrastructure/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportTodos(t *testing.T) {
+	uc := NewTodoUseCase(repository.NewMemoryTodoRepository(), repository.NewMemoryHistoryRepository())
+	existing := createTodos(t, uc, "A")[0]
+	rows := []ImportRow{
+		{Line: 2, ID: existing.ID, Done: ptr(true)},
+		{Line: 3, ID: existing.ID, Title: ptr("A")},
+		{Line: 4, Title: ptr("New")},
+		{Line: 5, ID: "legacy-1", Title: ptr("Migrated"), Tags: &[]string{"old"}},
+		{Line: 6, ID: "legacy-1", Done: ptr(true)},
+		{Line: 7, Title: ptr(" ")},
+		{Line: 8, Err: &domain.ValidationError{Field: "dueDate", Message: "unrecognised date"}},
+	}
+	want := []ImportStatus{ImportUpdated, ImportUnchanged, ImportCreated, ImportCreated, ImportUpdated, ImportFailed, ImportFailed}
+
+	preview, err := uc.ImportTodos("tester", rows, true)
+	require.NoError(t, err)
+	for i, r := range preview {
+		assert.Equal(t, want[i], r.Status, "dry run line %d", r.Line)
+	}
+	_, total, _ := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	assert.Equal(t, int64(1), total, "a dry run changes nothing")
+
+	results, err := uc.ImportTodos("tester", rows, false)
+	require.NoError(t, err)
+	for i, r := range results {
+		assert.Equal(t, want[i], r.Status, "line %d", r.Line)
+	}
+	assert.NotEmpty(t, results[2].ID)
+	migrated, err := uc.GetTodoByID("tester", "legacy-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Migrated", migrated.Title)
+	assert.True(t, migrated.Done)
+	assert.Equal(t, []string{"old"}, migrated.Tags)
+	stored, err := uc.GetTodoByID("tester", existing.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.Done)
+	assert.Equal(t, int64(2), stored.Version)
+}
+
+func TestImportTodos_CompletionDate(t *testing.T) {
+	uc := NewTodoUseCase(repository.NewMemoryTodoRepository(), repository.NewMemoryHistoryRepository())
+	day := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	results, err := uc.ImportTodos("tester", []ImportRow{
+		{Line: 1, ID: "a", Title: ptr("Pay rent"), Done: ptr(true), CompletedAt: &day, Priority: ptr("b")},
+	}, false)
+	require.NoError(t, err)
+	require.Equal(t, ImportCreated, results[0].Status)
+	todo, err := uc.GetTodoByID("tester", "a")
+	require.NoError(t, err)
+	assert.Equal(t, day, *todo.CompletedAt)
+	assert.Equal(t, "B", todo.Priority)
+
+	// the same day again is no change, another day is
+	later := day.Add(18 * time.Hour)
+	results, err = uc.ImportTodos("tester", []ImportRow{
+		{Line: 1, ID: "a", Done: ptr(true), CompletedAt: &later},
+		{Line: 2, ID: "a", Done: ptr(true), CompletedAt: ptr(day.AddDate(0, 0, 1))},
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, ImportUnchanged, results[0].Status)
+	assert.Equal(t, ImportUpdated, results[1].Status)
+	todo, err = uc.GetTodoByID("tester", "a")
+	require.NoError(t, err)
+	assert.Equal(t, day.AddDate(0, 0, 1), *todo.CompletedAt)
+}
+
+func TestImportTodos_OthersTodos(t *testing.T) {
+	uc, todo := newSharingUseCase(t)
+	_, err := uc.ShareTodo("alice", todo.ID, "carol", domain.PermissionView)
+	require.NoError(t, err)
+	rows := []ImportRow{{Line: 2, ID: todo.ID, Title: ptr("Hijacked")}}
+
+	// a dry run must not tell whether the row would change the todo
+	for _, dryRun := range []bool{true, false} {
+		results, err := uc.ImportTodos("bob", rows, dryRun)
+		require.NoError(t, err)
+		assert.Equal(t, ImportFailed, results[0].Status)
+		assert.ErrorIs(t, results[0].Err, domain.ErrNotFound)
+
+		results, err = uc.ImportTodos("carol", rows, dryRun)
+		require.NoError(t, err)
+		assert.Equal(t, ImportFailed, results[0].Status)
+		assert.ErrorIs(t, results[0].Err, domain.ErrForbidden)
+	}
+	stored, err := uc.GetTodoByID("alice", todo.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Plan trip", stored.Title)
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"time"
+	"todo-app/internal/todo/domain"
+)
+
+// QuickAddResult is how QuickAdd read a line, and the todo it made of it.
+type QuickAddResult struct {
+	domain.QuickAdd
+	Todo *domain.Todo
+}
+
+// QuickAdd creates a todo from a line such as "pay rent every month on the
+// 1st #bills !high", read by domain.ParseQuickAdd in the given time zone,
+// which the todo is due in and a recurrence is evaluated in. A line naming
+// a day but no time makes an all-day todo. A dry run checks the todo
+// without creating it, so that clients can preview what the line means;
+// the todo returned then has an ID that is not saved.
+func (uc *TodoUseCase) QuickAdd(actor, text, timeZone string, dryRun bool) (*QuickAddResult, error) {
+	loc, err := domain.LoadLocation(timeZone)
+	if err != nil {
+		return nil, err
+	}
+	parsed := domain.ParseQuickAdd(text, time.Now().In(loc))
+	opts := []TodoOption{WithDueZone(parsed.AllDay, timeZone)}
+	if len(parsed.Tags) > 0 {
+		opts = append(opts, WithTags(parsed.Tags))
+	}
+	if parsed.Priority != "" {
+		opts = append(opts, WithPriority(parsed.Priority))
+	}
+	if parsed.Rule != "" {
+		opts = append(opts, WithRecurrence(parsed.Rule, timeZone))
+	}
+	result := &QuickAddResult{QuickAdd: parsed}
+	if dryRun {
+		result.Todo, err = newTodo(actor, parsed.Title, parsed.DueDate, false, opts...)
+		if err == nil {
+			present(result.Todo)
+		}
+	} else {
+		result.Todo, err = uc.create(actor, parsed.Title, parsed.DueDate, false, opts...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
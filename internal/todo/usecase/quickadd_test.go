@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"testing"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/infrastructure/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuickAdd(t *testing.T) {
+	repo := repository.NewMemoryTodoRepository()
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
+
+	preview, err := uc.QuickAdd("tester", "pay rent every month on the 1st #bills !high 9am", "Asia/Taipei", true)
+	require.NoError(t, err)
+	assert.Equal(t, "pay rent", preview.Title)
+	assert.Equal(t, "pay rent", preview.Todo.Title)
+	assert.Equal(t, []string{"bills"}, preview.Todo.Tags)
+	assert.Equal(t, "A", preview.Todo.Priority)
+	require.NotNil(t, preview.Todo.Recurrence)
+	assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=1", preview.Todo.Recurrence.Rule)
+	assert.Equal(t, "Asia/Taipei", preview.DueDate.Location().String())
+	assert.Equal(t, 1, preview.DueDate.Day())
+	assert.Equal(t, 9, preview.DueDate.Hour())
+	_, total, _ := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	assert.Equal(t, int64(0), total, "a dry run saves nothing")
+
+	created, err := uc.QuickAdd("tester", "pay rent every month on the 1st #bills !high 9am", "Asia/Taipei", false)
+	require.NoError(t, err)
+	saved, err := uc.GetTodoByID("tester", created.Todo.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "pay rent", saved.Title)
+	assert.True(t, preview.DueDate.Equal(saved.DueDate))
+
+	_, err = uc.QuickAdd("tester", "#bills tomorrow", "", false)
+	var verr *domain.ValidationError
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "title", verr.Field)
+
+	_, err = uc.QuickAdd("tester", "pay rent", "Mars/Olympus", false)
+	require.ErrorAs(t, err, &verr)
+	assert.Equal(t, "timeZone", verr.Field)
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"log"
+	"todo-app/internal/todo/domain"
+)
+
+// WithRecurrence makes a new todo the first occurrence of a series. It must
+// be given after the due date is known, as that starts the series.
+func WithRecurrence(rule, timeZone string) TodoOption {
+	return func(todo *domain.Todo) error {
+		rec, err := domain.NewRecurrence(rule, timeZone, todo.DueDate)
+		if err != nil {
+			return err
+		}
+		todo.Recurrence = rec
+		return nil
+	}
+}
+
+// SetRecurrence makes the todo recurring, starting a new series at its
+// current due date.
+func (uc *TodoUseCase) SetRecurrence(actor, id, rule, timeZone string, version int64) (*domain.Todo, error) {
+	return uc.mutate(actor, id, version, WithRecurrence(rule, timeZone))
+}
+
+// EndSeries stops a recurring todo from generating further occurrences.
+// The todo itself is kept.
+func (uc *TodoUseCase) EndSeries(actor, id string, version int64) (*domain.Todo, error) {
+	return uc.mutate(actor, id, version, func(todo *domain.Todo) error {
+		if todo.Recurrence == nil {
+			return domain.ErrNotRecurring
+		}
+		todo.Recurrence = nil
+		return nil
+	})
+}
+
+// SkipOccurrence moves a recurring todo on to its next occurrence without
+// completing it.
+func (uc *TodoUseCase) SkipOccurrence(actor, id string, version int64) (*domain.Todo, error) {
+	return uc.mutate(actor, id, version, func(todo *domain.Todo) error {
+		if todo.Recurrence == nil {
+			return domain.ErrNotRecurring
+		}
+		next, ok, err := todo.NextDue()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return domain.ErrSeriesEnded
+		}
+		todo.DueDate = next
+		todo.Recurrence.Index++
+		return nil
+	})
+}
+
+// nextOccurrence returns the todo to generate when a change completes
+// a recurring todo, and links it from the completed one so that reopening
+// and completing again does not generate it twice. It returns nil when
+// nothing is to be generated.
+func nextOccurrence(before, after *domain.Todo) (*domain.Todo, error) {
+	rec := after.Recurrence
+	if before.Done || !after.Done || rec == nil || rec.NextID != "" {
+		return nil, nil
+	}
+	due, ok, err := after.NextDue()
+	if err != nil || !ok {
+		return nil, err
+	}
+	next := after.Clone()
+	next.ID = generateID()
+	next.DueDate = due
+	next.Done, next.CompletedAt = false, nil
+	next.Version = 1
+	next.Progress = nil
+	// files belong to the occurrence they were attached to
+	next.Attachments = nil
+	for i := range next.Checklist {
+		next.Checklist[i].Checked = false
+	}
+	next.Recurrence.Index++
+	rec.NextID = next.ID
+	return next, nil
+}
+
+// saveOccurrence stores a generated occurrence. The completion that caused
+// it has already been written, so a failure is only logged.
+func (uc *TodoUseCase) saveOccurrence(actor string, next *domain.Todo) {
+	if err := uc.repo.Save(next); err != nil {
+		log.Printf("todo recurrence: save next occurrence %s: %v", next.ID, err)
+		return
+	}
+	uc.record(actor, domain.HistoryCreated, nil, next)
+}
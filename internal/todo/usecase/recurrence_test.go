@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/infrastructure/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurrence_CompletingGeneratesNextOccurrence(t *testing.T) {
+	repo := repository.NewMemoryTodoRepository()
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
+
+	taipei, err := time.LoadLocation("Asia/Taipei")
+	require.NoError(t, err)
+	due := time.Date(2025, 7, 7, 9, 0, 0, 0, taipei) // Monday
+	require.NoError(t, uc.CreateTodo("tester", "Take out trash", due, false, WithRecurrence("FREQ=WEEKLY;BYDAY=MO,TH;COUNT=3", "Asia/Taipei")))
+	todos, _, _ := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	first := todos[0]
+	_, err = uc.AddChecklistItem("tester", first.ID, "Recycling", true, nil, 0)
+	require.NoError(t, err)
+
+	done := true
+	completed, err := uc.PatchTodo("tester", first.ID, TodoPatch{Done: &done}, 0)
+	require.NoError(t, err)
+	require.NotEmpty(t, completed.Recurrence.NextID)
+
+	second, err := uc.GetTodoByID("tester", completed.Recurrence.NextID)
+	require.NoError(t, err)
+	assert.Equal(t, "Take out trash", second.Title)
+	assert.False(t, second.Done)
+	assert.True(t, time.Date(2025, 7, 10, 9, 0, 0, 0, taipei).Equal(second.DueDate))
+	assert.Equal(t, 2, second.Recurrence.Index)
+	assert.False(t, second.Checklist[0].Checked)
+
+	// reopening and completing again does not generate a duplicate
+	notDone := false
+	_, err = uc.PatchTodo("tester", first.ID, TodoPatch{Done: &notDone}, 0)
+	require.NoError(t, err)
+	_, err = uc.PatchTodo("tester", first.ID, TodoPatch{Done: &done}, 0)
+	require.NoError(t, err)
+	_, total, _ := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	assert.Equal(t, int64(2), total)
+
+	// the third occurrence is the last one allowed by COUNT
+	_, err = uc.UpdateTodo("tester", second.ID, second.Title, second.DueDate, true, 0)
+	require.NoError(t, err)
+	second, _ = uc.GetTodoByID("tester", second.ID)
+	third, err := uc.GetTodoByID("tester", second.Recurrence.NextID)
+	require.NoError(t, err)
+	assert.True(t, time.Date(2025, 7, 14, 9, 0, 0, 0, taipei).Equal(third.DueDate))
+	_, err = uc.PatchTodo("tester", third.ID, TodoPatch{Done: &done}, 0)
+	require.NoError(t, err)
+	_, total, _ = uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	assert.Equal(t, int64(3), total)
+}
+
+func TestRecurrence_SkipAndEnd(t *testing.T) {
+	repo := repository.NewMemoryTodoRepository()
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
+
+	due := time.Date(2025, 7, 1, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, uc.CreateTodo("tester", "Pay rent", due, false))
+	todos, _, _ := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	id := todos[0].ID
+
+	_, err := uc.SkipOccurrence("tester", id, 0)
+	assert.ErrorIs(t, err, domain.ErrNotRecurring)
+
+	todo, err := uc.SetRecurrence("tester", id, "FREQ=MONTHLY;BYMONTHDAY=1;COUNT=2", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, todo.Recurrence.Index)
+
+	todo, err = uc.SkipOccurrence("tester", id, 0)
+	require.NoError(t, err)
+	assert.True(t, time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC).Equal(todo.DueDate))
+	assert.Equal(t, 2, todo.Recurrence.Index)
+	_, err = uc.SkipOccurrence("tester", id, 0)
+	assert.ErrorIs(t, err, domain.ErrSeriesEnded)
+
+	todo, err = uc.EndSeries("tester", id, 0)
+	require.NoError(t, err)
+	assert.Nil(t, todo.Recurrence)
+	done := true
+	_, err = uc.PatchTodo("tester", id, TodoPatch{Done: &done}, 0)
+	require.NoError(t, err)
+	_, total, _ := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	assert.Equal(t, int64(1), total)
+
+	var invalid *domain.ValidationError
+	_, err = uc.SetRecurrence("tester", id, "FREQ=SOMETIMES", "", 0)
+	assert.ErrorAs(t, err, &invalid)
+	_, err = uc.SetRecurrence("tester", id, "FREQ=DAILY", "Mars/Olympus_Mons", 0)
+	assert.ErrorAs(t, err, &invalid)
+}
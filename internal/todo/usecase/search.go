@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"html"
+	"slices"
+	"strconv"
+	"strings"
+	"todo-app/internal/todo/domain"
+	"unicode"
+)
+
+// MaxSearchTerms caps the number of distinct terms in a search query.
+const MaxSearchTerms = 10
+
+// maxSearchLength caps the length of a search query, in bytes.
+const maxSearchLength = 200
+
+// SearchResult is a todo found by SearchTodos.
+type SearchResult struct {
+	Todo  *domain.Todo
+	Score float64
+	// Highlight is the title as HTML, with the words that matched the
+	// query in <mark> elements.
+	Highlight string
+	// MatchedTags are the tags having a word of the query.
+	MatchedTags []string
+}
+
+// SearchTodos finds the live todos passing filter whose title or tags
+// have any word of the query, best matches first. Words match whole and
+// regardless of case.
+func (uc *TodoUseCase) SearchTodos(query string, filter domain.TodoFilter, page, limit int) ([]SearchResult, int64, error) {
+	if len(query) > maxSearchLength {
+		return nil, 0, &domain.ValidationError{Field: "q", Message: "must be at most " + strconv.Itoa(maxSearchLength) + " bytes long"}
+	}
+	var terms []string
+	for _, term := range domain.Tokenize(query) {
+		if !slices.Contains(terms, term) {
+			terms = append(terms, term)
+		}
+	}
+	switch {
+	case len(terms) == 0:
+		return nil, 0, &domain.ValidationError{Field: "q", Message: "must contain a word to search for"}
+	case len(terms) > MaxSearchTerms:
+		return nil, 0, &domain.ValidationError{Field: "q", Message: "must have at most " + strconv.Itoa(MaxSearchTerms) + " words"}
+	}
+	hits, total, err := uc.repo.Search(domain.SearchQuery{Terms: terms, Filter: filter}, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	results := make([]SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		result := SearchResult{Todo: present(hit.Todo), Score: hit.Score, Highlight: highlight(hit.Todo.Title, terms)}
+		for _, tag := range hit.Todo.Tags {
+			if slices.ContainsFunc(domain.Tokenize(tag), func(word string) bool { return slices.Contains(terms, word) }) {
+				result.MatchedTags = append(result.MatchedTags, tag)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, total, nil
+}
+
+// highlight escapes text as HTML and marks the words that are terms,
+// splitting words the way domain.Tokenize does.
+func highlight(text string, terms []string) string {
+	var b strings.Builder
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }
+	for text != "" {
+		end := strings.IndexFunc(text, func(r rune) bool { return !isWord(r) })
+		if end == 0 {
+			// a run of separators
+			if end = strings.IndexFunc(text, isWord); end < 0 {
+				end = len(text)
+			}
+			b.WriteString(html.EscapeString(text[:end]))
+			text = text[end:]
+			continue
+		}
+		if end < 0 {
+			end = len(text)
+		}
+		word := text[:end]
+		if slices.Contains(terms, strings.ToLower(word)) {
+			b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+		text = text[end:]
+	}
+	return b.String()
+}
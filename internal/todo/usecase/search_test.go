@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/infrastructure/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func searchTitles(t *testing.T, uc *TodoUseCase, query string) []string {
+	t.Helper()
+	results, _, err := uc.SearchTodos(query, domain.TodoFilter{}, 0, 10)
+	require.NoError(t, err)
+	titles := []string{}
+	for _, r := range results {
+		titles = append(titles, r.Todo.Title)
+	}
+	return titles
+}
+
+func TestSearchTodos(t *testing.T) {
+	uc := NewTodoUseCase(repository.NewMemoryTodoRepository(), repository.NewMemoryHistoryRepository())
+	require.NoError(t, uc.CreateTodo("tester", "Buy milk & eggs", time.Time{}, false, WithID("a"), WithTags([]string{"shopping"})))
+	require.NoError(t, uc.CreateTodo("tester", "Milk the cow, then more milk", time.Time{}, false, WithID("b")))
+	require.NoError(t, uc.CreateTodo("tester", "Walk dog", time.Time{}, true, WithID("c"), WithTags([]string{"milk-run"})))
+	require.NoError(t, uc.CreateTodo("tester", "Milkshake", time.Time{}, false, WithID("d")))
+
+	results, total, err := uc.SearchTodos("MILK eggs", domain.TodoFilter{}, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	require.Len(t, results, 3)
+	// a: milk and eggs in the title; b: milk twice; c: milk in a tag
+	assert.Equal(t, "a", results[0].Todo.ID)
+	assert.Equal(t, 4.0, results[0].Score)
+	assert.Equal(t, "Buy <mark>milk</mark> &amp; <mark>eggs</mark>", results[0].Highlight)
+	assert.Equal(t, "b", results[1].Todo.ID)
+	assert.Equal(t, "<mark>Milk</mark> the cow, then more <mark>milk</mark>", results[1].Highlight)
+	assert.Equal(t, "c", results[2].Todo.ID)
+	assert.Equal(t, "Walk dog", results[2].Highlight)
+	assert.Equal(t, []string{"milk-run"}, results[2].MatchedTags)
+
+	done := false
+	results, _, err = uc.SearchTodos("milk", domain.TodoFilter{Done: &done}, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	// the index follows changes, and trashed todos are not found
+	_, err = uc.PatchTodo("tester", "a", TodoPatch{Title: ptr("Buy bread")}, 0)
+	require.NoError(t, err)
+	require.NoError(t, uc.DeleteTodo("tester", "b", 0))
+	assert.Equal(t, []string{"Walk dog"}, searchTitles(t, uc, "milk"))
+	assert.Equal(t, []string{"Buy bread"}, searchTitles(t, uc, "bread"))
+
+	var invalid *domain.ValidationError
+	_, _, err = uc.SearchTodos(" ?! ", domain.TodoFilter{}, 0, 10)
+	assert.ErrorAs(t, err, &invalid)
+	_, _, err = uc.SearchTodos("a b c d e f g h i j k", domain.TodoFilter{}, 0, 10)
+	assert.ErrorAs(t, err, &invalid)
+}
+
+func TestSearchTodos_RolledBackBatch(t *testing.T) {
+	uc := NewTodoUseCase(repository.NewMemoryTodoRepository(), repository.NewMemoryHistoryRepository())
+	require.NoError(t, uc.CreateTodo("tester", "Buy milk", time.Time{}, false, WithID("a")))
+	failed := errors.New("failed")
+	err := uc.repo.RunInTx(func(tx domain.TodoRepository) error {
+		require.NoError(t, tx.Save(&domain.Todo{ID: "b", Title: "More milk", Version: 1}))
+		todo, err := tx.FindByID("a")
+		require.NoError(t, err)
+		todo.Title = "Buy bread"
+		require.NoError(t, tx.UpdateByID(todo))
+		return failed
+	})
+	require.ErrorIs(t, err, failed)
+	assert.Equal(t, []string{"Buy milk"}, searchTitles(t, uc, "milk"))
+	assert.Empty(t, searchTitles(t, uc, "bread"))
+}
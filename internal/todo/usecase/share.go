@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+	"todo-app/internal/todo/domain"
+)
+
+// ownerOnly is what only the owner of a todo may do, such as deleting or
+// sharing it; no share allows it.
+const ownerOnly domain.Permission = "owner"
+
+// authorize checks that actor may do what need says with the todo. The
+// assignee of a todo can edit it as if it were shared with them. Todos
+// that are neither theirs, nor assigned or shared to them do not exist as
+// far as actor is concerned, while a share that does not go far enough
+// yields domain.ErrForbidden. Todos without an owner predate users: anyone
+// may view and edit them, but since nobody owns them, nobody may do what
+// only the owner may.
+func (uc *TodoUseCase) authorize(actor string, todo *domain.Todo, need domain.Permission) error {
+	if todo.Owner == actor {
+		return nil
+	}
+	if todo.Owner == "" {
+		if need == ownerOnly {
+			return domain.ErrNotFound
+		}
+		return nil
+	}
+	granted, err := uc.granted(actor, todo)
+	if err != nil {
+		return err
+	}
+	if !granted.Allows(need) {
+		return domain.ErrForbidden
+	}
+	return nil
+}
+
+// granted returns the permission actor has on a todo of somebody else, or
+// domain.ErrNotFound when they have none.
+func (uc *TodoUseCase) granted(actor string, todo *domain.Todo) (domain.Permission, error) {
+	if todo.Assignee == actor {
+		return domain.PermissionEdit, nil
+	}
+	if uc.shares == nil {
+		return "", domain.ErrNotFound
+	}
+	share, err := uc.shares.Find(todo.ID, actor)
+	if errors.Is(err, domain.ErrShareNotFound) {
+		return "", domain.ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return share.Permission, nil
+}
+
+// SharedTodo is a todo shared with the caller, along with the share.
+type SharedTodo struct {
+	Todo  *domain.Todo
+	Share *domain.Share
+}
+
+// ShareTodo shares a live todo of actor with another registered user, or
+// changes the permission of an existing share.
+func (uc *TodoUseCase) ShareTodo(actor, id, grantee string, permission domain.Permission) (*domain.Share, error) {
+	if uc.shares == nil || uc.users == nil {
+		return nil, &domain.ValidationError{Field: "username", Message: "sharing is not available"}
+	}
+	if permission != domain.PermissionView && permission != domain.PermissionEdit {
+		return nil, &domain.ValidationError{Field: "permission", Message: "must be view or edit"}
+	}
+	todo, err := uc.ownTodo(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if grantee == todo.Owner {
+		return nil, &domain.ValidationError{Field: "username", Message: "is the owner of the todo"}
+	}
+	exists, err := uc.users.Exists(grantee)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &domain.ValidationError{Field: "username", Message: "is not a registered user"}
+	}
+	share := &domain.Share{TodoID: todo.ID, Owner: todo.Owner, Grantee: grantee, Permission: permission, CreatedAt: time.Now()}
+	if err := uc.shares.Save(share); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// ListShares lists who a live todo of actor is shared with, oldest share
+// first.
+func (uc *TodoUseCase) ListShares(actor, id string) ([]*domain.Share, error) {
+	if _, err := uc.ownTodo(actor, id); err != nil {
+		return nil, err
+	}
+	if uc.shares == nil {
+		return []*domain.Share{}, nil
+	}
+	return uc.shares.FindByTodo(id)
+}
+
+// RevokeShare stops sharing a todo with grantee. The owner can revoke any
+// share of the todo, and grantees can give up their own.
+func (uc *TodoUseCase) RevokeShare(actor, id, grantee string) error {
+	if uc.shares == nil {
+		return domain.ErrShareNotFound
+	}
+	if actor != grantee {
+		if _, err := uc.ownTodo(actor, id); err != nil {
+			return err
+		}
+	}
+	return uc.shares.Delete(id, grantee)
+}
+
+// SharedWithMe lists the live todos shared with actor, most recently
+// shared first. Shares of trashed todos count towards total but are left
+// out of the list.
+func (uc *TodoUseCase) SharedWithMe(actor string, page, limit int) ([]SharedTodo, int64, error) {
+	if uc.shares == nil {
+		return []SharedTodo{}, 0, nil
+	}
+	shares, total, err := uc.shares.FindByGrantee(actor, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	ids := make([]string, 0, len(shares))
+	for _, share := range shares {
+		ids = append(ids, share.TodoID)
+	}
+	todos, err := uc.repo.FindByIDs(ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[string]*domain.Todo, len(todos))
+	for _, todo := range todos {
+		byID[todo.ID] = todo
+	}
+	if err := uc.countComments(todos); err != nil {
+		return nil, 0, err
+	}
+	list := make([]SharedTodo, 0, len(shares))
+	for _, share := range shares {
+		if todo, ok := byID[share.TodoID]; ok {
+			list = append(list, SharedTodo{Todo: present(todo), Share: share})
+		}
+	}
+	return list, total, nil
+}
+
+// ownTodo returns a live todo of actor; shared todos do not count.
+func (uc *TodoUseCase) ownTodo(actor, id string) (*domain.Todo, error) {
+	todo, err := uc.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorize(actor, todo, ownerOnly); err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
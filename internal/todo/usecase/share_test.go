@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+	"todo-app/internal/todo/domain"
+	"todo-app/internal/todo/infrastructure/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type knownUsers map[string]bool
+
+func (u knownUsers) Exists(username string) (bool, error) {
+	return u[username], nil
+}
+
+func newSharingUseCase(t *testing.T) (*TodoUseCase, *domain.Todo) {
+	t.Helper()
+	uc := NewTodoUseCase(repository.NewMemoryTodoRepository(), repository.NewMemoryHistoryRepository())
+	uc.UseUsers(knownUsers{"alice": true, "bob": true, "carol": true})
+	uc.UseSharing(repository.NewMemoryShareRepository())
+	require.NoError(t, uc.CreateTodo("alice", "Plan trip", time.Time{}, false))
+	todos, _, err := uc.GetAllTodos(0, 10, domain.TodoFilter{Owner: "alice"})
+	require.NoError(t, err)
+	return uc, todos[0]
+}
+
+func TestShareTodo(t *testing.T) {
+	uc, todo := newSharingUseCase(t)
+	var invalid *domain.ValidationError
+
+	_, err := uc.ShareTodo("alice", todo.ID, "dave", domain.PermissionView)
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "username", invalid.Field)
+	_, err = uc.ShareTodo("alice", todo.ID, "alice", domain.PermissionView)
+	assert.ErrorAs(t, err, &invalid)
+	_, err = uc.ShareTodo("alice", todo.ID, "bob", "admin")
+	assert.ErrorAs(t, err, &invalid)
+	_, err = uc.ShareTodo("bob", todo.ID, "carol", domain.PermissionView)
+	assert.ErrorIs(t, err, domain.ErrNotFound, "todos of others cannot be shared")
+
+	first, err := uc.ShareTodo("alice", todo.ID, "bob", domain.PermissionView)
+	require.NoError(t, err)
+	_, err = uc.ShareTodo("alice", todo.ID, "carol", domain.PermissionView)
+	require.NoError(t, err)
+	// sharing again changes the permission of the share
+	again, err := uc.ShareTodo("alice", todo.ID, "bob", domain.PermissionEdit)
+	require.NoError(t, err)
+	assert.Equal(t, first.CreatedAt, again.CreatedAt)
+
+	shares, err := uc.ListShares("alice", todo.ID)
+	require.NoError(t, err)
+	require.Len(t, shares, 2)
+	assert.Equal(t, "bob", shares[0].Grantee)
+	assert.Equal(t, domain.PermissionEdit, shares[0].Permission)
+	_, err = uc.ListShares("bob", todo.ID)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	_, err = uc.ShareTodo("bob", todo.ID, "carol", domain.PermissionEdit)
+	assert.ErrorIs(t, err, domain.ErrForbidden, "grantees cannot reshare")
+
+	shared, total, err := uc.SharedWithMe("bob", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, shared, 1)
+	assert.Equal(t, "Plan trip", shared[0].Todo.Title)
+	assert.Equal(t, domain.PermissionEdit, shared[0].Share.Permission)
+}
+
+func TestShareTodo_Permissions(t *testing.T) {
+	uc, todo := newSharingUseCase(t)
+	_, err := uc.ShareTodo("alice", todo.ID, "bob", domain.PermissionEdit)
+	require.NoError(t, err)
+	_, err = uc.ShareTodo("alice", todo.ID, "carol", domain.PermissionView)
+	require.NoError(t, err)
+
+	_, err = uc.GetTodoByID("carol", todo.ID)
+	assert.NoError(t, err)
+	_, err = uc.PatchTodo("carol", todo.ID, TodoPatch{Title: ptr("Plan holiday")}, 0)
+	assert.ErrorIs(t, err, domain.ErrForbidden)
+	_, err = uc.GetTodoByID("dave", todo.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	patched, err := uc.PatchTodo("bob", todo.ID, TodoPatch{Title: ptr("Plan holiday")}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", patched.Owner)
+	assert.ErrorIs(t, uc.DeleteTodo("bob", todo.ID, 0), domain.ErrForbidden)
+	results, err := uc.BulkTodos("bob", BulkSelection{IDs: []string{todo.ID}}, domain.BulkDelete, domain.BulkChange{})
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, domain.ErrForbidden)
+	results, err = uc.BulkTodos("bob", BulkSelection{IDs: []string{todo.ID}}, domain.BulkComplete, domain.BulkChange{})
+	require.NoError(t, err)
+	assert.Equal(t, domain.BulkApplied, results[0].Status)
+
+	// grantees can give up a share, but not revoke the shares of others
+	assert.ErrorIs(t, uc.RevokeShare("bob", todo.ID, "carol"), domain.ErrForbidden)
+	require.NoError(t, uc.RevokeShare("carol", todo.ID, "carol"))
+	_, err = uc.GetTodoByID("carol", todo.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.ErrorIs(t, uc.RevokeShare("alice", todo.ID, "carol"), domain.ErrShareNotFound)
+
+	// purged todos take their shares with them
+	require.NoError(t, uc.DeleteTodo("alice", todo.ID, 0))
+	shared, total, err := uc.SharedWithMe("bob", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Empty(t, shared, "trashed todos are not listed")
+	require.NoError(t, uc.PurgeTodo("alice", todo.ID, 0))
+	_, total, err = uc.SharedWithMe("bob", 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+	_, _, err = uc.GetHistory("bob", todo.ID, 0, 10)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, total, err = uc.GetHistory("alice", todo.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+}
+
+func TestOwnerlessTodo(t *testing.T) {
+	repo := repository.NewMemoryTodoRepository()
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
+	uc.UseUsers(knownUsers{"alice": true, "bob": true})
+	uc.UseSharing(repository.NewMemoryShareRepository())
+	// stored before there were users
+	require.NoError(t, repo.Save(&domain.Todo{ID: "legacy", Title: "Old todo", Version: 1}))
+
+	_, err := uc.GetTodoByID("alice", "legacy")
+	require.NoError(t, err)
+	_, err = uc.PatchTodo("alice", "legacy", TodoPatch{Title: ptr("Still old")}, 0)
+	require.NoError(t, err)
+
+	// but nobody owns it, so nobody may do what only owners may
+	_, err = uc.ShareTodo("alice", "legacy", "bob", domain.PermissionView)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.ErrorIs(t, uc.DeleteTodo("bob", "legacy", 0), domain.ErrNotFound)
+	require.NoError(t, repo.TrashByID("legacy", 0, time.Now()))
+	_, err = uc.RestoreTodo("alice", "legacy", 0)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.ErrorIs(t, uc.PurgeTodo("alice", "legacy", 0), domain.ErrNotFound)
+}
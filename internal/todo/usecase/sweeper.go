@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+	"todo-app/internal/todo/domain"
+)
+
+// SweeperActor is the actor recorded for todos purged because they have
+// been in the trash for longer than the retention.
+const SweeperActor = "trash-sweeper"
+
+// sweepBatch caps the todos purged in one go, so that a large backlog of
+// expired todos is worked through a page at a time.
+const sweepBatch = 100
+
+// PurgeExpiredTrash permanently deletes the todos trashed before the given
+// time, the same way PurgeTodo does, so that their shares, comments and
+// attachments go with them. Todos restored or purged in the meantime are
+// skipped.
+func (uc *TodoUseCase) PurgeExpiredTrash(before time.Time) (purged int, err error) {
+	for {
+		expired, err := uc.repo.FindExpiredTrash(before, sweepBatch)
+		if err != nil {
+			return purged, err
+		}
+		skipped := 0
+		for _, todo := range expired {
+			err := uc.repo.DeleteByID(todo.ID, todo.Version)
+			if errors.Is(err, domain.ErrNotFound) || errors.Is(err, domain.ErrVersionConflict) {
+				skipped++
+				continue
+			}
+			if err != nil {
+				return purged, err
+			}
+			uc.record(SweeperActor, domain.HistoryPurged, todo, nil)
+			purged++
+		}
+		// a page that was all skipped would come back the same
+		if len(expired) < sweepBatch || skipped == len(expired) {
+			return purged, nil
+		}
+	}
+}
+
+// TrashSweeper purges the todos that have been in the trash for longer than
+// the retention through the use case it is given, checking every interval.
+// It is created ahead of the use case so that it can be run with the other
+// background workers.
+type TrashSweeper struct {
+	retention time.Duration
+	interval  time.Duration
+
+	mu sync.Mutex
+	uc *TodoUseCase
+}
+
+func NewTrashSweeper(retention, interval time.Duration) *TrashSweeper {
+	return &TrashSweeper{retention: retention, interval: interval}
+}
+
+// Use sets the use case todos are purged through; until it is set, passes
+// do nothing.
+func (s *TrashSweeper) Use(uc *TodoUseCase) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uc = uc
+}
+
+// Run sweeps the trash every interval until ctx is cancelled.
+func (s *TrashSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.sweep(now)
+		}
+	}
+}
+
+func (s *TrashSweeper) sweep(now time.Time) {
+	s.mu.Lock()
+	uc := s.uc
+	s.mu.Unlock()
+	if uc == nil {
+		return
+	}
+	n, err := uc.PurgeExpiredTrash(now.Add(-s.retention))
+	if err != nil {
+		log.Printf("trash sweeper: %v", err)
+	}
+	if n > 0 {
+		log.Printf("trash sweeper: purged %d todos", n)
+	}
+}
@@ -1,6 +1,9 @@
 package usecase
 
 import (
+	"errors"
+	"log"
+	"strings"
 	"time"
 	"todo-app/internal/todo/domain"
 
@@ -8,51 +11,614 @@ import (
 )
 
 type TodoUseCase struct {
-	repo domain.TodoRepository
+	repo     domain.TodoRepository
+	history  domain.HistoryRepository
+	handlers []domain.EventHandler
+	// projects checks the projects todos are filed under; without it todos
+	// cannot be filed under any.
+	projects domain.Projects
+	// shares lets owners share todos with other users; without it todos
+	// cannot be shared.
+	shares domain.ShareRepository
+	// users tells who todos can be shared with, assigned to and mention;
+	// without it todos can be neither shared nor assigned, and mentions are
+	// not recorded.
+	users domain.Users
+	// comments keeps the comments on todos; without it todos cannot be
+	// commented on.
+	comments domain.CommentRepository
+	// blobs keeps the content of attachments, within attachmentLimits;
+	// without it nothing can be attached to todos.
+	blobs            domain.BlobStore
+	attachmentLimits domain.AttachmentLimits
+	// deferred is set while running a batch: changes are only recorded
+	// there, and published once the batch has been committed.
+	deferred *[]domain.Event
 }
 
-func NewTodoUseCase(repo domain.TodoRepository) *TodoUseCase {
+func NewTodoUseCase(repo domain.TodoRepository, history domain.HistoryRepository) *TodoUseCase {
 	return &TodoUseCase{
-		repo: repo,
+		repo:    repo,
+		history: history,
 	}
 }
 
-func (uc *TodoUseCase) CreateTodo(title string, dueTime time.Time, done bool) error {
+// TodoOption sets an optional field of a todo being created.
+type TodoOption func(todo *domain.Todo) error
+
+// WithTags labels a new todo.
+func WithTags(tags []string) TodoOption {
+	return func(todo *domain.Todo) error {
+		normalized, err := domain.NormalizeTags(tags)
+		if err != nil {
+			return err
+		}
+		todo.Tags = normalized
+		return nil
+	}
+}
+
+// WithPriority gives a new todo a priority from A to Z.
+func WithPriority(priority string) TodoOption {
+	return func(todo *domain.Todo) error {
+		normalized, err := domain.NormalizePriority(priority)
+		if err != nil {
+			return err
+		}
+		todo.Priority = normalized
+		return nil
+	}
+}
+
+// WithCompletedAt dates the completion of a todo created done, for todos
+// completed elsewhere.
+func WithCompletedAt(at time.Time) TodoOption {
+	return func(todo *domain.Todo) error {
+		if todo.Done {
+			at = at.UTC()
+			todo.CompletedAt = &at
+		}
+		return nil
+	}
+}
+
+// WithDueZone makes the due date all-day, keeping only its date, and
+// records the time zone it was set in. It must be given after the due date
+// is known and before WithRecurrence, which starts at the due date.
+func WithDueZone(allDay bool, timeZone string) TodoOption {
+	return func(todo *domain.Todo) error {
+		return todo.SetDue(todo.DueDate, allDay, timeZone)
+	}
+}
+
+// WithProject files a new todo under a project of its owner.
+func WithProject(id string) TodoOption {
+	return func(todo *domain.Todo) error {
+		todo.ProjectID = id
+		return nil
+	}
+}
+
+// WithAssignee assigns a new todo to a registered user.
+func WithAssignee(username string) TodoOption {
+	return func(todo *domain.Todo) error {
+		todo.Assignee = strings.TrimSpace(username)
+		return nil
+	}
+}
+
+// WithID creates the todo under a given ID instead of a generated one, for
+// todos that already have an ID elsewhere.
+func WithID(id string) TodoOption {
+	return func(todo *domain.Todo) error {
+		if id == "" || len(id) > 100 || strings.ContainsAny(id, "/?#") {
+			return &domain.ValidationError{Field: "id", Message: "must be 1 to 100 characters and not contain /, ? or #"}
+		}
+		todo.ID = id
+		return nil
+	}
+}
+
+// Subscribe registers a handler to be told about every change made through
+// the use case. It is meant to be called during setup, before serving.
+func (uc *TodoUseCase) Subscribe(h domain.EventHandler) {
+	uc.handlers = append(uc.handlers, h)
+}
+
+// UseProjects sets where the projects todos are filed under are looked up.
+// Like Subscribe, it is meant to be called during setup.
+func (uc *TodoUseCase) UseProjects(projects domain.Projects) {
+	uc.projects = projects
+}
+
+// UseSharing sets where shares are kept. Like Subscribe, it is meant to
+// be called during setup.
+func (uc *TodoUseCase) UseSharing(shares domain.ShareRepository) {
+	uc.shares = shares
+}
+
+// UseUsers sets where usernames are looked up, for sharing, assignees and
+// mentions. Like Subscribe, it is meant to be called during setup.
+func (uc *TodoUseCase) UseUsers(users domain.Users) {
+	uc.users = users
+}
+
+func (uc *TodoUseCase) CreateTodo(actor, title string, dueTime time.Time, done bool, opts ...TodoOption) error {
+	_, err := uc.create(actor, title, dueTime, done, opts...)
+	return err
+}
+
+func (uc *TodoUseCase) create(actor, title string, dueTime time.Time, done bool, opts ...TodoOption) (*domain.Todo, error) {
 	// 處理業務邏輯
 	// 注意: UseCase 不知道資料從哪裡來，也不管要存去哪裡
+	todo, err := newTodo(actor, title, dueTime, done, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.checkProject(todo); err != nil {
+		return nil, err
+	}
+	if err := uc.checkAssignee(todo); err != nil {
+		return nil, err
+	}
+	if err := uc.mention(todo); err != nil {
+		return nil, err
+	}
+	if err := uc.repo.Save(todo); err != nil {
+		return nil, err
+	}
+	uc.record(actor, domain.HistoryCreated, nil, todo)
+	return present(todo), nil
+}
+
+// newTodo builds and validates a todo without saving it.
+func newTodo(actor, title string, dueTime time.Time, done bool, opts ...TodoOption) (*domain.Todo, error) {
+	if err := validateTitle(title); err != nil {
+		return nil, err
+	}
 	todo := &domain.Todo{
 		ID:      generateID(),
 		Title:   title,
 		DueDate: dueTime,
-		Done:    done,
+		Version: 1,
+		Owner:   actor,
+	}
+	todo.SetDone(done, time.Now())
+	for _, opt := range opts {
+		if err := opt(todo); err != nil {
+			return nil, err
+		}
+	}
+	return todo, nil
+}
+
+func (uc *TodoUseCase) GetAllTodos(page, limit int, filter domain.TodoFilter) (list []*domain.Todo, total int64, err error) {
+	list, total, err = uc.repo.FindAll(page, limit, filter)
+	if err != nil {
+		return nil, 0, err
 	}
-	return uc.repo.Save(todo)
+	if err := uc.countComments(list); err != nil {
+		return nil, 0, err
+	}
+	return presentAll(list), total, nil
+}
+
+// DeleteTodo moves the todo to the trash, from where it can be restored
+// until it is purged. A non-zero version must match the stored one.
+func (uc *TodoUseCase) DeleteTodo(actor, id string, version int64) error {
+	before, err := uc.repo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	if err := uc.authorize(actor, before, ownerOnly); err != nil {
+		return err
+	}
+	if version == 0 {
+		version = before.Version
+	}
+	now := time.Now()
+	if err := uc.repo.TrashByID(id, version, now); err != nil {
+		return err
+	}
+	after := before.Clone()
+	after.DeletedAt = &now
+	after.Version = version + 1
+	uc.record(actor, domain.HistoryDeleted, before, after)
+	return nil
+}
+
+// GetTrash lists the trashed todos of actor.
+func (uc *TodoUseCase) GetTrash(actor string, page, limit int) (list []*domain.Todo, total int64, err error) {
+	list, total, err = uc.repo.FindTrash(actor, page, limit)
+	return presentAll(list), total, err
 }
 
-func (uc *TodoUseCase) GetAllTodos(page, limit int, title string) (list []*domain.Todo, total int64, err error) {
-	return uc.repo.FindAll(page, limit, title)
+// RestoreTodo brings a trashed todo back. A non-zero version must match.
+// A todo whose project was deleted in the meantime is moved to the inbox.
+func (uc *TodoUseCase) RestoreTodo(actor, id string, version int64) (*domain.Todo, error) {
+	before, err := uc.repo.FindTrashedByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorize(actor, before, ownerOnly); err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		version = before.Version
+	}
+	if err := uc.repo.RestoreByID(id, version); err != nil {
+		return nil, err
+	}
+	todo := before.Clone()
+	todo.DeletedAt = nil
+	todo.Version = version + 1
+	uc.record(actor, domain.HistoryRestored, before, todo)
+	if todo.ProjectID != "" && uc.projects != nil {
+		exists, err := uc.projects.HasProject(todo.Owner, todo.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return uc.mutate(actor, id, todo.Version, func(todo *domain.Todo) error {
+				todo.ProjectID = ""
+				return nil
+			})
+		}
+	}
+	return present(todo), nil
 }
 
-func (uc *TodoUseCase) DeleteTodo(id string) error {
-	return uc.repo.DeleteByID(id)
+// PurgeTodo permanently deletes a trashed todo. A non-zero version must match.
+// Its history is kept.
+func (uc *TodoUseCase) PurgeTodo(actor, id string, version int64) error {
+	before, err := uc.repo.FindTrashedByID(id)
+	if err != nil {
+		return err
+	}
+	if err := uc.authorize(actor, before, ownerOnly); err != nil {
+		return err
+	}
+	if version == 0 {
+		version = before.Version
+	}
+	if err := uc.repo.DeleteByID(id, version); err != nil {
+		return err
+	}
+	uc.record(actor, domain.HistoryPurged, before, nil)
+	return nil
 }
 
-func (uc *TodoUseCase) GetTodoByID(id string) (*domain.Todo, error) {
+// GetTodoByID returns a todo of actor, or one shared with them.
+func (uc *TodoUseCase) GetTodoByID(actor, id string) (*domain.Todo, error) {
 	todo, err := uc.repo.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
-	return todo, nil
+	if err := uc.authorize(actor, todo, domain.PermissionView); err != nil {
+		return nil, err
+	}
+	return present(todo), nil
 }
 
-func (uc *TodoUseCase) UpdateTodo(id, title string, dueTime time.Time, done bool) error {
-	todo := &domain.Todo{
-		ID:      id,
-		Title:   title,
-		DueDate: dueTime,
-		Done:    done,
+// UpdateTodo replaces the todo's fields, then applies opts. The due date
+// stays all-day and in its time zone unless opts say otherwise. A non-zero
+// version must match the stored one, otherwise domain.ErrVersionConflict is
+// returned.
+func (uc *TodoUseCase) UpdateTodo(actor, id, title string, dueTime time.Time, done bool, version int64, opts ...TodoOption) (*domain.Todo, error) {
+	if err := validateTitle(title); err != nil {
+		return nil, err
+	}
+	return uc.mutate(actor, id, version, func(todo *domain.Todo) error {
+		todo.Title = title
+		if err := todo.SetDue(dueTime, todo.AllDay, todo.TimeZone); err != nil {
+			return err
+		}
+		todo.SetDone(done, time.Now())
+		for _, opt := range opts {
+			if err := opt(todo); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// TodoPatch lists the fields a patch changes; nil fields are left alone.
+type TodoPatch struct {
+	Title   *string
+	DueDate *time.Time
+	// AllDay and TimeZone change how the due date is read, see
+	// domain.Todo.SetDue.
+	AllDay   *bool
+	TimeZone *string
+	Done     *bool
+	Tags     *[]string
+	// Priority is a letter from A to Z, or empty to clear it.
+	Priority *string
+	// CompletedAt dates a completion that happened elsewhere, such as in an
+	// imported file; it is only used when Done is true.
+	CompletedAt *time.Time
+	// ProjectID moves the todo to a project of its owner, or to the inbox
+	// when empty.
+	ProjectID *string
+	// Assignee assigns the todo to a registered user, or to nobody when
+	// empty.
+	Assignee *string
+}
+
+// PatchTodo changes only the given fields. The write is checked against the
+// version the patch was applied to, so concurrent changes are never lost.
+func (uc *TodoUseCase) PatchTodo(actor, id string, patch TodoPatch, version int64) (*domain.Todo, error) {
+	// reject an invalid patch before looking the todo up
+	if err := patch.apply(&domain.Todo{}); err != nil {
+		return nil, err
+	}
+	return uc.mutate(actor, id, version, patch.apply)
+}
+
+// apply validates the patch and makes it to the todo.
+func (p TodoPatch) apply(todo *domain.Todo) error {
+	if p.Title != nil {
+		if err := validateTitle(*p.Title); err != nil {
+			return err
+		}
+		todo.Title = *p.Title
+	}
+	if p.DueDate != nil || p.AllDay != nil || p.TimeZone != nil {
+		due, allDay, timeZone := todo.DueDate, todo.AllDay, todo.TimeZone
+		if p.DueDate != nil {
+			due = *p.DueDate
+		}
+		if p.AllDay != nil {
+			allDay = *p.AllDay
+		}
+		if p.TimeZone != nil {
+			timeZone = *p.TimeZone
+		}
+		if err := todo.SetDue(due, allDay, timeZone); err != nil {
+			return err
+		}
+	}
+	if p.Done != nil {
+		todo.SetDone(*p.Done, time.Now())
+		if *p.Done && p.CompletedAt != nil {
+			completed := p.CompletedAt.UTC()
+			todo.CompletedAt = &completed
+		}
+	}
+	if p.Tags != nil {
+		tags, err := domain.NormalizeTags(*p.Tags)
+		if err != nil {
+			return err
+		}
+		todo.Tags = tags
+	}
+	if p.Priority != nil {
+		priority, err := domain.NormalizePriority(*p.Priority)
+		if err != nil {
+			return err
+		}
+		todo.Priority = priority
+	}
+	if p.ProjectID != nil {
+		todo.ProjectID = *p.ProjectID
+	}
+	if p.Assignee != nil {
+		todo.Assignee = strings.TrimSpace(*p.Assignee)
+	}
+	return nil
+}
+
+// mutate loads a live todo, applies change to a copy and writes it back
+// with a compare-and-swap on the version it was loaded at, recording the
+// difference in the history. A non-zero version must match the stored one.
+// Users the todo is shared with for editing can change it, but only the
+// owner can move it to another project.
+func (uc *TodoUseCase) mutate(actor, id string, version int64, change func(todo *domain.Todo) error) (*domain.Todo, error) {
+	before, err := uc.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.authorize(actor, before, domain.PermissionEdit); err != nil {
+		return nil, err
+	}
+	if version != 0 && before.Version != version {
+		return nil, domain.ErrVersionConflict
+	}
+	todo := before.Clone()
+	if err := change(todo); err != nil {
+		return nil, err
+	}
+	if todo.ProjectID != before.ProjectID {
+		if err := uc.authorize(actor, before, ownerOnly); err != nil {
+			return nil, err
+		}
+		if err := uc.checkProject(todo); err != nil {
+			return nil, err
+		}
+	}
+	if todo.Assignee != before.Assignee {
+		if err := uc.checkAssignee(todo); err != nil {
+			return nil, err
+		}
+	}
+	if todo.Title != before.Title {
+		if err := uc.mention(todo); err != nil {
+			return nil, err
+		}
+	}
+	next, err := nextOccurrence(before, todo)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.repo.UpdateByID(todo); err != nil {
+		return nil, err
+	}
+	uc.record(actor, domain.HistoryUpdated, before, todo)
+	if next != nil {
+		uc.saveOccurrence(actor, next)
+	}
+	return present(todo), nil
+}
+
+// GetHistory lists the recorded changes of a todo of actor, or of one
+// shared with them, most recent first.
+func (uc *TodoUseCase) GetHistory(actor, id string, page, limit int) (list []*domain.HistoryEntry, total int64, err error) {
+	owner, err := uc.historyOwner(id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := uc.authorize(actor, &domain.Todo{ID: id, Owner: owner}, domain.PermissionView); err != nil {
+		return nil, 0, err
+	}
+	return uc.history.FindByTodoID(id, page, limit)
+}
+
+// historyOwner finds the owner of a todo that has a history: a live or
+// trashed todo, or a purged one, whose owner the purge recorded.
+func (uc *TodoUseCase) historyOwner(id string) (string, error) {
+	todo, err := uc.repo.FindByID(id)
+	if errors.Is(err, domain.ErrNotFound) {
+		todo, err = uc.repo.FindTrashedByID(id)
+	}
+	if err == nil {
+		return todo.Owner, nil
+	}
+	if !errors.Is(err, domain.ErrNotFound) {
+		return "", err
+	}
+	latest, _, err := uc.history.FindByTodoID(id, 0, 1)
+	if err != nil {
+		return "", err
+	}
+	if len(latest) == 0 {
+		return "", domain.ErrNotFound
+	}
+	for _, change := range latest[0].Changes {
+		if change.Field == "owner" {
+			owner, _ := change.Before.(string)
+			return owner, nil
+		}
+	}
+	return "", nil
+}
+
+// record appends a history entry for a change that has already been
+// written and tells the subscribers about it; before is nil for creates and
+// after is nil for purges. Inside a batch, this waits until the batch is
+// committed.
+func (uc *TodoUseCase) record(actor string, action domain.HistoryAction, before, after *domain.Todo) {
+	event := domain.Event{Action: action, Actor: actor, At: time.Now(), Before: before, After: after}
+	if uc.deferred != nil {
+		*uc.deferred = append(*uc.deferred, event)
+		return
+	}
+	uc.publish(event)
+}
+
+// publish records an event in the history and passes it to the handlers.
+// A failure here must not undo the change, so it is only logged.
+func (uc *TodoUseCase) publish(event domain.Event) {
+	ref := event.Todo()
+	entry := &domain.HistoryEntry{
+		ID:      generateID(),
+		TodoID:  ref.ID,
+		Actor:   event.Actor,
+		Action:  event.Action,
+		At:      event.At,
+		Version: ref.Version,
+		Changes: domain.Diff(event.Before, event.After),
+		Owner:   ref.Owner,
+	}
+	if err := uc.history.Append(entry); err != nil {
+		log.Printf("todo history: append %s of %s: %v", event.Action, ref.ID, err)
+	}
+	if event.Action == domain.HistoryPurged && uc.shares != nil {
+		if err := uc.shares.DeleteByTodo(ref.ID); err != nil {
+			log.Printf("todo shares: delete shares of %s: %v", ref.ID, err)
+		}
+	}
+	if event.Action == domain.HistoryPurged && uc.comments != nil {
+		if err := uc.comments.DeleteByTodo(ref.ID); err != nil {
+			log.Printf("todo comments: delete comments of %s: %v", ref.ID, err)
+		}
+	}
+	if event.Action == domain.HistoryPurged && event.Before != nil {
+		for _, attachment := range event.Before.Attachments {
+			uc.deleteBlob(attachment.ID)
+		}
+	}
+	for _, h := range uc.handlers {
+		h.HandleTodoEvent(event)
+	}
+}
+
+// present fills in derived fields before a todo leaves the use case.
+func present(todo *domain.Todo) *domain.Todo {
+	todo.UpdateProgress()
+	return todo
+}
+
+func presentAll(todos []*domain.Todo) []*domain.Todo {
+	for _, todo := range todos {
+		present(todo)
+	}
+	return todos
+}
+
+// checkProject makes sure the todo can be filed under its project.
+func (uc *TodoUseCase) checkProject(todo *domain.Todo) error {
+	if todo.ProjectID == "" {
+		return nil
+	}
+	if uc.projects == nil {
+		return &domain.ValidationError{Field: "projectId", Message: "projects are not available"}
+	}
+	return uc.projects.CheckProject(todo.Owner, todo.ProjectID)
+}
+
+// checkAssignee makes sure the todo is assigned to a registered user, if
+// to anybody.
+func (uc *TodoUseCase) checkAssignee(todo *domain.Todo) error {
+	if todo.Assignee == "" {
+		return nil
+	}
+	if uc.users == nil {
+		return &domain.ValidationError{Field: "assignee", Message: "assignees are not available"}
+	}
+	exists, err := uc.users.Exists(todo.Assignee)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &domain.ValidationError{Field: "assignee", Message: "is not a registered user"}
+	}
+	return nil
+}
+
+// mention records the registered users the title of the todo mentions.
+func (uc *TodoUseCase) mention(todo *domain.Todo) error {
+	todo.Mentions = nil
+	if uc.users == nil {
+		return nil
+	}
+	for _, name := range domain.ParseMentions(todo.Title) {
+		exists, err := uc.users.Exists(name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			todo.Mentions = append(todo.Mentions, name)
+		}
+	}
+	return nil
+}
+
+func validateTitle(title string) error {
+	if strings.TrimSpace(title) == "" {
+		return &domain.ValidationError{Field: "title", Message: "must not be empty"}
 	}
-	return uc.repo.UpdateByID(todo)
+	return nil
 }
 
 func generateID() string {
@@ -3,6 +3,7 @@ package usecase
 import (
 	"testing"
 	"time"
+	"todo-app/internal/todo/domain"
 	"todo-app/internal/todo/infrastructure/repository"
 
 	"github.com/stretchr/testify/assert"
@@ -10,15 +11,15 @@ import (
 
 func TestCreateTodo(t *testing.T) {
 	repo := repository.NewMemoryTodoRepository()
-	uc := NewTodoUseCase(repo)
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
 
 	title := "Learn Clean Architecture"
 	dueDate := parseDate("2025-07-01")
 
-	err := uc.CreateTodo(title, dueDate, false)
+	err := uc.CreateTodo("tester", title, dueDate, false)
 	assert.NoError(t, err)
 
-	todos, total, err := uc.GetAllTodos(0, 10, "")
+	todos, total, err := uc.GetAllTodos(0, 10, domain.TodoFilter{})
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(todos))
 	assert.Equal(t, int64(1), total)
@@ -31,17 +32,17 @@ func TestCreateTodo(t *testing.T) {
 
 func TestGetAllTodos(t *testing.T) {
 	repo := repository.NewMemoryTodoRepository()
-	uc := NewTodoUseCase(repo)
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
 
-	todos, total, err := uc.GetAllTodos(0, 10, "")
+	todos, total, err := uc.GetAllTodos(0, 10, domain.TodoFilter{})
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(todos))
 	assert.Equal(t, int64(0), total)
 
 	title := "Learn Clean Architecture"
 	dueDate := parseDate("2025-07-01")
-	uc.CreateTodo(title, dueDate, false)
-	todos, total, err = uc.GetAllTodos(0, 10, "")
+	uc.CreateTodo("tester", title, dueDate, false)
+	todos, total, err = uc.GetAllTodos(0, 10, domain.TodoFilter{})
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(todos))
 	assert.Equal(t, int64(1), total)
@@ -49,16 +50,16 @@ func TestGetAllTodos(t *testing.T) {
 	assert.Equal(t, false, todos[0].Done)
 	assert.Equal(t, dueDate, todos[0].DueDate)
 
-	todos, total, err = uc.GetAllTodos(1, 10, "")
+	todos, total, err = uc.GetAllTodos(1, 10, domain.TodoFilter{})
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(todos))
 	assert.Equal(t, int64(1), total)
-	todos, total, err = uc.GetAllTodos(0, 10, "NonExistingTitle")
+	todos, total, err = uc.GetAllTodos(0, 10, domain.TodoFilter{Title: "NonExistingTitle"})
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(todos))
 	assert.Equal(t, int64(0), total)
 
-	todos, total, err = uc.GetAllTodos(0, 10, "Clean")
+	todos, total, err = uc.GetAllTodos(0, 10, domain.TodoFilter{Title: "Clean"})
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(todos))
 	assert.Equal(t, int64(1), total)
@@ -66,25 +67,25 @@ func TestGetAllTodos(t *testing.T) {
 
 func TestDeleteTodo(t *testing.T) {
 	repo := repository.NewMemoryTodoRepository()
-	uc := NewTodoUseCase(repo)
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
 
 	// Create a todo to delete
 	title := "Learn Clean Architecture"
 	dueDate := parseDate("2025-07-01")
-	err := uc.CreateTodo(title, dueDate, false)
+	err := uc.CreateTodo("tester", title, dueDate, false)
 	assert.NoError(t, err)
 
-	todos, total, err := uc.GetAllTodos(0, 10, "")
+	todos, total, err := uc.GetAllTodos(0, 10, domain.TodoFilter{})
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(todos))
 	assert.Equal(t, int64(1), total)
 
 	// Delete the todo
-	err = uc.DeleteTodo(todos[0].ID)
+	err = uc.DeleteTodo("tester", todos[0].ID, 0)
 	assert.NoError(t, err)
 
 	// Verify the todo is deleted
-	todos, total, err = uc.GetAllTodos(0, 10, "")
+	todos, total, err = uc.GetAllTodos(0, 10, domain.TodoFilter{})
 	assert.NoError(t, err)
 	assert.Equal(t, 0, len(todos))
 	assert.Equal(t, int64(0), total)
@@ -92,23 +93,23 @@ func TestDeleteTodo(t *testing.T) {
 
 func TestGetTodoByID(t *testing.T) {
 	repo := repository.NewMemoryTodoRepository()
-	uc := NewTodoUseCase(repo)
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
 
 	// Create a todo to find
 	title := "Learn Clean Architecture"
 	dueDate := parseDate("2025-07-01")
 	done := true
-	err := uc.CreateTodo(title, dueDate, done)
+	err := uc.CreateTodo("tester", title, dueDate, done)
 	assert.NoError(t, err)
 
-	todos, total, err := uc.GetAllTodos(0, 10, "")
+	todos, total, err := uc.GetAllTodos(0, 10, domain.TodoFilter{})
 
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(todos))
 	assert.Equal(t, int64(1), total)
 
 	// Find the todo by ID
-	foundTodo, err := uc.GetTodoByID(todos[0].ID)
+	foundTodo, err := uc.GetTodoByID("tester", todos[0].ID)
 	assert.NoError(t, err)
 	assert.NotNil(t, foundTodo)
 	assert.Equal(t, title, foundTodo.Title)
@@ -117,16 +118,16 @@ func TestGetTodoByID(t *testing.T) {
 
 func TestUpdateTodo(t *testing.T) {
 	repo := repository.NewMemoryTodoRepository()
-	uc := NewTodoUseCase(repo)
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
 
 	// Create a todo to update
 	title := "Learn Clean Architecture"
 	dueDate := parseDate("2025-07-01")
 	done := false
-	err := uc.CreateTodo(title, dueDate, done)
+	err := uc.CreateTodo("tester", title, dueDate, done)
 	assert.NoError(t, err)
 
-	todos, total, err := uc.GetAllTodos(0, 10, "")
+	todos, total, err := uc.GetAllTodos(0, 10, domain.TodoFilter{})
 	assert.NoError(t, err)
 	assert.Equal(t, 1, len(todos))
 	assert.Equal(t, false, todos[0].Done)
@@ -134,16 +135,301 @@ func TestUpdateTodo(t *testing.T) {
 
 	// Update the todo
 	todos[0].Title = "Learn Clean Architecture Updated"
-	err = uc.UpdateTodo(todos[0].ID, todos[0].Title, todos[0].DueDate, true)
+	_, err = uc.UpdateTodo("tester", todos[0].ID, todos[0].Title, todos[0].DueDate, true, 0)
 	assert.NoError(t, err)
 
 	// Verify the todo is updated
-	updatedTodo, err := uc.GetTodoByID(todos[0].ID)
+	updatedTodo, err := uc.GetTodoByID("tester", todos[0].ID)
 	assert.NoError(t, err)
 	assert.Equal(t, "Learn Clean Architecture Updated", updatedTodo.Title)
 	assert.Equal(t, true, updatedTodo.Done)
 }
+
+func TestUpdateTodo_VersionConflict(t *testing.T) {
+	repo := repository.NewMemoryTodoRepository()
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
+
+	err := uc.CreateTodo("tester", "Learn Clean Architecture", parseDate("2025-07-01"), false)
+	assert.NoError(t, err)
+	todos, _, err := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	assert.NoError(t, err)
+	id := todos[0].ID
+	assert.Equal(t, int64(1), todos[0].Version)
+
+	// First writer wins and bumps the version
+	updated, err := uc.UpdateTodo("tester", id, "First writer", todos[0].DueDate, false, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), updated.Version)
+
+	// Second writer still holds version 1 and must be rejected
+	_, err = uc.UpdateTodo("tester", id, "Second writer", todos[0].DueDate, false, 1)
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+	title := "Second writer"
+	_, err = uc.PatchTodo("tester", id, TodoPatch{Title: &title}, 1)
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+	err = uc.DeleteTodo("tester", id, 1)
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+
+	found, err := uc.GetTodoByID("tester", id)
+	assert.NoError(t, err)
+	assert.Equal(t, "First writer", found.Title)
+
+	// Retrying against the current version succeeds
+	done := true
+	patched, err := uc.PatchTodo("tester", id, TodoPatch{Done: &done}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "First writer", patched.Title)
+	assert.Equal(t, true, patched.Done)
+	assert.Equal(t, int64(3), patched.Version)
+	assert.NoError(t, uc.DeleteTodo("tester", id, 3))
+}
 func parseDate(dateStr string) time.Time {
 	t, _ := time.Parse("2006-01-02", dateStr)
 	return t
 }
+
+func TestTodoUseCase_Errors(t *testing.T) {
+	repo := repository.NewMemoryTodoRepository()
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
+
+	var invalid *domain.ValidationError
+	err := uc.CreateTodo("tester", "  ", parseDate("2025-07-01"), false)
+	assert.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "title", invalid.Field)
+
+	_, err = uc.GetTodoByID("tester", "missing")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = uc.UpdateTodo("tester", "missing", "title", parseDate("2025-07-01"), false, 0)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	err = uc.DeleteTodo("tester", "missing", 0)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestTrash(t *testing.T) {
+	repo := repository.NewMemoryTodoRepository()
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
+
+	assert.NoError(t, uc.CreateTodo("tester", "Keep me", parseDate("2025-07-01"), false))
+	assert.NoError(t, uc.CreateTodo("tester", "Trash me", parseDate("2025-07-02"), false))
+	todos, _, err := uc.GetAllTodos(0, 10, domain.TodoFilter{Title: "Trash"})
+	assert.NoError(t, err)
+	id := todos[0].ID
+
+	// Deleting moves the todo to the trash
+	assert.NoError(t, uc.DeleteTodo("tester", id, 0))
+	_, err = uc.GetTodoByID("tester", id)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, total, _ := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	assert.Equal(t, int64(1), total)
+	trash, total, err := uc.GetTrash("tester", 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, id, trash[0].ID)
+	assert.NotNil(t, trash[0].DeletedAt)
+
+	// Trashed todos cannot be edited, only restored or purged
+	_, err = uc.UpdateTodo("tester", id, "Edited", parseDate("2025-07-02"), false, 0)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, err = uc.RestoreTodo("tester", id, trash[0].Version-1)
+	assert.ErrorIs(t, err, domain.ErrVersionConflict)
+
+	restored, err := uc.RestoreTodo("tester", id, trash[0].Version)
+	assert.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, trash[0].Version+1, restored.Version)
+	_, total, _ = uc.GetTrash("tester", 0, 10)
+	assert.Equal(t, int64(0), total)
+
+	// Live todos cannot be purged directly
+	assert.ErrorIs(t, uc.PurgeTodo("tester", id, 0), domain.ErrNotFound)
+	assert.NoError(t, uc.DeleteTodo("tester", id, 0))
+	assert.NoError(t, uc.PurgeTodo("tester", id, 0))
+	_, err = uc.RestoreTodo("tester", id, 0)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestTrash_Purge(t *testing.T) {
+	repo := repository.NewMemoryTodoRepository()
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
+
+	assert.NoError(t, uc.CreateTodo("tester", "Old", parseDate("2025-07-01"), false))
+	todos, _, _ := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	old := todos[0].ID
+	assert.NoError(t, repo.TrashByID(old, 0, time.Now().Add(-48*time.Hour)))
+	assert.NoError(t, uc.CreateTodo("tester", "Recent", parseDate("2025-07-01"), false))
+	todos, _, _ = uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	assert.NoError(t, uc.DeleteTodo("tester", todos[0].ID, 0))
+
+	purged, err := uc.PurgeExpiredTrash(time.Now().Add(-24 * time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	trash, _, _ := uc.GetTrash("tester", 0, 10)
+	if assert.Len(t, trash, 1) {
+		assert.Equal(t, "Recent", trash[0].Title)
+	}
+	// the purge is in the history like any other
+	history, _, err := uc.GetHistory("tester", old, 0, 10)
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, history) {
+		assert.Equal(t, domain.HistoryPurged, history[0].Action)
+		assert.Equal(t, SweeperActor, history[0].Actor)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	repo := repository.NewMemoryTodoRepository()
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
+	uc.UseUsers(knownUsers{"bob": true})
+	uc.UseSharing(repository.NewMemoryShareRepository())
+
+	assert.NoError(t, uc.CreateTodo("alice", "Pay rent", parseDate("2025-07-01"), false))
+	todos, _, _ := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	id := todos[0].ID
+	_, err := uc.ShareTodo("alice", id, "bob", domain.PermissionEdit)
+	assert.NoError(t, err)
+
+	newDue := parseDate("2025-07-05")
+	_, err = uc.PatchTodo("bob", id, TodoPatch{DueDate: &newDue}, 0)
+	assert.NoError(t, err)
+	_, err = uc.UpdateTodo("bob", id, "Pay rent", newDue, true, 0)
+	assert.NoError(t, err)
+	assert.NoError(t, uc.DeleteTodo("alice", id, 0))
+
+	entries, total, err := uc.GetHistory("bob", id, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+	if !assert.Len(t, entries, 4) {
+		return
+	}
+
+	// most recent first
+	assert.Equal(t, domain.HistoryDeleted, entries[0].Action)
+	assert.Equal(t, "alice", entries[0].Actor)
+	assert.Equal(t, int64(4), entries[0].Version)
+	if assert.Len(t, entries[0].Changes, 1) {
+		assert.Equal(t, "deletedAt", entries[0].Changes[0].Field)
+		assert.Nil(t, entries[0].Changes[0].Before)
+	}
+
+	assert.Equal(t, domain.HistoryUpdated, entries[1].Action)
+	assert.Equal(t, []domain.FieldChange{{Field: "done", Before: false, After: true}}, entries[1].Changes)
+
+	assert.Equal(t, domain.HistoryUpdated, entries[2].Action)
+	assert.Equal(t, "bob", entries[2].Actor)
+	assert.Equal(t, []domain.FieldChange{{Field: "dueDate", Before: parseDate("2025-07-01"), After: newDue}}, entries[2].Changes)
+
+	assert.Equal(t, domain.HistoryCreated, entries[3].Action)
+	assert.Equal(t, "alice", entries[3].Actor)
+	assert.Equal(t, int64(1), entries[3].Version)
+
+	// paging
+	page, total, err := uc.GetHistory("alice", id, 1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), total)
+	if assert.Len(t, page, 1) {
+		assert.Equal(t, domain.HistoryCreated, page[0].Action)
+	}
+
+	_, _, err = uc.GetHistory("alice", "missing", 0, 10)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	_, _, err = uc.GetHistory("carol", id, 0, 10)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestAllDayTodo(t *testing.T) {
+	repo := repository.NewMemoryTodoRepository()
+	uc := NewTodoUseCase(repo, repository.NewMemoryHistoryRepository())
+
+	taipei, _ := time.LoadLocation("Asia/Taipei")
+	friday := time.Date(2025, 7, 4, 0, 0, 0, 0, taipei)
+	assert.NoError(t, uc.CreateTodo("tester", "Ship release", friday, false, WithDueZone(true, "Asia/Taipei")))
+	todos, _, _ := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	id := todos[0].ID
+	assert.True(t, todos[0].AllDay)
+	assert.Equal(t, "Asia/Taipei", todos[0].TimeZone)
+	assert.Equal(t, parseDate("2025-07-04"), todos[0].DueDate)
+
+	// moving an all-day todo keeps it on a date, whatever time is given
+	due := time.Date(2025, 7, 5, 15, 30, 0, 0, taipei)
+	todo, err := uc.PatchTodo("tester", id, TodoPatch{DueDate: &due}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, parseDate("2025-07-05"), todo.DueDate)
+
+	// a put keeps the todo all-day unless told otherwise
+	todo, err = uc.UpdateTodo("tester", id, "Ship release", due, false, 0)
+	assert.NoError(t, err)
+	assert.True(t, todo.AllDay)
+	allDay, zone := false, ""
+	todo, err = uc.PatchTodo("tester", id, TodoPatch{AllDay: &allDay, TimeZone: &zone, DueDate: &due}, 0)
+	assert.NoError(t, err)
+	assert.False(t, todo.AllDay)
+	assert.True(t, due.Equal(todo.DueDate))
+
+	badZone := "Mars/Olympus"
+	_, err = uc.PatchTodo("tester", id, TodoPatch{TimeZone: &badZone}, 0)
+	var verr *domain.ValidationError
+	assert.ErrorAs(t, err, &verr)
+}
+
+func TestAssignee(t *testing.T) {
+	uc := NewTodoUseCase(repository.NewMemoryTodoRepository(), repository.NewMemoryHistoryRepository())
+	var invalid *domain.ValidationError
+	err := uc.CreateTodo("alice", "Fix the sink", time.Time{}, false, WithAssignee("bob"))
+	assert.ErrorAs(t, err, &invalid, "nobody can be assigned without users")
+
+	uc.UseUsers(knownUsers{"alice": true, "bob": true, "carol": true})
+	err = uc.CreateTodo("alice", "Fix the sink", time.Time{}, false, WithAssignee("dave"))
+	if assert.ErrorAs(t, err, &invalid) {
+		assert.Equal(t, "assignee", invalid.Field)
+	}
+	assert.NoError(t, uc.CreateTodo("alice", "Fix the sink", time.Time{}, false, WithAssignee("bob")))
+	assert.NoError(t, uc.CreateTodo("alice", "Water plants", time.Time{}, false))
+
+	mine, _, err := uc.GetAllTodos(0, 10, domain.TodoFilter{Assignee: "bob"})
+	assert.NoError(t, err)
+	if !assert.Len(t, mine, 1) {
+		return
+	}
+	id := mine[0].ID
+
+	// the assignee can work on the todo, but not delete it
+	done := true
+	_, err = uc.PatchTodo("bob", id, TodoPatch{Done: &done}, 0)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, uc.DeleteTodo("bob", id, 0), domain.ErrForbidden)
+	_, err = uc.GetTodoByID("carol", id)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	carol := "carol"
+	_, err = uc.PatchTodo("bob", id, TodoPatch{Assignee: &carol}, 0)
+	assert.NoError(t, err)
+	_, err = uc.GetTodoByID("bob", id)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	nobody := ""
+	todo, err := uc.PatchTodo("alice", id, TodoPatch{Assignee: &nobody}, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, todo.Assignee)
+}
+
+func TestMentions(t *testing.T) {
+	uc := NewTodoUseCase(repository.NewMemoryTodoRepository(), repository.NewMemoryHistoryRepository())
+	uc.UseUsers(knownUsers{"alice": true, "bob": true, "carol": true})
+
+	assert.NoError(t, uc.CreateTodo("alice", "Ask @bob and @nobody about the budget", time.Time{}, false))
+	todos, _, _ := uc.GetAllTodos(0, 10, domain.TodoFilter{})
+	if !assert.Len(t, todos, 1) {
+		return
+	}
+	assert.Equal(t, []string{"bob"}, todos[0].Mentions)
+
+	title := "Ask @carol about the budget"
+	todo, err := uc.PatchTodo("alice", todos[0].ID, TodoPatch{Title: &title}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"carol"}, todo.Mentions)
+	entries, _, err := uc.GetHistory("alice", todo.ID, 0, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "title", entries[0].Changes[0].Field, "mentions follow the title and are not tracked apart")
+		assert.Len(t, entries[0].Changes, 1)
+	}
+}
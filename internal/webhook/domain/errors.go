@@ -0,0 +1,25 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when the user has no webhook subscription with
+	// the requested ID.
+	ErrNotFound = errors.New("webhook not found")
+	// ErrDeliveryNotFound is returned when a subscription has no delivery
+	// with the requested ID.
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrDisabled is returned when replaying a delivery of a subscription
+	// that is disabled; it has to be re-enabled first.
+	ErrDisabled = errors.New("webhook is disabled")
+)
+
+// ValidationError reports a webhook field that does not satisfy the domain rules.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// SubscriptionRepository stores webhook subscriptions. All lookups are
+// scoped to the owning user.
+type SubscriptionRepository interface {
+	Save(sub *Subscription) error
+	FindByID(owner, id string) (*Subscription, error)
+	// Get returns a subscription whoever owns it, for the dispatcher.
+	Get(id string) (*Subscription, error)
+	FindByOwner(owner string) ([]*Subscription, error)
+	// FindActive returns the active subscriptions of a user.
+	FindActive(owner string) ([]*Subscription, error)
+	// Update writes URL, Events, Active, ConsecutiveFailures and DisabledAt.
+	Update(sub *Subscription) error
+	DeleteByID(owner, id string) error
+
+	// RecordSuccess resets the failure count of a subscription.
+	RecordSuccess(id string) error
+	// RecordFailure counts a delivery that failed for good and disables the
+	// subscription once the count reaches disableAfter. It returns the
+	// updated subscription.
+	RecordFailure(id string, disableAfter int, at time.Time) (*Subscription, error)
+}
+
+// DeliveryRepository stores the delivery log. The dispatcher finds work
+// through ClaimDue, which leases deliveries so an interrupted attempt is
+// retried once the lease runs out; delivery is at-least-once.
+type DeliveryRepository interface {
+	Save(d *Delivery) error
+	FindByID(subscriptionID, id string) (*Delivery, error)
+	// FindBySubscription lists a subscription's deliveries, newest first.
+	FindBySubscription(subscriptionID string, page, limit int) (list []*Delivery, total int64, err error)
+	DeleteBySubscription(subscriptionID string) error
+
+	// ClaimDue returns up to limit pending deliveries whose next attempt is
+	// at or before now, and leases them until now+lease.
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]*Delivery, error)
+	// Update writes the outcome of an attempt (Status, Attempts,
+	// ResponseStatus, LastError, DeliveredAt and NextAttemptAt).
+	Update(d *Delivery) error
+}
+
+// Sender POSTs a signed delivery to a subscription. status is the HTTP
+// status received, or zero when no response arrived.
+type Sender interface {
+	Send(sub *Subscription, d *Delivery) (status int, err error)
+}
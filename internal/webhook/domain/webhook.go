@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+	todoDomain "todo-app/internal/todo/domain"
+)
+
+// EventType names a todo event that subscriptions can receive.
+type EventType string
+
+const (
+	EventCreated   EventType = "todo.created"
+	EventUpdated   EventType = "todo.updated"
+	EventCompleted EventType = "todo.completed"
+	EventDeleted   EventType = "todo.deleted"
+	EventRestored  EventType = "todo.restored"
+	EventPurged    EventType = "todo.purged"
+)
+
+// EventTypes lists every event type a subscription can ask for.
+var EventTypes = []EventType{EventCreated, EventUpdated, EventCompleted, EventDeleted, EventRestored, EventPurged}
+
+// Subscription asks for the events of a user's todos to be POSTed to URL.
+// An empty Events list subscribes to all of them. Subscriptions whose
+// deliveries keep failing are disabled and have to be re-enabled by their
+// owner.
+type Subscription struct {
+	ID     string      `json:"id"`
+	Owner  string      `json:"owner" readOnly:"true" doc:"User the subscription belongs to; only events of that user's todos are sent"`
+	URL    string      `json:"url" example:"https://example.com/hooks/todo" doc:"URL deliveries are POSTed to"`
+	Events []EventType `json:"events" example:"[\"todo.created\",\"todo.completed\"]" doc:"Event types to deliver; empty means all"`
+	// Secret signs the deliveries. It is only shown when the subscription
+	// is created.
+	Secret string `json:"-"`
+	Active bool   `json:"active" doc:"Whether deliveries are sent; cleared automatically after repeated failures"`
+	// ConsecutiveFailures counts deliveries that failed for good since the
+	// last successful one.
+	ConsecutiveFailures int        `json:"consecutiveFailures" readOnly:"true"`
+	DisabledAt          *time.Time `json:"disabledAt,omitempty" readOnly:"true" doc:"When the subscription was disabled because of failing deliveries"`
+	CreatedAt           time.Time  `json:"createdAt" readOnly:"true"`
+}
+
+// Wants reports whether the subscription receives events of the given type.
+func (s *Subscription) Wants(t EventType) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, e := range s.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryStatus is where a delivery is in its lifecycle.
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// Delivery is one event sent, or to be sent, to one subscription. The
+// deliveries of a subscription form its delivery log.
+type Delivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscriptionId"`
+	EventID        string          `json:"eventId" doc:"ID of the event; replays and retries keep it, so receivers can deduplicate on it"`
+	Event          EventType       `json:"event"`
+	Payload        json.RawMessage `json:"payload" doc:"JSON body that is POSTed"`
+	Status         DeliveryStatus  `json:"status" enum:"pending,succeeded,failed"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"responseStatus,omitempty" doc:"HTTP status of the last attempt"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt" doc:"When the next attempt is due, if pending"`
+	// ReplayOf is the delivery this one replays.
+	ReplayOf string `json:"replayOf,omitempty"`
+}
+
+// Payload is the JSON body of a delivery.
+type Payload struct {
+	ID         string           `json:"id"`
+	Type       EventType        `json:"type"`
+	OccurredAt time.Time        `json:"occurredAt"`
+	Actor      string           `json:"actor"`
+	Todo       *todoDomain.Todo `json:"todo"`
+}
+
+// Sign computes the signature sent with a delivery: the hex HMAC-SHA256,
+// keyed with the subscription secret, of the Unix timestamp, a dot and the
+// body. Covering the timestamp lets receivers reject replayed requests.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
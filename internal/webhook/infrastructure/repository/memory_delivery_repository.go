@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+	"todo-app/internal/webhook/domain"
+)
+
+type MemoryDeliveryRepository struct {
+	mu         sync.Mutex
+	deliveries map[string]*domain.Delivery
+	// order keeps the IDs in insertion order for the newest-first log
+	order []string
+}
+
+func NewMemoryDeliveryRepository() *MemoryDeliveryRepository {
+	return &MemoryDeliveryRepository{deliveries: map[string]*domain.Delivery{}}
+}
+
+func (r *MemoryDeliveryRepository) Save(d *domain.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *d
+	if _, ok := r.deliveries[d.ID]; !ok {
+		r.order = append(r.order, d.ID)
+	}
+	r.deliveries[d.ID] = &stored
+	return nil
+}
+
+func (r *MemoryDeliveryRepository) FindByID(subscriptionID, id string) (*domain.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.deliveries[id]
+	if !ok || d.SubscriptionID != subscriptionID {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	copy := *d
+	return &copy, nil
+}
+
+func (r *MemoryDeliveryRepository) FindBySubscription(subscriptionID string, page, limit int) (list []*domain.Delivery, total int64, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list = []*domain.Delivery{}
+	skip := page * limit
+	for i := len(r.order) - 1; i >= 0; i-- {
+		d, ok := r.deliveries[r.order[i]]
+		if !ok || d.SubscriptionID != subscriptionID {
+			continue
+		}
+		total++
+		if page < 0 || limit <= 0 || total <= int64(skip) || len(list) >= limit {
+			continue
+		}
+		copy := *d
+		list = append(list, &copy)
+	}
+	return list, total, nil
+}
+
+func (r *MemoryDeliveryRepository) DeleteBySubscription(subscriptionID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	order := r.order[:0]
+	for _, id := range r.order {
+		if r.deliveries[id].SubscriptionID == subscriptionID {
+			delete(r.deliveries, id)
+			continue
+		}
+		order = append(order, id)
+	}
+	r.order = order
+	return nil
+}
+
+func (r *MemoryDeliveryRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]*domain.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	due := []*domain.Delivery{}
+	for _, d := range r.deliveries {
+		if d.Status == domain.DeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(due[j].NextAttemptAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	claimed := make([]*domain.Delivery, 0, len(due))
+	for _, d := range due {
+		d.NextAttemptAt = now.Add(lease)
+		copy := *d
+		claimed = append(claimed, &copy)
+	}
+	return claimed, nil
+}
+
+func (r *MemoryDeliveryRepository) Update(d *domain.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.deliveries[d.ID]
+	if !ok {
+		return domain.ErrDeliveryNotFound
+	}
+	stored.Status = d.Status
+	stored.Attempts = d.Attempts
+	stored.ResponseStatus = d.ResponseStatus
+	stored.LastError = d.LastError
+	stored.DeliveredAt = d.DeliveredAt
+	stored.NextAttemptAt = d.NextAttemptAt
+	return nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+	"todo-app/internal/webhook/domain"
+)
+
+type MemorySubscriptionRepository struct {
+	mu   sync.Mutex
+	subs map[string]*domain.Subscription
+}
+
+func NewMemorySubscriptionRepository() *MemorySubscriptionRepository {
+	return &MemorySubscriptionRepository{subs: map[string]*domain.Subscription{}}
+}
+
+func (r *MemorySubscriptionRepository) Save(sub *domain.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subs[sub.ID] = cloneSubscription(sub)
+	return nil
+}
+
+func (r *MemorySubscriptionRepository) FindByID(owner, id string) (*domain.Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sub, ok := r.subs[id]
+	if !ok || sub.Owner != owner {
+		return nil, domain.ErrNotFound
+	}
+	return cloneSubscription(sub), nil
+}
+
+func (r *MemorySubscriptionRepository) Get(id string) (*domain.Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sub, ok := r.subs[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return cloneSubscription(sub), nil
+}
+
+func (r *MemorySubscriptionRepository) FindByOwner(owner string) ([]*domain.Subscription, error) {
+	return r.find(func(sub *domain.Subscription) bool { return sub.Owner == owner }), nil
+}
+
+func (r *MemorySubscriptionRepository) FindActive(owner string) ([]*domain.Subscription, error) {
+	return r.find(func(sub *domain.Subscription) bool { return sub.Owner == owner && sub.Active }), nil
+}
+
+func (r *MemorySubscriptionRepository) find(match func(*domain.Subscription) bool) []*domain.Subscription {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := []*domain.Subscription{}
+	for _, sub := range r.subs {
+		if match(sub) {
+			list = append(list, cloneSubscription(sub))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+func (r *MemorySubscriptionRepository) Update(sub *domain.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.subs[sub.ID]
+	if !ok || stored.Owner != sub.Owner {
+		return domain.ErrNotFound
+	}
+	updated := cloneSubscription(sub)
+	updated.Secret = stored.Secret
+	updated.CreatedAt = stored.CreatedAt
+	r.subs[sub.ID] = updated
+	return nil
+}
+
+func (r *MemorySubscriptionRepository) DeleteByID(owner, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sub, ok := r.subs[id]
+	if !ok || sub.Owner != owner {
+		return domain.ErrNotFound
+	}
+	delete(r.subs, id)
+	return nil
+}
+
+func (r *MemorySubscriptionRepository) RecordSuccess(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sub, ok := r.subs[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	sub.ConsecutiveFailures = 0
+	return nil
+}
+
+func (r *MemorySubscriptionRepository) RecordFailure(id string, disableAfter int, at time.Time) (*domain.Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sub, ok := r.subs[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	sub.ConsecutiveFailures++
+	if sub.Active && sub.ConsecutiveFailures >= disableAfter {
+		sub.Active = false
+		sub.DisabledAt = &at
+	}
+	return cloneSubscription(sub), nil
+}
+
+func cloneSubscription(sub *domain.Subscription) *domain.Subscription {
+	c := *sub
+	c.Events = append([]domain.EventType(nil), sub.Events...)
+	if sub.DisabledAt != nil {
+		d := *sub.DisabledAt
+		c.DisabledAt = &d
+	}
+	return &c
+}
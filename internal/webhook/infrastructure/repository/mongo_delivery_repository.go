@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo-app/internal/webhook/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoDeliveryRepository implements domain.DeliveryRepository using MongoDB.
+type MongoDeliveryRepository struct {
+	collection *mongo.Collection
+}
+
+// deliveryDocument is the persisted shape of a delivery in the
+// "webhook_deliveries" collection. The payload is kept as the exact JSON
+// that is sent, so replays are byte for byte identical.
+type deliveryDocument struct {
+	ID             string     `bson:"_id"`
+	SubscriptionID string     `bson:"subscriptionId"`
+	EventID        string     `bson:"eventId"`
+	Event          string     `bson:"event"`
+	Payload        string     `bson:"payload"`
+	Status         string     `bson:"status"`
+	Attempts       int        `bson:"attempts"`
+	ResponseStatus int        `bson:"responseStatus,omitempty"`
+	LastError      string     `bson:"lastError,omitempty"`
+	CreatedAt      time.Time  `bson:"createdAt"`
+	DeliveredAt    *time.Time `bson:"deliveredAt,omitempty"`
+	NextAttemptAt  time.Time  `bson:"nextAttemptAt"`
+	ReplayOf       string     `bson:"replayOf,omitempty"`
+}
+
+func (d *deliveryDocument) toDomain() *domain.Delivery {
+	return &domain.Delivery{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		Event:          domain.EventType(d.Event),
+		Payload:        []byte(d.Payload),
+		Status:         domain.DeliveryStatus(d.Status),
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+		NextAttemptAt:  d.NextAttemptAt,
+		ReplayOf:       d.ReplayOf,
+	}
+}
+
+// NewMongoDeliveryRepository creates the repository and ensures the indexes
+// for the delivery log and for finding due deliveries.
+func NewMongoDeliveryRepository(db *mongo.Database) *MongoDeliveryRepository {
+	coll := db.Collection("webhook_deliveries")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, _ = coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "subscriptionId", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("subscriptionId_createdAt"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}},
+			Options: options.Index().SetName("status_nextAttemptAt"),
+		},
+	})
+	return &MongoDeliveryRepository{collection: coll}
+}
+
+func (r *MongoDeliveryRepository) Save(d *domain.Delivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := r.collection.InsertOne(ctx, deliveryDocument{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		Event:          string(d.Event),
+		Payload:        string(d.Payload),
+		Status:         string(d.Status),
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+		NextAttemptAt:  d.NextAttemptAt,
+		ReplayOf:       d.ReplayOf,
+	})
+	return err
+}
+
+func (r *MongoDeliveryRepository) FindByID(subscriptionID, id string) (*domain.Delivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var doc deliveryDocument
+	err := r.collection.FindOne(ctx, bson.M{"_id": id, "subscriptionId": subscriptionID}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc.toDomain(), nil
+}
+
+func (r *MongoDeliveryRepository) FindBySubscription(subscriptionID string, page, limit int) (list []*domain.Delivery, total int64, err error) {
+	skip := int64(page * limit)
+	qLimit := int64(limit)
+	filter := bson.M{"subscriptionId": subscriptionID}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	total, err = r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	cursor, err := r.collection.Find(ctx, filter, &options.FindOptions{
+		Sort:  bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}},
+		Skip:  &skip,
+		Limit: &qLimit,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+	list = []*domain.Delivery{}
+	for cursor.Next(ctx) {
+		var doc deliveryDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, total, err
+		}
+		list = append(list, doc.toDomain())
+	}
+	return list, total, cursor.Err()
+}
+
+func (r *MongoDeliveryRepository) DeleteBySubscription(subscriptionID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := r.collection.DeleteMany(ctx, bson.M{"subscriptionId": subscriptionID})
+	return err
+}
+
+func (r *MongoDeliveryRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]*domain.Delivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	filter := bson.M{"status": string(domain.DeliveryPending), "nextAttemptAt": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"nextAttemptAt": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}}).
+		SetReturnDocument(options.After)
+	// claim one at a time so each lease is taken atomically
+	claimed := []*domain.Delivery{}
+	for len(claimed) < limit {
+		var doc deliveryDocument
+		err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&doc)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			break
+		}
+		if err != nil {
+			return claimed, err
+		}
+		claimed = append(claimed, doc.toDomain())
+	}
+	return claimed, nil
+}
+
+func (r *MongoDeliveryRepository) Update(d *domain.Delivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := r.collection.UpdateByID(ctx, d.ID, bson.M{"$set": bson.M{
+		"status":         string(d.Status),
+		"attempts":       d.Attempts,
+		"responseStatus": d.ResponseStatus,
+		"lastError":      d.LastError,
+		"deliveredAt":    d.DeliveredAt,
+		"nextAttemptAt":  d.NextAttemptAt,
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrDeliveryNotFound
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo-app/internal/webhook/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoSubscriptionRepository implements domain.SubscriptionRepository
+// using MongoDB.
+type MongoSubscriptionRepository struct {
+	collection *mongo.Collection
+}
+
+// subscriptionDocument is the persisted shape of a subscription in the
+// "webhooks" collection.
+type subscriptionDocument struct {
+	ID                  string     `bson:"_id"`
+	Owner               string     `bson:"owner"`
+	URL                 string     `bson:"url"`
+	Events              []string   `bson:"events"`
+	Secret              string     `bson:"secret"`
+	Active              bool       `bson:"active"`
+	ConsecutiveFailures int        `bson:"consecutiveFailures"`
+	DisabledAt          *time.Time `bson:"disabledAt,omitempty"`
+	CreatedAt           time.Time  `bson:"createdAt"`
+}
+
+func newSubscriptionDocument(sub *domain.Subscription) subscriptionDocument {
+	doc := subscriptionDocument{
+		ID:                  sub.ID,
+		Owner:               sub.Owner,
+		URL:                 sub.URL,
+		Events:              make([]string, 0, len(sub.Events)),
+		Secret:              sub.Secret,
+		Active:              sub.Active,
+		ConsecutiveFailures: sub.ConsecutiveFailures,
+		DisabledAt:          sub.DisabledAt,
+		CreatedAt:           sub.CreatedAt,
+	}
+	for _, e := range sub.Events {
+		doc.Events = append(doc.Events, string(e))
+	}
+	return doc
+}
+
+func (d *subscriptionDocument) toDomain() *domain.Subscription {
+	sub := &domain.Subscription{
+		ID:                  d.ID,
+		Owner:               d.Owner,
+		URL:                 d.URL,
+		Secret:              d.Secret,
+		Active:              d.Active,
+		ConsecutiveFailures: d.ConsecutiveFailures,
+		DisabledAt:          d.DisabledAt,
+		CreatedAt:           d.CreatedAt,
+	}
+	for _, e := range d.Events {
+		sub.Events = append(sub.Events, domain.EventType(e))
+	}
+	return sub
+}
+
+// NewMongoSubscriptionRepository creates the repository and ensures an index
+// for looking up a user's subscriptions.
+func NewMongoSubscriptionRepository(db *mongo.Database) *MongoSubscriptionRepository {
+	coll := db.Collection("webhooks")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, _ = coll.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "owner", Value: 1}, {Key: "createdAt", Value: 1}},
+		Options: options.Index().SetName("owner_createdAt"),
+	})
+	return &MongoSubscriptionRepository{collection: coll}
+}
+
+func (r *MongoSubscriptionRepository) Save(sub *domain.Subscription) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := r.collection.InsertOne(ctx, newSubscriptionDocument(sub))
+	return err
+}
+
+func (r *MongoSubscriptionRepository) FindByID(owner, id string) (*domain.Subscription, error) {
+	return r.findOne(bson.M{"_id": id, "owner": owner})
+}
+
+func (r *MongoSubscriptionRepository) Get(id string) (*domain.Subscription, error) {
+	return r.findOne(bson.M{"_id": id})
+}
+
+func (r *MongoSubscriptionRepository) findOne(filter bson.M) (*domain.Subscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var doc subscriptionDocument
+	err := r.collection.FindOne(ctx, filter).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc.toDomain(), nil
+}
+
+func (r *MongoSubscriptionRepository) FindByOwner(owner string) ([]*domain.Subscription, error) {
+	return r.find(bson.M{"owner": owner})
+}
+
+func (r *MongoSubscriptionRepository) FindActive(owner string) ([]*domain.Subscription, error) {
+	return r.find(bson.M{"owner": owner, "active": true})
+}
+
+func (r *MongoSubscriptionRepository) find(filter bson.M) ([]*domain.Subscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	list := []*domain.Subscription{}
+	for cursor.Next(ctx) {
+		var doc subscriptionDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		list = append(list, doc.toDomain())
+	}
+	return list, cursor.Err()
+}
+
+func (r *MongoSubscriptionRepository) Update(sub *domain.Subscription) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	doc := newSubscriptionDocument(sub)
+	res, err := r.collection.UpdateOne(ctx, bson.M{"_id": sub.ID, "owner": sub.Owner}, bson.M{"$set": bson.M{
+		"url":                 doc.URL,
+		"events":              doc.Events,
+		"active":              doc.Active,
+		"consecutiveFailures": doc.ConsecutiveFailures,
+		"disabledAt":          doc.DisabledAt,
+	}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoSubscriptionRepository) DeleteByID(owner, id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "owner": owner})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *MongoSubscriptionRepository) RecordSuccess(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := r.collection.UpdateByID(ctx, id, bson.M{"$set": bson.M{"consecutiveFailures": 0}})
+	return err
+}
+
+func (r *MongoSubscriptionRepository) RecordFailure(id string, disableAfter int, at time.Time) (*domain.Subscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// increment and disable in one atomic pipeline update
+	failures := bson.M{"$add": bson.A{"$consecutiveFailures", 1}}
+	disable := bson.M{"$and": bson.A{"$active", bson.M{"$gte": bson.A{failures, disableAfter}}}}
+	update := mongo.Pipeline{{{Key: "$set", Value: bson.M{
+		"consecutiveFailures": failures,
+		"disabledAt":          bson.M{"$cond": bson.A{disable, at, "$disabledAt"}},
+		"active":              bson.M{"$cond": bson.A{disable, false, "$active"}},
+	}}}}
+	var doc subscriptionDocument
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc.toDomain(), nil
+}
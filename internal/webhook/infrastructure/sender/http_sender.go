@@ -0,0 +1,58 @@
+package sender
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"todo-app/internal/webhook/domain"
+)
+
+// Headers sent with every delivery. Receivers verify a delivery by
+// recomputing domain.Sign over the timestamp header and the raw body with
+// their secret and comparing it to the signature header.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// HTTPSender POSTs deliveries and treats any 2xx response as success.
+type HTTPSender struct {
+	Client *http.Client
+	// Now is the clock used for the signature timestamp.
+	Now func() time.Time
+}
+
+func NewHTTPSender(timeout time.Duration) *HTTPSender {
+	return &HTTPSender{Client: &http.Client{Timeout: timeout}, Now: time.Now}
+}
+
+func (s *HTTPSender) Send(sub *domain.Subscription, d *domain.Delivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	// every attempt is signed afresh so its timestamp is current
+	now := s.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-app-webhooks/1")
+	req.Header.Set(HeaderEvent, string(d.Event))
+	req.Header.Set(HeaderDelivery, d.EventID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, "sha256="+domain.Sign(sub.Secret, now, d.Payload))
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// drain a little so the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,52 @@
+package sender
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-app/internal/webhook/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPSender_SignsDelivery(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	now := time.Unix(1700000000, 0)
+	s := NewHTTPSender(time.Second)
+	s.Now = func() time.Time { return now }
+	sub := &domain.Subscription{URL: ts.URL, Secret: "0123456789abcdef"}
+	d := &domain.Delivery{EventID: "evt-1", Event: domain.EventCreated, Payload: []byte(`{"id":"evt-1"}`)}
+
+	status, err := s.Send(sub, d)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, `{"id":"evt-1"}`, string(body))
+	assert.Equal(t, "todo.created", got.Header.Get(HeaderEvent))
+	assert.Equal(t, "evt-1", got.Header.Get(HeaderDelivery))
+	assert.Equal(t, "1700000000", got.Header.Get(HeaderTimestamp))
+	// HMAC-SHA256("0123456789abcdef", `1700000000.{"id":"evt-1"}`)
+	assert.Equal(t, "sha256="+domain.Sign("0123456789abcdef", now, body), got.Header.Get(HeaderSignature))
+	assert.Len(t, got.Header.Get(HeaderSignature), len("sha256=")+64)
+}
+
+func TestHTTPSender_FailsOnErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	status, err := NewHTTPSender(time.Second).Send(&domain.Subscription{URL: ts.URL}, &domain.Delivery{Payload: []byte(`{}`)})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusBadGateway, status)
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"todo-app/internal/webhook/domain"
+)
+
+type (
+	ListQueryParams struct {
+		Page  int `query:"page" doc:"Page number for pagination" example:"0"`
+		Limit int `query:"limit" doc:"Number of items per page" example:"10"`
+	}
+	CreateWebhookInput struct {
+		Body struct {
+			URL    string             `json:"url" doc:"URL deliveries are POSTed to" example:"https://example.com/hooks/todo"`
+			Events []domain.EventType `json:"events,omitempty" doc:"Event types to deliver; all when omitted" example:"[\"todo.created\",\"todo.completed\",\"todo.deleted\"]"`
+			Secret string             `json:"secret,omitempty" doc:"Secret deliveries are signed with; generated when omitted" example:"a-long-random-secret"`
+		}
+	}
+	WebhookInput struct {
+		ID string `path:"id" doc:"ID of the webhook"`
+	}
+	UpdateWebhookInput struct {
+		ID   string `path:"id" doc:"ID of the webhook"`
+		Body struct {
+			URL    *string             `json:"url,omitempty" doc:"New URL" example:"https://example.com/hooks/todo"`
+			Events *[]domain.EventType `json:"events,omitempty" doc:"New event types; an empty list means all"`
+			Active *bool               `json:"active,omitempty" doc:"Enable or disable deliveries; enabling resets the failure count" example:"true"`
+		}
+	}
+	ListDeliveriesInput struct {
+		ListQueryParams
+		ID string `path:"id" doc:"ID of the webhook"`
+	}
+	ReplayDeliveryInput struct {
+		ID         string `path:"id" doc:"ID of the webhook"`
+		DeliveryID string `path:"deliveryId" doc:"ID of the delivery to replay"`
+	}
+)
+
+type (
+	DeliveryPageMeta struct {
+		Page  int   `json:"page" example:"0" doc:"Current page number"`
+		Limit int   `json:"limit" example:"10" doc:"Number of items per page"`
+		Total int64 `json:"total" example:"100" doc:"Total number of items"`
+	}
+	CreateWebhookOutput struct {
+		Body struct {
+			Webhook *domain.Subscription `json:"webhook" doc:"Created webhook"`
+			Secret  string               `json:"secret" doc:"Signing secret; it is not shown again"`
+		}
+	}
+	WebhookOutput struct {
+		Body struct {
+			Webhook *domain.Subscription `json:"webhook" doc:"Webhook"`
+		}
+	}
+	ListWebhooksOutput struct {
+		Body struct {
+			Data []*domain.Subscription `json:"data" doc:"Webhooks of the caller"`
+		}
+	}
+	DeleteWebhookOutput struct {
+		Body struct {
+			Message string `json:"message" example:"Webhook deleted successfully" doc:"Confirmation message"`
+		}
+	}
+	ListDeliveriesOutput struct {
+		Body struct {
+			Data []*domain.Delivery `json:"data" doc:"Deliveries of the webhook, most recent first"`
+			Meta DeliveryPageMeta   `json:"meta" doc:"Pagination metadata"`
+		}
+	}
+	ReplayDeliveryOutput struct {
+		Body struct {
+			Delivery *domain.Delivery `json:"delivery" doc:"Queued replay"`
+		}
+	}
+)
@@ -0,0 +1,144 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"todo-app/internal/api/middleware"
+	"todo-app/internal/webhook/usecase"
+
+	"github.com/danielgtaylor/huma/v2"
+)
+
+type WebhookHandler struct {
+	uc *usecase.WebhookUseCase
+}
+
+func NewWebhookHandler(api huma.API, uc *usecase.WebhookUseCase) {
+	handler := &WebhookHandler{uc: uc}
+
+	grp := huma.NewGroup(api, "/webhooks")
+	myAuthSecurity := []map[string][]string{
+		{"myAuth": {}},
+	}
+	huma.Register(grp, huma.Operation{
+		OperationID: "create-webhook",
+		Summary:     "Subscribe a URL to events of your todos",
+		Description: "Deliveries are JSON POSTs signed with HMAC-SHA256: X-Webhook-Signature is sha256=hex(HMAC(secret, X-Webhook-Timestamp + \".\" + body)).",
+		Method:      http.MethodPost,
+		Path:        "",
+		Security:    myAuthSecurity,
+	}, handler.Create)
+	huma.Register(grp, huma.Operation{
+		OperationID: "list-webhooks",
+		Summary:     "List your webhooks",
+		Method:      http.MethodGet,
+		Path:        "",
+		Security:    myAuthSecurity,
+	}, handler.List)
+	huma.Register(grp, huma.Operation{
+		OperationID: "get-webhook",
+		Summary:     "Get a webhook",
+		Method:      http.MethodGet,
+		Path:        "/{id}",
+		Security:    myAuthSecurity,
+	}, handler.Get)
+	huma.Register(grp, huma.Operation{
+		OperationID: "update-webhook",
+		Summary:     "Update or re-enable a webhook",
+		Method:      http.MethodPatch,
+		Path:        "/{id}",
+		Security:    myAuthSecurity,
+	}, handler.Update)
+	huma.Register(grp, huma.Operation{
+		OperationID: "delete-webhook",
+		Summary:     "Delete a webhook and its delivery log",
+		Method:      http.MethodDelete,
+		Path:        "/{id}",
+		Security:    myAuthSecurity,
+	}, handler.Delete)
+	huma.Register(grp, huma.Operation{
+		OperationID: "list-webhook-deliveries",
+		Summary:     "List the deliveries of a webhook",
+		Method:      http.MethodGet,
+		Path:        "/{id}/deliveries",
+		Security:    myAuthSecurity,
+	}, handler.ListDeliveries)
+	huma.Register(grp, huma.Operation{
+		OperationID: "replay-webhook-delivery",
+		Summary:     "Send a delivery again",
+		Method:      http.MethodPost,
+		Path:        "/{id}/deliveries/{deliveryId}/replay",
+		Security:    myAuthSecurity,
+	}, handler.Replay)
+}
+
+func (h *WebhookHandler) Create(ctx context.Context, input *CreateWebhookInput) (*CreateWebhookOutput, error) {
+	sub, err := h.uc.CreateSubscription(middleware.UserID(ctx), input.Body.URL, input.Body.Events, input.Body.Secret)
+	if err != nil {
+		return nil, err
+	}
+	resp := &CreateWebhookOutput{}
+	resp.Body.Webhook = sub
+	resp.Body.Secret = sub.Secret
+	return resp, nil
+}
+
+func (h *WebhookHandler) List(ctx context.Context, input *struct{}) (*ListWebhooksOutput, error) {
+	subs, err := h.uc.ListSubscriptions(middleware.UserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListWebhooksOutput{}
+	resp.Body.Data = subs
+	return resp, nil
+}
+
+func (h *WebhookHandler) Get(ctx context.Context, input *WebhookInput) (*WebhookOutput, error) {
+	sub, err := h.uc.GetSubscription(middleware.UserID(ctx), input.ID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &WebhookOutput{}
+	resp.Body.Webhook = sub
+	return resp, nil
+}
+
+func (h *WebhookHandler) Update(ctx context.Context, input *UpdateWebhookInput) (*WebhookOutput, error) {
+	sub, err := h.uc.UpdateSubscription(middleware.UserID(ctx), input.ID, input.Body.URL, input.Body.Events, input.Body.Active)
+	if err != nil {
+		return nil, err
+	}
+	resp := &WebhookOutput{}
+	resp.Body.Webhook = sub
+	return resp, nil
+}
+
+func (h *WebhookHandler) Delete(ctx context.Context, input *WebhookInput) (*DeleteWebhookOutput, error) {
+	if err := h.uc.DeleteSubscription(middleware.UserID(ctx), input.ID); err != nil {
+		return nil, err
+	}
+	resp := &DeleteWebhookOutput{}
+	resp.Body.Message = "Webhook deleted successfully"
+	return resp, nil
+}
+
+func (h *WebhookHandler) ListDeliveries(ctx context.Context, input *ListDeliveriesInput) (*ListDeliveriesOutput, error) {
+	list, total, err := h.uc.ListDeliveries(middleware.UserID(ctx), input.ID, input.Page, input.Limit)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ListDeliveriesOutput{}
+	resp.Body.Data = list
+	resp.Body.Meta = DeliveryPageMeta{Page: input.Page, Limit: input.Limit, Total: total}
+	return resp, nil
+}
+
+func (h *WebhookHandler) Replay(ctx context.Context, input *ReplayDeliveryInput) (*ReplayDeliveryOutput, error) {
+	delivery, err := h.uc.ReplayDelivery(middleware.UserID(ctx), input.ID, input.DeliveryID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &ReplayDeliveryOutput{}
+	resp.Body.Delivery = delivery
+	return resp, nil
+}
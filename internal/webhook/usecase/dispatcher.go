@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"errors"
+	"log"
+	"time"
+	"todo-app/internal/delivery"
+	"todo-app/internal/webhook/domain"
+)
+
+// Dispatcher sends queued webhook deliveries in the background. Failed
+// attempts are retried with exponential backoff until MaxAttempts is
+// reached; a subscription is disabled once DisableAfter of its deliveries
+// in a row have failed for good.
+type Dispatcher struct {
+	*delivery.Worker[*domain.Delivery]
+	subs   domain.SubscriptionRepository
+	sender domain.Sender
+
+	// DisableAfter is the number of consecutive failed deliveries after
+	// which the subscription is disabled.
+	DisableAfter int
+}
+
+// NewDispatcher creates a dispatcher with sensible defaults for the lease,
+// batch size and backoff.
+func NewDispatcher(subs domain.SubscriptionRepository, deliveries domain.DeliveryRepository, sender domain.Sender, interval time.Duration, maxAttempts, disableAfter int) *Dispatcher {
+	d := &Dispatcher{subs: subs, sender: sender, DisableAfter: disableAfter}
+	d.Worker = delivery.NewWorker("webhooks", deliveries, d.deliver, interval, maxAttempts)
+	return d
+}
+
+func (d *Dispatcher) deliver(delivery *domain.Delivery, now time.Time) bool {
+	sub, err := d.subs.Get(delivery.SubscriptionID)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		delivery.Status = domain.DeliveryFailed
+		delivery.LastError = "webhook was deleted"
+	case err != nil:
+		// try again once the lease runs out
+		log.Printf("webhooks: load subscription %s: %v", delivery.SubscriptionID, err)
+		return false
+	case !sub.Active:
+		delivery.Status = domain.DeliveryFailed
+		delivery.LastError = "webhook is disabled"
+	default:
+		d.send(sub, delivery, now)
+	}
+	return true
+}
+
+func (d *Dispatcher) send(sub *domain.Subscription, delivery *domain.Delivery, now time.Time) {
+	delivery.Attempts++
+	status, err := d.sender.Send(sub, delivery)
+	delivery.ResponseStatus = status
+	if err == nil {
+		deliveredAt := now
+		delivery.Status = domain.DeliverySucceeded
+		delivery.DeliveredAt = &deliveredAt
+		delivery.LastError = ""
+		if err := d.subs.RecordSuccess(sub.ID); err != nil {
+			log.Printf("webhooks: record success of %s: %v", sub.ID, err)
+		}
+		return
+	}
+	delivery.LastError = err.Error()
+	if next, ok := d.Retry(delivery.Attempts, now); ok {
+		delivery.NextAttemptAt = next
+		return
+	}
+	delivery.Status = domain.DeliveryFailed
+	updated, err := d.subs.RecordFailure(sub.ID, d.DisableAfter, now)
+	if err != nil {
+		log.Printf("webhooks: record failure of %s: %v", sub.ID, err)
+		return
+	}
+	if !updated.Active && sub.Active {
+		log.Printf("webhooks: disabled %s after %d failed deliveries", sub.ID, updated.ConsecutiveFailures)
+	}
+}
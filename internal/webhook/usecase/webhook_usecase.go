@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+	todoDomain "todo-app/internal/todo/domain"
+	"todo-app/internal/webhook/domain"
+
+	"github.com/google/uuid"
+)
+
+// WebhookUseCase manages a user's webhook subscriptions and turns todo
+// events into deliveries for them.
+type WebhookUseCase struct {
+	subs       domain.SubscriptionRepository
+	deliveries domain.DeliveryRepository
+}
+
+func NewWebhookUseCase(subs domain.SubscriptionRepository, deliveries domain.DeliveryRepository) *WebhookUseCase {
+	return &WebhookUseCase{subs: subs, deliveries: deliveries}
+}
+
+// CreateSubscription subscribes owner's todo events. A secret is generated
+// when none is given; the returned subscription carries it in Secret, the
+// only time it is handed out.
+func (uc *WebhookUseCase) CreateSubscription(owner, rawURL string, events []domain.EventType, secret string) (*domain.Subscription, error) {
+	if err := validateURL(rawURL); err != nil {
+		return nil, err
+	}
+	events, err := validateEvents(events)
+	if err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		secret = generateSecret()
+	} else if len(secret) < 16 {
+		return nil, &domain.ValidationError{Field: "secret", Message: "must be at least 16 characters"}
+	}
+	sub := &domain.Subscription{
+		ID:        uuid.New().String(),
+		Owner:     owner,
+		URL:       rawURL,
+		Events:    events,
+		Secret:    secret,
+		Active:    true,
+		CreatedAt: time.Now(),
+	}
+	if err := uc.subs.Save(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (uc *WebhookUseCase) ListSubscriptions(owner string) ([]*domain.Subscription, error) {
+	return uc.subs.FindByOwner(owner)
+}
+
+func (uc *WebhookUseCase) GetSubscription(owner, id string) (*domain.Subscription, error) {
+	return uc.subs.FindByID(owner, id)
+}
+
+// UpdateSubscription changes the fields that are not nil. Setting active
+// re-enables a subscription that was disabled after failing deliveries
+// and gives it a clean failure count.
+func (uc *WebhookUseCase) UpdateSubscription(owner, id string, rawURL *string, events *[]domain.EventType, active *bool) (*domain.Subscription, error) {
+	sub, err := uc.subs.FindByID(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if rawURL != nil {
+		if err := validateURL(*rawURL); err != nil {
+			return nil, err
+		}
+		sub.URL = *rawURL
+	}
+	if events != nil {
+		if sub.Events, err = validateEvents(*events); err != nil {
+			return nil, err
+		}
+	}
+	if active != nil {
+		if *active && !sub.Active {
+			sub.ConsecutiveFailures = 0
+			sub.DisabledAt = nil
+		}
+		sub.Active = *active
+	}
+	if err := uc.subs.Update(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// DeleteSubscription removes a subscription together with its delivery log.
+func (uc *WebhookUseCase) DeleteSubscription(owner, id string) error {
+	if err := uc.subs.DeleteByID(owner, id); err != nil {
+		return err
+	}
+	return uc.deliveries.DeleteBySubscription(id)
+}
+
+// ListDeliveries returns the delivery log of a subscription, newest first.
+func (uc *WebhookUseCase) ListDeliveries(owner, id string, page, limit int) (list []*domain.Delivery, total int64, err error) {
+	if _, err := uc.subs.FindByID(owner, id); err != nil {
+		return nil, 0, err
+	}
+	return uc.deliveries.FindBySubscription(id, page, limit)
+}
+
+// ReplayDelivery queues the payload of an earlier delivery to be sent
+// again, whatever the outcome of the original. The replay keeps the event
+// ID so receivers can tell it is the same event.
+func (uc *WebhookUseCase) ReplayDelivery(owner, id, deliveryID string) (*domain.Delivery, error) {
+	sub, err := uc.subs.FindByID(owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if !sub.Active {
+		return nil, domain.ErrDisabled
+	}
+	original, err := uc.deliveries.FindByID(id, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	replay := newDelivery(sub.ID, original.EventID, original.Event, original.Payload, time.Now())
+	replay.ReplayOf = original.ID
+	if err := uc.deliveries.Save(replay); err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+// HandleTodoEvent queues a delivery of the event for every active
+// subscription of the todo's owner that asked for it.
+func (uc *WebhookUseCase) HandleTodoEvent(e todoDomain.Event) {
+	todo := e.Todo()
+	if todo.Owner == "" {
+		return
+	}
+	subs, err := uc.subs.FindActive(todo.Owner)
+	if err != nil {
+		log.Printf("webhooks: find subscriptions of %s: %v", todo.Owner, err)
+		return
+	}
+	typ := eventType(e)
+	payload := domain.Payload{ID: uuid.New().String(), Type: typ, OccurredAt: e.At, Actor: e.Actor, Todo: todo}
+	var body []byte
+	for _, sub := range subs {
+		if !sub.Wants(typ) {
+			continue
+		}
+		if body == nil {
+			if body, err = json.Marshal(payload); err != nil {
+				log.Printf("webhooks: encode %s of %s: %v", typ, todo.ID, err)
+				return
+			}
+		}
+		if err := uc.deliveries.Save(newDelivery(sub.ID, payload.ID, typ, body, e.At)); err != nil {
+			log.Printf("webhooks: queue %s of %s for %s: %v", typ, todo.ID, sub.ID, err)
+		}
+	}
+}
+
+// eventType tells completions apart from other updates.
+func eventType(e todoDomain.Event) domain.EventType {
+	switch e.Action {
+	case todoDomain.HistoryCreated:
+		return domain.EventCreated
+	case todoDomain.HistoryDeleted:
+		return domain.EventDeleted
+	case todoDomain.HistoryRestored:
+		return domain.EventRestored
+	case todoDomain.HistoryPurged:
+		return domain.EventPurged
+	}
+	if !e.Before.Done && e.After.Done {
+		return domain.EventCompleted
+	}
+	return domain.EventUpdated
+}
+
+func newDelivery(subscriptionID, eventID string, typ domain.EventType, payload []byte, at time.Time) *domain.Delivery {
+	return &domain.Delivery{
+		ID:             uuid.New().String(),
+		SubscriptionID: subscriptionID,
+		EventID:        eventID,
+		Event:          typ,
+		Payload:        payload,
+		Status:         domain.DeliveryPending,
+		CreatedAt:      at,
+		NextAttemptAt:  at,
+	}
+}
+
+func validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &domain.ValidationError{Field: "url", Message: "must be an http or https URL"}
+	}
+	return nil
+}
+
+// validateEvents checks the requested event types and drops duplicates.
+func validateEvents(events []domain.EventType) ([]domain.EventType, error) {
+	valid := []domain.EventType{}
+	seen := map[domain.EventType]bool{}
+	for _, e := range events {
+		known := false
+		for _, t := range domain.EventTypes {
+			known = known || e == t
+		}
+		if !known {
+			names := make([]string, 0, len(domain.EventTypes))
+			for _, t := range domain.EventTypes {
+				names = append(names, string(t))
+			}
+			return nil, &domain.ValidationError{Field: "events", Message: "unknown event " + string(e) + "; must be one of " + strings.Join(names, ", ")}
+		}
+		if !seen[e] {
+			seen[e] = true
+			valid = append(valid, e)
+		}
+	}
+	return valid, nil
+}
+
+func generateSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+	todoDomain "todo-app/internal/todo/domain"
+	todoRepository "todo-app/internal/todo/infrastructure/repository"
+	todoUsecase "todo-app/internal/todo/usecase"
+	"todo-app/internal/webhook/domain"
+	"todo-app/internal/webhook/infrastructure/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSender records deliveries and fails while failing is set.
+type fakeSender struct {
+	failing bool
+	sent    []*domain.Delivery
+}
+
+func (f *fakeSender) Send(_ *domain.Subscription, d *domain.Delivery) (int, error) {
+	if f.failing {
+		return 500, errors.New("webhook responded 500 Internal Server Error")
+	}
+	f.sent = append(f.sent, d)
+	return 204, nil
+}
+
+type fixture struct {
+	todos      *todoUsecase.TodoUseCase
+	subs       *repository.MemorySubscriptionRepository
+	deliveries *repository.MemoryDeliveryRepository
+	uc         *WebhookUseCase
+	sender     *fakeSender
+	dispatcher *Dispatcher
+}
+
+func newFixture() *fixture {
+	f := &fixture{
+		subs:       repository.NewMemorySubscriptionRepository(),
+		deliveries: repository.NewMemoryDeliveryRepository(),
+		sender:     &fakeSender{},
+	}
+	f.todos = todoUsecase.NewTodoUseCase(todoRepository.NewMemoryTodoRepository(), todoRepository.NewMemoryHistoryRepository())
+	f.uc = NewWebhookUseCase(f.subs, f.deliveries)
+	f.todos.Subscribe(f.uc)
+	f.dispatcher = NewDispatcher(f.subs, f.deliveries, f.sender, time.Second, 2, 2)
+	return f
+}
+
+func TestCreateSubscription_Validation(t *testing.T) {
+	f := newFixture()
+	var invalid *domain.ValidationError
+
+	_, err := f.uc.CreateSubscription("alice", "ftp://example.com", nil, "")
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "url", invalid.Field)
+
+	_, err = f.uc.CreateSubscription("alice", "https://example.com", []domain.EventType{"todo.exploded"}, "")
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "events", invalid.Field)
+
+	_, err = f.uc.CreateSubscription("alice", "https://example.com", nil, "short")
+	require.ErrorAs(t, err, &invalid)
+	assert.Equal(t, "secret", invalid.Field)
+
+	sub, err := f.uc.CreateSubscription("alice", "https://example.com", []domain.EventType{domain.EventCreated, domain.EventCreated}, "")
+	require.NoError(t, err)
+	assert.True(t, sub.Active)
+	assert.Equal(t, []domain.EventType{domain.EventCreated}, sub.Events)
+	assert.Regexp(t, "^whsec_[0-9a-f]{64}$", sub.Secret)
+
+	_, err = f.uc.GetSubscription("bob", sub.ID)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestWebhook_DeliversOwnersEvents(t *testing.T) {
+	f := newFixture()
+	sub, err := f.uc.CreateSubscription("alice", "https://example.com/hook", []domain.EventType{domain.EventCompleted, domain.EventDeleted}, "")
+	require.NoError(t, err)
+
+	require.NoError(t, f.todos.CreateTodo("alice", "Pay rent", time.Time{}, false))
+	require.NoError(t, f.todos.CreateTodo("bob", "Walk dog", time.Time{}, false))
+	todos, _, _ := f.todos.GetAllTodos(0, 10, todoDomain.TodoFilter{})
+	for _, todo := range todos {
+		done := true
+		_, err := f.todos.PatchTodo(todo.Owner, todo.ID, todoUsecase.TodoPatch{Done: &done}, 0)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, f.dispatcher.Pass(context.Background(), time.Now()))
+	require.Len(t, f.sender.sent, 1)
+	var payload struct {
+		Type  domain.EventType `json:"type"`
+		Actor string           `json:"actor"`
+		Todo  struct {
+			Title string `json:"title"`
+			Done  bool   `json:"done"`
+		} `json:"todo"`
+	}
+	require.NoError(t, json.Unmarshal(f.sender.sent[0].Payload, &payload))
+	assert.Equal(t, domain.EventCompleted, payload.Type)
+	assert.Equal(t, "alice", payload.Actor)
+	assert.Equal(t, "Pay rent", payload.Todo.Title)
+	assert.True(t, payload.Todo.Done)
+
+	log, total, err := f.uc.ListDeliveries("alice", sub.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, domain.DeliverySucceeded, log[0].Status)
+	assert.Equal(t, 204, log[0].ResponseStatus)
+}
+
+func TestWebhook_RetriesThenDisables(t *testing.T) {
+	f := newFixture()
+	sub, err := f.uc.CreateSubscription("alice", "https://example.com/hook", nil, "")
+	require.NoError(t, err)
+	f.sender.failing = true
+
+	// two events, each failing both of its attempts
+	require.NoError(t, f.todos.CreateTodo("alice", "One", time.Time{}, false))
+	require.NoError(t, f.todos.CreateTodo("alice", "Two", time.Time{}, false))
+	now := time.Now()
+	assert.Equal(t, 2, f.dispatcher.Pass(context.Background(), now))
+	assert.Equal(t, 0, f.dispatcher.Pass(context.Background(), now.Add(9*time.Second)), "backing off")
+	assert.Equal(t, 2, f.dispatcher.Pass(context.Background(), now.Add(10*time.Second)))
+
+	sub, err = f.uc.GetSubscription("alice", sub.ID)
+	require.NoError(t, err)
+	assert.False(t, sub.Active)
+	assert.NotNil(t, sub.DisabledAt)
+	assert.Equal(t, 2, sub.ConsecutiveFailures)
+
+	// nothing is queued for a disabled webhook, and replays are refused
+	require.NoError(t, f.todos.CreateTodo("alice", "Three", time.Time{}, false))
+	log, total, _ := f.uc.ListDeliveries("alice", sub.ID, 0, 10)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, domain.DeliveryFailed, log[0].Status)
+	_, err = f.uc.ReplayDelivery("alice", sub.ID, log[0].ID)
+	assert.ErrorIs(t, err, domain.ErrDisabled)
+
+	// re-enabling and replaying sends the same event again
+	active := true
+	sub, err = f.uc.UpdateSubscription("alice", sub.ID, nil, nil, &active)
+	require.NoError(t, err)
+	assert.Zero(t, sub.ConsecutiveFailures)
+	f.sender.failing = false
+	replay, err := f.uc.ReplayDelivery("alice", sub.ID, log[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, log[0].EventID, replay.EventID)
+	assert.Equal(t, log[0].ID, replay.ReplayOf)
+	assert.Equal(t, 1, f.dispatcher.Pass(context.Background(), time.Now()))
+	require.Len(t, f.sender.sent, 1)
+	assert.JSONEq(t, string(log[0].Payload), string(f.sender.sent[0].Payload))
+}
+
+func TestDeleteSubscription_RemovesLog(t *testing.T) {
+	f := newFixture()
+	sub, err := f.uc.CreateSubscription("alice", "https://example.com/hook", nil, "")
+	require.NoError(t, err)
+	require.NoError(t, f.todos.CreateTodo("alice", "One", time.Time{}, false))
+
+	require.NoError(t, f.uc.DeleteSubscription("alice", sub.ID))
+	list, total, err := f.deliveries.FindBySubscription(sub.ID, 0, 10)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, list)
+	assert.ErrorIs(t, f.uc.DeleteSubscription("alice", sub.ID), domain.ErrNotFound)
+}